@@ -7,6 +7,8 @@ import { getRole } from '../../../shared/utils/role.js';
 import {
   PAYROLL_PERIOD_DRAFT,
   PAYROLL_PERIOD_REVIEWED,
+  PAYROLL_PERIOD_PENDING_APPROVAL,
+  PAYROLL_PERIOD_APPROVED,
   PAYROLL_PERIOD_FINALIZED,
 } from '../../../shared/constants/statuses.js';
 import {
@@ -45,6 +47,7 @@ export default function Payroll() {
   const [groups, setGroups] = useState([]);
   const [elements, setElements] = useState([]);
   const [journalTemplates, setJournalTemplates] = useState([]);
+  const [payslipTemplates, setPayslipTemplates] = useState([]);
   const [periods, setPeriods] = useState([]);
   const [inputs, setInputs] = useState([]);
   const [adjustments, setAdjustments] = useState([]);
@@ -60,6 +63,7 @@ export default function Payroll() {
     name: '',
     elementType: 'earning',
     calcType: 'fixed',
+    rate: '',
     amount: '',
     taxable: true,
   });
@@ -79,8 +83,16 @@ export default function Payroll() {
     deductionAccount: '',
     cashAccount: '',
     headers: '',
+    format: 'csv',
   });
   const [journalTemplateId, setJournalTemplateId] = useState('');
+  const [payslipTemplateForm, setPayslipTemplateForm] = useState({
+    name: '',
+    companyName: '',
+    companyAddress: '',
+    footer: '',
+    isDefault: false,
+  });
   const [importFile, setImportFile] = useState(null);
 
   const scheduleLookup = useMemo(() => {
@@ -132,9 +144,10 @@ export default function Payroll() {
         api.get('/payroll/journal-templates'),
         api.get('/payroll/periods'),
         api.get(payslipPath),
+        api.get('/payroll/payslip-templates'),
       ]);
 
-      const setters = [setSchedules, setGroups, setElements, setJournalTemplates, setPeriods, setPayslips];
+      const setters = [setSchedules, setGroups, setElements, setJournalTemplates, setPeriods, setPayslips, setPayslipTemplates];
       results.forEach((result, idx) => {
         if (result.status === 'fulfilled') {
           setters[idx](Array.isArray(result.value) ? result.value : []);
@@ -242,9 +255,10 @@ export default function Payroll() {
         elementType: elementForm.elementType,
         calcType: elementForm.calcType,
         amount: Number(elementForm.amount || 0),
+        rate: Number(elementForm.rate || 0),
         taxable: elementForm.taxable,
       });
-      setElementForm({ name: '', elementType: 'earning', calcType: 'fixed', amount: '', taxable: true });
+      setElementForm({ name: '', elementType: 'earning', calcType: 'fixed', amount: '', rate: '', taxable: true });
       await loadBase();
     } catch (err) {
       setError(err.message);
@@ -264,6 +278,7 @@ export default function Payroll() {
           expenseAccount: journalTemplateForm.expenseAccount,
           deductionAccount: journalTemplateForm.deductionAccount,
           cashAccount: journalTemplateForm.cashAccount,
+          format: journalTemplateForm.format,
           headers,
         },
       });
@@ -273,6 +288,7 @@ export default function Payroll() {
         deductionAccount: '',
         cashAccount: '',
         headers: '',
+        format: 'csv',
       });
       await loadBase();
     } catch (err) {
@@ -280,6 +296,18 @@ export default function Payroll() {
     }
   };
 
+  const createPayslipTemplate = async (e) => {
+    e.preventDefault();
+    setError('');
+    try {
+      await api.post('/payroll/payslip-templates', payslipTemplateForm);
+      setPayslipTemplateForm({ name: '', companyName: '', companyAddress: '', footer: '', isDefault: false });
+      await loadBase();
+    } catch (err) {
+      setError(err.message);
+    }
+  };
+
   const createPeriod = async (e) => {
     e.preventDefault();
     setError('');
@@ -292,9 +320,50 @@ export default function Payroll() {
     }
   };
 
+  const waitForJobRun = async (runId) => {
+    for (let attempt = 0; attempt < 300; attempt += 1) {
+      const run = await api.get(`/reports/jobs/${runId}`);
+      if (run.status !== 'queued' && run.status !== 'running') {
+        return run;
+      }
+      await new Promise((resolve) => setTimeout(resolve, 1000));
+    }
+    return null;
+  };
+
   const runPayroll = async (id) => {
     try {
-      await api.post(`/payroll/periods/${id}/run`, {});
+      const result = await api.post(`/payroll/periods/${id}/run`, {});
+      if (result?.runId) {
+        const run = await waitForJobRun(result.runId);
+        if (run?.status === 'failed') {
+          setError(`Payroll run ${result.runId} failed for ${run.details?.failed || 0} employee(s); resume it once the issues are fixed.`);
+        }
+      }
+      await loadBase();
+      await loadPeriodDetails(id);
+    } catch (err) {
+      setError(err.message);
+    }
+  };
+
+  const submitPayroll = async (id) => {
+    try {
+      await api.post(`/payroll/periods/${id}/submit`, {});
+      await loadBase();
+      await loadPeriodDetails(id);
+    } catch (err) {
+      setError(err.message);
+    }
+  };
+
+  const decidePayroll = async (id, decision) => {
+    const comment = window.prompt(decision === 'reject' ? 'Reason for rejecting this payroll period?' : 'Approval comment (optional)');
+    if (comment === null || (decision === 'reject' && !comment)) {
+      return;
+    }
+    try {
+      await api.post(`/payroll/periods/${id}/${decision}`, { comment });
       await loadBase();
       await loadPeriodDetails(id);
     } catch (err) {
@@ -411,6 +480,15 @@ export default function Payroll() {
     }
   };
 
+  const exportPayments = async (id, format) => {
+    try {
+      const result = await api.download(`/payroll/periods/${id}/export/payments?format=${format}`);
+      downloadBlob(result);
+    } catch (err) {
+      setError(err.message);
+    }
+  };
+
   const downloadPayslip = async (id) => {
     try {
       const result = await api.download(`/payroll/payslips/${id}/download`);
@@ -537,16 +615,22 @@ export default function Payroll() {
                   <div key={period.id} className="table-row">
                     <span>{period.startDate?.slice(0, 10)} → {period.endDate?.slice(0, 10)}</span>
                     <span>{period.status}</span>
+                    {period.runType && period.runType !== 'regular' && <span>{period.runType} run</span>}
                     <span>{scheduleLookup[period.scheduleId] || period.scheduleId}</span>
                     <span className="row-actions">
                       <button onClick={() => setSelectedPeriodId(period.id)}>Details</button>
                       {isHR && (
                         <>
                           <button onClick={() => runPayroll(period.id)} disabled={period.status !== PAYROLL_PERIOD_DRAFT}>Run</button>
-                          <button onClick={() => finalizePayroll(period.id)} disabled={period.status !== PAYROLL_PERIOD_REVIEWED}>Finalize</button>
+                          <button onClick={() => submitPayroll(period.id)} disabled={period.status !== PAYROLL_PERIOD_REVIEWED}>Submit</button>
+                          <button onClick={() => decidePayroll(period.id, 'approve')} disabled={period.status !== PAYROLL_PERIOD_PENDING_APPROVAL}>Approve</button>
+                          <button onClick={() => decidePayroll(period.id, 'reject')} disabled={period.status !== PAYROLL_PERIOD_PENDING_APPROVAL}>Reject</button>
+                          <button onClick={() => finalizePayroll(period.id)} disabled={period.status !== PAYROLL_PERIOD_APPROVED}>Finalize</button>
                           <button onClick={() => reopenPayroll(period.id)} disabled={period.status !== PAYROLL_PERIOD_FINALIZED}>Reopen</button>
                           <button onClick={() => exportRegister(period.id)}>Export register</button>
                           <button onClick={() => exportJournal(period.id)}>Export journal</button>
+                          <button onClick={() => exportPayments(period.id, 'sepa')} disabled={period.status !== PAYROLL_PERIOD_FINALIZED}>SEPA file</button>
+                          <button onClick={() => exportPayments(period.id, 'nacha')} disabled={period.status !== PAYROLL_PERIOD_FINALIZED}>NACHA file</button>
                         </>
                       )}
                     </span>
@@ -691,10 +775,22 @@ export default function Payroll() {
                       <h3>Summary</h3>
                       {summary ? (
                         <>
-                          <p><strong>Total gross:</strong> {summary.totalGross}</p>
-                          <p><strong>Total deductions:</strong> {summary.totalDeductions}</p>
-                          <p><strong>Total net:</strong> {summary.totalNet}</p>
+                          <p><strong>Total gross:</strong> {summary.totalGross} {summary.reportingCurrency}</p>
+                          <p><strong>Total deductions:</strong> {summary.totalDeductions} {summary.reportingCurrency}</p>
+                          <p><strong>Total net:</strong> {summary.totalNet} {summary.reportingCurrency}</p>
                           <p><strong>Employees:</strong> {summary.employeeCount}</p>
+                          {summary.byCurrency?.length > 1 && (
+                            <div>
+                              <strong>By currency</strong>
+                              <ul>
+                                {summary.byCurrency.map((entry) => (
+                                  <li key={entry.currency}>
+                                    {entry.currency}: gross {entry.totalGross}, net {entry.totalNet} ({entry.employeeCount} employees, rate {entry.rate})
+                                  </li>
+                                ))}
+                              </ul>
+                            </div>
+                          )}
                           {summary.warnings && (
                             <div>
                               <strong>Warnings</strong>
@@ -842,6 +938,16 @@ export default function Payroll() {
                       value={elementForm.amount}
                       onChange={(e) => setElementForm({ ...elementForm, amount: e.target.value })}
                     />
+                    {elementForm.calcType !== 'fixed' && (
+                      <input
+                        type="number"
+                        step="0.0001"
+                        min="0"
+                        placeholder={elementForm.calcType === 'units_x_rate' ? 'Rate per unit' : 'Rate (0-1)'}
+                        value={elementForm.rate}
+                        onChange={(e) => setElementForm({ ...elementForm, rate: e.target.value })}
+                      />
+                    )}
                     <label className="checkbox">
                       <input
                         type="checkbox"
@@ -894,6 +1000,14 @@ export default function Payroll() {
                       value={journalTemplateForm.headers}
                       onChange={(e) => setJournalTemplateForm({ ...journalTemplateForm, headers: e.target.value })}
                     />
+                    <select
+                      value={journalTemplateForm.format}
+                      onChange={(e) => setJournalTemplateForm({ ...journalTemplateForm, format: e.target.value })}
+                    >
+                      <option value="csv">Generic CSV</option>
+                      <option value="xero">Xero manual journal CSV</option>
+                      <option value="json">JSON ledger</option>
+                    </select>
                     <button type="submit">Add template</button>
                   </form>
                   <div className="list">
@@ -908,6 +1022,53 @@ export default function Payroll() {
                     ))}
                   </div>
                 </div>
+
+                <div className="card">
+                  <h3>Payslip templates</h3>
+                  <form className="stack" onSubmit={createPayslipTemplate}>
+                    <input
+                      placeholder="Template name"
+                      value={payslipTemplateForm.name}
+                      onChange={(e) => setPayslipTemplateForm({ ...payslipTemplateForm, name: e.target.value })}
+                      required
+                    />
+                    <input
+                      placeholder="Company name"
+                      value={payslipTemplateForm.companyName}
+                      onChange={(e) => setPayslipTemplateForm({ ...payslipTemplateForm, companyName: e.target.value })}
+                    />
+                    <textarea
+                      placeholder="Company address"
+                      value={payslipTemplateForm.companyAddress}
+                      onChange={(e) => setPayslipTemplateForm({ ...payslipTemplateForm, companyAddress: e.target.value })}
+                    />
+                    <input
+                      placeholder="Footer"
+                      value={payslipTemplateForm.footer}
+                      onChange={(e) => setPayslipTemplateForm({ ...payslipTemplateForm, footer: e.target.value })}
+                    />
+                    <label className="inline-note">
+                      <input
+                        type="checkbox"
+                        checked={payslipTemplateForm.isDefault}
+                        onChange={(e) => setPayslipTemplateForm({ ...payslipTemplateForm, isDefault: e.target.checked })}
+                      />
+                      Use as default
+                    </label>
+                    <button type="submit">Add template</button>
+                  </form>
+                  <div className="list">
+                    {payslipTemplates.map((template) => (
+                      <div key={template.id} className="list-item">
+                        <div>
+                          <strong>{template.name}</strong>
+                          <p>{template.companyName || 'No company branding'}</p>
+                        </div>
+                        <small>{template.isDefault ? 'Default' : template.updatedAt?.slice(0, 10)}</small>
+                      </div>
+                    ))}
+                  </div>
+                </div>
               </div>
             }
           />
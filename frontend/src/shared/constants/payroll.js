@@ -1,6 +1,7 @@
 export const PAYROLL_FREQUENCIES = [
   { value: 'weekly', label: 'Weekly' },
   { value: 'biweekly', label: 'Biweekly' },
+  { value: 'semimonthly', label: 'Semi-monthly' },
   { value: 'monthly', label: 'Monthly' },
 ];
 
@@ -11,8 +12,9 @@ export const PAYROLL_ELEMENT_TYPES = [
 
 export const PAYROLL_CALC_TYPES = [
   { value: 'fixed', label: 'Fixed' },
-  { value: 'percent', label: 'Percent' },
-  { value: 'table', label: 'Table/Bracket' },
+  { value: 'pct_of_base', label: 'Percent of base salary' },
+  { value: 'units_x_rate', label: 'Units × rate' },
+  { value: 'pct_of_gross', label: 'Percent of gross' },
 ];
 
 export const PAYROLL_INPUT_SOURCES = [
@@ -9,6 +9,8 @@ export const LEAVE_STATUS_CANCELLED = 'cancelled';
 
 export const PAYROLL_PERIOD_DRAFT = 'draft';
 export const PAYROLL_PERIOD_REVIEWED = 'reviewed';
+export const PAYROLL_PERIOD_PENDING_APPROVAL = 'pending_approval';
+export const PAYROLL_PERIOD_APPROVED = 'approved';
 export const PAYROLL_PERIOD_FINALIZED = 'finalized';
 
 export const GOAL_STATUS_ACTIVE = 'active';
@@ -78,6 +78,8 @@ func New(ctx context.Context, cfg config.Config) (*App, error) {
 	notifySvc := notifications.New(notifications.NewStore(pool), mailer)
 	notifySvc.DefaultFrom = cfg.EmailFrom
 	jobsSvc := jobs.New(pool, cfg)
+	jobsSvc.Notify = notifySvc
+	jobsSvc.Crypto = cryptoSvc
 	metricsCollector := metrics.New()
 	router := buildRouter(cfg, pool, coreStore, cryptoSvc, notifySvc, jobsSvc, metricsCollector)
 
@@ -189,17 +191,21 @@ func buildRouter(cfg config.Config, pool *db.Pool, coreStore *core.Store, crypto
 		auditHandler := audithandler.NewHandler(auditSvc, coreStore)
 		auditHandler.RegisterRoutes(r)
 
-		leaveService := leave.NewService(leave.NewStore(pool), coreStore)
+		leaveStore := leave.NewStore(pool)
+		leaveService := leave.NewService(leaveStore, coreStore)
 		leaveHandler := leavehandler.NewHandler(leaveService, coreStore, notifySvc, auditSvc, jobsSvc)
 		leaveHandler.RegisterRoutes(r)
 
-		payrollService := payroll.NewService(payroll.NewStore(pool), cryptoSvc)
+		payrollService := payroll.NewService(payroll.NewStore(pool, leaveStore), cryptoSvc)
+		leaveService.Payroll = payrollService
 		idempotencyStore := middleware.NewIdempotencyStore(pool)
 		payrollHandler := payrollhandler.NewHandler(payrollService, coreStore, idempotencyStore, cryptoSvc, notifySvc, jobsSvc, auditSvc)
+		payrollHandler.Delegations = coreService
 		payrollHandler.RegisterRoutes(r)
 
 		performanceService := performance.NewService(performance.NewStore(pool))
 		performanceHandler := performancehandler.NewHandler(performanceService, coreStore, notifySvc, auditSvc)
+		performanceHandler.Delegations = coreService
 		performanceHandler.RegisterRoutes(r)
 
 		gdprService := gdpr.NewService(gdpr.NewStore(pool), coreStore, cryptoSvc)
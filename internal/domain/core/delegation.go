@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Delegation scopes name the approvals a delegate may decide.
+const (
+	DelegationScopeAll         = "all"
+	DelegationScopeLeave       = "leave"
+	DelegationScopePerformance = "performance"
+	DelegationScopePayroll     = "payroll"
+)
+
+var (
+	ErrDelegationNotFound = errors.New("delegation not found")
+	ErrDelegateNotFound   = errors.New("delegate not found")
+)
+
+// Delegation lets DelegateID decide the approvals assigned to DelegatorID
+// within Scope from StartDate to EndDate inclusive.
+type Delegation struct {
+	ID          string     `json:"id"`
+	DelegatorID string     `json:"delegatorId"`
+	DelegateID  string     `json:"delegateId"`
+	Scope       string     `json:"scope"`
+	StartDate   time.Time  `json:"startDate"`
+	EndDate     time.Time  `json:"endDate"`
+	Reason      string     `json:"reason,omitempty"`
+	CreatedBy   string     `json:"createdBy,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+}
+
+// DelegationScopes lists the accepted delegation scopes.
+func DelegationScopes() []string {
+	return []string{DelegationScopeAll, DelegationScopeLeave, DelegationScopePerformance, DelegationScopePayroll}
+}
+
+func (s *Service) ListDelegations(ctx context.Context, tenantID, userID string) ([]Delegation, error) {
+	return s.store.ListDelegations(ctx, tenantID, userID)
+}
+
+func (s *Service) GetDelegation(ctx context.Context, tenantID, delegationID string) (Delegation, error) {
+	return s.store.GetDelegation(ctx, tenantID, delegationID)
+}
+
+func (s *Service) CreateDelegation(ctx context.Context, tenantID string, delegation Delegation) (string, error) {
+	return s.store.CreateDelegation(ctx, tenantID, delegation)
+}
+
+func (s *Service) RevokeDelegation(ctx context.Context, tenantID, delegationID string) error {
+	return s.store.RevokeDelegation(ctx, tenantID, delegationID)
+}
+
+// DelegatorsFor lists the users whose approvals in scope delegateUserID may
+// decide on the given date. See Store.DelegatorsFor.
+func (s *Service) DelegatorsFor(ctx context.Context, tenantID, delegateUserID, scope string, on time.Time) ([]string, error) {
+	return s.store.DelegatorsFor(ctx, tenantID, delegateUserID, scope, on)
+}
+
+// ActingDelegate returns the user deciding approverUserID's approvals in
+// scope on the given date, or "" when the approver acts for themselves.
+func (s *Service) ActingDelegate(ctx context.Context, tenantID, approverUserID, scope string, on time.Time) (string, error) {
+	return s.store.ActingDelegate(ctx, tenantID, approverUserID, scope, on)
+}
@@ -146,6 +146,10 @@ func (s *Service) UpdateEmployee(ctx context.Context, tenantID, employeeID strin
 	return s.store.UpdateEmployee(ctx, tenantID, employeeID, emp)
 }
 
+func (s *Service) RecordCompensationChange(ctx context.Context, tenantID, employeeID, userID string) error {
+	return s.store.RecordCompensationChange(ctx, tenantID, employeeID, userID)
+}
+
 func (s *Service) ListEmergencyContacts(ctx context.Context, tenantID, employeeID string) ([]EmergencyContact, error) {
 	return s.store.ListEmergencyContacts(ctx, tenantID, employeeID)
 }
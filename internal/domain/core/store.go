@@ -421,12 +421,21 @@ func (s *Store) createEmployee(ctx context.Context, q rowQuerier, tenantID strin
 		userID = emp.UserID
 	}
 	var id string
+	// The opening compensation record is written with the employee so payroll
+	// proration has a starting point for the salary history.
 	err := q.QueryRow(ctx, `
-    INSERT INTO employees (tenant_id, user_id, employee_number, first_name, last_name, email, personal_email, preferred_name, pronouns, phone, date_of_birth,
-      address, national_id, national_id_enc, bank_account, bank_account_enc, salary, salary_enc, currency,
-      employment_type, department_id, manager_id, pay_group_id, start_date, end_date, status)
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26)
-    RETURNING id
+    WITH inserted AS (
+      INSERT INTO employees (tenant_id, user_id, employee_number, first_name, last_name, email, personal_email, preferred_name, pronouns, phone, date_of_birth,
+        address, national_id, national_id_enc, bank_account, bank_account_enc, salary, salary_enc, currency,
+        employment_type, department_id, manager_id, pay_group_id, start_date, end_date, status)
+      VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26)
+      RETURNING id, tenant_id, salary, salary_enc, currency, pay_group_id, start_date, created_at
+    ), history AS (
+      INSERT INTO employee_compensation (tenant_id, employee_id, effective_from, salary, salary_enc, currency, pay_group_id, reason)
+      SELECT tenant_id, id, COALESCE(start_date, created_at::date), salary, salary_enc, COALESCE(currency, 'USD'), pay_group_id, 'initial'
+      FROM inserted
+    )
+    SELECT id FROM inserted
   `,
 		tenantID, nullIfEmpty(userID), nullIfEmpty(emp.EmployeeNumber), emp.FirstName, emp.LastName, emp.Email,
 		nullIfEmpty(emp.PersonalEmail), nullIfEmpty(emp.PreferredName), nullIfEmpty(emp.Pronouns), emp.Phone,
@@ -496,6 +505,22 @@ func (s *Store) UpdateEmployee(ctx context.Context, tenantID, employeeID string,
 	return nil
 }
 
+// RecordCompensationChange copies the employee's current salary, currency and
+// pay group into the compensation history as a change effective today.
+func (s *Store) RecordCompensationChange(ctx context.Context, tenantID, employeeID, userID string) error {
+	_, err := s.DB.Exec(ctx, `
+    INSERT INTO employee_compensation (tenant_id, employee_id, effective_from, salary, salary_enc, currency, pay_group_id, reason, created_by)
+    SELECT tenant_id, id, CURRENT_DATE, salary, salary_enc, COALESCE(currency, 'USD'), pay_group_id, 'employee update', $3
+    FROM employees
+    WHERE tenant_id = $1 AND id = $2
+    ON CONFLICT (employee_id, effective_from)
+    DO UPDATE SET salary = EXCLUDED.salary, salary_enc = EXCLUDED.salary_enc, currency = EXCLUDED.currency,
+                  pay_group_id = EXCLUDED.pay_group_id, reason = EXCLUDED.reason, created_by = EXCLUDED.created_by,
+                  created_at = now()
+  `, tenantID, employeeID, nullIfEmpty(userID))
+	return err
+}
+
 func nullIfEmpty(value string) any {
 	if value == "" {
 		return nil
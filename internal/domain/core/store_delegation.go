@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ListDelegations returns the delegations userID gave or received, or every
+// delegation in the tenant when userID is empty.
+func (s *Store) ListDelegations(ctx context.Context, tenantID, userID string) ([]Delegation, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, delegator_id, delegate_id, scope, start_date, end_date, COALESCE(reason, ''),
+           COALESCE(created_by::text, ''), created_at, revoked_at
+    FROM approval_delegations
+    WHERE tenant_id = $1 AND ($2 = '' OR delegator_id::text = $2 OR delegate_id::text = $2)
+    ORDER BY start_date DESC, created_at DESC
+  `, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Delegation
+	for rows.Next() {
+		delegation, err := scanDelegation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, delegation)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetDelegation(ctx context.Context, tenantID, delegationID string) (Delegation, error) {
+	delegation, err := scanDelegation(s.DB.QueryRow(ctx, `
+    SELECT id, delegator_id, delegate_id, scope, start_date, end_date, COALESCE(reason, ''),
+           COALESCE(created_by::text, ''), created_at, revoked_at
+    FROM approval_delegations
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, delegationID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Delegation{}, ErrDelegationNotFound
+	}
+	return delegation, err
+}
+
+func scanDelegation(row pgx.Row) (Delegation, error) {
+	var delegation Delegation
+	err := row.Scan(
+		&delegation.ID,
+		&delegation.DelegatorID,
+		&delegation.DelegateID,
+		&delegation.Scope,
+		&delegation.StartDate,
+		&delegation.EndDate,
+		&delegation.Reason,
+		&delegation.CreatedBy,
+		&delegation.CreatedAt,
+		&delegation.RevokedAt,
+	)
+	return delegation, err
+}
+
+// CreateDelegation records a delegation between two users of the tenant.
+// It returns ErrDelegateNotFound when either user belongs to another tenant.
+func (s *Store) CreateDelegation(ctx context.Context, tenantID string, delegation Delegation) (string, error) {
+	var id string
+	err := s.DB.QueryRow(ctx, `
+    INSERT INTO approval_delegations (tenant_id, delegator_id, delegate_id, scope, start_date, end_date, reason, created_by)
+    SELECT $1, delegator.id, delegate.id, $4, $5, $6, $7, $8
+    FROM users delegator
+    JOIN users delegate ON delegate.tenant_id = delegator.tenant_id AND delegate.id = $3
+    WHERE delegator.tenant_id = $1 AND delegator.id = $2
+    RETURNING id
+  `, tenantID, delegation.DelegatorID, delegation.DelegateID, delegation.Scope, delegation.StartDate, delegation.EndDate, nullIfEmpty(delegation.Reason), nullIfEmpty(delegation.CreatedBy)).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrDelegateNotFound
+	}
+	return id, err
+}
+
+func (s *Store) RevokeDelegation(ctx context.Context, tenantID, delegationID string) error {
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE approval_delegations
+    SET revoked_at = now()
+    WHERE tenant_id = $1 AND id = $2 AND revoked_at IS NULL
+  `, tenantID, delegationID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDelegationNotFound
+	}
+	return nil
+}
+
+// DelegatorsFor lists the users whose approvals in scope delegateUserID may
+// decide on the given date: users who delegated to them explicitly, and
+// direct reports on approved leave that day who have no explicit delegation
+// of their own, so an absent approver's work falls to their manager.
+func (s *Store) DelegatorsFor(ctx context.Context, tenantID, delegateUserID, scope string, on time.Time) ([]string, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT d.delegator_id::text
+    FROM approval_delegations d
+    WHERE d.tenant_id = $1 AND d.delegate_id = $2 AND d.revoked_at IS NULL
+      AND d.scope IN ($3, $5) AND $4::date BETWEEN d.start_date AND d.end_date
+    UNION
+    SELECT absent.user_id::text
+    FROM employees absent
+    JOIN employees boss ON boss.id = absent.manager_id
+    WHERE absent.tenant_id = $1 AND boss.user_id = $2 AND absent.user_id IS NOT NULL
+      AND EXISTS (
+        SELECT 1 FROM leave_requests lr
+        WHERE lr.tenant_id = $1 AND lr.employee_id = absent.id AND lr.status = 'approved'
+          AND $4::date BETWEEN lr.start_date AND lr.end_date
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM approval_delegations d
+        WHERE d.tenant_id = $1 AND d.delegator_id = absent.user_id AND d.revoked_at IS NULL
+          AND d.scope IN ($3, $5) AND $4::date BETWEEN d.start_date AND d.end_date
+      )
+  `, tenantID, delegateUserID, scope, on, DelegationScopeAll)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		out = append(out, userID)
+	}
+	return out, rows.Err()
+}
+
+// ActingDelegate returns the user deciding approverUserID's approvals in
+// scope on the given date: the most recent explicit delegate, or the
+// approver's manager while the approver is on approved leave. It returns ""
+// when neither applies.
+func (s *Store) ActingDelegate(ctx context.Context, tenantID, approverUserID, scope string, on time.Time) (string, error) {
+	var delegateID string
+	err := s.DB.QueryRow(ctx, `
+    SELECT delegate_id::text
+    FROM approval_delegations
+    WHERE tenant_id = $1 AND delegator_id = $2 AND revoked_at IS NULL
+      AND scope IN ($3, $5) AND $4::date BETWEEN start_date AND end_date
+    ORDER BY created_at DESC
+    LIMIT 1
+  `, tenantID, approverUserID, scope, on, DelegationScopeAll).Scan(&delegateID)
+	if err == nil {
+		return delegateID, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+
+	err = s.DB.QueryRow(ctx, `
+    SELECT COALESCE(boss.user_id::text, '')
+    FROM employees absent
+    JOIN employees boss ON boss.id = absent.manager_id
+    WHERE absent.tenant_id = $1 AND absent.user_id = $2
+      AND EXISTS (
+        SELECT 1 FROM leave_requests lr
+        WHERE lr.tenant_id = $1 AND lr.employee_id = absent.id AND lr.status = 'approved'
+          AND $3::date BETWEEN lr.start_date AND lr.end_date
+      )
+  `, tenantID, approverUserID, on).Scan(&delegateID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return delegateID, err
+}
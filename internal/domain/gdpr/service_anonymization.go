@@ -80,6 +80,16 @@ func (s *Service) ExecuteAnonymization(ctx context.Context, tenantID, jobID stri
 		return AnonymizationResult{}, err
 	}
 
+	if err := s.store.AnonymizeCompensationTx(ctx, tx, tenantID, employeeID); err != nil {
+		s.failAnonymization(ctx, tenantID, jobID)
+		return AnonymizationResult{}, err
+	}
+
+	if err := s.store.AnonymizeRecurringItemsTx(ctx, tx, tenantID, employeeID); err != nil {
+		s.failAnonymization(ctx, tenantID, jobID)
+		return AnonymizationResult{}, err
+	}
+
 	if err := s.store.ClearPayslipURLsTx(ctx, tx, tenantID, employeeID); err != nil {
 		s.failAnonymization(ctx, tenantID, jobID)
 		return AnonymizationResult{}, err
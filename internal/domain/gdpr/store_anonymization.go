@@ -78,7 +78,7 @@ func (s *Store) AnonymizeUserTx(ctx context.Context, tx pgx.Tx, tenantID, userID
 func (s *Store) AnonymizeLeaveRequestsTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error {
 	_, err := tx.Exec(ctx, `
     UPDATE leave_requests
-    SET reason = NULL
+    SET reason = NULL, updated_at = now(), revision = revision + 1
     WHERE tenant_id = $1 AND employee_id = $2
   `, tenantID, employeeID)
 	return err
@@ -120,6 +120,24 @@ func (s *Store) AnonymizePIPsTx(ctx context.Context, tx pgx.Tx, tenantID, employ
 	return err
 }
 
+func (s *Store) AnonymizeCompensationTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error {
+	_, err := tx.Exec(ctx, `
+    UPDATE employee_compensation
+    SET salary = NULL, salary_enc = NULL, pay_group_id = NULL, reason = NULL
+    WHERE tenant_id = $1 AND employee_id = $2
+  `, tenantID, employeeID)
+	return err
+}
+
+func (s *Store) AnonymizeRecurringItemsTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error {
+	_, err := tx.Exec(ctx, `
+    UPDATE payroll_recurring_items
+    SET description = kind, reference = NULL
+    WHERE tenant_id = $1 AND employee_id = $2
+  `, tenantID, employeeID)
+	return err
+}
+
 func (s *Store) ClearPayslipURLsTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error {
 	_, err := tx.Exec(ctx, `
     UPDATE payslips
@@ -56,6 +56,8 @@ type StoreAPI interface {
 	AnonymizeFeedbackTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error
 	AnonymizeCheckinsTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error
 	AnonymizePIPsTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error
+	AnonymizeCompensationTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error
+	AnonymizeRecurringItemsTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error
 	ClearPayslipURLsTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error
 	DeleteEmergencyContactsTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID string) error
 	CompleteAnonymizationJobTx(ctx context.Context, tx pgx.Tx, tenantID, jobID, status string) error
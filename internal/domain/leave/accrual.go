@@ -21,6 +21,32 @@ type policyRow struct {
 	AccrualPeriod string
 	Entitlement   float64
 	CarryOver     float64
+	Bands         []AccrualBand
+	// Unit is the leave type's unit. Rates, entitlements and carry-over of
+	// hourly types are set in days and credited in hours.
+	Unit string
+}
+
+// accrualEmployee is an active employee with the attributes accrual bands are
+// keyed on and the length of their scheduled working day.
+type accrualEmployee struct {
+	ID             string
+	StartDate      *time.Time
+	EmploymentType string
+	DepartmentID   string
+	HoursPerDay    float64
+}
+
+// unitScale returns what one day of leave counts as on a balance kept in
+// unit: the employee's working day for hourly types and one otherwise.
+func unitScale(unit string, hoursPerDay float64) float64 {
+	if unit != UnitHours {
+		return 1
+	}
+	if hoursPerDay <= 0 {
+		return StandardHoursPerDay
+	}
+	return hoursPerDay
 }
 
 func ApplyAccruals(ctx context.Context, store AccrualStore, tenantID string, now time.Time) (AccrualSummary, error) {
@@ -58,25 +84,30 @@ func ApplyAccruals(ctx context.Context, store AccrualStore, tenantID string, now
 			return summary, err
 		}
 
-		for employeeID, startDate := range employees {
+		for _, employee := range employees {
 			accrual := policy.AccrualRate
-			if startDate != nil && startDate.After(periodStart) {
-				accrual = proratedAccrual(policy.AccrualRate, *startDate, periodStart, now, policy.AccrualPeriod)
+			entitlement := policy.Entitlement
+			if len(policy.Bands) > 0 {
+				accrual, entitlement = bandedAccrual(policy, employee, periodStart)
+			} else if employee.StartDate != nil && employee.StartDate.After(periodStart) {
+				accrual = proratedAccrual(policy.AccrualRate, *employee.StartDate, periodStart, now, policy.AccrualPeriod)
 			}
 			if accrual <= 0 {
 				continue
 			}
+			scale := unitScale(policy.Unit, employee.HoursPerDay)
+			accrual *= scale
 
 			var capValue *float64
-			if policy.Entitlement > 0 {
-				cap := policy.Entitlement + policy.CarryOver
+			if entitlement > 0 {
+				cap := (entitlement + policy.CarryOver) * scale
 				capValue = &cap
 			}
 
 			if capValue == nil {
-				err = store.UpsertBalanceTx(ctx, tx, tenantID, employeeID, policy.LeaveTypeID, accrual)
+				err = store.UpsertBalanceTx(ctx, tx, tenantID, employee.ID, policy.LeaveTypeID, accrual)
 			} else {
-				err = store.UpsertBalanceWithCapTx(ctx, tx, tenantID, employeeID, policy.LeaveTypeID, accrual, *capValue)
+				err = store.UpsertBalanceWithCapTx(ctx, tx, tenantID, employee.ID, policy.LeaveTypeID, accrual, *capValue)
 			}
 			if err != nil {
 				if rbErr := tx.Rollback(ctx); rbErr != nil {
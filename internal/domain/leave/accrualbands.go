@@ -0,0 +1,134 @@
+package leave
+
+import (
+	"math"
+	"time"
+)
+
+const maxAccrualBands = 20
+
+// AccrualBand overrides a policy's accrual rate for employees whose completed
+// years of service fall in [MinYears, MaxYears), optionally limited to an
+// employment type or department. MaxYears of zero leaves the band open-ended.
+// Entitlement, when set, replaces the policy entitlement in the balance cap.
+type AccrualBand struct {
+	MinYears       int      `json:"minYears"`
+	MaxYears       int      `json:"maxYears,omitempty"`
+	EmploymentType string   `json:"employmentType,omitempty"`
+	DepartmentID   string   `json:"departmentId,omitempty"`
+	Rate           float64  `json:"rate"`
+	Entitlement    *float64 `json:"entitlement,omitempty"`
+}
+
+// Valid reports whether the band has a non-negative rate and entitlement and
+// a non-empty year range.
+func (b AccrualBand) Valid() bool {
+	if b.MinYears < 0 || b.Rate < 0 {
+		return false
+	}
+	if b.MaxYears != 0 && b.MaxYears <= b.MinYears {
+		return false
+	}
+	return b.Entitlement == nil || *b.Entitlement >= 0
+}
+
+// ValidAccrualBands reports whether every band is valid and there are at most
+// twenty of them.
+func ValidAccrualBands(bands []AccrualBand) bool {
+	if len(bands) > maxAccrualBands {
+		return false
+	}
+	for _, band := range bands {
+		if !band.Valid() {
+			return false
+		}
+	}
+	return true
+}
+
+func (b AccrualBand) matches(employee accrualEmployee, years int) bool {
+	if years < b.MinYears || (b.MaxYears != 0 && years >= b.MaxYears) {
+		return false
+	}
+	if b.EmploymentType != "" && b.EmploymentType != employee.EmploymentType {
+		return false
+	}
+	return b.DepartmentID == "" || b.DepartmentID == employee.DepartmentID
+}
+
+// matchAccrualBand returns the first band that applies to the employee with
+// the given completed years of service.
+func matchAccrualBand(bands []AccrualBand, employee accrualEmployee, years int) (AccrualBand, bool) {
+	for _, band := range bands {
+		if band.matches(employee, years) {
+			return band, true
+		}
+	}
+	return AccrualBand{}, false
+}
+
+// serviceYears returns the completed years of service on the given date.
+func serviceYears(start *time.Time, on time.Time) int {
+	if start == nil || !on.After(*start) {
+		return 0
+	}
+	years := on.Year() - start.Year()
+	if start.AddDate(years, 0, 0).After(on) {
+		years--
+	}
+	return years
+}
+
+// bandedAccrual returns the employee's accrual for the period starting at
+// periodStart and the entitlement of the band that applies at the end of it.
+// The period is split at the employee's service anniversaries so that a band
+// change is prorated by the share of the period spent in each band; an
+// employee who started during the period accrues only from the start date.
+// Employees no band applies to accrue the policy rate.
+func bandedAccrual(policy policyRow, employee accrualEmployee, periodStart time.Time) (float64, float64) {
+	periodEnd := accrualPeriodEnd(periodStart, policy.AccrualPeriod)
+	total := periodEnd.Sub(periodStart).Hours()
+	entitlement := policy.Entitlement
+	if total <= 0 {
+		return 0, entitlement
+	}
+
+	from := periodStart
+	if employee.StartDate != nil && employee.StartDate.After(from) {
+		from = *employee.StartDate
+	}
+	accrual := 0.0
+	for from.Before(periodEnd) {
+		to := periodEnd
+		if employee.StartDate != nil {
+			next := employee.StartDate.AddDate(serviceYears(employee.StartDate, from)+1, 0, 0)
+			if next.Before(to) {
+				to = next
+			}
+		}
+		rate := policy.AccrualRate
+		entitlement = policy.Entitlement
+		if band, ok := matchAccrualBand(policy.Bands, employee, serviceYears(employee.StartDate, from)); ok {
+			rate = band.Rate
+			if band.Entitlement != nil {
+				entitlement = *band.Entitlement
+			}
+		}
+		accrual += rate * to.Sub(from).Hours() / total
+		from = to
+	}
+	return math.Round(accrual*10000) / 10000, entitlement
+}
+
+func accrualPeriodEnd(periodStart time.Time, period string) time.Time {
+	switch period {
+	case "weekly":
+		return periodStart.AddDate(0, 0, 7)
+	case "monthly":
+		return periodStart.AddDate(0, 1, 0)
+	case "yearly":
+		return periodStart.AddDate(1, 0, 0)
+	default:
+		return periodStart
+	}
+}
@@ -0,0 +1,88 @@
+package leave
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func utcDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBandedAccrualProratesAcrossAnniversary(t *testing.T) {
+	policy := policyRow{
+		AccrualRate:   18,
+		AccrualPeriod: "yearly",
+		Entitlement:   20,
+		Bands: []AccrualBand{
+			{MinYears: 0, MaxYears: 3, Rate: 20},
+			{MinYears: 3, MaxYears: 6, Rate: 23},
+			{MinYears: 6, Rate: 25},
+		},
+	}
+	start := utcDate(2023, time.July, 1)
+	accrual, _ := bandedAccrual(policy, accrualEmployee{StartDate: &start}, utcDate(2026, time.January, 1))
+	expected := (20*181 + 23*184) / 365.0
+	if math.Abs(accrual-expected) > 0.0001 {
+		t.Fatalf("expected %.4f days across the band change, got %v", expected, accrual)
+	}
+
+	longServing := utcDate(2010, time.March, 15)
+	accrual, _ = bandedAccrual(policy, accrualEmployee{StartDate: &longServing}, utcDate(2026, time.January, 1))
+	if accrual != 25 {
+		t.Fatalf("expected open-ended band rate, got %v", accrual)
+	}
+}
+
+func TestBandedAccrualMatchesEmploymentTypeAndDepartment(t *testing.T) {
+	entitlement := 10.0
+	policy := policyRow{
+		AccrualRate:   2,
+		AccrualPeriod: "monthly",
+		Entitlement:   24,
+		Bands: []AccrualBand{
+			{EmploymentType: "part_time", Rate: 1, Entitlement: &entitlement},
+			{DepartmentID: "dept-sales", Rate: 2.5},
+		},
+	}
+	start := utcDate(2020, time.January, 1)
+	periodStart := utcDate(2026, time.May, 1)
+
+	accrual, bandEntitlement := bandedAccrual(policy, accrualEmployee{StartDate: &start, EmploymentType: "part_time"}, periodStart)
+	if accrual != 1 || bandEntitlement != 10 {
+		t.Fatalf("expected part-time band with its entitlement, got %v and %v", accrual, bandEntitlement)
+	}
+	accrual, bandEntitlement = bandedAccrual(policy, accrualEmployee{StartDate: &start, DepartmentID: "dept-sales"}, periodStart)
+	if accrual != 2.5 || bandEntitlement != 24 {
+		t.Fatalf("expected department band with policy entitlement, got %v and %v", accrual, bandEntitlement)
+	}
+	accrual, _ = bandedAccrual(policy, accrualEmployee{StartDate: &start, EmploymentType: "full_time"}, periodStart)
+	if accrual != 2 {
+		t.Fatalf("expected policy rate without a matching band, got %v", accrual)
+	}
+
+	newStarter := utcDate(2026, time.May, 16)
+	accrual, _ = bandedAccrual(policy, accrualEmployee{StartDate: &newStarter, DepartmentID: "dept-sales"}, periodStart)
+	if math.Abs(accrual-2.5*16/31) > 0.0001 {
+		t.Fatalf("expected accrual prorated from the start date, got %v", accrual)
+	}
+}
+
+func TestValidAccrualBands(t *testing.T) {
+	negative := -1.0
+	invalid := [][]AccrualBand{
+		{{MinYears: -1, Rate: 20}},
+		{{MinYears: 3, MaxYears: 3, Rate: 20}},
+		{{Rate: -2}},
+		{{Rate: 20, Entitlement: &negative}},
+	}
+	for _, bands := range invalid {
+		if ValidAccrualBands(bands) {
+			t.Fatalf("expected bands %+v to be invalid", bands)
+		}
+	}
+	if !ValidAccrualBands([]AccrualBand{{MaxYears: 3, Rate: 20}, {MinYears: 3, Rate: 23}}) {
+		t.Fatal("expected tenure bands to be valid")
+	}
+}
@@ -0,0 +1,287 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"hrm/internal/domain/auth"
+)
+
+// maxApprovalChainSteps bounds the length of an approval chain.
+const maxApprovalChainSteps = 10
+
+// ApprovalChain is a tenant-defined sequence of approvers for leave requests.
+// A chain applies to requests for its leave type and to employees in its
+// department; either may be empty to match every leave type or department.
+// When several chains match, one naming both a leave type and a department
+// wins over one naming only a leave type, which wins over one naming only a
+// department.
+type ApprovalChain struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	LeaveTypeID  string         `json:"leaveTypeId,omitempty"`
+	DepartmentID string         `json:"departmentId,omitempty"`
+	Steps        []ApprovalStep `json:"steps"`
+	CreatedAt    time.Time      `json:"createdAt"`
+}
+
+// ApprovalStep is one approver in a chain. Approver is the employee's
+// manager, their department head, any HR user, or the user named by UserID.
+// A step with MinDays only applies to requests of at least that many days.
+type ApprovalStep struct {
+	Approver string  `json:"approver"`
+	UserID   string  `json:"userId,omitempty"`
+	MinDays  float64 `json:"minDays,omitempty"`
+}
+
+// Valid reports whether the step names a known approver.
+func (s ApprovalStep) Valid() bool {
+	if s.MinDays < 0 {
+		return false
+	}
+	switch s.Approver {
+	case ApproverManager, ApproverDepartmentHead, ApproverHR:
+		return s.UserID == ""
+	case ApproverUser:
+		return s.UserID != ""
+	default:
+		return false
+	}
+}
+
+// Valid reports whether the chain has between one and ten valid steps.
+func (c ApprovalChain) Valid() bool {
+	if len(c.Steps) == 0 || len(c.Steps) > maxApprovalChainSteps {
+		return false
+	}
+	for _, step := range c.Steps {
+		if !step.Valid() {
+			return false
+		}
+	}
+	return true
+}
+
+// LeaveApproval is a row of a request's approval trail. Chain steps carry
+// their position in the chain; approvals recorded without a chain have a
+// zero StepOrder. HR steps have no ApproverID until an HR user decides them.
+type LeaveApproval struct {
+	ID           string     `json:"id"`
+	StepOrder    int        `json:"stepOrder,omitempty"`
+	ApproverID   string     `json:"approverId,omitempty"`
+	ApproverRole string     `json:"approverRole,omitempty"`
+	Status       string     `json:"status"`
+	RequestedAt  time.Time  `json:"requestedAt"`
+	DecidedAt    *time.Time `json:"decidedAt,omitempty"`
+	Comment      string     `json:"comment,omitempty"`
+}
+
+// ApproverSet holds the users a chain step can resolve to for an employee.
+type ApproverSet struct {
+	EmployeeUserID       string
+	ManagerUserID        string
+	DepartmentHeadUserID string
+}
+
+// PlanApprovals resolves the chain steps that apply to a request of the given
+// length. Steps below their MinDays threshold, steps without an approver
+// (such as an employee with no manager), steps that would make the employee
+// approve their own request and repeats of an earlier approver are skipped.
+// The first remaining step is pending and the rest wait their turn.
+func PlanApprovals(steps []ApprovalStep, days float64, approvers ApproverSet) []LeaveApproval {
+	var planned []LeaveApproval
+	seen := map[string]bool{}
+	for i, step := range steps {
+		if step.MinDays > 0 && days < step.MinDays {
+			continue
+		}
+		approval := LeaveApproval{StepOrder: i + 1, Status: ApprovalWaiting}
+		switch step.Approver {
+		case ApproverManager:
+			approval.ApproverID = approvers.ManagerUserID
+		case ApproverDepartmentHead:
+			approval.ApproverID = approvers.DepartmentHeadUserID
+		case ApproverUser:
+			approval.ApproverID = step.UserID
+		case ApproverHR:
+			approval.ApproverRole = ApproverHR
+		default:
+			continue
+		}
+		key := approval.ApproverID
+		if approval.ApproverRole == ApproverHR {
+			key = "role:" + ApproverHR
+		} else if key == "" || key == approvers.EmployeeUserID {
+			continue
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if len(planned) == 0 {
+			approval.Status = ApprovalPending
+		}
+		planned = append(planned, approval)
+	}
+	return planned
+}
+
+// chainApprovals returns the approval chain steps recorded for a request, in
+// order, ignoring approvals recorded without a chain.
+func chainApprovals(approvals []LeaveApproval) []LeaveApproval {
+	var steps []LeaveApproval
+	for _, approval := range approvals {
+		if approval.StepOrder > 0 {
+			steps = append(steps, approval)
+		}
+	}
+	return steps
+}
+
+// currentStep returns the pending step of a chain and the step waiting after
+// it, if any.
+func currentStep(steps []LeaveApproval) (current LeaveApproval, next *LeaveApproval, ok bool) {
+	for i, step := range steps {
+		if step.Status != ApprovalPending {
+			continue
+		}
+		for j := i + 1; j < len(steps); j++ {
+			if steps[j].Status == ApprovalWaiting {
+				next = &steps[j]
+				break
+			}
+		}
+		return step, next, true
+	}
+	return LeaveApproval{}, nil, false
+}
+
+// canDecideStep reports whether a user may approve or reject a chain step.
+// HR users may decide any step; other steps are decided by their approver.
+func canDecideStep(step LeaveApproval, userID, roleName string) error {
+	if roleName == auth.RoleHR {
+		return nil
+	}
+	if step.ApproverRole == ApproverHR {
+		return ErrHRApprovalRequired
+	}
+	if step.ApproverID != userID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// stepStatus is the request status while step is the current approval step.
+func stepStatus(step LeaveApproval) string {
+	if step.ApproverRole == ApproverHR {
+		return StatusPendingHR
+	}
+	return StatusPending
+}
+
+func (s *Service) ListApprovalChains(ctx context.Context, tenantID string) ([]ApprovalChain, error) {
+	return s.Store.ListApprovalChains(ctx, tenantID)
+}
+
+func (s *Service) GetApprovalChain(ctx context.Context, tenantID, chainID string) (ApprovalChain, error) {
+	return s.Store.GetApprovalChain(ctx, tenantID, chainID)
+}
+
+func (s *Service) CreateApprovalChain(ctx context.Context, tenantID string, chain ApprovalChain) (string, error) {
+	return s.Store.CreateApprovalChain(ctx, tenantID, chain)
+}
+
+func (s *Service) UpdateApprovalChain(ctx context.Context, tenantID string, chain ApprovalChain) error {
+	return s.Store.UpdateApprovalChain(ctx, tenantID, chain)
+}
+
+// DeleteApprovalChain removes a chain. Requests already submitted keep the
+// steps planned from it.
+func (s *Service) DeleteApprovalChain(ctx context.Context, tenantID, chainID string) error {
+	return s.Store.DeleteApprovalChain(ctx, tenantID, chainID)
+}
+
+// ListApprovals returns a request's approval trail in chain order.
+func (s *Service) ListApprovals(ctx context.Context, tenantID, requestID string) ([]LeaveApproval, error) {
+	return s.Store.ListApprovals(ctx, tenantID, requestID)
+}
+
+// planApprovals resolves the approval chain for a new request. It returns no
+// steps when no chain matches or every step of the matching chain is skipped.
+func (s *Service) planApprovals(ctx context.Context, tenantID, employeeID, leaveTypeID string, days float64) ([]LeaveApproval, error) {
+	chain, err := s.Store.MatchApprovalChain(ctx, tenantID, leaveTypeID, employeeID)
+	if errors.Is(err, ErrApprovalChainNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	approvers, err := s.Store.ApprovalAssignees(ctx, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	return PlanApprovals(chain.Steps, days, approvers), nil
+}
+
+// ApprovalReminder is a chain step that has been waiting for a decision.
+type ApprovalReminder struct {
+	ApprovalID    string
+	RequestID     string
+	ApproverID    string
+	ApproverRole  string
+	LeaveTypeName string
+	StartDate     time.Time
+	EndDate       time.Time
+}
+
+// ReminderSummary reports a reminder run.
+type ReminderSummary struct {
+	Steps         int `json:"steps"`
+	Notifications int `json:"notifications"`
+}
+
+// ApprovalNotifier delivers a reminder to one user.
+type ApprovalNotifier func(ctx context.Context, userID, title, body string) error
+
+// SendApprovalReminders reminds the approvers of every pending chain step
+// that was requested, or last reminded, before cutoff, together with anyone
+// deciding for them through a delegation. HR steps remind every HR user.
+func (s *Service) SendApprovalReminders(ctx context.Context, tenantID string, cutoff time.Time, notify ApprovalNotifier) (ReminderSummary, error) {
+	var summary ReminderSummary
+	reminders, err := s.Store.DueApprovalReminders(ctx, tenantID, cutoff)
+	if err != nil {
+		return summary, err
+	}
+	var hrUserIDs []string
+	for _, reminder := range reminders {
+		recipients := []string{reminder.ApproverID}
+		if delegateID, err := s.ActingDelegate(ctx, tenantID, reminder.ApproverID); err == nil && delegateID != "" {
+			recipients = append(recipients, delegateID)
+		}
+		if reminder.ApproverRole == ApproverHR {
+			if hrUserIDs == nil {
+				if hrUserIDs, err = s.Store.HRUserIDs(ctx, tenantID); err != nil {
+					return summary, err
+				}
+			}
+			recipients = hrUserIDs
+		}
+		body := fmt.Sprintf("A %s leave request for %s to %s is still awaiting your approval.", reminder.LeaveTypeName, reminder.StartDate.Format("2006-01-02"), reminder.EndDate.Format("2006-01-02"))
+		for _, userID := range recipients {
+			if userID == "" {
+				continue
+			}
+			if err := notify(ctx, userID, "Leave approval reminder", body); err != nil {
+				return summary, err
+			}
+			summary.Notifications++
+		}
+		if err := s.Store.MarkApprovalReminded(ctx, tenantID, reminder.ApprovalID); err != nil {
+			return summary, err
+		}
+		summary.Steps++
+	}
+	return summary, nil
+}
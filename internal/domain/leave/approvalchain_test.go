@@ -0,0 +1,201 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hrm/internal/domain/auth"
+)
+
+func TestPlanApprovalsSkipsThresholdsMissingApproversAndRepeats(t *testing.T) {
+	steps := []ApprovalStep{
+		{Approver: ApproverManager},
+		{Approver: ApproverDepartmentHead},
+		{Approver: ApproverUser, UserID: "director", MinDays: 5},
+		{Approver: ApproverUser, UserID: "manager"},
+		{Approver: ApproverHR},
+	}
+	approvers := ApproverSet{EmployeeUserID: "employee", ManagerUserID: "manager"}
+
+	planned := PlanApprovals(steps, 3, approvers)
+	if len(planned) != 2 {
+		t.Fatalf("expected manager and hr steps, got %+v", planned)
+	}
+	if planned[0].StepOrder != 1 || planned[0].ApproverID != "manager" || planned[0].Status != ApprovalPending {
+		t.Fatalf("unexpected first step %+v", planned[0])
+	}
+	if planned[1].StepOrder != 5 || planned[1].ApproverRole != ApproverHR || planned[1].Status != ApprovalWaiting {
+		t.Fatalf("unexpected second step %+v", planned[1])
+	}
+
+	planned = PlanApprovals(steps, 5, approvers)
+	if len(planned) != 3 || planned[1].ApproverID != "director" {
+		t.Fatalf("expected director step at five days, got %+v", planned)
+	}
+
+	planned = PlanApprovals([]ApprovalStep{{Approver: ApproverUser, UserID: "employee"}}, 1, approvers)
+	if len(planned) != 0 {
+		t.Fatalf("expected self-approval step skipped, got %+v", planned)
+	}
+}
+
+func TestApprovalChainValid(t *testing.T) {
+	if (ApprovalChain{}).Valid() {
+		t.Fatal("expected chain without steps to be invalid")
+	}
+	if (ApprovalChain{Steps: []ApprovalStep{{Approver: ApproverUser}}}).Valid() {
+		t.Fatal("expected user step without user to be invalid")
+	}
+	if (ApprovalChain{Steps: []ApprovalStep{{Approver: "ceo"}}}).Valid() {
+		t.Fatal("expected unknown approver to be invalid")
+	}
+	if !(ApprovalChain{Steps: []ApprovalStep{{Approver: ApproverManager}, {Approver: ApproverHR, MinDays: 5}}}).Valid() {
+		t.Fatal("expected manager then hr chain to be valid")
+	}
+}
+
+type chainStore struct {
+	StoreAPI
+	status    string
+	approvals []LeaveApproval
+	approved  bool
+}
+
+func (s *chainStore) RequestInfo(context.Context, string, string) (string, string, float64, string, error) {
+	return "emp-1", "type-1", 6, s.status, nil
+}
+
+func (s *chainStore) ListApprovals(context.Context, string, string) ([]LeaveApproval, error) {
+	out := make([]LeaveApproval, len(s.approvals))
+	copy(out, s.approvals)
+	return out, nil
+}
+
+func (s *chainStore) DecideApprovalStep(_ context.Context, _, approvalID, status, approverID string) error {
+	for i := range s.approvals {
+		if s.approvals[i].ID == approvalID {
+			s.approvals[i].Status = status
+			s.approvals[i].ApproverID = approverID
+		}
+	}
+	return nil
+}
+
+func (s *chainStore) ActivateApprovalStep(_ context.Context, _, approvalID string) error {
+	for i := range s.approvals {
+		if s.approvals[i].ID == approvalID {
+			s.approvals[i].Status = ApprovalPending
+		}
+	}
+	return nil
+}
+
+func (s *chainStore) UpdateRequestStatusSimple(_ context.Context, _, status string) error {
+	s.status = status
+	return nil
+}
+
+func (s *chainStore) UpdateRequestStatus(_ context.Context, _, status, _ string) error {
+	s.status = status
+	return nil
+}
+
+func (s *chainStore) ApproveRequestStatus(context.Context, string, string, string, StaffingCheck) error {
+	s.status = StatusApproved
+	return nil
+}
+
+func (s *chainStore) UpdateBalanceOnApproval(context.Context, string, string, string, float64) error {
+	s.approved = true
+	return nil
+}
+
+func (s *chainStore) ConsumeTOIL(context.Context, string, string, string, float64) error {
+	return nil
+}
+
+func (s *chainStore) HRUserIDs(context.Context, string) ([]string, error) {
+	return []string{"hr-1"}, nil
+}
+
+func (s *chainStore) EmployeeUserAndLeaveType(context.Context, string, string, string) (string, string, error) {
+	return "employee", "Annual", nil
+}
+
+func (s *chainStore) DueApprovalReminders(context.Context, string, time.Time) ([]ApprovalReminder, error) {
+	return []ApprovalReminder{
+		{ApprovalID: "a1", ApproverID: "manager", LeaveTypeName: "Annual"},
+		{ApprovalID: "a2", ApproverRole: ApproverHR, LeaveTypeName: "Annual"},
+	}, nil
+}
+
+func (s *chainStore) MarkApprovalReminded(context.Context, string, string) error {
+	return nil
+}
+
+func TestApproveRequestAdvancesThroughChain(t *testing.T) {
+	store := &chainStore{
+		status: StatusPending,
+		approvals: []LeaveApproval{
+			{ID: "a1", StepOrder: 1, ApproverID: "manager", Status: ApprovalPending},
+			{ID: "a2", StepOrder: 2, ApproverID: "head", Status: ApprovalWaiting},
+			{ID: "a3", StepOrder: 3, ApproverRole: ApproverHR, Status: ApprovalWaiting},
+		},
+	}
+	service := &Service{Store: store}
+	ctx := context.Background()
+
+	if _, err := service.ApproveRequest(ctx, "t1", "r1", "head", auth.RoleManager); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected out-of-turn approver to be forbidden, got %v", err)
+	}
+
+	result, err := service.ApproveRequest(ctx, "t1", "r1", "manager", auth.RoleManager)
+	if err != nil {
+		t.Fatalf("approve manager step: %v", err)
+	}
+	if result.FinalApproval || result.Status != StatusPending || result.NextApproverID != "head" {
+		t.Fatalf("expected request handed to department head, got %+v", result)
+	}
+
+	result, err = service.ApproveRequest(ctx, "t1", "r1", "head", auth.RoleManager)
+	if err != nil {
+		t.Fatalf("approve department head step: %v", err)
+	}
+	if result.Status != StatusPendingHR || len(result.HRUserIDs) != 1 {
+		t.Fatalf("expected request handed to hr, got %+v", result)
+	}
+	if _, err := service.ApproveRequest(ctx, "t1", "r1", "manager", auth.RoleManager); !errors.Is(err, ErrHRApprovalRequired) {
+		t.Fatalf("expected hr step to need hr, got %v", err)
+	}
+
+	result, err = service.ApproveRequest(ctx, "t1", "r1", "hr-1", auth.RoleHR)
+	if err != nil {
+		t.Fatalf("approve hr step: %v", err)
+	}
+	if !result.FinalApproval || result.Status != StatusApproved || !store.approved {
+		t.Fatalf("expected final approval, got %+v", result)
+	}
+	if _, err := service.ApproveRequest(ctx, "t1", "r1", "hr-1", auth.RoleHR); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected approved request to reject further approvals, got %v", err)
+	}
+}
+
+func TestSendApprovalRemindersNotifiesApprovers(t *testing.T) {
+	service := &Service{Store: &chainStore{}}
+	var recipients []string
+	summary, err := service.SendApprovalReminders(context.Background(), "t1", time.Now(), func(_ context.Context, userID, _, _ string) error {
+		recipients = append(recipients, userID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("send reminders: %v", err)
+	}
+	if summary.Steps != 2 || summary.Notifications != 2 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if len(recipients) != 2 || recipients[0] != "manager" || recipients[1] != "hr-1" {
+		t.Fatalf("unexpected recipients %v", recipients)
+	}
+}
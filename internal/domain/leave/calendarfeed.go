@@ -0,0 +1,202 @@
+package leave
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"hrm/internal/domain/auth"
+)
+
+// CalendarFeed is an iCalendar subscription held by a user. Calendar apps
+// fetch it with a token that is only returned when the feed is created and
+// stops working once the feed is revoked. Holiday feeds without a region use
+// the owner's holiday region.
+type CalendarFeed struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Region     string     `json:"region,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	TenantID   string     `json:"-"`
+	UserID     string     `json:"-"`
+	RoleName   string     `json:"-"`
+}
+
+// FeedLeave is a leave request as shown in a calendar feed.
+type FeedLeave struct {
+	ID            string
+	EmployeeName  string
+	LeaveTypeName string
+	StartDate     time.Time
+	EndDate       time.Time
+	Status        string
+	Revision      int
+	UpdatedAt     time.Time
+}
+
+// FeedHoliday is a tenant holiday as shown in a calendar feed.
+type FeedHoliday struct {
+	ID   string
+	Name string
+	Date time.Time
+}
+
+// feedStatuses are the leave statuses feeds show. Rejected and cancelled
+// requests stay in the feed as cancelled events so subscribers drop them.
+var feedStatuses = []string{StatusPending, StatusPendingHR, StatusApproved, StatusRejected, StatusCancelled}
+
+func (s *Service) ListCalendarFeeds(ctx context.Context, tenantID, userID string) ([]CalendarFeed, error) {
+	return s.Store.ListCalendarFeeds(ctx, tenantID, userID)
+}
+
+// CreateCalendarFeed creates a feed for a user and returns it with the token
+// that serves it. Team feeds need the manager or HR role.
+func (s *Service) CreateCalendarFeed(ctx context.Context, tenantID, userID, roleName string, feed CalendarFeed) (CalendarFeed, string, error) {
+	if feed.Kind == FeedTeam && roleName != auth.RoleManager && roleName != auth.RoleHR {
+		return CalendarFeed{}, "", ErrForbidden
+	}
+	token, err := generateFeedToken()
+	if err != nil {
+		return CalendarFeed{}, "", err
+	}
+	feed.TenantID, feed.UserID, feed.RoleName = tenantID, userID, roleName
+	feed.ID, feed.CreatedAt, err = s.Store.CreateCalendarFeed(ctx, tenantID, userID, feed, auth.HashToken(token))
+	if err != nil {
+		return CalendarFeed{}, "", err
+	}
+	return feed, token, nil
+}
+
+func (s *Service) RevokeCalendarFeed(ctx context.Context, tenantID, userID, feedID string) error {
+	return s.Store.RevokeCalendarFeed(ctx, tenantID, userID, feedID)
+}
+
+// RenderCalendarFeed renders the feed a token serves. Leave is shown from a
+// year before now onwards, approved leave as confirmed and leave awaiting a
+// decision as tentative. A team feed stops serving when its owner loses the
+// manager and HR roles.
+func (s *Service) RenderCalendarFeed(ctx context.Context, token string, now time.Time) (CalendarFeed, []byte, error) {
+	feed, err := s.Store.CalendarFeedByToken(ctx, auth.HashToken(token))
+	if err != nil {
+		return feed, nil, err
+	}
+	since := dateOnly(now).AddDate(-1, 0, 0)
+
+	var name string
+	var events []ICalEvent
+	switch feed.Kind {
+	case FeedMine, FeedTeam:
+		employeeID := s.feedEmployeeID(ctx, feed)
+		var entries []FeedLeave
+		switch {
+		case feed.Kind == FeedMine:
+			name = "My leave"
+			if employeeID != "" {
+				entries, err = s.Store.FeedLeave(ctx, feed.TenantID, feedStatuses, employeeID, "", since)
+			}
+		case feed.RoleName == auth.RoleHR:
+			name = "Team leave"
+			entries, err = s.Store.FeedLeave(ctx, feed.TenantID, feedStatuses, "", "", since)
+		case feed.RoleName == auth.RoleManager:
+			name = "Team leave"
+			if employeeID != "" {
+				entries, err = s.Store.FeedLeave(ctx, feed.TenantID, feedStatuses, "", employeeID, since)
+			}
+		default:
+			return feed, nil, ErrCalendarFeedNotFound
+		}
+		if err != nil {
+			return feed, nil, err
+		}
+		for _, entry := range entries {
+			events = append(events, leaveEvent(entry, feed.Kind == FeedTeam))
+		}
+	case FeedHolidays:
+		name = "Holidays"
+		region := feed.Region
+		if region == "" {
+			if employeeID := s.feedEmployeeID(ctx, feed); employeeID != "" {
+				today := dateOnly(now)
+				schedule, err := s.Store.WorkSchedule(ctx, feed.TenantID, employeeID, today, today)
+				if err != nil {
+					return feed, nil, err
+				}
+				region = schedule.Region
+			}
+		}
+		if region != "" {
+			name = "Holidays (" + region + ")"
+		}
+		holidays, err := s.Store.FeedHolidays(ctx, feed.TenantID, region, since)
+		if err != nil {
+			return feed, nil, err
+		}
+		for _, holiday := range holidays {
+			events = append(events, ICalEvent{
+				UID:         "holiday-" + holiday.ID + "@pulsehr",
+				Summary:     holiday.Name,
+				Start:       holiday.Date,
+				End:         holiday.Date,
+				Status:      ICalConfirmed,
+				Modified:    holiday.Date,
+				Transparent: true,
+			})
+		}
+	default:
+		return feed, nil, ErrCalendarFeedNotFound
+	}
+	return feed, RenderICalendar(name, events), nil
+}
+
+// TouchCalendarFeed records that a feed was fetched.
+func (s *Service) TouchCalendarFeed(ctx context.Context, feedID string) error {
+	return s.Store.TouchCalendarFeed(ctx, feedID)
+}
+
+// leaveEvent turns a leave request into a feed event. Team feeds name the
+// employee and do not mark the subscriber as busy.
+func leaveEvent(entry FeedLeave, team bool) ICalEvent {
+	summary := entry.LeaveTypeName
+	if team {
+		summary = entry.EmployeeName + ": " + summary
+	}
+	status := ICalConfirmed
+	switch entry.Status {
+	case StatusPending, StatusPendingHR:
+		status = ICalTentative
+		summary += " (pending)"
+	case StatusRejected, StatusCancelled:
+		status = ICalCancelled
+		summary += " (" + entry.Status + ")"
+	}
+	return ICalEvent{
+		UID:         "leave-" + entry.ID + "@pulsehr",
+		Summary:     summary,
+		Start:       entry.StartDate,
+		End:         entry.EndDate,
+		Status:      status,
+		Sequence:    entry.Revision,
+		Modified:    entry.UpdatedAt,
+		Transparent: team,
+	}
+}
+
+// feedEmployeeID returns the employee record of a feed's owner, or "" when
+// they have none.
+func (s *Service) feedEmployeeID(ctx context.Context, feed CalendarFeed) string {
+	employeeID, err := s.EmployeeIDByUserID(ctx, feed.TenantID, feed.UserID)
+	if err != nil {
+		return ""
+	}
+	return employeeID
+}
+
+func generateFeedToken() (string, error) {
+	buff := make([]byte, 32)
+	if _, err := rand.Read(buff); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buff), nil
+}
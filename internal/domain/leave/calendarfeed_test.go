@@ -0,0 +1,131 @@
+package leave
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"hrm/internal/domain/auth"
+)
+
+type feedStore struct {
+	StoreAPI
+	feed          CalendarFeed
+	employeeID    string
+	managerID     string
+	holidayRegion string
+}
+
+func (s *feedStore) CalendarFeedByToken(_ context.Context, tokenHash string) (CalendarFeed, error) {
+	if tokenHash != auth.HashToken("secret") {
+		return CalendarFeed{}, ErrCalendarFeedNotFound
+	}
+	return s.feed, nil
+}
+
+func (s *feedStore) FeedLeave(_ context.Context, _ string, _ []string, employeeID, managerID string, _ time.Time) ([]FeedLeave, error) {
+	s.employeeID, s.managerID = employeeID, managerID
+	created := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	return []FeedLeave{{
+		ID: "req-1", EmployeeName: "Ada Lovelace", LeaveTypeName: "Annual", Status: StatusCancelled,
+		StartDate: utcDate(2026, time.April, 6), EndDate: utcDate(2026, time.April, 8),
+		Revision: 2, UpdatedAt: created.Add(90 * time.Second),
+	}}, nil
+}
+
+func (s *feedStore) FeedHolidays(_ context.Context, _, region string, _ time.Time) ([]FeedHoliday, error) {
+	s.holidayRegion = region
+	return []FeedHoliday{{ID: "hol-1", Name: "Founders' Day, observed", Date: utcDate(2026, time.May, 4)}}, nil
+}
+
+func (s *feedStore) WorkSchedule(context.Context, string, string, time.Time, time.Time) (WorkSchedule, error) {
+	return WorkSchedule{Pattern: StandardWorkPattern(), Region: "LK"}, nil
+}
+
+type feedEmployees struct {
+	EmployeeLookup
+	employeeID string
+}
+
+func (e feedEmployees) EmployeeIDByUserID(context.Context, string, string) (string, error) {
+	return e.employeeID, nil
+}
+
+func TestRenderCalendarFeedScopesAndCancels(t *testing.T) {
+	store := &feedStore{feed: CalendarFeed{ID: "feed-1", Kind: FeedTeam, TenantID: "t1", UserID: "user-1", RoleName: auth.RoleManager}}
+	service := &Service{Store: store, Employees: feedEmployees{employeeID: "emp-mgr"}}
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	if _, _, err := service.RenderCalendarFeed(context.Background(), "guess", now); !errors.Is(err, ErrCalendarFeedNotFound) {
+		t.Fatalf("expected an unknown token to find no feed, got %v", err)
+	}
+	_, body, err := service.RenderCalendarFeed(context.Background(), "secret", now)
+	if err != nil {
+		t.Fatalf("render team feed: %v", err)
+	}
+	if store.managerID != "emp-mgr" || store.employeeID != "" {
+		t.Fatalf("expected the manager's reports, got employee %q manager %q", store.employeeID, store.managerID)
+	}
+	for _, want := range []string{
+		"UID:leave-req-1@pulsehr\r\n",
+		"SUMMARY:Ada Lovelace: Annual (cancelled)\r\n",
+		"STATUS:CANCELLED\r\n",
+		"SEQUENCE:2\r\n",
+		"DTSTART;VALUE=DATE:20260406\r\n",
+		"DTEND;VALUE=DATE:20260409\r\n",
+		"TRANSP:TRANSPARENT\r\n",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("expected feed to contain %q, got\n%s", want, body)
+		}
+	}
+	_, again, err := service.RenderCalendarFeed(context.Background(), "secret", now.Add(time.Hour))
+	if err != nil || !bytes.Equal(body, again) {
+		t.Fatalf("expected an unchanged feed to render the same bytes, got %v", err)
+	}
+
+	store.feed.RoleName = auth.RoleEmployee
+	if _, _, err := service.RenderCalendarFeed(context.Background(), "secret", now); !errors.Is(err, ErrCalendarFeedNotFound) {
+		t.Fatalf("expected a team feed to stop serving without the manager role, got %v", err)
+	}
+
+	store.feed.Kind = FeedHolidays
+	_, body, err = service.RenderCalendarFeed(context.Background(), "secret", now)
+	if err != nil {
+		t.Fatalf("render holiday feed: %v", err)
+	}
+	if store.holidayRegion != "LK" || !strings.Contains(string(body), `SUMMARY:Founders' Day\, observed`) {
+		t.Fatalf("expected the owner's region holidays with escaped text, got region %q\n%s", store.holidayRegion, body)
+	}
+}
+
+func TestCreateCalendarFeedNeedsManagerForTeam(t *testing.T) {
+	service := &Service{Store: &feedStore{}}
+	_, _, err := service.CreateCalendarFeed(context.Background(), "t1", "user-1", auth.RoleEmployee, CalendarFeed{Kind: FeedTeam})
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected employees to be refused team feeds, got %v", err)
+	}
+}
+
+func TestRenderICalendarFoldsLongLines(t *testing.T) {
+	body := string(RenderICalendar("Leave", []ICalEvent{{
+		UID:      "leave-1@pulsehr",
+		Summary:  strings.Repeat("é", 60),
+		Start:    utcDate(2026, time.January, 1),
+		End:      utcDate(2026, time.January, 1),
+		Status:   ICalConfirmed,
+		Modified: utcDate(2026, time.January, 1),
+	}}))
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("expected lines of at most 75 octets, got %d: %q", len(line), line)
+		}
+	}
+	unfolded := strings.ReplaceAll(body, "\r\n ", "")
+	if !strings.Contains(unfolded, "SUMMARY:"+strings.Repeat("é", 60)+"\r\n") {
+		t.Fatalf("expected the summary to unfold intact, got\n%s", body)
+	}
+}
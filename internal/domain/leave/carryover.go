@@ -0,0 +1,225 @@
+package leave
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// CarryOverPolicy is the part of a leave policy that drives year-end
+// processing. ExpiryMonths of zero keeps carried days until they are used.
+// The limit of an hourly leave type is in days and is converted with each
+// employee's working day. The policy year starts on YearStartDay of
+// YearStartMonth; zero values mean 1 January.
+type CarryOverPolicy struct {
+	ID             string
+	LeaveTypeID    string
+	Unit           string
+	Limit          float64
+	ExpiryMonths   int
+	YearStartMonth int
+	YearStartDay   int
+	CreatedAt      time.Time
+}
+
+// ValidYearStart reports whether month and day name a day every year has, so
+// 29 February is rejected.
+func ValidYearStart(month, day int) bool {
+	if month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	return day <= time.Date(2023, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// lastYearEnd returns the start of the policy year containing now, which is
+// where the last completed policy year ended, and that completed year,
+// numbered by the calendar year it started in.
+func (p CarryOverPolicy) lastYearEnd(now time.Time) (int, time.Time) {
+	month, day := time.Month(p.YearStartMonth), p.YearStartDay
+	if month < time.January || day < 1 {
+		month, day = time.January, 1
+	}
+	start := time.Date(now.Year(), month, day, 0, 0, 0, 0, time.UTC)
+	if start.After(now) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start.Year() - 1, start
+}
+
+// CarryOverBalance is an employee balance at the end of a policy year.
+type CarryOverBalance struct {
+	EmployeeID  string
+	LeaveTypeID string
+	Balance     float64
+	Pending     float64
+	Used        float64
+	HoursPerDay float64
+}
+
+// CarryOverNotice is a carried-forward balance nearing its expiry date.
+type CarryOverNotice struct {
+	BalanceID     string
+	EmployeeUser  string
+	LeaveTypeName string
+	Days          float64
+	ExpiresOn     time.Time
+}
+
+// CarryOverSummary reports what a year-end run did. Year is the latest
+// policy year rolled over.
+type CarryOverSummary struct {
+	Year              int     `json:"year"`
+	PoliciesProcessed int     `json:"policiesProcessed"`
+	BalancesCarried   int     `json:"balancesCarried"`
+	DaysCarried       float64 `json:"daysCarried"`
+	DaysForfeited     float64 `json:"daysForfeited"`
+	BalancesExpired   int     `json:"balancesExpired"`
+	DaysExpired       float64 `json:"daysExpired"`
+	ExpiryNotices     int     `json:"expiryNotices"`
+}
+
+// BalanceNotifier delivers a notification about a leave balance to a user.
+type BalanceNotifier func(ctx context.Context, userID, title, body string) error
+
+// SplitCarryOver divides the unused days of a balance into the days carried
+// into the next year, up to limit, and the days forfeited.
+func SplitCarryOver(balance CarryOverBalance, limit float64) (carried, forfeited float64) {
+	unused := roundDays(balance.Balance - balance.Used - balance.Pending)
+	if unused <= 0 {
+		return 0, 0
+	}
+	carried = math.Min(unused, math.Max(limit, 0))
+	return carried, roundDays(unused - carried)
+}
+
+// carryOverExpiry returns the last day carried days may be used, counting
+// months from yearEnd, the first day of the new policy year, or nil when they
+// do not expire.
+func carryOverExpiry(yearEnd time.Time, months int) *time.Time {
+	if months <= 0 {
+		return nil
+	}
+	expires := yearEnd.AddDate(0, months, -1)
+	return &expires
+}
+
+func roundDays(days float64) float64 {
+	return math.Round(days*100) / 100
+}
+
+// ProcessYearEnd rolls every policy's balances over from its last completed
+// policy year, expires carried days past their expiry date and warns
+// employees whose carried days expire within noticeDays. Each policy year is
+// processed once; policies created after the year ended are skipped.
+func (s *Service) ProcessYearEnd(ctx context.Context, tenantID string, now time.Time, noticeDays int, notify BalanceNotifier) (CarryOverSummary, error) {
+	now = now.UTC()
+	var summary CarryOverSummary
+
+	policies, err := s.Store.ListCarryOverPolicies(ctx, tenantID)
+	if err != nil {
+		return summary, err
+	}
+	for _, policy := range policies {
+		year, yearEnd := policy.lastYearEnd(now)
+		if !policy.CreatedAt.Before(yearEnd) {
+			continue
+		}
+		done, err := s.Store.CarryOverProcessed(ctx, tenantID, policy.ID, year)
+		if err != nil {
+			return summary, err
+		}
+		if done {
+			continue
+		}
+		if err := s.carryOverPolicy(ctx, tenantID, policy, year, yearEnd, &summary); err != nil {
+			return summary, err
+		}
+		summary.PoliciesProcessed++
+		if year > summary.Year {
+			summary.Year = year
+		}
+	}
+
+	expired, days, err := s.Store.ExpireCarriedBalances(ctx, tenantID, now)
+	if err != nil {
+		return summary, err
+	}
+	summary.BalancesExpired = expired
+	summary.DaysExpired = days
+
+	if notify == nil || noticeDays <= 0 {
+		return summary, nil
+	}
+	notices, err := s.Store.DueCarryOverNotices(ctx, tenantID, now.AddDate(0, 0, noticeDays))
+	if err != nil {
+		return summary, err
+	}
+	for _, notice := range notices {
+		if notice.EmployeeUser != "" {
+			body := fmt.Sprintf("%.2f carried-over %s days expire after %s.", notice.Days, notice.LeaveTypeName, notice.ExpiresOn.Format("2006-01-02"))
+			if err := notify(ctx, notice.EmployeeUser, "Carried-over leave expiring", body); err != nil {
+				slog.Warn("leave carry-over expiry notification failed", "err", err)
+				continue
+			}
+			summary.ExpiryNotices++
+		}
+		if err := s.Store.MarkCarryOverNotified(ctx, tenantID, notice.BalanceID); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// carryOverPolicy rolls the balances of one policy's leave type over from
+// year, which ended at yearEnd, in a single transaction and records the run.
+func (s *Service) carryOverPolicy(ctx context.Context, tenantID string, policy CarryOverPolicy, year int, yearEnd time.Time, summary *CarryOverSummary) error {
+	tx, err := s.Store.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	rollback := func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			slog.Warn("leave carry-over rollback failed", "err", rbErr)
+		}
+	}
+
+	balances, err := s.Store.ListCarryOverBalancesTx(ctx, tx, tenantID, policy.LeaveTypeID)
+	if err != nil {
+		rollback()
+		return err
+	}
+	expires := carryOverExpiry(yearEnd, policy.ExpiryMonths)
+	processed := 0
+	for _, balance := range balances {
+		unit := UnitDays
+		if policy.Unit == UnitHours {
+			unit = UnitHours
+		}
+		carried, forfeited := SplitCarryOver(balance, policy.Limit*unitScale(unit, balance.HoursPerDay))
+		if carried == 0 && forfeited == 0 {
+			continue
+		}
+		reason := fmt.Sprintf("Year-end %d carry-over: %.2f %s carried, %.2f %s forfeited", year, carried, unit, forfeited, unit)
+		if expires != nil && carried > 0 {
+			reason += fmt.Sprintf("; carried %s expire after %s", unit, expires.Format("2006-01-02"))
+		}
+		if err := s.Store.ApplyCarryOverTx(ctx, tx, tenantID, balance.EmployeeID, balance.LeaveTypeID, carried, forfeited, expires, reason); err != nil {
+			rollback()
+			return err
+		}
+		processed++
+		if carried > 0 {
+			summary.BalancesCarried++
+		}
+		summary.DaysCarried = roundDays(summary.DaysCarried + carried)
+		summary.DaysForfeited = roundDays(summary.DaysForfeited + forfeited)
+	}
+
+	if err := s.Store.RecordCarryOverRunTx(ctx, tx, tenantID, policy.ID, year, processed); err != nil {
+		rollback()
+		return err
+	}
+	return tx.Commit(ctx)
+}
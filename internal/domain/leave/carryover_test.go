@@ -0,0 +1,173 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestSplitCarryOver(t *testing.T) {
+	cases := []struct {
+		name      string
+		balance   CarryOverBalance
+		limit     float64
+		carried   float64
+		forfeited float64
+	}{
+		{"under limit", CarryOverBalance{Balance: 20, Used: 17}, 5, 3, 0},
+		{"over limit", CarryOverBalance{Balance: 20, Used: 8, Pending: 2}, 5, 5, 5},
+		{"no carry over", CarryOverBalance{Balance: 20, Used: 15}, 0, 0, 5},
+		{"overdrawn", CarryOverBalance{Balance: 10, Used: 12}, 5, 0, 0},
+	}
+	for _, tc := range cases {
+		carried, forfeited := SplitCarryOver(tc.balance, tc.limit)
+		if carried != tc.carried || forfeited != tc.forfeited {
+			t.Fatalf("%s: expected %v carried and %v forfeited, got %v and %v", tc.name, tc.carried, tc.forfeited, carried, forfeited)
+		}
+	}
+}
+
+func TestCarryOverExpiry(t *testing.T) {
+	yearEnd := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if carryOverExpiry(yearEnd, 0) != nil {
+		t.Fatal("expected carried days without expiry months to never expire")
+	}
+	expires := carryOverExpiry(yearEnd, 3)
+	if expires == nil || expires.Format("2006-01-02") != "2026-03-31" {
+		t.Fatalf("expected expiry at end of March, got %v", expires)
+	}
+}
+
+func TestCarryOverPolicyLastYearEnd(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  CarryOverPolicy
+		now     time.Time
+		year    int
+		yearEnd string
+	}{
+		{"calendar year", CarryOverPolicy{}, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), 2025, "2026-01-01"},
+		{"april year before its start", CarryOverPolicy{YearStartMonth: 4, YearStartDay: 6}, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), 2024, "2025-04-06"},
+		{"april year on its start", CarryOverPolicy{YearStartMonth: 4, YearStartDay: 6}, time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC), 2025, "2026-04-06"},
+	}
+	for _, tc := range cases {
+		year, yearEnd := tc.policy.lastYearEnd(tc.now)
+		if year != tc.year || yearEnd.Format("2006-01-02") != tc.yearEnd {
+			t.Errorf("%s: got %d ending %s, want %d ending %s", tc.name, year, yearEnd.Format("2006-01-02"), tc.year, tc.yearEnd)
+		}
+	}
+	if ValidYearStart(2, 29) || !ValidYearStart(4, 6) || ValidYearStart(13, 1) {
+		t.Fatal("expected 29 February and month 13 rejected and 6 April accepted")
+	}
+}
+
+type carryOverTx struct {
+	pgx.Tx
+	committed bool
+}
+
+func (tx *carryOverTx) Commit(context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *carryOverTx) Rollback(context.Context) error {
+	return nil
+}
+
+type carryOverSplit struct {
+	employeeID string
+	carried    float64
+	forfeited  float64
+}
+
+type carryOverStore struct {
+	StoreAPI
+	tx        carryOverTx
+	processed map[string]bool
+	applied   []carryOverSplit
+	runs      []int
+	notified  []string
+}
+
+func (s *carryOverStore) ListCarryOverPolicies(context.Context, string) ([]CarryOverPolicy, error) {
+	return []CarryOverPolicy{
+		{ID: "annual", LeaveTypeID: "type-annual", Limit: 5, ExpiryMonths: 3, CreatedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "done", LeaveTypeID: "type-done", Limit: 5, CreatedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "new", LeaveTypeID: "type-new", Limit: 5, CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}, nil
+}
+
+func (s *carryOverStore) CarryOverProcessed(_ context.Context, _, policyID string, _ int) (bool, error) {
+	return s.processed[policyID], nil
+}
+
+func (s *carryOverStore) BeginTx(context.Context) (pgx.Tx, error) {
+	return &s.tx, nil
+}
+
+func (s *carryOverStore) ListCarryOverBalancesTx(_ context.Context, _ pgx.Tx, _, leaveTypeID string) ([]CarryOverBalance, error) {
+	return []CarryOverBalance{
+		{EmployeeID: "emp-1", LeaveTypeID: leaveTypeID, Balance: 20, Used: 12},
+		{EmployeeID: "emp-2", LeaveTypeID: leaveTypeID, Balance: 20, Used: 20},
+	}, nil
+}
+
+func (s *carryOverStore) ApplyCarryOverTx(_ context.Context, _ pgx.Tx, _, employeeID, _ string, carried, forfeited float64, expiresOn *time.Time, _ string) error {
+	if expiresOn == nil || expiresOn.Format("2006-01-02") != "2026-03-31" {
+		return errors.New("unexpected carry-over expiry")
+	}
+	s.applied = append(s.applied, carryOverSplit{employeeID: employeeID, carried: carried, forfeited: forfeited})
+	return nil
+}
+
+func (s *carryOverStore) RecordCarryOverRunTx(_ context.Context, _ pgx.Tx, _, _ string, year, _ int) error {
+	s.runs = append(s.runs, year)
+	return nil
+}
+
+func (s *carryOverStore) ExpireCarriedBalances(context.Context, string, time.Time) (int, float64, error) {
+	return 1, 2.5, nil
+}
+
+func (s *carryOverStore) DueCarryOverNotices(context.Context, string, time.Time) ([]CarryOverNotice, error) {
+	return []CarryOverNotice{{BalanceID: "bal-1", EmployeeUser: "user-1", LeaveTypeName: "Annual", Days: 5, ExpiresOn: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)}}, nil
+}
+
+func (s *carryOverStore) MarkCarryOverNotified(_ context.Context, _, balanceID string) error {
+	s.notified = append(s.notified, balanceID)
+	return nil
+}
+
+func TestProcessYearEndCarriesForfeitsAndNotifies(t *testing.T) {
+	store := &carryOverStore{processed: map[string]bool{"done": true}}
+	service := &Service{Store: store}
+	var recipients []string
+	notify := func(_ context.Context, userID, _, _ string) error {
+		recipients = append(recipients, userID)
+		return nil
+	}
+
+	summary, err := service.ProcessYearEnd(context.Background(), "t1", time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC), 30, notify)
+	if err != nil {
+		t.Fatalf("process year end: %v", err)
+	}
+	if summary.Year != 2025 || summary.PoliciesProcessed != 1 {
+		t.Fatalf("expected only the annual policy processed for 2025, got %+v", summary)
+	}
+	if len(store.applied) != 1 || store.applied[0].employeeID != "emp-1" || store.applied[0].carried != 5 || store.applied[0].forfeited != 3 {
+		t.Fatalf("expected 5 days carried and 3 forfeited, got %+v", store.applied)
+	}
+	if !store.tx.committed || len(store.runs) != 1 || store.runs[0] != 2025 {
+		t.Fatalf("expected committed run for 2025, got %v", store.runs)
+	}
+	if summary.BalancesExpired != 1 || summary.DaysExpired != 2.5 {
+		t.Fatalf("expected expiry totals in summary, got %+v", summary)
+	}
+	if summary.ExpiryNotices != 1 || len(recipients) != 1 || recipients[0] != "user-1" || len(store.notified) != 1 {
+		t.Fatalf("expected one expiry notice, got %+v recipients %v", summary, recipients)
+	}
+}
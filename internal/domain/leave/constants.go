@@ -7,3 +7,81 @@ const (
 	StatusRejected  = "rejected"
 	StatusCancelled = "cancelled"
 )
+
+// Approval chain step approvers.
+const (
+	ApproverManager        = "manager"
+	ApproverDepartmentHead = "department_head"
+	ApproverHR             = "hr"
+	ApproverUser           = "user"
+)
+
+// Statuses of an approval chain step recorded in leave_approvals. Steps after
+// the current one wait until the steps before them are approved.
+const (
+	ApprovalPending   = "pending"
+	ApprovalWaiting   = "waiting"
+	ApprovalApproved  = "approved"
+	ApprovalRejected  = "rejected"
+	ApprovalCancelled = "cancelled"
+)
+
+// Units leave types are counted in.
+const (
+	UnitDays  = "days"
+	UnitHours = "hours"
+)
+
+// Statuses of a TOIL ledger entry. Approved entries credit the balance until
+// their hours are used or expire.
+const (
+	TOILPending  = "pending"
+	TOILApproved = "approved"
+	TOILRejected = "rejected"
+	TOILExpired  = "expired"
+)
+
+// Statuses of a leave encashment. Approved encashments have left the balance
+// and are queued for payment in a payroll period.
+const (
+	EncashmentPending  = "pending"
+	EncashmentApproved = "approved"
+	EncashmentRejected = "rejected"
+)
+
+// Sources of a leave encashment: cashed out on request or paid out when the
+// employee leaves.
+const (
+	EncashmentSourceRequest     = "request"
+	EncashmentSourceTermination = "termination"
+)
+
+// The payroll period status and run type an encashment can still be added
+// to. They match payroll.PeriodStatusDraft and payroll.PeriodRunRegular,
+// which this package cannot import.
+const (
+	payrollPeriodDraft = "draft"
+	payrollRunRegular  = "regular"
+)
+
+// Kinds of staffing rule: a cap on how many people in scope may be absent on
+// the same day, or a blackout period in which no leave may be taken.
+const (
+	StaffingMaxAbsent = "max_absent"
+	StaffingBlackout  = "blackout"
+)
+
+// How a broken staffing rule is enforced: as a warning shown with the request
+// or as a block that refuses it.
+const (
+	StaffingWarn  = "warn"
+	StaffingBlock = "block"
+)
+
+// Kinds of iCalendar subscription feed: the owner's own leave, the leave of
+// their reports (the whole tenant for HR), and the tenant holidays of a region.
+const (
+	FeedMine     = "mine"
+	FeedTeam     = "team"
+	FeedHolidays = "holidays"
+)
@@ -0,0 +1,111 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"hrm/internal/domain/core"
+)
+
+// actsFor reports whether userID decides approverUserID's leave approvals
+// today through an explicit or automatic delegation.
+func (s *Service) actsFor(ctx context.Context, tenantID, userID, approverUserID string) (bool, error) {
+	if s.Employees == nil || approverUserID == "" || userID == approverUserID {
+		return false, nil
+	}
+	delegators, err := s.Employees.DelegatorsFor(ctx, tenantID, userID, core.DelegationScopeLeave, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	for _, delegatorID := range delegators {
+		if delegatorID == approverUserID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ActingDelegate returns the user deciding approverUserID's leave approvals
+// today, or "" when the approver acts for themselves.
+func (s *Service) ActingDelegate(ctx context.Context, tenantID, approverUserID string) (string, error) {
+	if s.Employees == nil || approverUserID == "" {
+		return "", nil
+	}
+	return s.Employees.ActingDelegate(ctx, tenantID, approverUserID, core.DelegationScopeLeave, time.Now().UTC())
+}
+
+// ApproverUserIDs returns userID and the users whose leave approvals userID
+// decides today.
+func (s *Service) ApproverUserIDs(ctx context.Context, tenantID, userID string) ([]string, error) {
+	approverIDs := []string{userID}
+	if s.Employees == nil {
+		return approverIDs, nil
+	}
+	delegators, err := s.Employees.DelegatorsFor(ctx, tenantID, userID, core.DelegationScopeLeave, time.Now().UTC())
+	if err != nil {
+		return approverIDs, err
+	}
+	return append(approverIDs, delegators...), nil
+}
+
+// DelegatedManagerIDs returns the employee IDs of the managers whose leave
+// approvals userID decides today.
+func (s *Service) DelegatedManagerIDs(ctx context.Context, tenantID, userID string) ([]string, error) {
+	if s.Employees == nil {
+		return nil, nil
+	}
+	delegators, err := s.Employees.DelegatorsFor(ctx, tenantID, userID, core.DelegationScopeLeave, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	var managerIDs []string
+	for _, delegatorID := range delegators {
+		employeeID, err := s.Employees.EmployeeIDByUserID(ctx, tenantID, delegatorID)
+		if err != nil || employeeID == "" {
+			continue
+		}
+		managerIDs = append(managerIDs, employeeID)
+	}
+	return managerIDs, nil
+}
+
+// checkManagerApprover allows a manager to decide a request from one of
+// their reports, or from a report of a manager whose approvals they decide
+// through a delegation. It returns the manager's user ID when a delegate
+// decides.
+func (s *Service) checkManagerApprover(ctx context.Context, tenantID, employeeID, approverUserID string) (string, error) {
+	if s.Employees == nil {
+		return "", nil
+	}
+	managerEmployeeID, err := s.Employees.ManagerIDByEmployeeID(ctx, tenantID, employeeID)
+	if err != nil || managerEmployeeID == "" {
+		return "", nil
+	}
+	selfEmployeeID, err := s.Employees.EmployeeIDByUserID(ctx, tenantID, approverUserID)
+	if err == nil && selfEmployeeID != "" && selfEmployeeID == managerEmployeeID {
+		return "", nil
+	}
+	managerUserID, err := s.Store.ManagerUserIDForEmployee(ctx, tenantID, employeeID)
+	if err != nil {
+		return "", ErrForbidden
+	}
+	if ok, err := s.actsFor(ctx, tenantID, approverUserID, managerUserID); err != nil || !ok {
+		return "", ErrForbidden
+	}
+	return managerUserID, nil
+}
+
+// checkStepApprover allows the approver of a chain step, HR, or a user
+// deciding the approver's approvals through a delegation to decide the
+// step. It returns the step's approver when a delegate decides.
+func (s *Service) checkStepApprover(ctx context.Context, tenantID string, step LeaveApproval, userID, roleName string) (string, error) {
+	err := canDecideStep(step, userID, roleName)
+	if !errors.Is(err, ErrForbidden) {
+		return "", err
+	}
+	if ok, lookupErr := s.actsFor(ctx, tenantID, userID, step.ApproverID); lookupErr == nil && ok {
+		return step.ApproverID, nil
+	}
+	return "", err
+}
@@ -0,0 +1,101 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hrm/internal/domain/auth"
+)
+
+type delegationLookup struct {
+	delegators map[string][]string
+}
+
+func (l delegationLookup) EmployeeIDByUserID(_ context.Context, _, userID string) (string, error) {
+	return "emp-" + userID, nil
+}
+
+func (l delegationLookup) ManagerIDByEmployeeID(context.Context, string, string) (string, error) {
+	return "emp-manager", nil
+}
+
+func (l delegationLookup) IsManagerOf(context.Context, string, string, string) (bool, error) {
+	return false, nil
+}
+
+func (l delegationLookup) DelegatorsFor(_ context.Context, _, delegateUserID, _ string, _ time.Time) ([]string, error) {
+	return l.delegators[delegateUserID], nil
+}
+
+func (l delegationLookup) ActingDelegate(context.Context, string, string, string, time.Time) (string, error) {
+	return "", nil
+}
+
+type legacyStore struct {
+	*chainStore
+	approvedBy []string
+}
+
+func (s *legacyStore) RequiresHRApproval(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+func (s *legacyStore) ManagerUserIDForEmployee(context.Context, string, string) (string, error) {
+	return "manager", nil
+}
+
+func (s *legacyStore) InsertApproval(_ context.Context, _, _, approverID, _ string) error {
+	s.approvedBy = append(s.approvedBy, approverID)
+	return nil
+}
+
+func TestApproveRequestByManagerDelegate(t *testing.T) {
+	store := &legacyStore{chainStore: &chainStore{status: StatusPending}}
+	lookup := delegationLookup{delegators: map[string][]string{"deputy": {"manager"}}}
+	service := &Service{Store: store, Employees: lookup}
+	ctx := context.Background()
+
+	if _, err := service.ApproveRequest(ctx, "t1", "r1", "stranger", auth.RoleManager); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected non-delegate to be forbidden, got %v", err)
+	}
+
+	result, err := service.ApproveRequest(ctx, "t1", "r1", "deputy", auth.RoleManager)
+	if err != nil {
+		t.Fatalf("approve as delegate: %v", err)
+	}
+	if result.OnBehalfOf != "manager" || result.Status != StatusApproved {
+		t.Fatalf("expected delegate approval for manager, got %+v", result)
+	}
+	if len(store.approvedBy) != 1 || store.approvedBy[0] != "deputy" {
+		t.Fatalf("expected approval recorded for the delegate, got %v", store.approvedBy)
+	}
+}
+
+func TestApproveChainStepByDelegate(t *testing.T) {
+	store := &chainStore{
+		status: StatusPending,
+		approvals: []LeaveApproval{
+			{ID: "a1", StepOrder: 1, ApproverID: "head", Status: ApprovalPending},
+		},
+	}
+	lookup := delegationLookup{delegators: map[string][]string{"deputy": {"head"}}}
+	service := &Service{Store: store, Employees: lookup}
+	ctx := context.Background()
+
+	if _, err := service.ApproveRequest(ctx, "t1", "r1", "stranger", auth.RoleManager); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected non-delegate to be forbidden, got %v", err)
+	}
+
+	result, err := service.ApproveRequest(ctx, "t1", "r1", "deputy", auth.RoleManager)
+	if err != nil {
+		t.Fatalf("approve step as delegate: %v", err)
+	}
+	if result.OnBehalfOf != "head" || !result.FinalApproval {
+		t.Fatalf("expected final approval on behalf of head, got %+v", result)
+	}
+	if store.approvals[0].ApproverID != "deputy" {
+		t.Fatalf("expected step decided by delegate, got %+v", store.approvals[0])
+	}
+}
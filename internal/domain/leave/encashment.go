@@ -0,0 +1,285 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Encashment converts unused leave into pay. Amount is taken from the balance
+// in the leave type's unit; Days is the same amount in working days, which is
+// what DailyRate values. Approval deducts Amount from the balance and adds
+// Value to the payroll period PayrollPeriodID as a payroll adjustment.
+type Encashment struct {
+	ID                  string     `json:"id"`
+	EmployeeID          string     `json:"employeeId"`
+	LeaveTypeID         string     `json:"leaveTypeId"`
+	Amount              float64    `json:"amount"`
+	Days                float64    `json:"days"`
+	Source              string     `json:"source"`
+	Status              string     `json:"status"`
+	Reason              string     `json:"reason"`
+	DailyRate           float64    `json:"dailyRate"`
+	Value               float64    `json:"value"`
+	Currency            string     `json:"currency,omitempty"`
+	PayrollPeriodID     string     `json:"payrollPeriodId,omitempty"`
+	PayrollAdjustmentID string     `json:"payrollAdjustmentId,omitempty"`
+	RequestedBy         string     `json:"requestedBy,omitempty"`
+	DecidedBy           string     `json:"decidedBy,omitempty"`
+	DecidedAt           *time.Time `json:"decidedAt,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+}
+
+// PayoutQuote is the daily rate encashed leave is paid at and the payroll
+// period that pays it.
+type PayoutQuote struct {
+	PeriodID  string
+	DailyRate float64
+	Currency  string
+}
+
+// PayoutQuoter values encashed leave for payroll. QuoteLeavePayout returns
+// ErrNoPayrollPeriod when no open period can pay an encashment made on the
+// given day, and QuoteTerminationPayout when the period covering a leaver's
+// end date is no longer open.
+type PayoutQuoter interface {
+	QuoteLeavePayout(ctx context.Context, tenantID, employeeID string, on time.Time) (PayoutQuote, error)
+	QuoteTerminationPayout(ctx context.Context, tenantID, employeeID string, endDate time.Time) (PayoutQuote, error)
+}
+
+// Termination is an employee whose end date has been reached but whose
+// leave has not been settled for it yet.
+type Termination struct {
+	EmployeeID string
+	EndDate    time.Time
+}
+
+// TerminationSummary reports a termination payout run.
+type TerminationSummary struct {
+	Employees int `json:"employees"`
+	Settled   int `json:"settled"`
+}
+
+// EncashmentResult reports a new encashment request and the HR users who
+// decide it.
+type EncashmentResult struct {
+	ID        string
+	HRUserIDs []string
+}
+
+// EncashmentDecision describes an approved or rejected encashment for
+// notifications and audit.
+type EncashmentDecision struct {
+	Encashment    Encashment
+	EmployeeUser  string
+	LeaveTypeName string
+}
+
+// EncashableBalance is an employee's unused balance of an encashable leave
+// type.
+type EncashableBalance struct {
+	LeaveTypeID string
+	Available   float64
+}
+
+// encashmentValue prices days of leave at the daily rate, in cents.
+func encashmentValue(days, dailyRate float64) float64 {
+	return math.Round(days*dailyRate*100) / 100
+}
+
+func (s *Service) ListEncashments(ctx context.Context, tenantID, employeeID string) ([]Encashment, error) {
+	return s.Store.ListEncashments(ctx, tenantID, employeeID)
+}
+
+func (s *Service) GetEncashment(ctx context.Context, tenantID, encashmentID string) (Encashment, error) {
+	return s.Store.GetEncashment(ctx, tenantID, encashmentID)
+}
+
+// RequestEncashment records a pending request to cash out part of an
+// employee's balance. The leave type must be encashable and the amount must
+// not exceed what is left after used and pending leave.
+func (s *Service) RequestEncashment(ctx context.Context, tenantID string, encashment Encashment) (EncashmentResult, error) {
+	var result EncashmentResult
+	if encashment.Amount <= 0 {
+		return result, ErrInsufficientBalance
+	}
+	leaveType, err := s.Store.GetType(ctx, tenantID, encashment.LeaveTypeID)
+	if err != nil {
+		return result, err
+	}
+	if !leaveType.Encashable {
+		return result, ErrNotEncashable
+	}
+	available, err := s.Store.AvailableBalance(ctx, tenantID, encashment.EmployeeID, encashment.LeaveTypeID)
+	if err != nil {
+		return result, err
+	}
+	encashment.Amount = roundHours(encashment.Amount)
+	if encashment.Amount > available {
+		return result, ErrInsufficientBalance
+	}
+	encashment.Status = EncashmentPending
+	if encashment.Source == "" {
+		encashment.Source = EncashmentSourceRequest
+	}
+	if result.ID, err = s.Store.CreateEncashment(ctx, tenantID, encashment); err != nil {
+		return result, err
+	}
+	if hrUserIDs, err := s.Store.HRUserIDs(ctx, tenantID); err == nil {
+		result.HRUserIDs = hrUserIDs
+	}
+	return result, nil
+}
+
+// ApproveEncashment values a pending encashment at the employee's daily rate,
+// deducts it from the balance and queues the payout in the next open payroll
+// period ending on or after the given day.
+func (s *Service) ApproveEncashment(ctx context.Context, tenantID, encashmentID, approverUserID string, on time.Time) (EncashmentDecision, error) {
+	decision, err := s.pendingEncashment(ctx, tenantID, encashmentID)
+	if err != nil {
+		return decision, err
+	}
+	decision.Encashment, err = s.settleEncashment(ctx, tenantID, decision.Encashment, approverUserID, on, false)
+	return decision, err
+}
+
+// RejectEncashment rejects a pending encashment, leaving the balance as it
+// was.
+func (s *Service) RejectEncashment(ctx context.Context, tenantID, encashmentID, approverUserID string) (EncashmentDecision, error) {
+	decision, err := s.pendingEncashment(ctx, tenantID, encashmentID)
+	if err != nil {
+		return decision, err
+	}
+	if err := s.Store.RejectEncashment(ctx, tenantID, encashmentID, approverUserID); err != nil {
+		return decision, err
+	}
+	decision.Encashment.Status = EncashmentRejected
+	decision.Encashment.DecidedBy = approverUserID
+	return decision, nil
+}
+
+// pendingEncashment loads an encashment awaiting a decision with the
+// employee's user and leave type name.
+func (s *Service) pendingEncashment(ctx context.Context, tenantID, encashmentID string) (EncashmentDecision, error) {
+	var decision EncashmentDecision
+	encashment, err := s.Store.GetEncashment(ctx, tenantID, encashmentID)
+	if err != nil {
+		return decision, err
+	}
+	decision.Encashment = encashment
+	if encashment.Status != EncashmentPending {
+		return decision, ErrInvalidState
+	}
+	if employeeUser, leaveTypeName, err := s.Store.EmployeeUserAndLeaveType(ctx, tenantID, encashment.LeaveTypeID, encashment.EmployeeID); err == nil {
+		decision.EmployeeUser = employeeUser
+		decision.LeaveTypeName = leaveTypeName
+	}
+	return decision, nil
+}
+
+// SettleTerminations settles the leave of every employee whose end date is on
+// or before the given day and has not been settled yet. Employees who still
+// have a pending termination encashment are left to HR.
+func (s *Service) SettleTerminations(ctx context.Context, tenantID string, on time.Time) (TerminationSummary, error) {
+	var summary TerminationSummary
+	due, err := s.Store.TerminationsDue(ctx, tenantID, dateOnly(on))
+	if err != nil {
+		return summary, err
+	}
+	var errs []error
+	for _, termination := range due {
+		summary.Employees++
+		settled, err := s.SettleTermination(ctx, tenantID, termination.EmployeeID, "", termination.EndDate)
+		summary.Settled += len(settled)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("employee %s: %w", termination.EmployeeID, err))
+		}
+	}
+	return summary, errors.Join(errs...)
+}
+
+// SettleTermination pays out the unused balance of every encashable leave
+// type once an employee has left, and records the end date as settled. Each
+// balance becomes an approved termination encashment paid in the payroll
+// period covering the end date. A balance that cannot be paid stays behind as
+// a pending encashment for HR, and its error is returned alongside the
+// encashments that were settled.
+func (s *Service) SettleTermination(ctx context.Context, tenantID, employeeID, userID string, endDate time.Time) ([]Encashment, error) {
+	balances, err := s.Store.EncashableBalances(ctx, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Store.MarkTerminationSettled(ctx, tenantID, employeeID, endDate); err != nil {
+		return nil, err
+	}
+	var settled []Encashment
+	var errs []error
+	for _, balance := range balances {
+		if balance.Available <= 0 {
+			continue
+		}
+		encashment := Encashment{
+			EmployeeID:  employeeID,
+			LeaveTypeID: balance.LeaveTypeID,
+			Amount:      roundHours(balance.Available),
+			Source:      EncashmentSourceTermination,
+			Status:      EncashmentPending,
+			Reason:      "Termination payout on " + endDate.Format("2006-01-02"),
+			RequestedBy: userID,
+		}
+		if encashment.ID, err = s.Store.CreateEncashment(ctx, tenantID, encashment); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		paid, err := s.settleEncashment(ctx, tenantID, encashment, userID, endDate, true)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("leave type %s: %w", balance.LeaveTypeID, err))
+			continue
+		}
+		settled = append(settled, paid)
+	}
+	return settled, errors.Join(errs...)
+}
+
+// settleEncashment prices an encashment and approves it. Hourly balances are
+// converted to days at the employee's working day length. A termination
+// payout is only paid in the period covering on, the end date.
+func (s *Service) settleEncashment(ctx context.Context, tenantID string, encashment Encashment, approverUserID string, on time.Time, termination bool) (Encashment, error) {
+	if s.Payroll == nil {
+		return encashment, ErrNoPayrollPeriod
+	}
+	leaveType, err := s.Store.GetType(ctx, tenantID, encashment.LeaveTypeID)
+	if err != nil {
+		return encashment, err
+	}
+	encashment.Days = encashment.Amount
+	if leaveType.Hourly() {
+		schedule, err := s.Store.WorkSchedule(ctx, tenantID, encashment.EmployeeID, on, on)
+		if err != nil {
+			return encashment, err
+		}
+		encashment.Days = encashment.Amount / schedule.HoursPerDay()
+	}
+
+	quote, err := s.Payroll.QuoteLeavePayout(ctx, tenantID, encashment.EmployeeID, on)
+	if termination {
+		quote, err = s.Payroll.QuoteTerminationPayout(ctx, tenantID, encashment.EmployeeID, on)
+	}
+	if err != nil {
+		return encashment, err
+	}
+	encashment.DailyRate = quote.DailyRate
+	encashment.Value = encashmentValue(encashment.Days, quote.DailyRate)
+	encashment.Currency = quote.Currency
+	encashment.PayrollPeriodID = quote.PeriodID
+
+	description := fmt.Sprintf("Leave encashment: %.2f %s of %s", encashment.Amount, leaveType.Unit, leaveType.Name)
+	if encashment.PayrollAdjustmentID, err = s.Store.ApproveEncashment(ctx, tenantID, encashment, approverUserID, description); err != nil {
+		return encashment, err
+	}
+	encashment.Status = EncashmentApproved
+	encashment.DecidedBy = approverUserID
+	return encashment, nil
+}
@@ -0,0 +1,164 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type encashmentStore struct {
+	StoreAPI
+	balances  map[string]float64
+	created   []Encashment
+	approved  []Encashment
+	approveOK bool
+
+	due                 []Termination
+	settledTerminations int
+}
+
+func (s *encashmentStore) GetType(_ context.Context, _, leaveTypeID string) (LeaveType, error) {
+	switch leaveTypeID {
+	case "type-annual":
+		return LeaveType{ID: leaveTypeID, Name: "Annual", Unit: UnitDays, Encashable: true}, nil
+	case "type-toil":
+		return LeaveType{ID: leaveTypeID, Name: "TOIL", Unit: UnitHours, Encashable: true}, nil
+	case "type-sick":
+		return LeaveType{ID: leaveTypeID, Name: "Sick", Unit: UnitDays}, nil
+	}
+	return LeaveType{}, ErrLeaveTypeNotFound
+}
+
+func (s *encashmentStore) AvailableBalance(_ context.Context, _, _, leaveTypeID string) (float64, error) {
+	return s.balances[leaveTypeID], nil
+}
+
+func (s *encashmentStore) EncashableBalances(context.Context, string, string) ([]EncashableBalance, error) {
+	return []EncashableBalance{
+		{LeaveTypeID: "type-annual", Available: s.balances["type-annual"]},
+		{LeaveTypeID: "type-toil", Available: s.balances["type-toil"]},
+	}, nil
+}
+
+func (s *encashmentStore) CreateEncashment(_ context.Context, _ string, encashment Encashment) (string, error) {
+	s.created = append(s.created, encashment)
+	return "enc-" + encashment.LeaveTypeID, nil
+}
+
+func (s *encashmentStore) HRUserIDs(context.Context, string) ([]string, error) {
+	return []string{"hr-1"}, nil
+}
+
+func (s *encashmentStore) WorkSchedule(context.Context, string, string, time.Time, time.Time) (WorkSchedule, error) {
+	pattern := StandardWorkPattern()
+	pattern.HoursPerDay = 7.5
+	return WorkSchedule{Pattern: pattern}, nil
+}
+
+func (s *encashmentStore) MarkTerminationSettled(context.Context, string, string, time.Time) error {
+	s.settledTerminations++
+	return nil
+}
+
+func (s *encashmentStore) TerminationsDue(context.Context, string, time.Time) ([]Termination, error) {
+	return s.due, nil
+}
+
+func (s *encashmentStore) ApproveEncashment(_ context.Context, _ string, encashment Encashment, _, _ string) (string, error) {
+	s.approved = append(s.approved, encashment)
+	return "adj-" + encashment.LeaveTypeID, nil
+}
+
+type fixedQuoter struct {
+	quote PayoutQuote
+	err   error
+}
+
+func (q fixedQuoter) QuoteLeavePayout(context.Context, string, string, time.Time) (PayoutQuote, error) {
+	return PayoutQuote{}, errors.New("termination payouts must use the period covering the end date")
+}
+
+func (q fixedQuoter) QuoteTerminationPayout(context.Context, string, string, time.Time) (PayoutQuote, error) {
+	return q.quote, q.err
+}
+
+func TestRequestEncashmentChecksTypeAndBalance(t *testing.T) {
+	store := &encashmentStore{balances: map[string]float64{"type-annual": 5, "type-sick": 10}}
+	service := &Service{Store: store}
+	encashment := Encashment{EmployeeID: "emp-1", LeaveTypeID: "type-sick", Amount: 2}
+
+	if _, err := service.RequestEncashment(context.Background(), "t1", encashment); !errors.Is(err, ErrNotEncashable) {
+		t.Fatalf("expected non-encashable type to be refused, got %v", err)
+	}
+	encashment.LeaveTypeID = "type-annual"
+	encashment.Amount = 5.5
+	if _, err := service.RequestEncashment(context.Background(), "t1", encashment); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("expected amount above the available balance to be refused, got %v", err)
+	}
+
+	encashment.Amount = 3
+	result, err := service.RequestEncashment(context.Background(), "t1", encashment)
+	if err != nil {
+		t.Fatalf("request encashment: %v", err)
+	}
+	if len(store.created) != 1 || store.created[0].Status != EncashmentPending || store.created[0].Source != EncashmentSourceRequest {
+		t.Fatalf("expected one pending requested encashment, got %+v", store.created)
+	}
+	if len(result.HRUserIDs) != 1 {
+		t.Fatalf("expected hr to be asked to decide, got %+v", result)
+	}
+}
+
+func TestSettleTerminationPaysEncashableBalances(t *testing.T) {
+	store := &encashmentStore{balances: map[string]float64{"type-annual": 4, "type-toil": 15}}
+	service := &Service{Store: store, Payroll: fixedQuoter{quote: PayoutQuote{PeriodID: "period-1", DailyRate: 200, Currency: "USD"}}}
+	endDate := utcDate(2026, time.March, 31)
+
+	settled, err := service.SettleTermination(context.Background(), "t1", "emp-1", "hr-1", endDate)
+	if err != nil {
+		t.Fatalf("settle termination: %v", err)
+	}
+	if len(settled) != 2 || len(store.approved) != 2 {
+		t.Fatalf("expected both balances to be paid out, got %+v", settled)
+	}
+	annual, toil := settled[0], settled[1]
+	if annual.Days != 4 || annual.Value != 800 || annual.Source != EncashmentSourceTermination || annual.PayrollPeriodID != "period-1" {
+		t.Fatalf("unexpected annual payout %+v", annual)
+	}
+	if toil.Amount != 15 || toil.Days != 2 || toil.Value != 400 {
+		t.Fatalf("expected 15 hours paid as two 7.5 hour days, got %+v", toil)
+	}
+	if annual.Status != EncashmentApproved || annual.PayrollAdjustmentID != "adj-type-annual" {
+		t.Fatalf("expected approved payout linked to its payroll adjustment, got %+v", annual)
+	}
+
+	store = &encashmentStore{balances: map[string]float64{"type-annual": 4}}
+	service = &Service{Store: store, Payroll: fixedQuoter{err: ErrNoPayrollPeriod}}
+	settled, err = service.SettleTermination(context.Background(), "t1", "emp-1", "hr-1", endDate)
+	if !errors.Is(err, ErrNoPayrollPeriod) || len(settled) != 0 {
+		t.Fatalf("expected no payout without an open period, got %+v and %v", settled, err)
+	}
+	if len(store.created) != 1 || store.created[0].Status != EncashmentPending {
+		t.Fatalf("expected the unpaid balance to be left pending for hr, got %+v", store.created)
+	}
+}
+
+func TestSettleTerminationsPaysDueLeavers(t *testing.T) {
+	store := &encashmentStore{
+		balances: map[string]float64{"type-annual": 2},
+		due:      []Termination{{EmployeeID: "emp-1", EndDate: utcDate(2026, time.March, 31)}},
+	}
+	service := &Service{Store: store, Payroll: fixedQuoter{quote: PayoutQuote{PeriodID: "period-1", DailyRate: 100}}}
+
+	summary, err := service.SettleTerminations(context.Background(), "t1", utcDate(2026, time.April, 1))
+	if err != nil {
+		t.Fatalf("settle terminations: %v", err)
+	}
+	if summary.Employees != 1 || summary.Settled != 1 || store.settledTerminations != 1 {
+		t.Fatalf("expected the leaver's balance to be paid and their end date marked settled, got %+v", summary)
+	}
+	if store.created[0].RequestedBy != "" || store.approved[0].Value != 200 {
+		t.Fatalf("expected a system payout of two days, got %+v", store.approved[0])
+	}
+}
@@ -0,0 +1,84 @@
+package leave
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar event statuses.
+const (
+	ICalConfirmed = "CONFIRMED"
+	ICalTentative = "TENTATIVE"
+	ICalCancelled = "CANCELLED"
+)
+
+// ICalEvent is an all-day event in an iCalendar feed, running from Start to
+// End inclusive. UID stays the same for the life of the record behind the
+// event, and Sequence grows with each change to it, so calendar apps update
+// or cancel the event they already hold rather than adding another.
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Start       time.Time
+	End         time.Time
+	Status      string
+	Sequence    int
+	Modified    time.Time
+	Transparent bool
+}
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// RenderICalendar writes events as an iCalendar (RFC 5545) calendar called
+// name. Nothing in the output depends on when it is rendered, so an unchanged
+// feed renders to the same bytes.
+func RenderICalendar(name string, events []ICalEvent) []byte {
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//PulseHR//Leave Calendar//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+icalTextEscaper.Replace(name))
+	for _, event := range events {
+		stamp := event.Modified.UTC().Format("20060102T150405Z")
+		transparency := "OPAQUE"
+		if event.Transparent {
+			transparency = "TRANSPARENT"
+		}
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+event.UID)
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "LAST-MODIFIED:"+stamp)
+		writeICalLine(&b, "SEQUENCE:"+strconv.Itoa(event.Sequence))
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+event.Start.Format("20060102"))
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+event.End.AddDate(0, 0, 1).Format("20060102"))
+		writeICalLine(&b, "SUMMARY:"+icalTextEscaper.Replace(event.Summary))
+		writeICalLine(&b, "STATUS:"+event.Status)
+		writeICalLine(&b, "TRANSP:"+transparency)
+		writeICalLine(&b, "END:VEVENT")
+	}
+	writeICalLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// writeICalLine writes a content line, folded so no line is longer than 75
+// octets without splitting a UTF-8 character.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with the folding space.
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -2,6 +2,7 @@ package leave
 
 import (
 	"errors"
+	"math"
 	"time"
 )
 
@@ -13,26 +14,71 @@ func CalculateDays(start, end time.Time) (float64, error) {
 	return end.Sub(start).Hours()/24 + 1, nil
 }
 
-// CalculateRequestDays returns inclusive leave day count with optional half-day start/end boundaries.
-func CalculateRequestDays(start, end time.Time, startHalf, endHalf bool) (float64, error) {
-	days, err := CalculateDays(start, end)
-	if err != nil {
-		return 0, err
+// WorkingDays returns the scheduled working days between start and end
+// inclusive, skipping rest days in the work pattern and holidays.
+func WorkingDays(start, end time.Time, schedule WorkSchedule) (float64, error) {
+	if end.Before(start) {
+		return 0, errors.New("end date before start date")
+	}
+	var days float64
+	for day := dateOnly(start); !day.After(dateOnly(end)); day = day.AddDate(0, 0, 1) {
+		days += schedule.WorkingFraction(day)
 	}
+	return days, nil
+}
 
+// CalculateRequestDays returns the working days a leave request uses with
+// optional half-day start/end boundaries. A half-day boundary takes half of
+// the time scheduled on that day.
+func CalculateRequestDays(start, end time.Time, startHalf, endHalf bool, schedule WorkSchedule) (float64, error) {
 	sameDay := start.Equal(end)
 	if sameDay && startHalf && endHalf {
-		return 0, errors.New("invalid half-day range")
+		return 0, ErrInvalidHalfDay
+	}
+
+	days, err := WorkingDays(start, end, schedule)
+	if err != nil {
+		return 0, err
+	}
+	if days <= 0 {
+		return 0, ErrNoWorkingDays
 	}
 
 	if startHalf {
-		days -= 0.5
+		days -= schedule.WorkingFraction(start) / 2
 	}
 	if endHalf {
-		days -= 0.5
+		days -= schedule.WorkingFraction(end) / 2
 	}
 	if days <= 0 {
-		return 0, errors.New("invalid half-day range")
+		return 0, ErrInvalidHalfDay
 	}
 	return days, nil
 }
+
+// CalculateRequestHours returns the working days and hours a request against
+// an hourly leave type uses. Without explicit hours the request takes every
+// hour scheduled in the range, honouring half-day boundaries. Explicit hours
+// are allowed for a single day without half-day flags and may not exceed the
+// hours scheduled that day; the days are then the matching share of it.
+func CalculateRequestHours(start, end time.Time, startHalf, endHalf bool, hours float64, schedule WorkSchedule) (float64, float64, error) {
+	days, err := CalculateRequestDays(start, end, startHalf, endHalf, schedule)
+	if err != nil {
+		return 0, 0, err
+	}
+	if hours == 0 {
+		return days, roundHours(days * schedule.HoursPerDay()), nil
+	}
+	if hours < 0 || !start.Equal(end) || startHalf || endHalf {
+		return 0, 0, ErrInvalidHours
+	}
+	scheduled := schedule.WorkingHours(start)
+	if hours > scheduled {
+		return 0, 0, ErrInvalidHours
+	}
+	return days * hours / scheduled, roundHours(hours), nil
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
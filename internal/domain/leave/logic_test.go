@@ -1,6 +1,7 @@
 package leave
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -93,7 +94,7 @@ func TestCalculateRequestDays(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := CalculateRequestDays(tc.start, tc.end, tc.startHalf, tc.endHalf)
+			got, err := CalculateRequestDays(tc.start, tc.end, tc.startHalf, tc.endHalf, WorkSchedule{})
 			if tc.wantErr {
 				if err == nil {
 					t.Fatal("expected error")
@@ -109,3 +110,49 @@ func TestCalculateRequestDays(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateRequestDaysSkipsWeekendsAndHolidays(t *testing.T) {
+	schedule := WorkSchedule{Pattern: StandardWorkPattern()}
+	friday := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	days, err := CalculateRequestDays(friday, monday, false, false, schedule)
+	if err != nil || days != 2 {
+		t.Fatalf("expected Friday to Monday to cost 2 days, got %v (%v)", days, err)
+	}
+
+	schedule.Holidays = map[string]bool{"2026-01-12": true}
+	days, err = CalculateRequestDays(friday, monday, true, false, schedule)
+	if err != nil || days != 0.5 {
+		t.Fatalf("expected a Friday afternoon before a holiday Monday to cost 0.5 days, got %v (%v)", days, err)
+	}
+
+	saturday := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	sunday := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+	if _, err := CalculateRequestDays(saturday, sunday, false, false, schedule); !errors.Is(err, ErrNoWorkingDays) {
+		t.Fatalf("expected a weekend request to have no working days, got %v", err)
+	}
+}
+
+func TestWorkPatternRotaCycles(t *testing.T) {
+	// Four days on, four days off, with a half shift on the fourth day.
+	rota := WorkPattern{
+		CycleStart: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Days:       []float64{1, 1, 1, 0.5, 0, 0, 0, 0},
+	}
+	if !rota.Valid() {
+		t.Fatal("expected rota to be valid")
+	}
+	schedule := WorkSchedule{Pattern: rota}
+
+	days, err := WorkingDays(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), schedule)
+	if err != nil || days != 7 {
+		t.Fatalf("expected two cycles of 3.5 days, got %v (%v)", days, err)
+	}
+	if fraction := rota.DayFraction(time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)); fraction != 0 {
+		t.Fatalf("expected the day before the cycle start to be a rest day, got %v", fraction)
+	}
+	if (WorkPattern{Days: []float64{0, 0}}).Valid() || (WorkPattern{Days: []float64{1.5}}).Valid() {
+		t.Fatal("expected patterns without working days or with over-long days to be invalid")
+	}
+}
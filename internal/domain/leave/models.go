@@ -2,24 +2,41 @@ package leave
 
 import "time"
 
+// LeaveType is a kind of leave. Unit is UnitDays or UnitHours; balances and
+// requests of an hourly type are counted in hours. TOIL types are hourly and
+// are credited from approved overtime in the TOIL ledger; TOILExpiryDays,
+// when positive, is how long credited hours stay usable.
 type LeaveType struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Code        string    `json:"code"`
-	IsPaid      bool      `json:"isPaid"`
-	RequiresDoc bool      `json:"requiresDoc"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Code           string    `json:"code"`
+	IsPaid         bool      `json:"isPaid"`
+	RequiresDoc    bool      `json:"requiresDoc"`
+	Unit           string    `json:"unit"`
+	TOIL           bool      `json:"toil"`
+	TOILExpiryDays int       `json:"toilExpiryDays"`
+	Encashable     bool      `json:"encashable"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Hourly reports whether the type is counted in hours.
+func (t LeaveType) Hourly() bool {
+	return t.Unit == UnitHours
 }
 
 type LeavePolicy struct {
-	ID                 string  `json:"id"`
-	LeaveTypeID        string  `json:"leaveTypeId"`
-	AccrualRate        float64 `json:"accrualRate"`
-	AccrualPeriod      string  `json:"accrualPeriod"`
-	Entitlement        float64 `json:"entitlement"`
-	CarryOver          float64 `json:"carryOverLimit"`
-	AllowNegative      bool    `json:"allowNegative"`
-	RequiresHRApproval bool    `json:"requiresHrApproval"`
+	ID                    string        `json:"id"`
+	LeaveTypeID           string        `json:"leaveTypeId"`
+	AccrualRate           float64       `json:"accrualRate"`
+	AccrualPeriod         string        `json:"accrualPeriod"`
+	Entitlement           float64       `json:"entitlement"`
+	CarryOver             float64       `json:"carryOverLimit"`
+	CarryOverExpiryMonths int           `json:"carryOverExpiryMonths"`
+	YearStartMonth        int           `json:"yearStartMonth"`
+	YearStartDay          int           `json:"yearStartDay"`
+	AccrualBands          []AccrualBand `json:"accrualBands,omitempty"`
+	AllowNegative         bool          `json:"allowNegative"`
+	RequiresHRApproval    bool          `json:"requiresHrApproval"`
 }
 
 type LeaveRequest struct {
@@ -31,9 +48,11 @@ type LeaveRequest struct {
 	StartHalf   bool                   `json:"startHalf"`
 	EndHalf     bool                   `json:"endHalf"`
 	Days        float64                `json:"days"`
+	Hours       *float64               `json:"hours,omitempty"`
 	Reason      string                 `json:"reason"`
 	Status      string                 `json:"status"`
 	Documents   []LeaveRequestDocument `json:"documents,omitempty"`
+	Approvals   []LeaveApproval        `json:"approvals,omitempty"`
 	CreatedAt   time.Time              `json:"createdAt"`
 }
 
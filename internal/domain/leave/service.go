@@ -12,18 +12,40 @@ import (
 type Service struct {
 	Store     StoreAPI
 	Employees EmployeeLookup
+	// Payroll values encashed leave and pays it out. Encashments cannot be
+	// approved without it.
+	Payroll PayoutQuoter
 }
 
 type EmployeeLookup interface {
 	EmployeeIDByUserID(ctx context.Context, tenantID, userID string) (string, error)
 	ManagerIDByEmployeeID(ctx context.Context, tenantID, employeeID string) (string, error)
 	IsManagerOf(ctx context.Context, tenantID, managerEmployeeID, employeeID string) (bool, error)
+	DelegatorsFor(ctx context.Context, tenantID, delegateUserID, scope string, on time.Time) ([]string, error)
+	ActingDelegate(ctx context.Context, tenantID, approverUserID, scope string, on time.Time) (string, error)
 }
 
 var (
-	ErrHRApprovalRequired = errors.New("hr approval required")
-	ErrForbidden          = errors.New("forbidden")
-	ErrInvalidState       = errors.New("invalid state")
+	ErrHRApprovalRequired    = errors.New("hr approval required")
+	ErrForbidden             = errors.New("forbidden")
+	ErrInvalidState          = errors.New("invalid state")
+	ErrInvalidHalfDay        = errors.New("invalid half-day range")
+	ErrNoWorkingDays         = errors.New("no scheduled working days in range")
+	ErrInvalidHours          = errors.New("invalid leave hours")
+	ErrLeaveTypeNotFound     = errors.New("leave type not found")
+	ErrNotTOILType           = errors.New("leave type does not track toil")
+	ErrTOILEntryNotFound     = errors.New("toil entry not found")
+	ErrNotEncashable         = errors.New("leave type cannot be encashed")
+	ErrInsufficientBalance   = errors.New("insufficient leave balance")
+	ErrEncashmentNotFound    = errors.New("leave encashment not found")
+	ErrNoPayrollPeriod       = errors.New("no open payroll period")
+	ErrEmployeeNotFound      = errors.New("employee not found")
+	ErrDepartmentNotFound    = errors.New("department not found")
+	ErrWorkPatternNotFound   = errors.New("work pattern not found")
+	ErrApprovalChainNotFound = errors.New("approval chain not found")
+	ErrStaffingRuleNotFound  = errors.New("staffing rule not found")
+	ErrCalendarFeedNotFound  = errors.New("calendar feed not found")
+	ErrStaffingBlocked       = errors.New("leave breaks a blocking staffing rule")
 )
 
 func NewService(store StoreAPI, coreStore *core.Store) *Service {
@@ -38,6 +60,10 @@ func (s *Service) CreateType(ctx context.Context, tenantID string, payload Leave
 	return s.Store.CreateType(ctx, tenantID, payload)
 }
 
+func (s *Service) GetType(ctx context.Context, tenantID, leaveTypeID string) (LeaveType, error) {
+	return s.Store.GetType(ctx, tenantID, leaveTypeID)
+}
+
 func (s *Service) LeaveTypeRequiresDoc(ctx context.Context, tenantID, leaveTypeID string) (bool, error) {
 	return s.Store.LeaveTypeRequiresDoc(ctx, tenantID, leaveTypeID)
 }
@@ -97,8 +123,11 @@ type RequestListResult struct {
 	Total    int
 }
 
-func (s *Service) ListRequests(ctx context.Context, tenantID, roleName, employeeID, managerEmployeeID string, limit, offset int) (RequestListResult, error) {
-	return s.Store.ListRequests(ctx, tenantID, roleName, employeeID, managerEmployeeID, limit, offset)
+// ListRequests lists the requests visible to a role. Managers see the
+// requests with an approval step assigned to one of approverUserIDs that is
+// awaiting them or that they have decided.
+func (s *Service) ListRequests(ctx context.Context, tenantID, roleName, employeeID string, approverUserIDs []string, limit, offset int) (RequestListResult, error) {
+	return s.Store.ListRequests(ctx, tenantID, roleName, employeeID, approverUserIDs, limit, offset)
 }
 
 func (s *Service) GetRequest(ctx context.Context, tenantID, requestID string) (LeaveRequest, error) {
@@ -110,51 +139,53 @@ type CreateRequestResult struct {
 	Status        string
 	ManagerUserID string
 	HRUserIDs     []string
+	// StaffingConflicts are the staffing rules the request breaks, checked
+	// while the request is filed.
+	StaffingConflicts []StaffingConflict
 }
 
-func (s *Service) CreateRequest(ctx context.Context, tenantID, employeeID, leaveTypeID, reason string, startDate, endDate time.Time, startHalf, endHalf bool, days float64) (CreateRequestResult, error) {
+// CreateRequest files a leave request and holds its amount as pending on the
+// balance: hours for hourly leave types, which pass hours greater than zero,
+// and days otherwise.
+func (s *Service) CreateRequest(ctx context.Context, tenantID, employeeID, leaveTypeID, reason string, startDate, endDate time.Time, startHalf, endHalf bool, days, hours float64) (CreateRequestResult, error) {
 	result := CreateRequestResult{Status: StatusPending}
-	requiresHR, err := s.Store.RequiresHRApproval(ctx, tenantID, leaveTypeID)
-	if err != nil {
-		requiresHR = false
-	}
 
-	if id, err := s.Store.CreateRequest(ctx, tenantID, employeeID, leaveTypeID, reason, startDate, endDate, startHalf, endHalf, days, StatusPending); err != nil {
+	approvals, err := s.planApprovals(ctx, tenantID, employeeID, leaveTypeID, days)
+	if err != nil {
 		return result, err
-	} else {
-		result.ID = id
 	}
-
-	if err := s.Store.AddPendingBalance(ctx, tenantID, employeeID, leaveTypeID, days); err != nil {
-		return result, err
+	if len(approvals) > 0 {
+		result.Status = stepStatus(approvals[0])
+		if result.Status != StatusPendingHR {
+			result.ManagerUserID = approvals[0].ApproverID
+		}
+	} else {
+		if managerUserID, err := s.Store.ManagerUserIDForEmployee(ctx, tenantID, employeeID); err == nil {
+			result.ManagerUserID = managerUserID
+		}
+		if result.ManagerUserID != "" {
+			approvals = []LeaveApproval{{ApproverID: result.ManagerUserID, Status: ApprovalPending}}
+		} else {
+			result.Status = StatusPendingHR
+		}
 	}
 
-	if managerUserID, err := s.Store.ManagerUserIDForEmployee(ctx, tenantID, employeeID); err != nil {
-		result.ManagerUserID = ""
-	} else {
-		result.ManagerUserID = managerUserID
+	amount := days
+	if hours > 0 {
+		amount = hours
 	}
-	if result.ManagerUserID == "" {
-		requiresHR = true
+	check := staffingCheck(tenantID, []string{StatusPending, StatusPendingHR, StatusApproved}, &result.StaffingConflicts)
+	id, err := s.Store.CreateRequest(ctx, tenantID, employeeID, leaveTypeID, reason, startDate, endDate, startHalf, endHalf, days, hours, amount, result.Status, approvals, check)
+	if err != nil {
+		return result, err
 	}
+	result.ID = id
 
-	if result.ManagerUserID != "" {
-		if err := s.Store.InsertApproval(ctx, tenantID, result.ID, result.ManagerUserID, StatusPending); err != nil {
-			return result, err
-		}
-	} else if requiresHR {
-		if err := s.Store.UpdateRequestStatusSimple(ctx, result.ID, StatusPendingHR); err != nil {
-			return result, err
-		}
+	if result.Status == StatusPendingHR {
 		if hrUserIDs, err := s.Store.HRUserIDs(ctx, tenantID); err == nil {
 			result.HRUserIDs = hrUserIDs
 		}
 	}
-
-	if result.ManagerUserID == "" && requiresHR {
-		result.Status = StatusPendingHR
-	}
-
 	return result, nil
 }
 
@@ -174,7 +205,14 @@ type ApprovalResult struct {
 	EmployeeUser  string
 	ManagerUserID string
 	HRUserIDs     []string
+	// NextApproverID is the approver of the next approval chain step when
+	// it is assigned to a single user.
+	NextApproverID string
+	// OnBehalfOf is the approver a delegate decided for.
+	OnBehalfOf    string
 	FinalApproval bool
+	// StaffingConflicts are the staffing rules a final approval breaks.
+	StaffingConflicts []StaffingConflict
 }
 
 func (s *Service) ApproveRequest(ctx context.Context, tenantID, requestID, approverUserID, roleName string) (ApprovalResult, error) {
@@ -186,6 +224,14 @@ func (s *Service) ApproveRequest(ctx context.Context, tenantID, requestID, appro
 	result.EmployeeID = employeeID
 	result.LeaveTypeID = leaveTypeID
 
+	approvals, err := s.Store.ListApprovals(ctx, tenantID, requestID)
+	if err != nil {
+		return result, err
+	}
+	if steps := chainApprovals(approvals); len(steps) > 0 {
+		return s.approveChainStep(ctx, tenantID, requestID, approverUserID, roleName, currentStatus, days, steps, result)
+	}
+
 	requiresHR, err := s.Store.RequiresHRApproval(ctx, tenantID, leaveTypeID)
 	if err != nil {
 		requiresHR = false
@@ -196,15 +242,11 @@ func (s *Service) ApproveRequest(ctx context.Context, tenantID, requestID, appro
 	}
 
 	if roleName == auth.RoleManager {
-		if s.Employees != nil {
-			managerEmployeeID, err := s.Employees.ManagerIDByEmployeeID(ctx, tenantID, employeeID)
-			if err == nil && managerEmployeeID != "" {
-				selfEmployeeID, err := s.Employees.EmployeeIDByUserID(ctx, tenantID, approverUserID)
-				if err != nil || selfEmployeeID == "" || selfEmployeeID != managerEmployeeID {
-					return result, ErrForbidden
-				}
-			}
+		onBehalfOf, err := s.checkManagerApprover(ctx, tenantID, employeeID, approverUserID)
+		if err != nil {
+			return result, err
 		}
+		result.OnBehalfOf = onBehalfOf
 	}
 
 	finalApproval := !requiresHR || roleName == auth.RoleHR
@@ -216,7 +258,12 @@ func (s *Service) ApproveRequest(ctx context.Context, tenantID, requestID, appro
 	result.FinalApproval = finalApproval
 	result.Status = nextStatus
 
-	if err := s.Store.UpdateRequestStatus(ctx, requestID, nextStatus, approverUserID); err != nil {
+	if finalApproval {
+		check := staffingCheck(tenantID, []string{StatusApproved}, &result.StaffingConflicts)
+		if err := s.Store.ApproveRequestStatus(ctx, tenantID, requestID, approverUserID, check); err != nil {
+			return result, err
+		}
+	} else if err := s.Store.UpdateRequestStatus(ctx, requestID, nextStatus, approverUserID); err != nil {
 		return result, err
 	}
 
@@ -228,6 +275,9 @@ func (s *Service) ApproveRequest(ctx context.Context, tenantID, requestID, appro
 		if err := s.Store.UpdateBalanceOnApproval(ctx, tenantID, employeeID, leaveTypeID, days); err != nil {
 			return result, err
 		}
+		if err := s.Store.ConsumeTOIL(ctx, tenantID, employeeID, leaveTypeID, days); err != nil {
+			return result, err
+		}
 	} else {
 		if hrUserIDs, err := s.Store.HRUserIDs(ctx, tenantID); err == nil {
 			result.HRUserIDs = hrUserIDs
@@ -244,38 +294,116 @@ func (s *Service) ApproveRequest(ctx context.Context, tenantID, requestID, appro
 	return result, nil
 }
 
+// approveChainStep approves the current step of a request's approval chain
+// and either hands the request to the next step or, after the last step,
+// approves it.
+func (s *Service) approveChainStep(ctx context.Context, tenantID, requestID, approverUserID, roleName, currentStatus string, days float64, steps []LeaveApproval, result ApprovalResult) (ApprovalResult, error) {
+	current, next, ok := currentStep(steps)
+	if !ok || (currentStatus != StatusPending && currentStatus != StatusPendingHR) {
+		return result, ErrInvalidState
+	}
+	onBehalfOf, err := s.checkStepApprover(ctx, tenantID, current, approverUserID, roleName)
+	if err != nil {
+		return result, err
+	}
+	result.OnBehalfOf = onBehalfOf
+	if next == nil {
+		// Approve the request before deciding the last step so a staffing
+		// rule that blocks it leaves the step open.
+		check := staffingCheck(tenantID, []string{StatusApproved}, &result.StaffingConflicts)
+		if err := s.Store.ApproveRequestStatus(ctx, tenantID, requestID, approverUserID, check); err != nil {
+			return result, err
+		}
+	}
+	if err := s.Store.DecideApprovalStep(ctx, tenantID, current.ID, ApprovalApproved, approverUserID); err != nil {
+		return result, err
+	}
+
+	if next != nil {
+		if err := s.Store.ActivateApprovalStep(ctx, tenantID, next.ID); err != nil {
+			return result, err
+		}
+		result.Status = stepStatus(*next)
+		if err := s.Store.UpdateRequestStatusSimple(ctx, requestID, result.Status); err != nil {
+			return result, err
+		}
+		if result.Status == StatusPendingHR {
+			if hrUserIDs, err := s.Store.HRUserIDs(ctx, tenantID); err == nil {
+				result.HRUserIDs = hrUserIDs
+			}
+		} else {
+			result.NextApproverID = next.ApproverID
+		}
+	} else {
+		result.Status = StatusApproved
+		result.FinalApproval = true
+		if err := s.Store.UpdateBalanceOnApproval(ctx, tenantID, result.EmployeeID, result.LeaveTypeID, days); err != nil {
+			return result, err
+		}
+		if err := s.Store.ConsumeTOIL(ctx, tenantID, result.EmployeeID, result.LeaveTypeID, days); err != nil {
+			return result, err
+		}
+	}
+
+	if employeeUser, leaveTypeName, err := s.Store.EmployeeUserAndLeaveType(ctx, tenantID, result.LeaveTypeID, result.EmployeeID); err == nil {
+		result.EmployeeUser = employeeUser
+		result.LeaveTypeName = leaveTypeName
+	}
+	return result, nil
+}
+
 type RejectResult struct {
 	EmployeeID    string
 	LeaveTypeID   string
 	LeaveTypeName string
 	EmployeeUser  string
+	OnBehalfOf    string
 }
 
 func (s *Service) RejectRequest(ctx context.Context, tenantID, requestID, approverUserID, roleName string) (RejectResult, error) {
-	employeeID, leaveTypeID, days, _, err := s.Store.RequestInfo(ctx, tenantID, requestID)
+	employeeID, leaveTypeID, days, currentStatus, err := s.Store.RequestInfo(ctx, tenantID, requestID)
 	if err != nil {
 		return RejectResult{}, err
 	}
 	result := RejectResult{EmployeeID: employeeID, LeaveTypeID: leaveTypeID}
 
-	if roleName == auth.RoleManager {
-		if s.Employees != nil {
-			managerEmployeeID, err := s.Employees.ManagerIDByEmployeeID(ctx, tenantID, employeeID)
-			if err == nil && managerEmployeeID != "" {
-				selfEmployeeID, err := s.Employees.EmployeeIDByUserID(ctx, tenantID, approverUserID)
-				if err != nil || selfEmployeeID == "" || selfEmployeeID != managerEmployeeID {
-					return RejectResult{}, ErrForbidden
-				}
-			}
+	approvals, err := s.Store.ListApprovals(ctx, tenantID, requestID)
+	if err != nil {
+		return RejectResult{}, err
+	}
+	steps := chainApprovals(approvals)
+	if len(steps) > 0 {
+		current, _, ok := currentStep(steps)
+		if !ok || (currentStatus != StatusPending && currentStatus != StatusPendingHR) {
+			return RejectResult{}, ErrInvalidState
+		}
+		onBehalfOf, err := s.checkStepApprover(ctx, tenantID, current, approverUserID, roleName)
+		if err != nil {
+			return RejectResult{}, err
+		}
+		result.OnBehalfOf = onBehalfOf
+		if err := s.Store.DecideApprovalStep(ctx, tenantID, current.ID, ApprovalRejected, approverUserID); err != nil {
+			return RejectResult{}, err
+		}
+		if err := s.Store.CloseApprovalSteps(ctx, tenantID, requestID); err != nil {
+			return RejectResult{}, err
+		}
+	} else if roleName == auth.RoleManager {
+		onBehalfOf, err := s.checkManagerApprover(ctx, tenantID, employeeID, approverUserID)
+		if err != nil {
+			return RejectResult{}, err
 		}
+		result.OnBehalfOf = onBehalfOf
 	}
 
 	if err := s.Store.UpdateRequestStatus(ctx, requestID, StatusRejected, approverUserID); err != nil {
 		return RejectResult{}, err
 	}
 
-	if err := s.Store.InsertApproval(ctx, tenantID, requestID, approverUserID, "rejected"); err != nil {
-		return RejectResult{}, err
+	if len(steps) == 0 {
+		if err := s.Store.InsertApproval(ctx, tenantID, requestID, approverUserID, "rejected"); err != nil {
+			return RejectResult{}, err
+		}
 	}
 
 	if err := s.Store.UpdateBalanceOnReject(ctx, tenantID, employeeID, leaveTypeID, days); err != nil {
@@ -311,6 +439,9 @@ func (s *Service) CancelRequest(ctx context.Context, tenantID, requestID, actorU
 	if err := s.Store.UpdateRequestStatusSimple(ctx, requestID, StatusCancelled); err != nil {
 		return CancelResult{}, err
 	}
+	if err := s.Store.CloseApprovalSteps(ctx, tenantID, requestID); err != nil {
+		return CancelResult{}, err
+	}
 
 	if err := s.Store.UpdateBalanceOnReject(ctx, tenantID, employeeID, leaveTypeID, days); err != nil {
 		return CancelResult{}, err
@@ -326,12 +457,14 @@ func (s *Service) CancelRequest(ctx context.Context, tenantID, requestID, actorU
 }
 
 type CalendarEntry struct {
-	ID          string
-	EmployeeID  string
-	LeaveTypeID string
-	StartDate   time.Time
-	EndDate     time.Time
-	Status      string
+	ID           string
+	EmployeeID   string
+	LeaveTypeID  string
+	StartDate    time.Time
+	EndDate      time.Time
+	Status       string
+	DepartmentID string
+	ManagerID    string
 }
 
 type CalendarExportRow struct {
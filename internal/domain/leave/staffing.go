@@ -0,0 +1,246 @@
+package leave
+
+import (
+	"context"
+	"time"
+)
+
+// StaffingRule limits absence in a department, a team or, with neither set,
+// the whole tenant. A team is the direct reports of the employee ManagerID.
+// max_absent rules allow at most MaxAbsent people in scope off on the same
+// day; blackout rules allow no leave from StartDate to EndDate.
+type StaffingRule struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Kind         string     `json:"kind"`
+	DepartmentID string     `json:"departmentId,omitempty"`
+	ManagerID    string     `json:"managerId,omitempty"`
+	MaxAbsent    int        `json:"maxAbsent,omitempty"`
+	StartDate    *time.Time `json:"startDate,omitempty"`
+	EndDate      *time.Time `json:"endDate,omitempty"`
+	Enforcement  string     `json:"enforcement"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// Valid reports whether the rule is complete for its kind.
+func (r StaffingRule) Valid() bool {
+	if r.Enforcement != StaffingWarn && r.Enforcement != StaffingBlock {
+		return false
+	}
+	switch r.Kind {
+	case StaffingMaxAbsent:
+		return r.MaxAbsent > 0
+	case StaffingBlackout:
+		return r.StartDate != nil && r.EndDate != nil && !r.EndDate.Before(*r.StartDate)
+	}
+	return false
+}
+
+// covers reports whether an employee in departmentID reporting to managerID
+// falls in the rule's scope.
+func (r StaffingRule) covers(departmentID, managerID string) bool {
+	if r.DepartmentID != "" && r.DepartmentID != departmentID {
+		return false
+	}
+	if r.ManagerID != "" && r.ManagerID != managerID {
+		return false
+	}
+	return true
+}
+
+// StaffingTeam is where an employee sits for staffing rules.
+type StaffingTeam struct {
+	EmployeeID   string
+	DepartmentID string
+	ManagerID    string
+}
+
+// StaffingConflict is a staffing rule a leave request breaks. Date is the
+// first day it is broken; for max_absent rules Absent is the most people in
+// scope off on one day, the requester included.
+type StaffingConflict struct {
+	RuleID      string    `json:"ruleId"`
+	RuleName    string    `json:"ruleName"`
+	Kind        string    `json:"kind"`
+	Enforcement string    `json:"enforcement"`
+	Date        time.Time `json:"date"`
+	Absent      int       `json:"absent,omitempty"`
+	MaxAbsent   int       `json:"maxAbsent,omitempty"`
+}
+
+// StaffingBlocked reports whether any conflict blocks the request.
+func StaffingBlocked(conflicts []StaffingConflict) bool {
+	for _, conflict := range conflicts {
+		if conflict.Enforcement == StaffingBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// StaffingOverlap is what an approver sees for a request: the team's leave
+// overlapping it and the staffing rules it breaks.
+type StaffingOverlap struct {
+	Entries   []CalendarEntry    `json:"entries"`
+	Conflicts []StaffingConflict `json:"conflicts"`
+}
+
+// EvaluateStaffing checks leave for team from start to end against the rules
+// covering it. entries is other leave overlapping the range; the requester's
+// own leave is ignored.
+func EvaluateStaffing(rules []StaffingRule, team StaffingTeam, start, end time.Time, entries []CalendarEntry) []StaffingConflict {
+	start, end = dateOnly(start), dateOnly(end)
+	var conflicts []StaffingConflict
+	for _, rule := range rules {
+		if !rule.covers(team.DepartmentID, team.ManagerID) {
+			continue
+		}
+		conflict := StaffingConflict{RuleID: rule.ID, RuleName: rule.Name, Kind: rule.Kind, Enforcement: rule.Enforcement}
+		switch rule.Kind {
+		case StaffingBlackout:
+			if rule.StartDate == nil || rule.EndDate == nil || end.Before(dateOnly(*rule.StartDate)) || start.After(dateOnly(*rule.EndDate)) {
+				continue
+			}
+			conflict.Date = start
+			if blackoutStart := dateOnly(*rule.StartDate); blackoutStart.After(start) {
+				conflict.Date = blackoutStart
+			}
+			conflicts = append(conflicts, conflict)
+		case StaffingMaxAbsent:
+			conflict.MaxAbsent = rule.MaxAbsent
+			for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+				absent := 1 + absentOn(day, rule, team.EmployeeID, entries)
+				if absent <= rule.MaxAbsent {
+					continue
+				}
+				if conflict.Date.IsZero() {
+					conflict.Date = day
+				}
+				if absent > conflict.Absent {
+					conflict.Absent = absent
+				}
+			}
+			if !conflict.Date.IsZero() {
+				conflicts = append(conflicts, conflict)
+			}
+		}
+	}
+	return conflicts
+}
+
+// absentOn counts the other people in the rule's scope on leave on day.
+func absentOn(day time.Time, rule StaffingRule, employeeID string, entries []CalendarEntry) int {
+	absent := map[string]bool{}
+	for _, entry := range entries {
+		if entry.EmployeeID == employeeID || absent[entry.EmployeeID] || !rule.covers(entry.DepartmentID, entry.ManagerID) {
+			continue
+		}
+		if !day.Before(dateOnly(entry.StartDate)) && !day.After(dateOnly(entry.EndDate)) {
+			absent[entry.EmployeeID] = true
+		}
+	}
+	return len(absent)
+}
+
+func (s *Service) ListStaffingRules(ctx context.Context, tenantID string) ([]StaffingRule, error) {
+	return s.Store.ListStaffingRules(ctx, tenantID)
+}
+
+func (s *Service) GetStaffingRule(ctx context.Context, tenantID, ruleID string) (StaffingRule, error) {
+	return s.Store.GetStaffingRule(ctx, tenantID, ruleID)
+}
+
+func (s *Service) CreateStaffingRule(ctx context.Context, tenantID string, rule StaffingRule) (string, error) {
+	return s.Store.CreateStaffingRule(ctx, tenantID, rule)
+}
+
+func (s *Service) UpdateStaffingRule(ctx context.Context, tenantID string, rule StaffingRule) error {
+	return s.Store.UpdateStaffingRule(ctx, tenantID, rule)
+}
+
+func (s *Service) DeleteStaffingRule(ctx context.Context, tenantID, ruleID string) error {
+	return s.Store.DeleteStaffingRule(ctx, tenantID, ruleID)
+}
+
+// CheckStaffing returns the staffing rules leave for employeeID from start to
+// end would break, counting other leave in the given statuses. New requests
+// count pending and approved leave; approvals count approved leave only, so
+// requests still awaiting a decision do not block each other.
+func (s *Service) CheckStaffing(ctx context.Context, tenantID, employeeID string, start, end time.Time, statuses []string) ([]StaffingConflict, error) {
+	return checkStaffing(ctx, s.Store, tenantID, employeeID, start, end, statuses)
+}
+
+// StaffingReader loads what a staffing check needs.
+type StaffingReader interface {
+	ListStaffingRules(ctx context.Context, tenantID string) ([]StaffingRule, error)
+	StaffingTeam(ctx context.Context, tenantID, employeeID string) (StaffingTeam, error)
+	TeamCalendarEntries(ctx context.Context, tenantID string, team StaffingTeam, statuses []string, from, to time.Time) ([]CalendarEntry, error)
+}
+
+// StaffingCheck re-checks request against the staffing rules from inside the
+// transaction that files or approves it, while the tenant's staffing lock is
+// held, so two requests cannot both pass a rule only one of them fits.
+// reader reads through that transaction.
+type StaffingCheck func(ctx context.Context, reader StaffingReader, request LeaveRequest) error
+
+func checkStaffing(ctx context.Context, reader StaffingReader, tenantID, employeeID string, start, end time.Time, statuses []string) ([]StaffingConflict, error) {
+	rules, err := reader.ListStaffingRules(ctx, tenantID)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	team, err := reader.StaffingTeam(ctx, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := reader.TeamCalendarEntries(ctx, tenantID, StaffingTeam{}, statuses, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return EvaluateStaffing(rules, team, start, end, entries), nil
+}
+
+// staffingCheck checks a request against other leave in statuses, keeps the
+// conflicts it finds in conflicts and fails with ErrStaffingBlocked when one
+// of them blocks the request.
+func staffingCheck(tenantID string, statuses []string, conflicts *[]StaffingConflict) StaffingCheck {
+	return func(ctx context.Context, reader StaffingReader, request LeaveRequest) error {
+		found, err := checkStaffing(ctx, reader, tenantID, request.EmployeeID, request.StartDate, request.EndDate, statuses)
+		if err != nil {
+			return err
+		}
+		*conflicts = found
+		if StaffingBlocked(found) {
+			return ErrStaffingBlocked
+		}
+		return nil
+	}
+}
+
+// RequestOverlap returns the leave of the requester's team, their department
+// and fellow reports of their manager, overlapping a request, with the
+// staffing rules approving it would break.
+func (s *Service) RequestOverlap(ctx context.Context, tenantID string, request LeaveRequest) (StaffingOverlap, error) {
+	overlap := StaffingOverlap{Entries: []CalendarEntry{}, Conflicts: []StaffingConflict{}}
+	team, err := s.Store.StaffingTeam(ctx, tenantID, request.EmployeeID)
+	if err != nil {
+		return overlap, err
+	}
+	if team.DepartmentID != "" || team.ManagerID != "" {
+		statuses := []string{StatusPending, StatusPendingHR, StatusApproved}
+		entries, err := s.Store.TeamCalendarEntries(ctx, tenantID, team, statuses, request.StartDate, request.EndDate)
+		if err != nil {
+			return overlap, err
+		}
+		for _, entry := range entries {
+			if entry.ID != request.ID {
+				overlap.Entries = append(overlap.Entries, entry)
+			}
+		}
+	}
+	conflicts, err := s.CheckStaffing(ctx, tenantID, request.EmployeeID, request.StartDate, request.EndDate, []string{StatusApproved})
+	if err != nil {
+		return overlap, err
+	}
+	overlap.Conflicts = append(overlap.Conflicts, conflicts...)
+	return overlap, nil
+}
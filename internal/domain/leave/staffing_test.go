@@ -0,0 +1,121 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvaluateStaffingCountsTeamAbsence(t *testing.T) {
+	rules := []StaffingRule{
+		{ID: "rule-eng", Name: "Engineering cover", Kind: StaffingMaxAbsent, DepartmentID: "dept-eng", MaxAbsent: 2, Enforcement: StaffingBlock},
+		{ID: "rule-sales", Name: "Sales cover", Kind: StaffingMaxAbsent, DepartmentID: "dept-sales", MaxAbsent: 1, Enforcement: StaffingBlock},
+	}
+	team := StaffingTeam{EmployeeID: "emp-1", DepartmentID: "dept-eng", ManagerID: "mgr-1"}
+	entries := []CalendarEntry{
+		{ID: "req-2", EmployeeID: "emp-2", DepartmentID: "dept-eng", StartDate: utcDate(2026, time.March, 2), EndDate: utcDate(2026, time.March, 4)},
+		{ID: "req-3", EmployeeID: "emp-3", DepartmentID: "dept-eng", StartDate: utcDate(2026, time.March, 4), EndDate: utcDate(2026, time.March, 6)},
+		{ID: "req-4", EmployeeID: "emp-4", DepartmentID: "dept-sales", StartDate: utcDate(2026, time.March, 2), EndDate: utcDate(2026, time.March, 6)},
+		{ID: "req-1", EmployeeID: "emp-1", DepartmentID: "dept-eng", StartDate: utcDate(2026, time.March, 2), EndDate: utcDate(2026, time.March, 6)},
+	}
+
+	conflicts := EvaluateStaffing(rules, team, utcDate(2026, time.March, 2), utcDate(2026, time.March, 6), entries)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected only the engineering rule to be broken, got %+v", conflicts)
+	}
+	conflict := conflicts[0]
+	if conflict.RuleID != "rule-eng" || conflict.Absent != 3 || conflict.MaxAbsent != 2 || !conflict.Date.Equal(utcDate(2026, time.March, 4)) {
+		t.Fatalf("expected three absent on 4 March, got %+v", conflict)
+	}
+	if !StaffingBlocked(conflicts) {
+		t.Fatal("expected a block rule conflict to block the request")
+	}
+
+	if conflicts := EvaluateStaffing(rules, team, utcDate(2026, time.March, 5), utcDate(2026, time.March, 6), entries); len(conflicts) != 0 {
+		t.Fatalf("expected two absent to be within the limit, got %+v", conflicts)
+	}
+}
+
+func TestEvaluateStaffingBlackout(t *testing.T) {
+	start, end := utcDate(2026, time.December, 20), utcDate(2026, time.December, 31)
+	rules := []StaffingRule{
+		{ID: "rule-freeze", Name: "Year-end close", Kind: StaffingBlackout, ManagerID: "mgr-1", StartDate: &start, EndDate: &end, Enforcement: StaffingWarn},
+	}
+	team := StaffingTeam{EmployeeID: "emp-1", ManagerID: "mgr-1"}
+
+	conflicts := EvaluateStaffing(rules, team, utcDate(2026, time.December, 15), utcDate(2026, time.December, 22), nil)
+	if len(conflicts) != 1 || !conflicts[0].Date.Equal(start) {
+		t.Fatalf("expected the blackout to be hit from its first day, got %+v", conflicts)
+	}
+	if StaffingBlocked(conflicts) {
+		t.Fatal("expected a warn rule conflict not to block the request")
+	}
+	if conflicts := EvaluateStaffing(rules, team, utcDate(2027, time.January, 1), utcDate(2027, time.January, 2), nil); len(conflicts) != 0 {
+		t.Fatalf("expected leave after the blackout to pass, got %+v", conflicts)
+	}
+	team.ManagerID = "mgr-2"
+	if conflicts := EvaluateStaffing(rules, team, utcDate(2026, time.December, 21), utcDate(2026, time.December, 22), nil); len(conflicts) != 0 {
+		t.Fatalf("expected another team to be outside the blackout, got %+v", conflicts)
+	}
+}
+
+func TestStaffingRuleValid(t *testing.T) {
+	start, end := utcDate(2026, time.June, 1), utcDate(2026, time.May, 1)
+	cases := []struct {
+		name string
+		rule StaffingRule
+		want bool
+	}{
+		{"max absent", StaffingRule{Kind: StaffingMaxAbsent, MaxAbsent: 2, Enforcement: StaffingWarn}, true},
+		{"max absent without limit", StaffingRule{Kind: StaffingMaxAbsent, Enforcement: StaffingWarn}, false},
+		{"blackout without dates", StaffingRule{Kind: StaffingBlackout, Enforcement: StaffingBlock}, false},
+		{"blackout ending before it starts", StaffingRule{Kind: StaffingBlackout, StartDate: &start, EndDate: &end, Enforcement: StaffingBlock}, false},
+		{"unknown enforcement", StaffingRule{Kind: StaffingMaxAbsent, MaxAbsent: 1, Enforcement: "maybe"}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.rule.Valid(); got != tc.want {
+			t.Errorf("%s: Valid() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+type staffingReader struct {
+	rules   []StaffingRule
+	entries []CalendarEntry
+}
+
+func (r staffingReader) ListStaffingRules(context.Context, string) ([]StaffingRule, error) {
+	return r.rules, nil
+}
+
+func (r staffingReader) StaffingTeam(_ context.Context, _, employeeID string) (StaffingTeam, error) {
+	return StaffingTeam{EmployeeID: employeeID, DepartmentID: "dept-eng"}, nil
+}
+
+func (r staffingReader) TeamCalendarEntries(context.Context, string, StaffingTeam, []string, time.Time, time.Time) ([]CalendarEntry, error) {
+	return r.entries, nil
+}
+
+func TestStaffingCheckBlocksOnFreshLeave(t *testing.T) {
+	reader := staffingReader{
+		rules: []StaffingRule{{ID: "rule-eng", Name: "Engineering cover", Kind: StaffingMaxAbsent, DepartmentID: "dept-eng", MaxAbsent: 1, Enforcement: StaffingBlock}},
+	}
+	request := LeaveRequest{EmployeeID: "emp-1", StartDate: utcDate(2026, time.March, 2), EndDate: utcDate(2026, time.March, 3)}
+
+	var conflicts []StaffingConflict
+	check := staffingCheck("tenant-1", []string{StatusApproved}, &conflicts)
+	if err := check(context.Background(), reader, request); err != nil || len(conflicts) != 0 {
+		t.Fatalf("expected the first absence to pass, got %v %+v", err, conflicts)
+	}
+
+	// Leave approved since the handler's early check is seen by the check
+	// run inside the approving transaction.
+	reader.entries = []CalendarEntry{{ID: "req-2", EmployeeID: "emp-2", DepartmentID: "dept-eng", StartDate: utcDate(2026, time.March, 3), EndDate: utcDate(2026, time.March, 3)}}
+	if err := check(context.Background(), reader, request); !errors.Is(err, ErrStaffingBlocked) {
+		t.Fatalf("expected ErrStaffingBlocked, got %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].RuleID != "rule-eng" || conflicts[0].Absent != 2 {
+		t.Fatalf("expected the engineering rule conflict to be kept, got %+v", conflicts)
+	}
+}
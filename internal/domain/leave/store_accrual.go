@@ -2,6 +2,7 @@ package leave
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -9,9 +10,11 @@ import (
 
 func (s *Store) ListAccrualPolicies(ctx context.Context, tenantID string) ([]policyRow, error) {
 	rows, err := s.DB.Query(ctx, `
-    SELECT id, leave_type_id, accrual_rate, accrual_period, entitlement, carry_over_limit
-    FROM leave_policies
-    WHERE tenant_id = $1 AND accrual_rate IS NOT NULL
+    SELECT p.id, p.leave_type_id, COALESCE(p.accrual_rate, 0), p.accrual_period, p.entitlement, p.carry_over_limit,
+           p.accrual_bands, t.unit
+    FROM leave_policies p
+    JOIN leave_types t ON t.id = p.leave_type_id
+    WHERE p.tenant_id = $1 AND (p.accrual_rate IS NOT NULL OR jsonb_array_length(p.accrual_bands) > 0)
   `, tenantID)
 	if err != nil {
 		return nil, err
@@ -21,7 +24,11 @@ func (s *Store) ListAccrualPolicies(ctx context.Context, tenantID string) ([]pol
 	policies := make([]policyRow, 0)
 	for rows.Next() {
 		var p policyRow
-		if err := rows.Scan(&p.ID, &p.LeaveTypeID, &p.AccrualRate, &p.AccrualPeriod, &p.Entitlement, &p.CarryOver); err != nil {
+		var bandsJSON []byte
+		if err := rows.Scan(&p.ID, &p.LeaveTypeID, &p.AccrualRate, &p.AccrualPeriod, &p.Entitlement, &p.CarryOver, &bandsJSON, &p.Unit); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(bandsJSON, &p.Bands); err != nil {
 			return nil, err
 		}
 		policies = append(policies, p)
@@ -43,25 +50,27 @@ func (s *Store) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	return s.DB.Begin(ctx)
 }
 
-func (s *Store) ListActiveEmployeesTx(ctx context.Context, tx pgx.Tx, tenantID string) (map[string]*time.Time, error) {
+func (s *Store) ListActiveEmployeesTx(ctx context.Context, tx pgx.Tx, tenantID string) ([]accrualEmployee, error) {
 	rows, err := tx.Query(ctx, `
-    SELECT id, start_date
-    FROM employees
-    WHERE tenant_id = $1 AND status = 'active'
+    SELECT e.id, e.start_date, COALESCE(e.employment_type, ''), COALESCE(e.department_id::text, ''),
+           COALESCE(wp.hours_per_day, 8)
+    FROM employees e
+    LEFT JOIN departments d ON d.id = e.department_id
+    LEFT JOIN work_patterns wp ON wp.id = COALESCE(e.work_pattern_id, d.work_pattern_id)
+    WHERE e.tenant_id = $1 AND e.status = 'active'
   `, tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	employees := make(map[string]*time.Time)
+	var employees []accrualEmployee
 	for rows.Next() {
-		var employeeID string
-		var startDate *time.Time
-		if err := rows.Scan(&employeeID, &startDate); err != nil {
+		var employee accrualEmployee
+		if err := rows.Scan(&employee.ID, &employee.StartDate, &employee.EmploymentType, &employee.DepartmentID, &employee.HoursPerDay); err != nil {
 			return nil, err
 		}
-		employees[employeeID] = startDate
+		employees = append(employees, employee)
 	}
 	return employees, nil
 }
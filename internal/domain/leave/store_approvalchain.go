@@ -0,0 +1,242 @@
+package leave
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) ListApprovalChains(ctx context.Context, tenantID string) ([]ApprovalChain, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, name, COALESCE(leave_type_id::text, ''), COALESCE(department_id::text, ''), steps_json, created_at
+    FROM leave_approval_chains
+    WHERE tenant_id = $1
+    ORDER BY name
+  `, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ApprovalChain
+	for rows.Next() {
+		chain, err := scanApprovalChain(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chain)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetApprovalChain(ctx context.Context, tenantID, chainID string) (ApprovalChain, error) {
+	chain, err := scanApprovalChain(s.DB.QueryRow(ctx, `
+    SELECT id, name, COALESCE(leave_type_id::text, ''), COALESCE(department_id::text, ''), steps_json, created_at
+    FROM leave_approval_chains
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, chainID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ApprovalChain{}, ErrApprovalChainNotFound
+	}
+	return chain, err
+}
+
+// MatchApprovalChain returns the most specific chain for a request of
+// leaveTypeID by employeeID, preferring chains that name both the leave type
+// and the employee's department, then the leave type, then the department.
+func (s *Store) MatchApprovalChain(ctx context.Context, tenantID, leaveTypeID, employeeID string) (ApprovalChain, error) {
+	chain, err := scanApprovalChain(s.DB.QueryRow(ctx, `
+    SELECT c.id, c.name, COALESCE(c.leave_type_id::text, ''), COALESCE(c.department_id::text, ''), c.steps_json, c.created_at
+    FROM leave_approval_chains c
+    LEFT JOIN employees e ON e.tenant_id = c.tenant_id AND e.id = $3
+    WHERE c.tenant_id = $1
+      AND (c.leave_type_id IS NULL OR c.leave_type_id = $2)
+      AND (c.department_id IS NULL OR c.department_id = e.department_id)
+    ORDER BY (c.leave_type_id IS NOT NULL) DESC, (c.department_id IS NOT NULL) DESC, c.created_at
+    LIMIT 1
+  `, tenantID, leaveTypeID, employeeID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ApprovalChain{}, ErrApprovalChainNotFound
+	}
+	return chain, err
+}
+
+func scanApprovalChain(row pgx.Row) (ApprovalChain, error) {
+	var chain ApprovalChain
+	var stepsJSON []byte
+	if err := row.Scan(&chain.ID, &chain.Name, &chain.LeaveTypeID, &chain.DepartmentID, &stepsJSON, &chain.CreatedAt); err != nil {
+		return chain, err
+	}
+	if err := json.Unmarshal(stepsJSON, &chain.Steps); err != nil {
+		return chain, err
+	}
+	return chain, nil
+}
+
+func (s *Store) CreateApprovalChain(ctx context.Context, tenantID string, chain ApprovalChain) (string, error) {
+	stepsJSON, err := json.Marshal(chain.Steps)
+	if err != nil {
+		return "", err
+	}
+	var id string
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO leave_approval_chains (tenant_id, name, leave_type_id, department_id, steps_json)
+    VALUES ($1,$2,$3,$4,$5)
+    RETURNING id
+  `, tenantID, chain.Name, nullIfEmpty(chain.LeaveTypeID), nullIfEmpty(chain.DepartmentID), stepsJSON).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *Store) UpdateApprovalChain(ctx context.Context, tenantID string, chain ApprovalChain) error {
+	stepsJSON, err := json.Marshal(chain.Steps)
+	if err != nil {
+		return err
+	}
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE leave_approval_chains
+    SET name = $3, leave_type_id = $4, department_id = $5, steps_json = $6, updated_at = now()
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, chain.ID, chain.Name, nullIfEmpty(chain.LeaveTypeID), nullIfEmpty(chain.DepartmentID), stepsJSON)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrApprovalChainNotFound
+	}
+	return nil
+}
+
+func (s *Store) DeleteApprovalChain(ctx context.Context, tenantID, chainID string) error {
+	tag, err := s.DB.Exec(ctx, `
+    DELETE FROM leave_approval_chains
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, chainID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrApprovalChainNotFound
+	}
+	return nil
+}
+
+// ApprovalAssignees returns the users an approval chain step can resolve to
+// for employeeID.
+func (s *Store) ApprovalAssignees(ctx context.Context, tenantID, employeeID string) (ApproverSet, error) {
+	var approvers ApproverSet
+	err := s.DB.QueryRow(ctx, `
+    SELECT COALESCE(e.user_id::text, ''), COALESCE(m.user_id::text, ''), COALESCE(d.manager_id::text, '')
+    FROM employees e
+    LEFT JOIN employees m ON m.id = e.manager_id
+    LEFT JOIN departments d ON d.id = e.department_id
+    WHERE e.tenant_id = $1 AND e.id = $2
+  `, tenantID, employeeID).Scan(&approvers.EmployeeUserID, &approvers.ManagerUserID, &approvers.DepartmentHeadUserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return approvers, ErrEmployeeNotFound
+	}
+	return approvers, err
+}
+
+func (s *Store) ListApprovals(ctx context.Context, tenantID, requestID string) ([]LeaveApproval, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, COALESCE(step_order, 0), COALESCE(approver_id::text, ''), COALESCE(approver_role, ''),
+           status, requested_at, decided_at, COALESCE(comment, '')
+    FROM leave_approvals
+    WHERE tenant_id = $1 AND leave_request_id = $2
+    ORDER BY step_order NULLS LAST, requested_at
+  `, tenantID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []LeaveApproval
+	for rows.Next() {
+		var approval LeaveApproval
+		if err := rows.Scan(&approval.ID, &approval.StepOrder, &approval.ApproverID, &approval.ApproverRole, &approval.Status, &approval.RequestedAt, &approval.DecidedAt, &approval.Comment); err != nil {
+			return nil, err
+		}
+		out = append(out, approval)
+	}
+	return out, rows.Err()
+}
+
+// DecideApprovalStep records a decision on a pending chain step. HR steps
+// take the deciding user as their approver.
+func (s *Store) DecideApprovalStep(ctx context.Context, tenantID, approvalID, status, approverID string) error {
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE leave_approvals
+    SET status = $3, approver_id = $4, decided_at = now()
+    WHERE tenant_id = $1 AND id = $2 AND status = $5
+  `, tenantID, approvalID, status, approverID, ApprovalPending)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvalidState
+	}
+	return nil
+}
+
+func (s *Store) ActivateApprovalStep(ctx context.Context, tenantID, approvalID string) error {
+	_, err := s.DB.Exec(ctx, `
+    UPDATE leave_approvals
+    SET status = $3, requested_at = now()
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, approvalID, ApprovalPending)
+	return err
+}
+
+// CloseApprovalSteps cancels the undecided chain steps of a request.
+func (s *Store) CloseApprovalSteps(ctx context.Context, tenantID, requestID string) error {
+	_, err := s.DB.Exec(ctx, `
+    UPDATE leave_approvals
+    SET status = $3
+    WHERE tenant_id = $1 AND leave_request_id = $2 AND step_order IS NOT NULL AND status IN ($4,$5)
+  `, tenantID, requestID, ApprovalCancelled, ApprovalPending, ApprovalWaiting)
+	return err
+}
+
+func (s *Store) DueApprovalReminders(ctx context.Context, tenantID string, cutoff time.Time) ([]ApprovalReminder, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT a.id, a.leave_request_id, COALESCE(a.approver_id::text, ''), COALESCE(a.approver_role, ''),
+           lt.name, r.start_date, r.end_date
+    FROM leave_approvals a
+    JOIN leave_requests r ON r.id = a.leave_request_id
+    JOIN leave_types lt ON lt.id = r.leave_type_id
+    WHERE a.tenant_id = $1
+      AND a.step_order IS NOT NULL
+      AND a.status = $3
+      AND r.status IN ($4,$5)
+      AND COALESCE(a.reminded_at, a.requested_at) <= $2
+    ORDER BY a.requested_at
+  `, tenantID, cutoff, ApprovalPending, StatusPending, StatusPendingHR)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ApprovalReminder
+	for rows.Next() {
+		var reminder ApprovalReminder
+		if err := rows.Scan(&reminder.ApprovalID, &reminder.RequestID, &reminder.ApproverID, &reminder.ApproverRole, &reminder.LeaveTypeName, &reminder.StartDate, &reminder.EndDate); err != nil {
+			return nil, err
+		}
+		out = append(out, reminder)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) MarkApprovalReminded(ctx context.Context, tenantID, approvalID string) error {
+	_, err := s.DB.Exec(ctx, `
+    UPDATE leave_approvals
+    SET reminded_at = now()
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, approvalID)
+	return err
+}
@@ -0,0 +1,154 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"hrm/internal/domain/core"
+)
+
+func (s *Store) ListCalendarFeeds(ctx context.Context, tenantID, userID string) ([]CalendarFeed, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, kind, COALESCE(region, ''), last_used_at, created_at
+    FROM leave_calendar_feeds
+    WHERE tenant_id = $1 AND user_id = $2 AND revoked_at IS NULL
+    ORDER BY created_at
+  `, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CalendarFeed
+	for rows.Next() {
+		feed := CalendarFeed{TenantID: tenantID, UserID: userID}
+		if err := rows.Scan(&feed.ID, &feed.Kind, &feed.Region, &feed.LastUsedAt, &feed.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, feed)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) CreateCalendarFeed(ctx context.Context, tenantID, userID string, feed CalendarFeed, tokenHash string) (string, time.Time, error) {
+	var id string
+	var createdAt time.Time
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO leave_calendar_feeds (tenant_id, user_id, kind, region, token_hash)
+    VALUES ($1,$2,$3,$4,$5)
+    RETURNING id, created_at
+  `, tenantID, userID, feed.Kind, nullIfEmpty(feed.Region), tokenHash).Scan(&id, &createdAt); err != nil {
+		return "", time.Time{}, err
+	}
+	return id, createdAt, nil
+}
+
+func (s *Store) RevokeCalendarFeed(ctx context.Context, tenantID, userID, feedID string) error {
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE leave_calendar_feeds
+    SET revoked_at = now()
+    WHERE tenant_id = $1 AND user_id = $2 AND id = $3 AND revoked_at IS NULL
+  `, tenantID, userID, feedID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// CalendarFeedByToken returns the live feed served by a token hash, with its
+// owner's current role. Revoked feeds and feeds of inactive users are not
+// found.
+func (s *Store) CalendarFeedByToken(ctx context.Context, tokenHash string) (CalendarFeed, error) {
+	var feed CalendarFeed
+	err := s.DB.QueryRow(ctx, `
+    SELECT f.id, f.tenant_id, f.user_id, r.name, f.kind, COALESCE(f.region, ''), f.last_used_at, f.created_at
+    FROM leave_calendar_feeds f
+    JOIN users u ON u.id = f.user_id
+    JOIN roles r ON r.id = u.role_id
+    WHERE f.token_hash = $1 AND f.revoked_at IS NULL AND u.status = $2
+  `, tokenHash, core.UserStatusActive).Scan(&feed.ID, &feed.TenantID, &feed.UserID, &feed.RoleName, &feed.Kind, &feed.Region, &feed.LastUsedAt, &feed.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return feed, ErrCalendarFeedNotFound
+	}
+	return feed, err
+}
+
+func (s *Store) TouchCalendarFeed(ctx context.Context, feedID string) error {
+	_, err := s.DB.Exec(ctx, `
+    UPDATE leave_calendar_feeds SET last_used_at = now() WHERE id = $1
+  `, feedID)
+	return err
+}
+
+// FeedLeave returns leave in the given statuses ending on or after since,
+// limited to employeeID when set and to managerID's direct reports when set.
+func (s *Store) FeedLeave(ctx context.Context, tenantID string, statuses []string, employeeID, managerID string, since time.Time) ([]FeedLeave, error) {
+	query := `
+    SELECT lr.id, e.first_name || ' ' || e.last_name, lt.name, lr.start_date, lr.end_date, lr.status,
+           lr.revision, lr.updated_at
+    FROM leave_requests lr
+    JOIN employees e ON lr.employee_id = e.id
+    JOIN leave_types lt ON lr.leave_type_id = lt.id
+    WHERE lr.tenant_id = $1 AND lr.status = ANY($2) AND lr.end_date >= $3
+  `
+	args := []any{tenantID, statuses, since}
+	if employeeID != "" {
+		query += " AND lr.employee_id = $4"
+		args = append(args, employeeID)
+	}
+	if managerID != "" {
+		query += " AND e.manager_id = $4"
+		args = append(args, managerID)
+	}
+	query += " ORDER BY lr.start_date, lr.id"
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []FeedLeave
+	for rows.Next() {
+		var entry FeedLeave
+		if err := rows.Scan(&entry.ID, &entry.EmployeeName, &entry.LeaveTypeName, &entry.StartDate, &entry.EndDate, &entry.Status,
+			&entry.Revision, &entry.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, entry)
+	}
+	return out, rows.Err()
+}
+
+// FeedHolidays returns the holidays on or after since that apply to region:
+// those without a region and, when region is set, those of the region.
+func (s *Store) FeedHolidays(ctx context.Context, tenantID, region string, since time.Time) ([]FeedHoliday, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, name, date
+    FROM holidays
+    WHERE tenant_id = $1
+      AND date >= $2
+      AND (COALESCE(region, '') = '' OR region = $3)
+    ORDER BY date, id
+  `, tenantID, since, region)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []FeedHoliday
+	for rows.Next() {
+		var holiday FeedHoliday
+		if err := rows.Scan(&holiday.ID, &holiday.Name, &holiday.Date); err != nil {
+			return nil, err
+		}
+		out = append(out, holiday)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,177 @@
+package leave
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) ListCarryOverPolicies(ctx context.Context, tenantID string) ([]CarryOverPolicy, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT p.id, p.leave_type_id, t.unit, p.carry_over_limit, p.carry_over_expiry_months, p.year_start_month, p.year_start_day, p.created_at
+    FROM leave_policies p
+    JOIN leave_types t ON t.id = p.leave_type_id
+    WHERE p.tenant_id = $1
+    ORDER BY p.created_at
+  `, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []CarryOverPolicy
+	for rows.Next() {
+		var p CarryOverPolicy
+		if err := rows.Scan(&p.ID, &p.LeaveTypeID, &p.Unit, &p.Limit, &p.ExpiryMonths, &p.YearStartMonth, &p.YearStartDay, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+func (s *Store) CarryOverProcessed(ctx context.Context, tenantID, policyID string, year int) (bool, error) {
+	var exists bool
+	err := s.DB.QueryRow(ctx, `
+    SELECT EXISTS (
+      SELECT 1 FROM leave_carry_over_runs
+      WHERE tenant_id = $1 AND policy_id = $2 AND year >= $3
+    )
+  `, tenantID, policyID, year).Scan(&exists)
+	return exists, err
+}
+
+// ListCarryOverBalancesTx locks the balances of a leave type for year-end
+// processing, with the length of each employee's working day.
+func (s *Store) ListCarryOverBalancesTx(ctx context.Context, tx pgx.Tx, tenantID, leaveTypeID string) ([]CarryOverBalance, error) {
+	rows, err := tx.Query(ctx, `
+    SELECT lb.employee_id, lb.leave_type_id, lb.balance, lb.pending, lb.used, COALESCE(wp.hours_per_day, 8)
+    FROM leave_balances lb
+    JOIN employees e ON e.id = lb.employee_id
+    LEFT JOIN departments d ON d.id = e.department_id
+    LEFT JOIN work_patterns wp ON wp.id = COALESCE(e.work_pattern_id, d.work_pattern_id)
+    WHERE lb.tenant_id = $1 AND lb.leave_type_id = $2
+    FOR UPDATE OF lb
+  `, tenantID, leaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []CarryOverBalance
+	for rows.Next() {
+		var b CarryOverBalance
+		if err := rows.Scan(&b.EmployeeID, &b.LeaveTypeID, &b.Balance, &b.Pending, &b.Used, &b.HoursPerDay); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// ApplyCarryOverTx removes the forfeited days from a balance, moves the
+// carried days into its carried-forward bucket and logs the change as a
+// balance adjustment.
+func (s *Store) ApplyCarryOverTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID, leaveTypeID string, carried, forfeited float64, expiresOn *time.Time, reason string) error {
+	if carried <= 0 {
+		expiresOn = nil
+	}
+	if _, err := tx.Exec(ctx, `
+    UPDATE leave_balances
+    SET balance = balance - $4,
+        carried_over = $5,
+        carry_over_expires_on = $6,
+        expiry_notified_at = NULL,
+        updated_at = now()
+    WHERE tenant_id = $1 AND employee_id = $2 AND leave_type_id = $3
+  `, tenantID, employeeID, leaveTypeID, forfeited, carried, expiresOn); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+    INSERT INTO leave_balance_adjustments (tenant_id, employee_id, leave_type_id, amount, reason)
+    VALUES ($1,$2,$3,$4,$5)
+  `, tenantID, employeeID, leaveTypeID, -forfeited, reason)
+	return err
+}
+
+func (s *Store) RecordCarryOverRunTx(ctx context.Context, tx pgx.Tx, tenantID, policyID string, year, balancesProcessed int) error {
+	_, err := tx.Exec(ctx, `
+    INSERT INTO leave_carry_over_runs (tenant_id, policy_id, year, balances_processed)
+    VALUES ($1,$2,$3,$4)
+    ON CONFLICT (tenant_id, policy_id, year) DO NOTHING
+  `, tenantID, policyID, year, balancesProcessed)
+	return err
+}
+
+// ExpireCarriedBalances forfeits the carried days still unused in balances
+// whose carry-over expired before the given date and logs each forfeiture. It
+// returns the number of balances expired and the days forfeited.
+func (s *Store) ExpireCarriedBalances(ctx context.Context, tenantID string, on time.Time) (int, float64, error) {
+	var count int
+	var days float64
+	err := s.DB.QueryRow(ctx, `
+    WITH due AS (
+      SELECT id, LEAST(carried_over, GREATEST(balance - used - pending, 0)) AS forfeited
+      FROM leave_balances
+      WHERE tenant_id = $1 AND carry_over_expires_on IS NOT NULL AND carry_over_expires_on < $2::date
+      FOR UPDATE
+    ), expired AS (
+      UPDATE leave_balances lb
+      SET balance = lb.balance - due.forfeited,
+          carried_over = 0,
+          carry_over_expires_on = NULL,
+          expiry_notified_at = NULL,
+          updated_at = now()
+      FROM due
+      WHERE lb.id = due.id
+      RETURNING lb.employee_id, lb.leave_type_id, due.forfeited
+    ), logged AS (
+      INSERT INTO leave_balance_adjustments (tenant_id, employee_id, leave_type_id, amount, reason)
+      SELECT $1, employee_id, leave_type_id, -forfeited, 'Carried-over leave expired'
+      FROM expired
+      WHERE forfeited > 0
+    )
+    SELECT COUNT(*), COALESCE(SUM(forfeited), 0)
+    FROM expired
+  `, tenantID, on).Scan(&count, &days)
+	return count, days, err
+}
+
+// DueCarryOverNotices lists carried-forward balances with days left that
+// expire on or before the given date and have not been notified yet.
+func (s *Store) DueCarryOverNotices(ctx context.Context, tenantID string, before time.Time) ([]CarryOverNotice, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT lb.id, COALESCE(e.user_id::text, ''), lt.name,
+           LEAST(lb.carried_over, GREATEST(lb.balance - lb.used - lb.pending, 0)), lb.carry_over_expires_on
+    FROM leave_balances lb
+    JOIN employees e ON e.id = lb.employee_id
+    JOIN leave_types lt ON lt.id = lb.leave_type_id
+    WHERE lb.tenant_id = $1 AND lb.carry_over_expires_on IS NOT NULL
+      AND lb.carry_over_expires_on <= $2::date AND lb.expiry_notified_at IS NULL
+      AND LEAST(lb.carried_over, lb.balance - lb.used - lb.pending) > 0
+  `, tenantID, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notices []CarryOverNotice
+	for rows.Next() {
+		var n CarryOverNotice
+		if err := rows.Scan(&n.BalanceID, &n.EmployeeUser, &n.LeaveTypeName, &n.Days, &n.ExpiresOn); err != nil {
+			return nil, err
+		}
+		notices = append(notices, n)
+	}
+	return notices, rows.Err()
+}
+
+func (s *Store) MarkCarryOverNotified(ctx context.Context, tenantID, balanceID string) error {
+	_, err := s.DB.Exec(ctx, `
+    UPDATE leave_balances
+    SET expiry_notified_at = now()
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, balanceID)
+	return err
+}
@@ -2,16 +2,20 @@ package leave
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"hrm/internal/domain/auth"
 )
 
 func (s *Store) ListTypes(ctx context.Context, tenantID string) ([]LeaveType, error) {
 	rows, err := s.DB.Query(ctx, `
-    SELECT id, name, code, is_paid, requires_doc, created_at
+    SELECT id, name, code, is_paid, requires_doc, unit, toil, toil_expiry_days, encashable, created_at
     FROM leave_types
     WHERE tenant_id = $1
     ORDER BY name
@@ -24,7 +28,7 @@ func (s *Store) ListTypes(ctx context.Context, tenantID string) ([]LeaveType, er
 	var types []LeaveType
 	for rows.Next() {
 		var t LeaveType
-		if err := rows.Scan(&t.ID, &t.Name, &t.Code, &t.IsPaid, &t.RequiresDoc, &t.CreatedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.Name, &t.Code, &t.IsPaid, &t.RequiresDoc, &t.Unit, &t.TOIL, &t.TOILExpiryDays, &t.Encashable, &t.CreatedAt); err != nil {
 			return nil, err
 		}
 		types = append(types, t)
@@ -35,15 +39,28 @@ func (s *Store) ListTypes(ctx context.Context, tenantID string) ([]LeaveType, er
 func (s *Store) CreateType(ctx context.Context, tenantID string, payload LeaveType) (string, error) {
 	var id string
 	if err := s.DB.QueryRow(ctx, `
-    INSERT INTO leave_types (tenant_id, name, code, is_paid, requires_doc)
-    VALUES ($1,$2,$3,$4,$5)
+    INSERT INTO leave_types (tenant_id, name, code, is_paid, requires_doc, unit, toil, toil_expiry_days, encashable)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
     RETURNING id
-  `, tenantID, payload.Name, payload.Code, payload.IsPaid, payload.RequiresDoc).Scan(&id); err != nil {
+  `, tenantID, payload.Name, payload.Code, payload.IsPaid, payload.RequiresDoc, payload.Unit, payload.TOIL, payload.TOILExpiryDays, payload.Encashable).Scan(&id); err != nil {
 		return "", err
 	}
 	return id, nil
 }
 
+func (s *Store) GetType(ctx context.Context, tenantID, leaveTypeID string) (LeaveType, error) {
+	var t LeaveType
+	err := s.DB.QueryRow(ctx, `
+    SELECT id, name, code, is_paid, requires_doc, unit, toil, toil_expiry_days, encashable, created_at
+    FROM leave_types
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, leaveTypeID).Scan(&t.ID, &t.Name, &t.Code, &t.IsPaid, &t.RequiresDoc, &t.Unit, &t.TOIL, &t.TOILExpiryDays, &t.Encashable, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LeaveType{}, ErrLeaveTypeNotFound
+	}
+	return t, err
+}
+
 func (s *Store) LeaveTypeRequiresDoc(ctx context.Context, tenantID, leaveTypeID string) (bool, error) {
 	var requiresDoc bool
 	if err := s.DB.QueryRow(ctx, `
@@ -58,7 +75,7 @@ func (s *Store) LeaveTypeRequiresDoc(ctx context.Context, tenantID, leaveTypeID
 
 func (s *Store) ListPolicies(ctx context.Context, tenantID string) ([]LeavePolicy, error) {
 	rows, err := s.DB.Query(ctx, `
-    SELECT id, leave_type_id, accrual_rate, accrual_period, entitlement, carry_over_limit, allow_negative, requires_hr_approval
+    SELECT id, leave_type_id, COALESCE(accrual_rate, 0), accrual_period, entitlement, carry_over_limit, carry_over_expiry_months, year_start_month, year_start_day, accrual_bands, allow_negative, requires_hr_approval
     FROM leave_policies
     WHERE tenant_id = $1
   `, tenantID)
@@ -70,7 +87,11 @@ func (s *Store) ListPolicies(ctx context.Context, tenantID string) ([]LeavePolic
 	var policies []LeavePolicy
 	for rows.Next() {
 		var p LeavePolicy
-		if err := rows.Scan(&p.ID, &p.LeaveTypeID, &p.AccrualRate, &p.AccrualPeriod, &p.Entitlement, &p.CarryOver, &p.AllowNegative, &p.RequiresHRApproval); err != nil {
+		var bandsJSON []byte
+		if err := rows.Scan(&p.ID, &p.LeaveTypeID, &p.AccrualRate, &p.AccrualPeriod, &p.Entitlement, &p.CarryOver, &p.CarryOverExpiryMonths, &p.YearStartMonth, &p.YearStartDay, &bandsJSON, &p.AllowNegative, &p.RequiresHRApproval); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(bandsJSON, &p.AccrualBands); err != nil {
 			return nil, err
 		}
 		policies = append(policies, p)
@@ -79,12 +100,23 @@ func (s *Store) ListPolicies(ctx context.Context, tenantID string) ([]LeavePolic
 }
 
 func (s *Store) CreatePolicy(ctx context.Context, tenantID string, payload LeavePolicy) (string, error) {
+	bands := payload.AccrualBands
+	if bands == nil {
+		bands = []AccrualBand{}
+	}
+	bandsJSON, err := json.Marshal(bands)
+	if err != nil {
+		return "", err
+	}
+	if payload.YearStartMonth == 0 || payload.YearStartDay == 0 {
+		payload.YearStartMonth, payload.YearStartDay = 1, 1
+	}
 	var id string
 	if err := s.DB.QueryRow(ctx, `
-    INSERT INTO leave_policies (tenant_id, leave_type_id, accrual_rate, accrual_period, entitlement, carry_over_limit, allow_negative, requires_hr_approval)
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+    INSERT INTO leave_policies (tenant_id, leave_type_id, accrual_rate, accrual_period, entitlement, carry_over_limit, carry_over_expiry_months, year_start_month, year_start_day, accrual_bands, allow_negative, requires_hr_approval)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
     RETURNING id
-  `, tenantID, payload.LeaveTypeID, payload.AccrualRate, payload.AccrualPeriod, payload.Entitlement, payload.CarryOver, payload.AllowNegative, payload.RequiresHRApproval).Scan(&id); err != nil {
+  `, tenantID, payload.LeaveTypeID, payload.AccrualRate, payload.AccrualPeriod, payload.Entitlement, payload.CarryOver, payload.CarryOverExpiryMonths, payload.YearStartMonth, payload.YearStartDay, bandsJSON, payload.AllowNegative, payload.RequiresHRApproval).Scan(&id); err != nil {
 		return "", err
 	}
 	return id, nil
@@ -138,7 +170,7 @@ func (s *Store) DeleteHoliday(ctx context.Context, tenantID, holidayID string) e
 
 func (s *Store) ListBalances(ctx context.Context, tenantID, employeeID string) ([]map[string]any, error) {
 	rows, err := s.DB.Query(ctx, `
-    SELECT id, employee_id, leave_type_id, balance, pending, used, updated_at
+    SELECT id, employee_id, leave_type_id, balance, pending, used, carried_over, carry_over_expires_on, updated_at
     FROM leave_balances
     WHERE tenant_id = $1 AND employee_id = $2
   `, tenantID, employeeID)
@@ -150,19 +182,22 @@ func (s *Store) ListBalances(ctx context.Context, tenantID, employeeID string) (
 	var balances []map[string]any
 	for rows.Next() {
 		var id, employee, leaveType string
-		var balance, pending, used float64
+		var balance, pending, used, carriedOver float64
+		var carryOverExpiresOn *time.Time
 		var updatedAt time.Time
-		if err := rows.Scan(&id, &employee, &leaveType, &balance, &pending, &used, &updatedAt); err != nil {
+		if err := rows.Scan(&id, &employee, &leaveType, &balance, &pending, &used, &carriedOver, &carryOverExpiresOn, &updatedAt); err != nil {
 			return nil, err
 		}
 		balances = append(balances, map[string]any{
-			"id":          id,
-			"employeeId":  employee,
-			"leaveTypeId": leaveType,
-			"balance":     balance,
-			"pending":     pending,
-			"used":        used,
-			"updatedAt":   updatedAt,
+			"id":                 id,
+			"employeeId":         employee,
+			"leaveTypeId":        leaveType,
+			"balance":            balance,
+			"pending":            pending,
+			"used":               used,
+			"carriedOver":        carriedOver,
+			"carryOverExpiresOn": carryOverExpiresOn,
+			"updatedAt":          updatedAt,
 		})
 	}
 	return balances, nil
@@ -186,13 +221,13 @@ func (s *Store) AdjustBalance(ctx context.Context, tenantID, employeeID, leaveTy
 	return nil
 }
 
-func (s *Store) ListRequests(ctx context.Context, tenantID, roleName, employeeID, managerEmployeeID string, limit, offset int) (RequestListResult, error) {
-	if roleName == auth.RoleManager && managerEmployeeID == "" {
+func (s *Store) ListRequests(ctx context.Context, tenantID, roleName, employeeID string, approverUserIDs []string, limit, offset int) (RequestListResult, error) {
+	if roleName == auth.RoleManager && len(approverUserIDs) == 0 {
 		return RequestListResult{Requests: []LeaveRequest{}, Total: 0}, nil
 	}
 
 	query := `
-    SELECT id, employee_id, leave_type_id, start_date, end_date, start_half, end_half, days, reason, status, created_at
+    SELECT id, employee_id, leave_type_id, start_date, end_date, start_half, end_half, days, hours, reason, status, created_at
     FROM leave_requests
     WHERE tenant_id = $1
   `
@@ -203,8 +238,8 @@ func (s *Store) ListRequests(ctx context.Context, tenantID, roleName, employeeID
 		args = append(args, employeeID)
 	}
 	if roleName == auth.RoleManager {
-		query += " AND employee_id IN (SELECT id FROM employees WHERE tenant_id = $1 AND manager_id = $2)"
-		args = append(args, managerEmployeeID)
+		query += " AND id IN (SELECT leave_request_id FROM leave_approvals WHERE tenant_id = $1 AND approver_id::text = ANY($2) AND status <> $3)"
+		args = append(args, approverUserIDs, ApprovalWaiting)
 	}
 	query += " ORDER BY created_at DESC"
 
@@ -219,8 +254,8 @@ func (s *Store) ListRequests(ctx context.Context, tenantID, roleName, employeeID
 		countArgs = append(countArgs, employeeID)
 	}
 	if roleName == auth.RoleManager {
-		countQuery += " AND employee_id IN (SELECT id FROM employees WHERE tenant_id = $1 AND manager_id = $2)"
-		countArgs = append(countArgs, managerEmployeeID)
+		countQuery += " AND id IN (SELECT leave_request_id FROM leave_approvals WHERE tenant_id = $1 AND approver_id::text = ANY($2) AND status <> $3)"
+		countArgs = append(countArgs, approverUserIDs, ApprovalWaiting)
 	}
 	var total int
 	if err := s.DB.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
@@ -241,7 +276,7 @@ func (s *Store) ListRequests(ctx context.Context, tenantID, roleName, employeeID
 	var requests []LeaveRequest
 	for rows.Next() {
 		var req LeaveRequest
-		if err := rows.Scan(&req.ID, &req.EmployeeID, &req.LeaveTypeID, &req.StartDate, &req.EndDate, &req.StartHalf, &req.EndHalf, &req.Days, &req.Reason, &req.Status, &req.CreatedAt); err != nil {
+		if err := rows.Scan(&req.ID, &req.EmployeeID, &req.LeaveTypeID, &req.StartDate, &req.EndDate, &req.StartHalf, &req.EndHalf, &req.Days, &req.Hours, &req.Reason, &req.Status, &req.CreatedAt); err != nil {
 			return RequestListResult{}, err
 		}
 		requests = append(requests, req)
@@ -266,7 +301,7 @@ func (s *Store) ListRequests(ctx context.Context, tenantID, roleName, employeeID
 func (s *Store) GetRequest(ctx context.Context, tenantID, requestID string) (LeaveRequest, error) {
 	var req LeaveRequest
 	if err := s.DB.QueryRow(ctx, `
-    SELECT id, employee_id, leave_type_id, start_date, end_date, start_half, end_half, days, reason, status, created_at
+    SELECT id, employee_id, leave_type_id, start_date, end_date, start_half, end_half, days, hours, reason, status, created_at
     FROM leave_requests
     WHERE tenant_id = $1 AND id = $2
   `, tenantID, requestID).Scan(
@@ -278,6 +313,7 @@ func (s *Store) GetRequest(ctx context.Context, tenantID, requestID string) (Lea
 		&req.StartHalf,
 		&req.EndHalf,
 		&req.Days,
+		&req.Hours,
 		&req.Reason,
 		&req.Status,
 		&req.CreatedAt,
@@ -290,6 +326,12 @@ func (s *Store) GetRequest(ctx context.Context, tenantID, requestID string) (Lea
 		return LeaveRequest{}, err
 	}
 	req.Documents = docsByRequest[requestID]
+
+	approvals, err := s.ListApprovals(ctx, tenantID, requestID)
+	if err != nil {
+		return LeaveRequest{}, err
+	}
+	req.Approvals = approvals
 	return req, nil
 }
 
@@ -306,15 +348,72 @@ func (s *Store) RequiresHRApproval(ctx context.Context, tenantID, leaveTypeID st
 	return requiresHR, nil
 }
 
-func (s *Store) CreateRequest(ctx context.Context, tenantID, employeeID, leaveTypeID, reason string, startDate, endDate time.Time, startHalf, endHalf bool, days float64, status string) (string, error) {
+// CreateRequest stores a leave request with its approval steps and holds
+// pending on the employee's balance, all in one transaction. Hours are
+// recorded only for requests against hourly leave types; zero hours leaves
+// them unset. Approvals with a zero StepOrder are recorded outside a chain.
+// check, when set, runs first under the tenant's staffing lock.
+func (s *Store) CreateRequest(ctx context.Context, tenantID, employeeID, leaveTypeID, reason string, startDate, endDate time.Time, startHalf, endHalf bool, days, hours, pending float64, status string, approvals []LeaveApproval, check StaffingCheck) (string, error) {
+	var hoursValue *float64
+	if hours > 0 {
+		hoursValue = &hours
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if check != nil {
+		if err := lockStaffing(ctx, tx, tenantID); err != nil {
+			return "", err
+		}
+		request := LeaveRequest{EmployeeID: employeeID, LeaveTypeID: leaveTypeID, StartDate: startDate, EndDate: endDate}
+		if err := check(ctx, &Store{DB: tx}, request); err != nil {
+			return "", err
+		}
+	}
+
 	var id string
-	if err := s.DB.QueryRow(ctx, `
-    INSERT INTO leave_requests (tenant_id, employee_id, leave_type_id, start_date, end_date, start_half, end_half, days, reason, status)
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+	if err := tx.QueryRow(ctx, `
+    INSERT INTO leave_requests (tenant_id, employee_id, leave_type_id, start_date, end_date, start_half, end_half, days, hours, reason, status)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
     RETURNING id
-  `, tenantID, employeeID, leaveTypeID, startDate, endDate, startHalf, endHalf, days, reason, status).Scan(&id); err != nil {
+  `, tenantID, employeeID, leaveTypeID, startDate, endDate, startHalf, endHalf, days, hoursValue, reason, status).Scan(&id); err != nil {
 		return "", err
 	}
+
+	if _, err := tx.Exec(ctx, `
+    INSERT INTO leave_balances (tenant_id, employee_id, leave_type_id, balance, pending, used)
+    VALUES ($1,$2,$3,0,$4,0)
+    ON CONFLICT (employee_id, leave_type_id) DO UPDATE SET pending = leave_balances.pending + EXCLUDED.pending, updated_at = now()
+  `, tenantID, employeeID, leaveTypeID, pending); err != nil {
+		return "", err
+	}
+
+	for _, approval := range approvals {
+		var stepOrder *int
+		if approval.StepOrder > 0 {
+			stepOrder = &approval.StepOrder
+		}
+		if _, err := tx.Exec(ctx, `
+      INSERT INTO leave_approvals (tenant_id, leave_request_id, approver_id, approver_role, step_order, status)
+      VALUES ($1,$2,$3,$4,$5,$6)
+    `, tenantID, id, nullIfEmpty(approval.ApproverID), nullIfEmpty(approval.ApproverRole), stepOrder, approval.Status); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	committed = true
 	return id, nil
 }
 
@@ -397,15 +496,6 @@ func (s *Store) RequestDocumentData(ctx context.Context, tenantID, requestID, do
 	return doc, data, nil
 }
 
-func (s *Store) AddPendingBalance(ctx context.Context, tenantID, employeeID, leaveTypeID string, days float64) error {
-	_, err := s.DB.Exec(ctx, `
-    INSERT INTO leave_balances (tenant_id, employee_id, leave_type_id, balance, pending, used)
-    VALUES ($1,$2,$3,0,$4,0)
-    ON CONFLICT (employee_id, leave_type_id) DO UPDATE SET pending = leave_balances.pending + EXCLUDED.pending, updated_at = now()
-  `, tenantID, employeeID, leaveTypeID, days)
-	return err
-}
-
 func (s *Store) ManagerUserIDForEmployee(ctx context.Context, tenantID, employeeID string) (string, error) {
 	var managerUserID string
 	if err := s.DB.QueryRow(ctx, `
@@ -429,14 +519,62 @@ func (s *Store) InsertApproval(ctx context.Context, tenantID, requestID, approve
 
 func (s *Store) UpdateRequestStatus(ctx context.Context, requestID, status, approverID string) error {
 	_, err := s.DB.Exec(ctx, `
-    UPDATE leave_requests SET status = $1, approved_by = $2, approved_at = now() WHERE id = $3
+    UPDATE leave_requests SET status = $1, approved_by = $2, approved_at = now(), updated_at = now(), revision = revision + 1 WHERE id = $3
   `, status, approverID, requestID)
 	return err
 }
 
+// ApproveRequestStatus approves a pending request once check passes, both
+// under the tenant's staffing lock. It returns ErrInvalidState when the
+// request is no longer awaiting approval.
+func (s *Store) ApproveRequestStatus(ctx context.Context, tenantID, requestID, approverID string, check StaffingCheck) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err := lockStaffing(ctx, tx, tenantID); err != nil {
+		return err
+	}
+	request := LeaveRequest{ID: requestID}
+	if err := tx.QueryRow(ctx, `
+    SELECT employee_id, leave_type_id, start_date, end_date, status
+    FROM leave_requests
+    WHERE tenant_id = $1 AND id = $2
+    FOR UPDATE
+  `, tenantID, requestID).Scan(&request.EmployeeID, &request.LeaveTypeID, &request.StartDate, &request.EndDate, &request.Status); err != nil {
+		return err
+	}
+	if request.Status != StatusPending && request.Status != StatusPendingHR {
+		return ErrInvalidState
+	}
+	if check != nil {
+		if err := check(ctx, &Store{DB: tx}, request); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+    UPDATE leave_requests SET status = $1, approved_by = $2, approved_at = now(), updated_at = now(), revision = revision + 1 WHERE tenant_id = $3 AND id = $4
+  `, StatusApproved, approverID, tenantID, requestID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
 func (s *Store) UpdateRequestStatusSimple(ctx context.Context, requestID, status string) error {
 	_, err := s.DB.Exec(ctx, `
-    UPDATE leave_requests SET status = $1 WHERE id = $2
+    UPDATE leave_requests SET status = $1, updated_at = now(), revision = revision + 1 WHERE id = $2
   `, status, requestID)
 	return err
 }
@@ -463,11 +601,14 @@ func (s *Store) HRUserIDs(ctx context.Context, tenantID string) ([]string, error
 	return hrUserIDs, nil
 }
 
+// RequestInfo returns a request's employee, leave type, status and the amount
+// it takes from the balance: its hours for hourly leave types and its days
+// otherwise.
 func (s *Store) RequestInfo(ctx context.Context, tenantID, requestID string) (string, string, float64, string, error) {
 	var employeeID, leaveTypeID, status string
 	var days float64
 	if err := s.DB.QueryRow(ctx, `
-    SELECT employee_id, leave_type_id, days, status
+    SELECT employee_id, leave_type_id, COALESCE(hours, days), status
     FROM leave_requests
     WHERE id = $1 AND tenant_id = $2
   `, requestID, tenantID).Scan(&employeeID, &leaveTypeID, &days, &status); err != nil {
@@ -479,7 +620,8 @@ func (s *Store) RequestInfo(ctx context.Context, tenantID, requestID string) (st
 func (s *Store) UpdateBalanceOnApproval(ctx context.Context, tenantID, employeeID, leaveTypeID string, days float64) error {
 	_, err := s.DB.Exec(ctx, `
     UPDATE leave_balances
-    SET pending = pending - $1, used = used + $1, updated_at = now()
+    SET pending = pending - $1, used = used + $1,
+        carried_over = GREATEST(carried_over - $1, 0), updated_at = now()
     WHERE tenant_id = $2 AND employee_id = $3 AND leave_type_id = $4
   `, days, tenantID, employeeID, leaveTypeID)
 	return err
@@ -0,0 +1,266 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const encashmentColumns = `id, employee_id, leave_type_id, amount, COALESCE(days, 0), source, status, COALESCE(reason, ''),
+           COALESCE(daily_rate, 0), COALESCE(value, 0), COALESCE(currency, ''), COALESCE(payroll_period_id::text, ''),
+           COALESCE(payroll_adjustment_id::text, ''), COALESCE(requested_by::text, ''), COALESCE(decided_by::text, ''),
+           decided_at, created_at`
+
+func scanEncashment(row pgx.Row) (Encashment, error) {
+	var encashment Encashment
+	err := row.Scan(&encashment.ID, &encashment.EmployeeID, &encashment.LeaveTypeID, &encashment.Amount, &encashment.Days,
+		&encashment.Source, &encashment.Status, &encashment.Reason, &encashment.DailyRate, &encashment.Value, &encashment.Currency,
+		&encashment.PayrollPeriodID, &encashment.PayrollAdjustmentID, &encashment.RequestedBy, &encashment.DecidedBy,
+		&encashment.DecidedAt, &encashment.CreatedAt)
+	return encashment, err
+}
+
+func (s *Store) ListEncashments(ctx context.Context, tenantID, employeeID string) ([]Encashment, error) {
+	query := `
+    SELECT ` + encashmentColumns + `
+    FROM leave_encashments
+    WHERE tenant_id = $1
+  `
+	args := []any{tenantID}
+	if employeeID != "" {
+		query += " AND employee_id = $2"
+		args = append(args, employeeID)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	encashments := make([]Encashment, 0)
+	for rows.Next() {
+		encashment, err := scanEncashment(rows)
+		if err != nil {
+			return nil, err
+		}
+		encashments = append(encashments, encashment)
+	}
+	return encashments, rows.Err()
+}
+
+func (s *Store) GetEncashment(ctx context.Context, tenantID, encashmentID string) (Encashment, error) {
+	encashment, err := scanEncashment(s.DB.QueryRow(ctx, `
+    SELECT `+encashmentColumns+`
+    FROM leave_encashments
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, encashmentID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Encashment{}, ErrEncashmentNotFound
+	}
+	return encashment, err
+}
+
+func (s *Store) CreateEncashment(ctx context.Context, tenantID string, encashment Encashment) (string, error) {
+	var id string
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO leave_encashments (tenant_id, employee_id, leave_type_id, amount, source, status, reason, requested_by)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+    RETURNING id
+  `, tenantID, encashment.EmployeeID, encashment.LeaveTypeID, encashment.Amount, encashment.Source, encashment.Status,
+		nullIfEmpty(encashment.Reason), nullIfEmpty(encashment.RequestedBy)).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// AvailableBalance returns what is left of an employee's balance of a leave
+// type after used and pending leave.
+func (s *Store) AvailableBalance(ctx context.Context, tenantID, employeeID, leaveTypeID string) (float64, error) {
+	var available float64
+	err := s.DB.QueryRow(ctx, `
+    SELECT balance - used - pending
+    FROM leave_balances
+    WHERE tenant_id = $1 AND employee_id = $2 AND leave_type_id = $3
+  `, tenantID, employeeID, leaveTypeID).Scan(&available)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return available, err
+}
+
+// EncashableBalances returns the employee's available balances of encashable
+// leave types.
+func (s *Store) EncashableBalances(ctx context.Context, tenantID, employeeID string) ([]EncashableBalance, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT lb.leave_type_id, lb.balance - lb.used - lb.pending
+    FROM leave_balances lb
+    JOIN leave_types lt ON lt.id = lb.leave_type_id
+    WHERE lb.tenant_id = $1 AND lb.employee_id = $2 AND lt.encashable
+    ORDER BY lt.name
+  `, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []EncashableBalance
+	for rows.Next() {
+		var balance EncashableBalance
+		if err := rows.Scan(&balance.LeaveTypeID, &balance.Available); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+	return balances, rows.Err()
+}
+
+// TerminationsDue returns employees whose end date is on or before the given
+// day and differs from the end date their leave was last settled for,
+// skipping those with a termination encashment still pending.
+func (s *Store) TerminationsDue(ctx context.Context, tenantID string, on time.Time) ([]Termination, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT e.id, e.end_date
+    FROM employees e
+    WHERE e.tenant_id = $1 AND e.end_date IS NOT NULL AND e.end_date <= $2
+      AND e.termination_settled_on IS DISTINCT FROM e.end_date
+      AND NOT EXISTS (
+        SELECT 1 FROM leave_encashments le
+        WHERE le.tenant_id = e.tenant_id AND le.employee_id = e.id AND le.source = $3 AND le.status = $4
+      )
+    ORDER BY e.end_date, e.id
+  `, tenantID, on, EncashmentSourceTermination, EncashmentPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Termination
+	for rows.Next() {
+		var termination Termination
+		if err := rows.Scan(&termination.EmployeeID, &termination.EndDate); err != nil {
+			return nil, err
+		}
+		out = append(out, termination)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) MarkTerminationSettled(ctx context.Context, tenantID, employeeID string, endDate time.Time) error {
+	_, err := s.DB.Exec(ctx, `
+    UPDATE employees SET termination_settled_on = $3 WHERE tenant_id = $1 AND id = $2
+  `, tenantID, employeeID, endDate)
+	return err
+}
+
+// ApproveEncashment approves a pending encashment and, in the same
+// transaction, deducts it from the balance, logs the deduction as a balance
+// adjustment and adds its value to the payroll period as a payroll
+// adjustment. The payroll period is locked and must still be a draft regular
+// period, so it cannot be reviewed past the adjustment. It returns the
+// payroll adjustment ID, ErrNoPayrollPeriod when the period is no longer
+// open, or ErrInsufficientBalance when the balance no longer covers the
+// amount.
+func (s *Store) ApproveEncashment(ctx context.Context, tenantID string, encashment Encashment, approverID, description string) (string, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var periodID string
+	err = tx.QueryRow(ctx, `
+    SELECT id
+    FROM payroll_periods
+    WHERE tenant_id = $1 AND id = $2 AND status = $3 AND run_type = $4
+    FOR UPDATE
+  `, tenantID, encashment.PayrollPeriodID, payrollPeriodDraft, payrollRunRegular).Scan(&periodID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNoPayrollPeriod
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var available float64
+	err = tx.QueryRow(ctx, `
+    SELECT balance - used - pending
+    FROM leave_balances
+    WHERE tenant_id = $1 AND employee_id = $2 AND leave_type_id = $3
+    FOR UPDATE
+  `, tenantID, encashment.EmployeeID, encashment.LeaveTypeID).Scan(&available)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrInsufficientBalance
+	}
+	if err != nil {
+		return "", err
+	}
+	if encashment.Amount > available {
+		return "", ErrInsufficientBalance
+	}
+
+	if _, err := tx.Exec(ctx, `
+    UPDATE leave_balances
+    SET balance = balance - $4, carried_over = GREATEST(carried_over - $4, 0), updated_at = now()
+    WHERE tenant_id = $1 AND employee_id = $2 AND leave_type_id = $3
+  `, tenantID, encashment.EmployeeID, encashment.LeaveTypeID, encashment.Amount); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+    INSERT INTO leave_balance_adjustments (tenant_id, employee_id, leave_type_id, amount, reason, created_by)
+    VALUES ($1,$2,$3,$4,$5,$6)
+  `, tenantID, encashment.EmployeeID, encashment.LeaveTypeID, -encashment.Amount, description, nullIfEmpty(approverID)); err != nil {
+		return "", err
+	}
+
+	var adjustmentID string
+	if err := tx.QueryRow(ctx, `
+    INSERT INTO payroll_adjustments (tenant_id, period_id, employee_id, description, amount)
+    VALUES ($1,$2,$3,$4,$5)
+    RETURNING id
+  `, tenantID, encashment.PayrollPeriodID, encashment.EmployeeID, description, encashment.Value).Scan(&adjustmentID); err != nil {
+		return "", err
+	}
+
+	tag, err := tx.Exec(ctx, `
+    UPDATE leave_encashments
+    SET status = $3, days = $4, daily_rate = $5, value = $6, currency = $7, payroll_period_id = $8,
+        payroll_adjustment_id = $9, decided_by = $10, decided_at = now()
+    WHERE tenant_id = $1 AND id = $2 AND status = $11
+  `, tenantID, encashment.ID, EncashmentApproved, encashment.Days, encashment.DailyRate, encashment.Value,
+		nullIfEmpty(encashment.Currency), encashment.PayrollPeriodID, adjustmentID, nullIfEmpty(approverID), EncashmentPending)
+	if err != nil {
+		return "", err
+	}
+	if tag.RowsAffected() == 0 {
+		return "", ErrInvalidState
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	committed = true
+	return adjustmentID, nil
+}
+
+func (s *Store) RejectEncashment(ctx context.Context, tenantID, encashmentID, approverID string) error {
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE leave_encashments
+    SET status = $4, decided_by = $3, decided_at = now()
+    WHERE tenant_id = $1 AND id = $2 AND status = $5
+  `, tenantID, encashmentID, approverID, EncashmentRejected, EncashmentPending)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvalidState
+	}
+	return nil
+}
@@ -10,25 +10,46 @@ import (
 type StoreAPI interface {
 	ListTypes(ctx context.Context, tenantID string) ([]LeaveType, error)
 	CreateType(ctx context.Context, tenantID string, payload LeaveType) (string, error)
+	GetType(ctx context.Context, tenantID, leaveTypeID string) (LeaveType, error)
 	LeaveTypeRequiresDoc(ctx context.Context, tenantID, leaveTypeID string) (bool, error)
 	ListPolicies(ctx context.Context, tenantID string) ([]LeavePolicy, error)
 	CreatePolicy(ctx context.Context, tenantID string, payload LeavePolicy) (string, error)
 	ListHolidays(ctx context.Context, tenantID string) ([]map[string]any, error)
 	CreateHoliday(ctx context.Context, tenantID string, date time.Time, name, region string) (string, error)
 	DeleteHoliday(ctx context.Context, tenantID, holidayID string) error
+	ListWorkPatterns(ctx context.Context, tenantID string) ([]WorkPattern, error)
+	GetWorkPattern(ctx context.Context, tenantID, patternID string) (WorkPattern, error)
+	CreateWorkPattern(ctx context.Context, tenantID string, pattern WorkPattern) (string, error)
+	UpdateWorkPattern(ctx context.Context, tenantID string, pattern WorkPattern) error
+	AssignEmployeeSchedule(ctx context.Context, tenantID, employeeID, patternID, region string) error
+	AssignDepartmentSchedule(ctx context.Context, tenantID, departmentID, patternID, region string) error
+	WorkSchedule(ctx context.Context, tenantID, employeeID string, from, to time.Time) (WorkSchedule, error)
+	ListApprovalChains(ctx context.Context, tenantID string) ([]ApprovalChain, error)
+	GetApprovalChain(ctx context.Context, tenantID, chainID string) (ApprovalChain, error)
+	MatchApprovalChain(ctx context.Context, tenantID, leaveTypeID, employeeID string) (ApprovalChain, error)
+	CreateApprovalChain(ctx context.Context, tenantID string, chain ApprovalChain) (string, error)
+	UpdateApprovalChain(ctx context.Context, tenantID string, chain ApprovalChain) error
+	DeleteApprovalChain(ctx context.Context, tenantID, chainID string) error
+	ApprovalAssignees(ctx context.Context, tenantID, employeeID string) (ApproverSet, error)
+	ListApprovals(ctx context.Context, tenantID, requestID string) ([]LeaveApproval, error)
+	DecideApprovalStep(ctx context.Context, tenantID, approvalID, status, approverID string) error
+	ActivateApprovalStep(ctx context.Context, tenantID, approvalID string) error
+	CloseApprovalSteps(ctx context.Context, tenantID, requestID string) error
+	DueApprovalReminders(ctx context.Context, tenantID string, cutoff time.Time) ([]ApprovalReminder, error)
+	MarkApprovalReminded(ctx context.Context, tenantID, approvalID string) error
 	ListBalances(ctx context.Context, tenantID, employeeID string) ([]map[string]any, error)
 	AdjustBalance(ctx context.Context, tenantID, employeeID, leaveTypeID, reason, userID string, amount float64) error
-	ListRequests(ctx context.Context, tenantID, roleName, employeeID, managerEmployeeID string, limit, offset int) (RequestListResult, error)
+	ListRequests(ctx context.Context, tenantID, roleName, employeeID string, approverUserIDs []string, limit, offset int) (RequestListResult, error)
 	GetRequest(ctx context.Context, tenantID, requestID string) (LeaveRequest, error)
 	RequiresHRApproval(ctx context.Context, tenantID, leaveTypeID string) (bool, error)
-	CreateRequest(ctx context.Context, tenantID, employeeID, leaveTypeID, reason string, startDate, endDate time.Time, startHalf, endHalf bool, days float64, status string) (string, error)
+	CreateRequest(ctx context.Context, tenantID, employeeID, leaveTypeID, reason string, startDate, endDate time.Time, startHalf, endHalf bool, days, hours, pending float64, status string, approvals []LeaveApproval, check StaffingCheck) (string, error)
 	CreateRequestDocument(ctx context.Context, tenantID, requestID string, payload LeaveRequestDocumentUpload, uploadedBy string) (LeaveRequestDocument, error)
 	ListRequestDocuments(ctx context.Context, tenantID string, requestIDs []string) (map[string][]LeaveRequestDocument, error)
 	RequestDocumentData(ctx context.Context, tenantID, requestID, documentID string) (LeaveRequestDocument, []byte, error)
-	AddPendingBalance(ctx context.Context, tenantID, employeeID, leaveTypeID string, days float64) error
 	ManagerUserIDForEmployee(ctx context.Context, tenantID, employeeID string) (string, error)
 	InsertApproval(ctx context.Context, tenantID, requestID, approverID, status string) error
 	UpdateRequestStatus(ctx context.Context, requestID, status, approverID string) error
+	ApproveRequestStatus(ctx context.Context, tenantID, requestID, approverID string, check StaffingCheck) error
 	UpdateRequestStatusSimple(ctx context.Context, requestID, status string) error
 	HRUserIDs(ctx context.Context, tenantID string) ([]string, error)
 	RequestInfo(ctx context.Context, tenantID, requestID string) (string, string, float64, string, error)
@@ -39,13 +60,52 @@ type StoreAPI interface {
 	CalendarExportRows(ctx context.Context, tenantID string, statuses []string, employeeID, managerID string) ([]CalendarExportRow, error)
 	ReportBalances(ctx context.Context, tenantID string) ([]map[string]any, error)
 	ReportUsage(ctx context.Context, tenantID string) ([]map[string]any, error)
+	BeginTx(ctx context.Context) (pgx.Tx, error)
+	ListCarryOverPolicies(ctx context.Context, tenantID string) ([]CarryOverPolicy, error)
+	CarryOverProcessed(ctx context.Context, tenantID, policyID string, year int) (bool, error)
+	ListCarryOverBalancesTx(ctx context.Context, tx pgx.Tx, tenantID, leaveTypeID string) ([]CarryOverBalance, error)
+	ApplyCarryOverTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID, leaveTypeID string, carried, forfeited float64, expiresOn *time.Time, reason string) error
+	RecordCarryOverRunTx(ctx context.Context, tx pgx.Tx, tenantID, policyID string, year, balancesProcessed int) error
+	ExpireCarriedBalances(ctx context.Context, tenantID string, on time.Time) (int, float64, error)
+	DueCarryOverNotices(ctx context.Context, tenantID string, before time.Time) ([]CarryOverNotice, error)
+	MarkCarryOverNotified(ctx context.Context, tenantID, balanceID string) error
+	ListTOILEntries(ctx context.Context, tenantID string, filter TOILFilter) ([]TOILEntry, error)
+	GetTOILEntry(ctx context.Context, tenantID, entryID string) (TOILEntry, error)
+	CreateTOILEntry(ctx context.Context, tenantID string, entry TOILEntry) (string, error)
+	ApproveTOILEntry(ctx context.Context, tenantID, entryID, approverID string, expiresOn *time.Time) error
+	RejectTOILEntry(ctx context.Context, tenantID, entryID, approverID string) error
+	ConsumeTOIL(ctx context.Context, tenantID, employeeID, leaveTypeID string, hours float64) error
+	ExpireTOIL(ctx context.Context, tenantID string, on time.Time) (int, float64, error)
+	ListEncashments(ctx context.Context, tenantID, employeeID string) ([]Encashment, error)
+	GetEncashment(ctx context.Context, tenantID, encashmentID string) (Encashment, error)
+	CreateEncashment(ctx context.Context, tenantID string, encashment Encashment) (string, error)
+	AvailableBalance(ctx context.Context, tenantID, employeeID, leaveTypeID string) (float64, error)
+	EncashableBalances(ctx context.Context, tenantID, employeeID string) ([]EncashableBalance, error)
+	TerminationsDue(ctx context.Context, tenantID string, on time.Time) ([]Termination, error)
+	MarkTerminationSettled(ctx context.Context, tenantID, employeeID string, endDate time.Time) error
+	ApproveEncashment(ctx context.Context, tenantID string, encashment Encashment, approverID, description string) (string, error)
+	RejectEncashment(ctx context.Context, tenantID, encashmentID, approverID string) error
+	ListStaffingRules(ctx context.Context, tenantID string) ([]StaffingRule, error)
+	GetStaffingRule(ctx context.Context, tenantID, ruleID string) (StaffingRule, error)
+	CreateStaffingRule(ctx context.Context, tenantID string, rule StaffingRule) (string, error)
+	UpdateStaffingRule(ctx context.Context, tenantID string, rule StaffingRule) error
+	DeleteStaffingRule(ctx context.Context, tenantID, ruleID string) error
+	StaffingTeam(ctx context.Context, tenantID, employeeID string) (StaffingTeam, error)
+	TeamCalendarEntries(ctx context.Context, tenantID string, team StaffingTeam, statuses []string, from, to time.Time) ([]CalendarEntry, error)
+	ListCalendarFeeds(ctx context.Context, tenantID, userID string) ([]CalendarFeed, error)
+	CreateCalendarFeed(ctx context.Context, tenantID, userID string, feed CalendarFeed, tokenHash string) (string, time.Time, error)
+	RevokeCalendarFeed(ctx context.Context, tenantID, userID, feedID string) error
+	CalendarFeedByToken(ctx context.Context, tokenHash string) (CalendarFeed, error)
+	TouchCalendarFeed(ctx context.Context, feedID string) error
+	FeedLeave(ctx context.Context, tenantID string, statuses []string, employeeID, managerID string, since time.Time) ([]FeedLeave, error)
+	FeedHolidays(ctx context.Context, tenantID, region string, since time.Time) ([]FeedHoliday, error)
 }
 
 type AccrualStore interface {
 	ListAccrualPolicies(ctx context.Context, tenantID string) ([]policyRow, error)
 	LastAccruedOn(ctx context.Context, tenantID, policyID string) (time.Time, error)
 	BeginTx(ctx context.Context) (pgx.Tx, error)
-	ListActiveEmployeesTx(ctx context.Context, tx pgx.Tx, tenantID string) (map[string]*time.Time, error)
+	ListActiveEmployeesTx(ctx context.Context, tx pgx.Tx, tenantID string) ([]accrualEmployee, error)
 	UpsertBalanceTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID, leaveTypeID string, accrual float64) error
 	UpsertBalanceWithCapTx(ctx context.Context, tx pgx.Tx, tenantID, employeeID, leaveTypeID string, accrual, cap float64) error
 	RecordAccrualRunTx(ctx context.Context, tx pgx.Tx, tenantID, policyID string, lastAccruedOn time.Time, employeesAccrued int) error
@@ -0,0 +1,159 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const staffingRuleColumns = `id, name, kind, COALESCE(department_id::text, ''), COALESCE(manager_id::text, ''),
+           COALESCE(max_absent, 0), start_date, end_date, enforcement, created_at`
+
+func scanStaffingRule(row pgx.Row) (StaffingRule, error) {
+	var rule StaffingRule
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Kind, &rule.DepartmentID, &rule.ManagerID,
+		&rule.MaxAbsent, &rule.StartDate, &rule.EndDate, &rule.Enforcement, &rule.CreatedAt)
+	return rule, err
+}
+
+func (s *Store) ListStaffingRules(ctx context.Context, tenantID string) ([]StaffingRule, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT `+staffingRuleColumns+`
+    FROM leave_staffing_rules
+    WHERE tenant_id = $1
+    ORDER BY name
+  `, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []StaffingRule
+	for rows.Next() {
+		rule, err := scanStaffingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rule)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetStaffingRule(ctx context.Context, tenantID, ruleID string) (StaffingRule, error) {
+	rule, err := scanStaffingRule(s.DB.QueryRow(ctx, `
+    SELECT `+staffingRuleColumns+`
+    FROM leave_staffing_rules
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, ruleID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return StaffingRule{}, ErrStaffingRuleNotFound
+	}
+	return rule, err
+}
+
+func (s *Store) CreateStaffingRule(ctx context.Context, tenantID string, rule StaffingRule) (string, error) {
+	var id string
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO leave_staffing_rules (tenant_id, name, kind, department_id, manager_id, max_absent, start_date, end_date, enforcement)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+    RETURNING id
+  `, tenantID, rule.Name, rule.Kind, nullIfEmpty(rule.DepartmentID), nullIfEmpty(rule.ManagerID), rule.MaxAbsent,
+		rule.StartDate, rule.EndDate, rule.Enforcement).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *Store) UpdateStaffingRule(ctx context.Context, tenantID string, rule StaffingRule) error {
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE leave_staffing_rules
+    SET name = $3, kind = $4, department_id = $5, manager_id = $6, max_absent = $7, start_date = $8, end_date = $9,
+        enforcement = $10, updated_at = now()
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, rule.ID, rule.Name, rule.Kind, nullIfEmpty(rule.DepartmentID), nullIfEmpty(rule.ManagerID), rule.MaxAbsent,
+		rule.StartDate, rule.EndDate, rule.Enforcement)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrStaffingRuleNotFound
+	}
+	return nil
+}
+
+func (s *Store) DeleteStaffingRule(ctx context.Context, tenantID, ruleID string) error {
+	tag, err := s.DB.Exec(ctx, `
+    DELETE FROM leave_staffing_rules
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, ruleID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrStaffingRuleNotFound
+	}
+	return nil
+}
+
+// lockStaffing holds the tenant's staffing lock until tx ends. Filing and
+// approving leave take it before checking staffing rules so concurrent
+// requests are checked one after another.
+func lockStaffing(ctx context.Context, tx pgx.Tx, tenantID string) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('leave_staffing'), hashtext($1))`, tenantID)
+	return err
+}
+
+// StaffingTeam returns the department and manager staffing rules place an
+// employee under.
+func (s *Store) StaffingTeam(ctx context.Context, tenantID, employeeID string) (StaffingTeam, error) {
+	team := StaffingTeam{EmployeeID: employeeID}
+	err := s.DB.QueryRow(ctx, `
+    SELECT COALESCE(department_id::text, ''), COALESCE(manager_id::text, '')
+    FROM employees
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, employeeID).Scan(&team.DepartmentID, &team.ManagerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return team, ErrEmployeeNotFound
+	}
+	return team, err
+}
+
+// TeamCalendarEntries returns leave in the given statuses overlapping from to
+// to, with each employee's department and manager. A team with a department
+// or manager limits it to that department and the manager's reports; an empty
+// team returns the whole tenant.
+func (s *Store) TeamCalendarEntries(ctx context.Context, tenantID string, team StaffingTeam, statuses []string, from, to time.Time) ([]CalendarEntry, error) {
+	query := `
+    SELECT r.id, r.employee_id, r.leave_type_id, r.start_date, r.end_date, r.status,
+           COALESCE(e.department_id::text, ''), COALESCE(e.manager_id::text, '')
+    FROM leave_requests r
+    JOIN employees e ON r.employee_id = e.id
+    WHERE r.tenant_id = $1 AND r.status = ANY($2) AND r.start_date <= $4 AND r.end_date >= $3
+  `
+	args := []any{tenantID, statuses, from, to}
+	if team.DepartmentID != "" || team.ManagerID != "" {
+		args = append(args, team.DepartmentID, team.ManagerID)
+		query += fmt.Sprintf(" AND ((e.department_id::text = $%d AND $%d <> '') OR (e.manager_id::text = $%d AND $%d <> ''))", len(args)-1, len(args)-1, len(args), len(args))
+	}
+	query += " ORDER BY r.start_date, r.employee_id"
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CalendarEntry
+	for rows.Next() {
+		var entry CalendarEntry
+		if err := rows.Scan(&entry.ID, &entry.EmployeeID, &entry.LeaveTypeID, &entry.StartDate, &entry.EndDate, &entry.Status,
+			&entry.DepartmentID, &entry.ManagerID); err != nil {
+			return nil, err
+		}
+		out = append(out, entry)
+	}
+	return out, rows.Err()
+}
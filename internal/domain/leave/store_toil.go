@@ -0,0 +1,214 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const toilEntryColumns = `id, employee_id, leave_type_id, work_date, hours, remaining, COALESCE(reason, ''), status,
+           expires_on, COALESCE(requested_by::text, ''), COALESCE(decided_by::text, ''), decided_at, created_at`
+
+func scanTOILEntry(row pgx.Row) (TOILEntry, error) {
+	var entry TOILEntry
+	err := row.Scan(&entry.ID, &entry.EmployeeID, &entry.LeaveTypeID, &entry.WorkDate, &entry.Hours, &entry.Remaining, &entry.Reason, &entry.Status,
+		&entry.ExpiresOn, &entry.RequestedBy, &entry.DecidedBy, &entry.DecidedAt, &entry.CreatedAt)
+	return entry, err
+}
+
+func (s *Store) ListTOILEntries(ctx context.Context, tenantID string, filter TOILFilter) ([]TOILEntry, error) {
+	query := `
+    SELECT ` + toilEntryColumns + `
+    FROM toil_entries
+    WHERE tenant_id = $1
+  `
+	args := []any{tenantID}
+	var scopes []string
+	if filter.EmployeeID != "" {
+		args = append(args, filter.EmployeeID)
+		scopes = append(scopes, fmt.Sprintf("employee_id = $%d", len(args)))
+	}
+	if len(filter.ManagerEmployeeIDs) > 0 {
+		args = append(args, filter.ManagerEmployeeIDs)
+		scopes = append(scopes, fmt.Sprintf("employee_id IN (SELECT id FROM employees WHERE tenant_id = $1 AND manager_id::text = ANY($%d))", len(args)))
+	}
+	if len(scopes) > 0 {
+		query += " AND (" + strings.Join(scopes, " OR ") + ")"
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	query += " ORDER BY work_date DESC, created_at DESC"
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]TOILEntry, 0)
+	for rows.Next() {
+		entry, err := scanTOILEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *Store) GetTOILEntry(ctx context.Context, tenantID, entryID string) (TOILEntry, error) {
+	entry, err := scanTOILEntry(s.DB.QueryRow(ctx, `
+    SELECT `+toilEntryColumns+`
+    FROM toil_entries
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, entryID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return TOILEntry{}, ErrTOILEntryNotFound
+	}
+	return entry, err
+}
+
+func (s *Store) CreateTOILEntry(ctx context.Context, tenantID string, entry TOILEntry) (string, error) {
+	var id string
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO toil_entries (tenant_id, employee_id, leave_type_id, work_date, hours, reason, status, requested_by)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+    RETURNING id
+  `, tenantID, entry.EmployeeID, entry.LeaveTypeID, entry.WorkDate, entry.Hours, entry.Reason, entry.Status, nullIfEmpty(entry.RequestedBy)).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// ApproveTOILEntry approves a pending entry and, in the same transaction,
+// credits its hours to the employee's balance and logs the credit as a
+// balance adjustment.
+func (s *Store) ApproveTOILEntry(ctx context.Context, tenantID, entryID, approverID string, expiresOn *time.Time) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var employeeID, leaveTypeID string
+	var hours float64
+	var workDate time.Time
+	err = tx.QueryRow(ctx, `
+    UPDATE toil_entries
+    SET status = $4, remaining = hours, expires_on = $5, decided_by = $3, decided_at = now()
+    WHERE tenant_id = $1 AND id = $2 AND status = $6
+    RETURNING employee_id, leave_type_id, hours, work_date
+  `, tenantID, entryID, approverID, TOILApproved, expiresOn, TOILPending).Scan(&employeeID, &leaveTypeID, &hours, &workDate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInvalidState
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+    INSERT INTO leave_balances (tenant_id, employee_id, leave_type_id, balance, pending, used)
+    VALUES ($1,$2,$3,$4,0,0)
+    ON CONFLICT (employee_id, leave_type_id) DO UPDATE SET balance = leave_balances.balance + EXCLUDED.balance, updated_at = now()
+  `, tenantID, employeeID, leaveTypeID, hours); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+    INSERT INTO leave_balance_adjustments (tenant_id, employee_id, leave_type_id, amount, reason, created_by)
+    VALUES ($1,$2,$3,$4,$5,$6)
+  `, tenantID, employeeID, leaveTypeID, hours, "TOIL for overtime on "+workDate.Format("2006-01-02"), approverID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (s *Store) RejectTOILEntry(ctx context.Context, tenantID, entryID, approverID string) error {
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE toil_entries
+    SET status = $4, decided_by = $3, decided_at = now()
+    WHERE tenant_id = $1 AND id = $2 AND status = $5
+  `, tenantID, entryID, approverID, TOILRejected, TOILPending)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvalidState
+	}
+	return nil
+}
+
+// ConsumeTOIL uses hours from an employee's approved TOIL entries of a leave
+// type, taking the entries that expire first. Leave types without TOIL
+// entries are left untouched.
+func (s *Store) ConsumeTOIL(ctx context.Context, tenantID, employeeID, leaveTypeID string, hours float64) error {
+	_, err := s.DB.Exec(ctx, `
+    WITH ordered AS (
+      SELECT id, remaining,
+             SUM(remaining) OVER (ORDER BY expires_on NULLS LAST, work_date, created_at, id) AS running
+      FROM toil_entries
+      WHERE tenant_id = $1 AND employee_id = $2 AND leave_type_id = $3
+        AND status = $5 AND remaining > 0
+    )
+    UPDATE toil_entries t
+    SET remaining = LEAST(o.remaining, GREATEST(o.running - $4, 0))
+    FROM ordered o
+    WHERE t.id = o.id AND o.running - o.remaining < $4
+  `, tenantID, employeeID, leaveTypeID, hours, TOILApproved)
+	return err
+}
+
+// ExpireTOIL expires approved entries whose expiry date is before the given
+// date, removes their unused hours from the balances and logs each removal.
+// No more is removed than the balance has left after used and pending leave,
+// so hours already booked stay covered. It returns the number of entries
+// expired and the hours forfeited.
+func (s *Store) ExpireTOIL(ctx context.Context, tenantID string, on time.Time) (int, float64, error) {
+	var count int
+	var hours float64
+	err := s.DB.QueryRow(ctx, `
+    WITH expired AS (
+      UPDATE toil_entries
+      SET status = $3
+      WHERE tenant_id = $1 AND status = $4 AND expires_on IS NOT NULL AND expires_on < $2::date
+      RETURNING employee_id, leave_type_id, remaining
+    ), forfeited AS (
+      SELECT lb.employee_id, lb.leave_type_id,
+             LEAST(totals.hours, GREATEST(lb.balance - lb.used - lb.pending, 0)) AS hours
+      FROM (
+        SELECT employee_id, leave_type_id, SUM(remaining) AS hours
+        FROM expired
+        GROUP BY employee_id, leave_type_id
+      ) totals
+      JOIN leave_balances lb ON lb.tenant_id = $1 AND lb.employee_id = totals.employee_id
+        AND lb.leave_type_id = totals.leave_type_id
+    ), debited AS (
+      UPDATE leave_balances lb
+      SET balance = lb.balance - f.hours, updated_at = now()
+      FROM forfeited f
+      WHERE lb.tenant_id = $1 AND lb.employee_id = f.employee_id
+        AND lb.leave_type_id = f.leave_type_id AND f.hours > 0
+    ), logged AS (
+      INSERT INTO leave_balance_adjustments (tenant_id, employee_id, leave_type_id, amount, reason)
+      SELECT $1, employee_id, leave_type_id, -hours, 'TOIL expired'
+      FROM forfeited
+      WHERE hours > 0
+    )
+    SELECT (SELECT COUNT(*) FROM expired), COALESCE((SELECT SUM(hours) FROM forfeited), 0)
+  `, tenantID, on, TOILExpired, TOILApproved).Scan(&count, &hours)
+	return count, hours, err
+}
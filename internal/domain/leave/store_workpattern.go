@@ -0,0 +1,185 @@
+package leave
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) ListWorkPatterns(ctx context.Context, tenantID string) ([]WorkPattern, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, name, cycle_start, days_json, hours_per_day, created_at
+    FROM work_patterns
+    WHERE tenant_id = $1
+    ORDER BY name
+  `, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []WorkPattern
+	for rows.Next() {
+		pattern, err := scanWorkPattern(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pattern)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetWorkPattern(ctx context.Context, tenantID, patternID string) (WorkPattern, error) {
+	pattern, err := scanWorkPattern(s.DB.QueryRow(ctx, `
+    SELECT id, name, cycle_start, days_json, hours_per_day, created_at
+    FROM work_patterns
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, patternID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return WorkPattern{}, ErrWorkPatternNotFound
+	}
+	return pattern, err
+}
+
+func scanWorkPattern(row pgx.Row) (WorkPattern, error) {
+	var pattern WorkPattern
+	var daysJSON []byte
+	if err := row.Scan(&pattern.ID, &pattern.Name, &pattern.CycleStart, &daysJSON, &pattern.HoursPerDay, &pattern.CreatedAt); err != nil {
+		return pattern, err
+	}
+	if err := json.Unmarshal(daysJSON, &pattern.Days); err != nil {
+		return pattern, err
+	}
+	return pattern, nil
+}
+
+func (s *Store) CreateWorkPattern(ctx context.Context, tenantID string, pattern WorkPattern) (string, error) {
+	daysJSON, err := json.Marshal(pattern.Days)
+	if err != nil {
+		return "", err
+	}
+	var id string
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO work_patterns (tenant_id, name, cycle_start, days_json, hours_per_day)
+    VALUES ($1,$2,$3,$4,$5)
+    RETURNING id
+  `, tenantID, pattern.Name, pattern.CycleStart, daysJSON, pattern.HoursPerDay).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *Store) UpdateWorkPattern(ctx context.Context, tenantID string, pattern WorkPattern) error {
+	daysJSON, err := json.Marshal(pattern.Days)
+	if err != nil {
+		return err
+	}
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE work_patterns
+    SET name = $3, cycle_start = $4, days_json = $5, hours_per_day = $6, updated_at = now()
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, pattern.ID, pattern.Name, pattern.CycleStart, daysJSON, pattern.HoursPerDay)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWorkPatternNotFound
+	}
+	return nil
+}
+
+func (s *Store) AssignEmployeeSchedule(ctx context.Context, tenantID, employeeID, patternID, region string) error {
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE employees
+    SET work_pattern_id = $3, holiday_region = $4, updated_at = now()
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, employeeID, nullIfEmpty(patternID), nullIfEmpty(region))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrEmployeeNotFound
+	}
+	return nil
+}
+
+func (s *Store) AssignDepartmentSchedule(ctx context.Context, tenantID, departmentID, patternID, region string) error {
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE departments
+    SET work_pattern_id = $3, holiday_region = $4
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, departmentID, nullIfEmpty(patternID), nullIfEmpty(region))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDepartmentNotFound
+	}
+	return nil
+}
+
+// WorkSchedule resolves an employee's work pattern and holiday region, taking
+// each from the employee when set and from their department otherwise, and
+// loads the holidays between from and to that apply to that region.
+func (s *Store) WorkSchedule(ctx context.Context, tenantID, employeeID string, from, to time.Time) (WorkSchedule, error) {
+	var schedule WorkSchedule
+	var patternID, patternName string
+	var cycleStart *time.Time
+	var daysJSON []byte
+	var hoursPerDay float64
+	err := s.DB.QueryRow(ctx, `
+    SELECT COALESCE(p.id::text, ''), COALESCE(p.name, ''), p.cycle_start, p.days_json,
+           COALESCE(p.hours_per_day, 8), COALESCE(NULLIF(e.holiday_region, ''), d.holiday_region, '')
+    FROM employees e
+    LEFT JOIN departments d ON d.id = e.department_id
+    LEFT JOIN work_patterns p ON p.id = COALESCE(e.work_pattern_id, d.work_pattern_id)
+    WHERE e.tenant_id = $1 AND e.id = $2
+  `, tenantID, employeeID).Scan(&patternID, &patternName, &cycleStart, &daysJSON, &hoursPerDay, &schedule.Region)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schedule, ErrEmployeeNotFound
+	}
+	if err != nil {
+		return schedule, err
+	}
+
+	schedule.Pattern = StandardWorkPattern()
+	if patternID != "" && cycleStart != nil {
+		pattern := WorkPattern{ID: patternID, Name: patternName, CycleStart: *cycleStart, HoursPerDay: hoursPerDay}
+		if err := json.Unmarshal(daysJSON, &pattern.Days); err != nil {
+			return schedule, err
+		}
+		schedule.Pattern = pattern
+	}
+
+	rows, err := s.DB.Query(ctx, `
+    SELECT date
+    FROM holidays
+    WHERE tenant_id = $1
+      AND date BETWEEN $2 AND $3
+      AND (COALESCE(region, '') = '' OR region = $4)
+  `, tenantID, from, to, schedule.Region)
+	if err != nil {
+		return schedule, err
+	}
+	defer rows.Close()
+
+	schedule.Holidays = map[string]bool{}
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return schedule, err
+		}
+		schedule.Holidays[date.Format("2006-01-02")] = true
+	}
+	return schedule, rows.Err()
+}
+
+func nullIfEmpty(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
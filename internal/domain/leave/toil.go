@@ -0,0 +1,185 @@
+package leave
+
+import (
+	"context"
+	"time"
+
+	"hrm/internal/domain/auth"
+)
+
+// maxTOILHours bounds a single overtime entry to one day's worth of hours.
+const maxTOILHours = 24
+
+// TOILEntry is overtime worked on WorkDate that, once approved, credits its
+// hours to the employee's balance of a TOIL leave type. Remaining is what is
+// still unused; leave taken against the type uses the entries that expire
+// first. Approved entries past ExpiresOn expire and their remaining hours
+// leave the balance.
+type TOILEntry struct {
+	ID          string     `json:"id"`
+	EmployeeID  string     `json:"employeeId"`
+	LeaveTypeID string     `json:"leaveTypeId"`
+	WorkDate    time.Time  `json:"workDate"`
+	Hours       float64    `json:"hours"`
+	Remaining   float64    `json:"remaining"`
+	Reason      string     `json:"reason"`
+	Status      string     `json:"status"`
+	ExpiresOn   *time.Time `json:"expiresOn,omitempty"`
+	RequestedBy string     `json:"requestedBy,omitempty"`
+	DecidedBy   string     `json:"decidedBy,omitempty"`
+	DecidedAt   *time.Time `json:"decidedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// TOILFilter narrows the TOIL ledger to one employee, the reports of the
+// given managers, or both together. Empty fields do not filter.
+type TOILFilter struct {
+	EmployeeID         string
+	ManagerEmployeeIDs []string
+	Status             string
+}
+
+// RecordTOILResult reports a new TOIL entry and who must decide it: the
+// employee's manager, or HR when the employee has none.
+type RecordTOILResult struct {
+	ID            string
+	ManagerUserID string
+	HRUserIDs     []string
+}
+
+// TOILDecision describes an approved or rejected TOIL entry for
+// notifications and audit.
+type TOILDecision struct {
+	Entry         TOILEntry
+	EmployeeUser  string
+	LeaveTypeName string
+	// OnBehalfOf is the manager a delegate decided for.
+	OnBehalfOf string
+}
+
+// toilExpiry returns the last day hours earned on workDate may be used, or
+// nil when they do not expire.
+func toilExpiry(workDate time.Time, expiryDays int) *time.Time {
+	if expiryDays <= 0 {
+		return nil
+	}
+	expires := dateOnly(workDate).AddDate(0, 0, expiryDays)
+	return &expires
+}
+
+func (s *Service) ListTOILEntries(ctx context.Context, tenantID string, filter TOILFilter) ([]TOILEntry, error) {
+	return s.Store.ListTOILEntries(ctx, tenantID, filter)
+}
+
+func (s *Service) GetTOILEntry(ctx context.Context, tenantID, entryID string) (TOILEntry, error) {
+	return s.Store.GetTOILEntry(ctx, tenantID, entryID)
+}
+
+// RecordTOIL adds a pending overtime entry to the TOIL ledger. The leave type
+// must track TOIL.
+func (s *Service) RecordTOIL(ctx context.Context, tenantID string, entry TOILEntry) (RecordTOILResult, error) {
+	var result RecordTOILResult
+	if entry.Hours <= 0 || entry.Hours > maxTOILHours {
+		return result, ErrInvalidHours
+	}
+	leaveType, err := s.Store.GetType(ctx, tenantID, entry.LeaveTypeID)
+	if err != nil {
+		return result, err
+	}
+	if !leaveType.TOIL {
+		return result, ErrNotTOILType
+	}
+	entry.Hours = roundHours(entry.Hours)
+	entry.Status = TOILPending
+	if result.ID, err = s.Store.CreateTOILEntry(ctx, tenantID, entry); err != nil {
+		return result, err
+	}
+
+	if managerUserID, err := s.Store.ManagerUserIDForEmployee(ctx, tenantID, entry.EmployeeID); err == nil {
+		result.ManagerUserID = managerUserID
+	}
+	if result.ManagerUserID == "" {
+		if hrUserIDs, err := s.Store.HRUserIDs(ctx, tenantID); err == nil {
+			result.HRUserIDs = hrUserIDs
+		}
+	}
+	return result, nil
+}
+
+// ApproveTOIL approves a pending entry, credits its hours to the employee's
+// balance and starts its expiry period. Managers decide entries of their
+// reports, directly or through a delegation; HR decides any entry, and alone
+// decides entries of employees without a manager.
+func (s *Service) ApproveTOIL(ctx context.Context, tenantID, entryID, approverUserID, roleName string) (TOILDecision, error) {
+	decision, err := s.checkTOILDecision(ctx, tenantID, entryID, approverUserID, roleName)
+	if err != nil {
+		return decision, err
+	}
+	leaveType, err := s.Store.GetType(ctx, tenantID, decision.Entry.LeaveTypeID)
+	if err != nil {
+		return decision, err
+	}
+	expires := toilExpiry(decision.Entry.WorkDate, leaveType.TOILExpiryDays)
+	if err := s.Store.ApproveTOILEntry(ctx, tenantID, entryID, approverUserID, expires); err != nil {
+		return decision, err
+	}
+	decision.Entry.Status = TOILApproved
+	decision.Entry.Remaining = decision.Entry.Hours
+	decision.Entry.ExpiresOn = expires
+	decision.Entry.DecidedBy = approverUserID
+	decision.LeaveTypeName = leaveType.Name
+	return decision, nil
+}
+
+// RejectTOIL rejects a pending entry without crediting the balance.
+func (s *Service) RejectTOIL(ctx context.Context, tenantID, entryID, approverUserID, roleName string) (TOILDecision, error) {
+	decision, err := s.checkTOILDecision(ctx, tenantID, entryID, approverUserID, roleName)
+	if err != nil {
+		return decision, err
+	}
+	if err := s.Store.RejectTOILEntry(ctx, tenantID, entryID, approverUserID); err != nil {
+		return decision, err
+	}
+	decision.Entry.Status = TOILRejected
+	decision.Entry.DecidedBy = approverUserID
+	return decision, nil
+}
+
+// checkTOILDecision loads a pending entry and checks the user may decide it.
+func (s *Service) checkTOILDecision(ctx context.Context, tenantID, entryID, approverUserID, roleName string) (TOILDecision, error) {
+	var decision TOILDecision
+	entry, err := s.Store.GetTOILEntry(ctx, tenantID, entryID)
+	if err != nil {
+		return decision, err
+	}
+	decision.Entry = entry
+	if entry.Status != TOILPending {
+		return decision, ErrInvalidState
+	}
+	switch roleName {
+	case auth.RoleHR:
+	case auth.RoleManager:
+		if managerUserID, err := s.Store.ManagerUserIDForEmployee(ctx, tenantID, entry.EmployeeID); err != nil || managerUserID == "" {
+			return decision, ErrHRApprovalRequired
+		}
+		onBehalfOf, err := s.checkManagerApprover(ctx, tenantID, entry.EmployeeID, approverUserID)
+		if err != nil {
+			return decision, err
+		}
+		decision.OnBehalfOf = onBehalfOf
+	default:
+		return decision, ErrForbidden
+	}
+	if employeeUser, leaveTypeName, err := s.Store.EmployeeUserAndLeaveType(ctx, tenantID, entry.LeaveTypeID, entry.EmployeeID); err == nil {
+		decision.EmployeeUser = employeeUser
+		decision.LeaveTypeName = leaveTypeName
+	}
+	return decision, nil
+}
+
+// ExpireTOIL expires approved entries whose expiry date has passed and
+// removes their unused hours from the balances. It returns the number of
+// entries expired and the hours forfeited.
+func (s *Service) ExpireTOIL(ctx context.Context, tenantID string, now time.Time) (int, float64, error) {
+	return s.Store.ExpireTOIL(ctx, tenantID, now.UTC())
+}
@@ -0,0 +1,143 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hrm/internal/domain/auth"
+)
+
+func TestCalculateRequestHours(t *testing.T) {
+	pattern := StandardWorkPattern()
+	pattern.HoursPerDay = 7.5
+	schedule := WorkSchedule{Pattern: pattern}
+	monday := utcDate(2026, time.March, 2)
+	wednesday := utcDate(2026, time.March, 4)
+
+	days, hours, err := CalculateRequestHours(monday, wednesday, false, true, 0, schedule)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if days != 2.5 || hours != 18.75 {
+		t.Fatalf("expected 2.5 days and 18.75 hours, got %v and %v", days, hours)
+	}
+
+	days, hours, err = CalculateRequestHours(monday, monday, false, false, 3, schedule)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if days != 0.4 || hours != 3 {
+		t.Fatalf("expected 0.4 days and 3 hours, got %v and %v", days, hours)
+	}
+
+	invalid := []struct {
+		end       time.Time
+		startHalf bool
+		hours     float64
+	}{
+		{wednesday, false, 3},
+		{monday, true, 3},
+		{monday, false, 8},
+		{monday, false, -1},
+	}
+	for _, tc := range invalid {
+		if _, _, err := CalculateRequestHours(monday, tc.end, tc.startHalf, false, tc.hours, schedule); !errors.Is(err, ErrInvalidHours) {
+			t.Fatalf("expected invalid hours for %+v, got %v", tc, err)
+		}
+	}
+}
+
+type toilStore struct {
+	StoreAPI
+	entry     TOILEntry
+	manager   string
+	expiresOn *time.Time
+	approved  bool
+	created   []TOILEntry
+}
+
+func (s *toilStore) GetType(_ context.Context, _, leaveTypeID string) (LeaveType, error) {
+	switch leaveTypeID {
+	case "type-toil":
+		return LeaveType{ID: leaveTypeID, Name: "TOIL", Unit: UnitHours, TOIL: true, TOILExpiryDays: 90}, nil
+	case "type-annual":
+		return LeaveType{ID: leaveTypeID, Name: "Annual", Unit: UnitDays}, nil
+	}
+	return LeaveType{}, ErrLeaveTypeNotFound
+}
+
+func (s *toilStore) GetTOILEntry(context.Context, string, string) (TOILEntry, error) {
+	return s.entry, nil
+}
+
+func (s *toilStore) CreateTOILEntry(_ context.Context, _ string, entry TOILEntry) (string, error) {
+	s.created = append(s.created, entry)
+	return "toil-2", nil
+}
+
+func (s *toilStore) ApproveTOILEntry(_ context.Context, _, _, _ string, expiresOn *time.Time) error {
+	s.approved = true
+	s.expiresOn = expiresOn
+	return nil
+}
+
+func (s *toilStore) ManagerUserIDForEmployee(context.Context, string, string) (string, error) {
+	return s.manager, nil
+}
+
+func (s *toilStore) HRUserIDs(context.Context, string) ([]string, error) {
+	return []string{"hr-1"}, nil
+}
+
+func (s *toilStore) EmployeeUserAndLeaveType(context.Context, string, string, string) (string, string, error) {
+	return "user-1", "TOIL", nil
+}
+
+func TestApproveTOILCreditsWithExpiry(t *testing.T) {
+	store := &toilStore{entry: TOILEntry{ID: "toil-1", EmployeeID: "emp-1", LeaveTypeID: "type-toil", WorkDate: utcDate(2026, time.March, 7), Hours: 4, Status: TOILPending}}
+	service := &Service{Store: store}
+
+	if _, err := service.ApproveTOIL(context.Background(), "t1", "toil-1", "manager-1", auth.RoleManager); !errors.Is(err, ErrHRApprovalRequired) {
+		t.Fatalf("expected hr approval for an employee without a manager, got %v", err)
+	}
+
+	decision, err := service.ApproveTOIL(context.Background(), "t1", "toil-1", "hr-1", auth.RoleHR)
+	if err != nil {
+		t.Fatalf("approve toil: %v", err)
+	}
+	if !store.approved || store.expiresOn == nil || store.expiresOn.Format("2006-01-02") != "2026-06-05" {
+		t.Fatalf("expected approval expiring 90 days after the work date, got %v", store.expiresOn)
+	}
+	if decision.Entry.Remaining != 4 || decision.EmployeeUser != "user-1" {
+		t.Fatalf("unexpected decision %+v", decision)
+	}
+
+	store.entry.Status = TOILApproved
+	if _, err := service.RejectTOIL(context.Background(), "t1", "toil-1", "hr-1", auth.RoleHR); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected decided entry to be rejected as invalid state, got %v", err)
+	}
+}
+
+func TestRecordTOILRequiresTOILType(t *testing.T) {
+	store := &toilStore{}
+	service := &Service{Store: store}
+	entry := TOILEntry{EmployeeID: "emp-1", LeaveTypeID: "type-annual", WorkDate: utcDate(2026, time.March, 7), Hours: 2.5}
+
+	if _, err := service.RecordTOIL(context.Background(), "t1", entry); !errors.Is(err, ErrNotTOILType) {
+		t.Fatalf("expected non-toil leave type to be refused, got %v", err)
+	}
+
+	entry.LeaveTypeID = "type-toil"
+	result, err := service.RecordTOIL(context.Background(), "t1", entry)
+	if err != nil {
+		t.Fatalf("record toil: %v", err)
+	}
+	if len(store.created) != 1 || store.created[0].Status != TOILPending {
+		t.Fatalf("expected one pending entry, got %+v", store.created)
+	}
+	if result.ManagerUserID != "" || len(result.HRUserIDs) != 1 {
+		t.Fatalf("expected hr to decide an entry without a manager, got %+v", result)
+	}
+}
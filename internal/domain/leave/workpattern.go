@@ -0,0 +1,180 @@
+package leave
+
+import (
+	"context"
+	"time"
+)
+
+// maxWorkPatternDays bounds the length of a work pattern cycle; eight weeks
+// covers the shift rotas in common use.
+const maxWorkPatternDays = 56
+
+// StandardHoursPerDay is the length of a full working day for patterns that
+// do not set one.
+const StandardHoursPerDay = 8.0
+
+// WorkPattern describes the days an employee is scheduled to work as a cycle
+// that repeats from CycleStart. Days holds the share of a full working day
+// scheduled on each day of the cycle: 1 for a full day, 0.5 for a half day
+// and 0 for a rest day. A seven-day cycle starting on a Monday is an ordinary
+// week; longer or shorter cycles describe part-time fortnights and shift
+// rotas. HoursPerDay is the length of a full working day, used to count
+// hourly leave.
+type WorkPattern struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	CycleStart  time.Time `json:"cycleStart"`
+	Days        []float64 `json:"days"`
+	HoursPerDay float64   `json:"hoursPerDay"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// StandardWorkPattern is the Monday to Friday week used for employees with
+// no pattern assigned directly or through their department.
+func StandardWorkPattern() WorkPattern {
+	return WorkPattern{
+		Name:        "Monday to Friday",
+		CycleStart:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Days:        []float64{1, 1, 1, 1, 1, 0, 0},
+		HoursPerDay: StandardHoursPerDay,
+	}
+}
+
+// Valid reports whether the pattern has a usable cycle with at least one
+// working day and a working day of at most 24 hours. Zero hours per day
+// stands for StandardHoursPerDay.
+func (p WorkPattern) Valid() bool {
+	if len(p.Days) == 0 || len(p.Days) > maxWorkPatternDays {
+		return false
+	}
+	if p.HoursPerDay < 0 || p.HoursPerDay > 24 {
+		return false
+	}
+	working := false
+	for _, fraction := range p.Days {
+		if fraction < 0 || fraction > 1 {
+			return false
+		}
+		if fraction > 0 {
+			working = true
+		}
+	}
+	return working
+}
+
+// DayFraction returns the share of a working day the pattern schedules on
+// date. A pattern without days treats every day as a full working day.
+func (p WorkPattern) DayFraction(date time.Time) float64 {
+	if len(p.Days) == 0 {
+		return 1
+	}
+	offset := int(dateOnly(date).Sub(dateOnly(p.CycleStart)).Hours() / 24)
+	index := offset % len(p.Days)
+	if index < 0 {
+		index += len(p.Days)
+	}
+	return p.Days[index]
+}
+
+// WorkSchedule combines an employee's work pattern with the holidays that
+// apply to them: those without a region and those for the employee's
+// holiday region. The zero value counts every calendar day.
+type WorkSchedule struct {
+	Pattern  WorkPattern     `json:"pattern"`
+	Region   string          `json:"region,omitempty"`
+	Holidays map[string]bool `json:"-"`
+}
+
+// WorkingFraction returns the share of a working day scheduled on date, or
+// zero on a holiday.
+func (s WorkSchedule) WorkingFraction(date time.Time) float64 {
+	if s.Holidays[date.Format("2006-01-02")] {
+		return 0
+	}
+	return s.Pattern.DayFraction(date)
+}
+
+// WorkingHours returns the hours scheduled on date, or zero on a holiday.
+func (s WorkSchedule) WorkingHours(date time.Time) float64 {
+	return s.WorkingFraction(date) * s.HoursPerDay()
+}
+
+// HoursPerDay returns the length of a full working day in the schedule.
+func (s WorkSchedule) HoursPerDay() float64 {
+	if s.Pattern.HoursPerDay <= 0 {
+		return StandardHoursPerDay
+	}
+	return s.Pattern.HoursPerDay
+}
+
+func dateOnly(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (s *Service) ListWorkPatterns(ctx context.Context, tenantID string) ([]WorkPattern, error) {
+	return s.Store.ListWorkPatterns(ctx, tenantID)
+}
+
+func (s *Service) GetWorkPattern(ctx context.Context, tenantID, patternID string) (WorkPattern, error) {
+	return s.Store.GetWorkPattern(ctx, tenantID, patternID)
+}
+
+func (s *Service) CreateWorkPattern(ctx context.Context, tenantID string, pattern WorkPattern) (string, error) {
+	return s.Store.CreateWorkPattern(ctx, tenantID, pattern)
+}
+
+func (s *Service) UpdateWorkPattern(ctx context.Context, tenantID string, pattern WorkPattern) error {
+	return s.Store.UpdateWorkPattern(ctx, tenantID, pattern)
+}
+
+// AssignEmployeeSchedule sets an employee's own work pattern and holiday
+// region. Empty values fall back to the employee's department and then to
+// the standard pattern and region-less holidays.
+func (s *Service) AssignEmployeeSchedule(ctx context.Context, tenantID, employeeID, patternID, region string) error {
+	if err := s.checkWorkPattern(ctx, tenantID, patternID); err != nil {
+		return err
+	}
+	return s.Store.AssignEmployeeSchedule(ctx, tenantID, employeeID, patternID, region)
+}
+
+// AssignDepartmentSchedule sets the work pattern and holiday region used by
+// a department's employees who have none of their own.
+func (s *Service) AssignDepartmentSchedule(ctx context.Context, tenantID, departmentID, patternID, region string) error {
+	if err := s.checkWorkPattern(ctx, tenantID, patternID); err != nil {
+		return err
+	}
+	return s.Store.AssignDepartmentSchedule(ctx, tenantID, departmentID, patternID, region)
+}
+
+func (s *Service) checkWorkPattern(ctx context.Context, tenantID, patternID string) error {
+	if patternID == "" {
+		return nil
+	}
+	_, err := s.Store.GetWorkPattern(ctx, tenantID, patternID)
+	return err
+}
+
+// WorkSchedule resolves the work pattern, holiday region and holidays that
+// apply to an employee between from and to.
+func (s *Service) WorkSchedule(ctx context.Context, tenantID, employeeID string, from, to time.Time) (WorkSchedule, error) {
+	return s.Store.WorkSchedule(ctx, tenantID, employeeID, from, to)
+}
+
+// RequestDays returns the working days a request for employeeID would use.
+func (s *Service) RequestDays(ctx context.Context, tenantID, employeeID string, start, end time.Time, startHalf, endHalf bool) (float64, error) {
+	schedule, err := s.Store.WorkSchedule(ctx, tenantID, employeeID, start, end)
+	if err != nil {
+		return 0, err
+	}
+	return CalculateRequestDays(start, end, startHalf, endHalf, schedule)
+}
+
+// RequestHours returns the working days and hours a request for employeeID
+// against an hourly leave type would use. See CalculateRequestHours.
+func (s *Service) RequestHours(ctx context.Context, tenantID, employeeID string, start, end time.Time, startHalf, endHalf bool, hours float64) (float64, float64, error) {
+	schedule, err := s.Store.WorkSchedule(ctx, tenantID, employeeID, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+	return CalculateRequestHours(start, end, startHalf, endHalf, hours, schedule)
+}
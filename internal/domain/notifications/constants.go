@@ -5,7 +5,12 @@ const (
 	TypeLeaveApproved    = "leave_approved"
 	TypeLeaveRejected    = "leave_rejected"
 	TypeLeaveCancelled   = "leave_cancelled"
+	TypeLeaveReminder    = "leave_approval_reminder"
+	TypeLeaveCarryOver   = "leave_carry_over_expiry"
 	TypePayslipPublished = "payslip_published"
+	TypePayrollApproval  = "payroll_approval_requested"
+	TypePayrollApproved  = "payroll_approved"
+	TypePayrollRejected  = "payroll_rejected"
 	TypeGoalCreated      = "goal_created"
 	TypeReviewAssigned   = "review_assigned"
 	TypeFeedbackReceived = "feedback_received"
@@ -0,0 +1,157 @@
+package payroll
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Accumulator is a running tax-year total for one employee in one pay
+// currency. Totals use line_type "total" with codes gross, taxable_gross,
+// deductions, net and employer_cost; every other row mirrors a result line
+// code.
+type Accumulator struct {
+	TaxYear     int       `json:"taxYear"`
+	Currency    string    `json:"currency"`
+	LineType    string    `json:"lineType"`
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+	Periods     int       `json:"periods"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type AnnualStatement struct {
+	ID          string    `json:"id"`
+	EmployeeID  string    `json:"employeeId"`
+	TaxYear     int       `json:"taxYear"`
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+type AnnualStatementData struct {
+	FirstName    string
+	LastName     string
+	Email        string
+	TaxYear      int
+	Accumulators []Accumulator
+}
+
+// AnnualStatementResult reports how many statements were generated for a tax
+// year and which employees failed.
+type AnnualStatementResult struct {
+	TaxYear   int               `json:"taxYear"`
+	Generated int               `json:"generated"`
+	Failed    map[string]string `json:"failed,omitempty"`
+}
+
+func (s *Service) ReopenPeriod(ctx context.Context, tenantID, periodID string) error {
+	return s.store.ReopenPeriod(ctx, tenantID, periodID)
+}
+
+func (s *Service) ListAccumulators(ctx context.Context, tenantID, employeeID string, taxYear int) ([]Accumulator, error) {
+	return s.store.ListAccumulators(ctx, tenantID, employeeID, taxYear)
+}
+
+func (s *Service) ListAnnualStatements(ctx context.Context, tenantID, employeeID string) ([]AnnualStatement, error) {
+	return s.store.ListAnnualStatements(ctx, tenantID, employeeID)
+}
+
+func (s *Service) AnnualStatementFile(ctx context.Context, tenantID, statementID string) (string, string, error) {
+	return s.store.AnnualStatementFile(ctx, tenantID, statementID)
+}
+
+// GenerateAnnualStatements renders and stores a statement for every employee
+// with finalized payroll in the tax year, replacing earlier versions.
+func (s *Service) GenerateAnnualStatements(ctx context.Context, tenantID string, taxYear int) (AnnualStatementResult, error) {
+	result := AnnualStatementResult{TaxYear: taxYear}
+	employeeIDs, err := s.store.AccumulatorEmployees(ctx, tenantID, taxYear)
+	if err != nil {
+		return result, err
+	}
+	template, err := s.payslipTemplate(ctx, tenantID, "")
+	if err != nil {
+		return result, err
+	}
+
+	for _, employeeID := range employeeIDs {
+		if err := s.generateAnnualStatement(ctx, tenantID, employeeID, taxYear, template); err != nil {
+			slog.Warn("annual statement generation failed", "employeeId", employeeID, "taxYear", taxYear, "err", err)
+			if result.Failed == nil {
+				result.Failed = map[string]string{}
+			}
+			result.Failed[employeeID] = err.Error()
+			continue
+		}
+		result.Generated++
+	}
+	return result, nil
+}
+
+func (s *Service) generateAnnualStatement(ctx context.Context, tenantID, employeeID string, taxYear int, template PayslipTemplate) error {
+	data, err := s.store.AnnualStatementData(ctx, tenantID, employeeID, taxYear)
+	if err != nil {
+		return err
+	}
+	content, err := RenderAnnualStatement(data, template)
+	if err != nil {
+		return err
+	}
+	fileURL, err := s.writePDF("storage/annual-statements", fmt.Sprintf("%s-%d", employeeID, taxYear), content)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.UpsertAnnualStatement(ctx, tenantID, employeeID, taxYear, fileURL)
+	return err
+}
+
+// RenderAnnualStatement lays out the year's totals followed by each earning,
+// deduction and employer contribution accumulated over the tax year.
+func RenderAnnualStatement(data AnnualStatementData, template PayslipTemplate) ([]byte, error) {
+	pdf := newBrandedPDF(template)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.Cell(0, 10, fmt.Sprintf("Annual earnings statement %d", data.TaxYear))
+	pdf.Ln(12)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.Cell(0, 8, fmt.Sprintf("Employee: %s %s", data.FirstName, data.LastName))
+	pdf.Ln(7)
+	pdf.Cell(0, 8, fmt.Sprintf("Email: %s", data.Email))
+	pdf.Ln(10)
+
+	sections := []struct {
+		title    string
+		lineType string
+	}{
+		{"Earnings", ResultLineEarning},
+		{"Deductions", ResultLineDeduction},
+		{"Employer contributions", ResultLineEmployerContribution},
+		{"Totals", AccumulatorTotal},
+	}
+	for _, section := range sections {
+		var rows []Accumulator
+		for _, acc := range data.Accumulators {
+			if acc.LineType == section.lineType && acc.Amount != 0 {
+				rows = append(rows, acc)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.Cell(0, 8, section.title)
+		pdf.Ln(7)
+		pdf.SetFont("Helvetica", "", 11)
+		for _, acc := range rows {
+			pdf.CellFormat(120, 7, acc.Description, "", 0, "L", false, 0, "")
+			pdf.CellFormat(0, 7, fmt.Sprintf("%.2f %s", acc.Amount, acc.Currency), "", 1, "R", false, 0, "")
+		}
+		pdf.Ln(3)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
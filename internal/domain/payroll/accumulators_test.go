@@ -0,0 +1,27 @@
+package payroll
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRenderAnnualStatement(t *testing.T) {
+	data := AnnualStatementData{
+		FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", TaxYear: 2026,
+		Accumulators: []Accumulator{
+			{Currency: "EUR", LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 36000, Periods: 12},
+			{Currency: "EUR", LineType: ResultLineDeduction, Code: "income_tax", Description: "Income tax", Amount: 7200, Periods: 12},
+			{Currency: "EUR", LineType: ResultLineEmployerContribution, Code: "pension", Description: "Pension", Amount: 1800, Periods: 12},
+			{Currency: "EUR", LineType: AccumulatorTotal, Code: "gross", Description: "Gross pay", Amount: 36000, Periods: 12},
+			{Currency: "EUR", LineType: AccumulatorTotal, Code: "employer_cost", Description: "Employer cost", Amount: 37800, Periods: 12},
+		},
+	}
+
+	content, err := RenderAnnualStatement(data, PayslipTemplate{CompanyName: "Acme GmbH", Footer: "Confidential"})
+	if err != nil {
+		t.Fatalf("render annual statement: %v", err)
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF")) {
+		t.Fatal("expected PDF output")
+	}
+}
@@ -0,0 +1,86 @@
+package payroll
+
+import (
+	"context"
+
+	"hrm/internal/domain/auth"
+)
+
+// PeriodApproval is the maker-checker state of a period: who ran, resumed
+// and submitted it and the approver's decision.
+type PeriodApproval struct {
+	PeriodID    string `json:"periodId"`
+	Status      string `json:"status"`
+	RunBy       string `json:"runBy,omitempty"`
+	ResumedBy   string `json:"resumedBy,omitempty"`
+	SubmittedBy string `json:"submittedBy,omitempty"`
+	DecidedBy   string `json:"decidedBy,omitempty"`
+	Decision    string `json:"decision,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// MadeBy reports whether userID ran, resumed or submitted the period.
+func (a PeriodApproval) MadeBy(userID string) bool {
+	return userID != "" && (userID == a.RunBy || userID == a.ResumedBy || userID == a.SubmittedBy)
+}
+
+// CheckApprover enforces segregation of duties: only a period awaiting
+// approval can be decided, and never by the user who ran, resumed or
+// submitted it, either directly or through a delegate deciding on their
+// behalf.
+func CheckApprover(approval PeriodApproval, userID, onBehalfOf string) error {
+	if approval.Status != PeriodStatusPendingApproval {
+		return ErrApprovalInvalidState
+	}
+	for _, approver := range []string{userID, onBehalfOf} {
+		if approval.MadeBy(approver) {
+			return ErrApprovalSameUser
+		}
+	}
+	return nil
+}
+
+// MarkPeriodRun moves the period back to draft for a new run by userID and
+// clears any earlier submission or decision. Resuming a failed run keeps the
+// user who started it and records userID as the one who resumed it. A
+// finalized period, or one that does not exist, is left alone and
+// ErrRunFinalized returned.
+func (s *Service) MarkPeriodRun(ctx context.Context, tenantID, periodID, userID string, resumed bool) error {
+	return s.store.MarkPeriodRun(ctx, tenantID, periodID, userID, resumed)
+}
+
+// SubmitPeriod sends a reviewed period for approval.
+func (s *Service) SubmitPeriod(ctx context.Context, tenantID, periodID, userID string) (PeriodApproval, error) {
+	return s.store.SubmitPeriod(ctx, tenantID, periodID, userID)
+}
+
+// DecidePeriod approves a period awaiting approval, allowing it to be
+// finalized, or rejects it back to draft so it must be run again. onBehalfOf
+// is the approver a delegate decides for, or "".
+func (s *Service) DecidePeriod(ctx context.Context, tenantID, periodID, userID, onBehalfOf string, approve bool, comment string) (PeriodApproval, error) {
+	decision := ApprovalDecisionRejected
+	if approve {
+		decision = ApprovalDecisionApproved
+	}
+	return s.store.DecidePeriod(ctx, tenantID, periodID, userID, onBehalfOf, decision, comment)
+}
+
+// ApproverUserIDs lists the active users allowed to approve payroll: holders
+// of payroll.finalize or of the system admin permission.
+func (s *Service) ApproverUserIDs(ctx context.Context, tenantID string) ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	for _, permission := range []string{auth.PermPayrollFinalize, auth.PermSystemAdmin} {
+		ids, err := s.store.UserIDsWithPermission(ctx, tenantID, permission)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				out = append(out, id)
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,31 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckApproverRequiresSecondUser(t *testing.T) {
+	approval := PeriodApproval{Status: PeriodStatusPendingApproval, RunBy: "maker", ResumedBy: "resumer", SubmittedBy: "submitter"}
+
+	if err := CheckApprover(approval, "maker", ""); !errors.Is(err, ErrApprovalSameUser) {
+		t.Fatalf("expected the user who ran payroll to be refused, got %v", err)
+	}
+	if err := CheckApprover(approval, "resumer", ""); !errors.Is(err, ErrApprovalSameUser) {
+		t.Fatalf("expected the user who resumed the run to be refused, got %v", err)
+	}
+	if err := CheckApprover(approval, "submitter", ""); !errors.Is(err, ErrApprovalSameUser) {
+		t.Fatalf("expected the submitter to be refused, got %v", err)
+	}
+	if err := CheckApprover(approval, "checker", ""); err != nil {
+		t.Fatalf("expected another user to be allowed, got %v", err)
+	}
+	if err := CheckApprover(approval, "accomplice", "maker"); !errors.Is(err, ErrApprovalSameUser) {
+		t.Fatalf("expected a delegate of the user who ran payroll to be refused, got %v", err)
+	}
+
+	approval.Status = PeriodStatusReviewed
+	if err := CheckApprover(approval, "checker", ""); !errors.Is(err, ErrApprovalInvalidState) {
+		t.Fatalf("expected periods not awaiting approval to be refused, got %v", err)
+	}
+}
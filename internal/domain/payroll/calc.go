@@ -1,8 +1,22 @@
 package payroll
 
 type InputLine struct {
-	Type   string
-	Amount float64
+	Type        string
+	Amount      float64
+	Taxable     bool
+	Source      string
+	SourceID    string
+	Code        string
+	Description string
+}
+
+type Calculation struct {
+	Gross        float64
+	Deductions   float64
+	Net          float64
+	TaxableGross float64
+	Taxes        []TaxLine
+	Lines        []ResultLine
 }
 
 func ComputePayroll(baseSalary float64, inputs []InputLine) (gross, deductions, net float64) {
@@ -18,3 +32,58 @@ func ComputePayroll(baseSalary float64, inputs []InputLine) (gross, deductions,
 	net = gross - deductions
 	return gross, deductions, net
 }
+
+// Calculate applies tax rules to the employee's inputs and itemises every
+// amount that contributed to gross, deductions and employer cost.
+func Calculate(baseSalary float64, inputs []InputLine, taxRules []TaxRule) Calculation {
+	calc := Calculation{TaxableGross: TaxableGross(baseSalary, inputs)}
+	calc.Taxes = ComputeTaxes(taxRules, calc.TaxableGross)
+
+	all := make([]InputLine, 0, len(inputs)+len(calc.Taxes))
+	all = append(all, inputs...)
+	for _, tax := range calc.Taxes {
+		if tax.Employee > 0 {
+			all = append(all, InputLine{
+				Type:        ElementTypeDeduction,
+				Amount:      tax.Employee,
+				Source:      ResultSourceTax,
+				SourceID:    tax.RuleID,
+				Code:        tax.Code,
+				Description: tax.Name,
+			})
+		}
+	}
+	calc.Gross, calc.Deductions, calc.Net = ComputePayroll(baseSalary, all)
+
+	calc.Lines = ResultLines(baseSalary, inputs)
+	calc.Lines = append(calc.Lines, TaxResultLines(calc.Taxes)...)
+	return calc
+}
+
+// ResultLines itemises the base salary and each earning or deduction input.
+func ResultLines(baseSalary float64, inputs []InputLine) []ResultLine {
+	lines := make([]ResultLine, 0, len(inputs)+1)
+	if baseSalary != 0 {
+		lines = append(lines, ResultLine{
+			LineType:    ResultLineEarning,
+			Source:      ResultSourceSalary,
+			Code:        ResultCodeBaseSalary,
+			Description: "Base salary",
+			Amount:      roundCents(baseSalary),
+		})
+	}
+	for _, input := range inputs {
+		if input.Type != ElementTypeEarning && input.Type != ElementTypeDeduction {
+			continue
+		}
+		lines = append(lines, ResultLine{
+			LineType:    input.Type,
+			Source:      input.Source,
+			SourceID:    input.SourceID,
+			Code:        input.Code,
+			Description: input.Description,
+			Amount:      roundCents(input.Amount),
+		})
+	}
+	return lines
+}
@@ -37,3 +37,49 @@ func TestComputePayrollIgnoresUnknownTypes(t *testing.T) {
 		t.Fatalf("expected net 475, got %v", net)
 	}
 }
+
+func TestCalculateItemisesInputsAndTaxes(t *testing.T) {
+	inputs := []InputLine{
+		{Type: ElementTypeEarning, Amount: 200, Taxable: true, Source: ResultSourceElement, SourceID: "e1", Code: "overtime", Description: "Overtime"},
+		{Type: ElementTypeDeduction, Amount: 50, Source: ResultSourceElement, SourceID: "e2", Code: "union", Description: "Union dues"},
+	}
+	rules := []TaxRule{{ID: "r1", Code: "paye", Name: "Income tax", Kind: TaxKindIncomeTax, EmployeeRate: 0.1, EmployerRate: 0.05}}
+
+	calc := Calculate(1000, inputs, rules)
+	if calc.Gross != 1200 || calc.Deductions != 170 || calc.Net != 1030 {
+		t.Fatalf("expected 1200/170/1030, got %v/%v/%v", calc.Gross, calc.Deductions, calc.Net)
+	}
+	if len(calc.Lines) != 5 {
+		t.Fatalf("expected 5 result lines, got %d", len(calc.Lines))
+	}
+	if calc.Lines[0].Code != ResultCodeBaseSalary || calc.Lines[0].Amount != 1000 {
+		t.Fatalf("expected base salary line first, got %+v", calc.Lines[0])
+	}
+	if calc.Lines[3].Code != "paye" || calc.Lines[3].LineType != ResultLineDeduction || calc.Lines[3].Amount != 120 {
+		t.Fatalf("expected income tax deduction 120, got %+v", calc.Lines[3])
+	}
+	if calc.Lines[4].LineType != ResultLineEmployerContribution || calc.Lines[4].Amount != 60 {
+		t.Fatalf("expected employer contribution 60, got %+v", calc.Lines[4])
+	}
+}
+
+func TestCalculateLinesReconcileToTotals(t *testing.T) {
+	inputs := []InputLine{
+		{Type: ElementTypeEarning, Amount: 333.33, Code: "bonus"},
+		{Type: ElementTypeDeduction, Amount: 12.5, Code: "parking"},
+	}
+	calc := Calculate(2500, inputs, nil)
+
+	var earnings, deductions float64
+	for _, line := range calc.Lines {
+		switch line.LineType {
+		case ResultLineEarning:
+			earnings += line.Amount
+		case ResultLineDeduction:
+			deductions += line.Amount
+		}
+	}
+	if roundCents(earnings) != calc.Gross || roundCents(deductions) != calc.Deductions {
+		t.Fatalf("expected lines to sum to %v/%v, got %v/%v", calc.Gross, calc.Deductions, earnings, deductions)
+	}
+}
@@ -0,0 +1,280 @@
+package payroll
+
+import (
+	"context"
+	"time"
+)
+
+// CalendarPeriod is a pay period laid out from a schedule. PeriodID and Status
+// are set when the period already exists; ScheduledPayDate is the pay date
+// before it was moved off weekends and holidays.
+type CalendarPeriod struct {
+	ScheduleID       string    `json:"scheduleId"`
+	PeriodID         string    `json:"periodId,omitempty"`
+	Status           string    `json:"status,omitempty"`
+	StartDate        time.Time `json:"startDate"`
+	EndDate          time.Time `json:"endDate"`
+	PayDate          time.Time `json:"payDate"`
+	ScheduledPayDate time.Time `json:"scheduledPayDate"`
+}
+
+type CalendarSummary struct {
+	SchedulesProcessed int `json:"schedulesProcessed"`
+	PeriodsCreated     int `json:"periodsCreated"`
+}
+
+// GenerateCalendars creates the regular periods of every schedule in the
+// tenant so that periods exist for the year after now. Generation continues
+// from the end of each schedule's latest regular period, or from the period
+// containing now when that ended earlier, and never replaces or overlaps
+// existing periods.
+func (s *Service) GenerateCalendars(ctx context.Context, tenantID string, now time.Time) (CalendarSummary, error) {
+	var summary CalendarSummary
+	schedules, err := s.store.ListSchedules(ctx, tenantID)
+	if err != nil {
+		return summary, err
+	}
+	holidaysByRegion := map[string]map[string]bool{}
+	for _, schedule := range schedules {
+		if !ValidFrequency(schedule.Frequency) {
+			continue
+		}
+		holidays, ok := holidaysByRegion[schedule.HolidayRegion]
+		if !ok {
+			holidays, err = s.calendarHolidays(ctx, tenantID, schedule.HolidayRegion, now, now.AddDate(1, 0, 0))
+			if err != nil {
+				return summary, err
+			}
+			holidaysByRegion[schedule.HolidayRegion] = holidays
+		}
+		periods, err := s.projectSchedule(ctx, tenantID, schedule, now, now.AddDate(1, 0, 0), holidays)
+		if err != nil {
+			return summary, err
+		}
+		created, err := s.store.CreateCalendarPeriods(ctx, tenantID, schedule.ID, periods)
+		if err != nil {
+			return summary, err
+		}
+		summary.SchedulesProcessed++
+		summary.PeriodsCreated += created
+	}
+	return summary, nil
+}
+
+// ProjectCalendar returns the schedule's regular periods between from and to:
+// the periods that already exist followed by the ones the generator would
+// create next.
+func (s *Service) ProjectCalendar(ctx context.Context, tenantID, scheduleID string, from, to time.Time) ([]CalendarPeriod, error) {
+	schedule, err := s.store.GetSchedule(ctx, tenantID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if !ValidFrequency(schedule.Frequency) {
+		return nil, ErrScheduleFrequency
+	}
+
+	holidays, err := s.calendarHolidays(ctx, tenantID, schedule.HolidayRegion, from, to)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.store.ListScheduleCalendar(ctx, tenantID, scheduleID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]CalendarPeriod, 0, len(existing))
+	for _, period := range existing {
+		period.ScheduledPayDate = ScheduledPayDate(schedule, period.EndDate)
+		if period.PayDate.IsZero() {
+			period.PayDate = ShiftToWorkingDay(period.ScheduledPayDate, holidays)
+		}
+		out = append(out, period)
+	}
+
+	projected, err := s.projectSchedule(ctx, tenantID, schedule, time.Now().UTC(), to, holidays)
+	if err != nil {
+		return nil, err
+	}
+	for _, period := range projected {
+		if !period.EndDate.Before(from) {
+			out = append(out, period)
+		}
+	}
+	return out, nil
+}
+
+// projectSchedule lays out the periods that follow the schedule's latest
+// regular period, or that start with the period containing now when the
+// schedule has none, until a period would begin after until. Periods that
+// ended before now are skipped rather than backfilled.
+func (s *Service) projectSchedule(ctx context.Context, tenantID string, schedule Schedule, now, until time.Time, holidays map[string]bool) ([]CalendarPeriod, error) {
+	start := FirstPeriodStart(schedule.Frequency, now)
+	lastEnd, err := s.store.LastRegularPeriodEnd(ctx, tenantID, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if lastEnd != nil {
+		start = CurrentPeriodStart(schedule.Frequency, lastEnd.AddDate(0, 0, 1), now)
+	}
+	return ProjectPeriods(schedule, start, until, holidays), nil
+}
+
+// calendarHolidays loads the holidays of a region, and those without one,
+// around a calendar range. Pay dates can fall up to a month after the period
+// they belong to, and periods may continue from before the range, so the
+// lookup is widened on both sides.
+func (s *Service) calendarHolidays(ctx context.Context, tenantID, region string, from, to time.Time) (map[string]bool, error) {
+	dates, err := s.store.ListHolidayDates(ctx, tenantID, region, from.AddDate(-1, 0, 0), to.AddDate(0, 2, 0))
+	if err != nil {
+		return nil, err
+	}
+	holidays := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		holidays[date.Format("2006-01-02")] = true
+	}
+	return holidays, nil
+}
+
+// ValidFrequency reports whether the generator can lay out periods for the
+// schedule frequency.
+func ValidFrequency(frequency string) bool {
+	switch frequency {
+	case ScheduleFrequencyWeekly, ScheduleFrequencyBiweekly, ScheduleFrequencySemiMonthly, ScheduleFrequencyMonthly:
+		return true
+	}
+	return false
+}
+
+// ProjectPeriods lays out consecutive periods from start until a period would
+// begin after until. Pay dates that fall on a weekend or one of the holidays
+// (keyed by YYYY-MM-DD) move to the previous working day.
+func ProjectPeriods(schedule Schedule, start, until time.Time, holidays map[string]bool) []CalendarPeriod {
+	start = dateOnly(start)
+	until = dateOnly(until)
+
+	var out []CalendarPeriod
+	for !start.After(until) {
+		end := PeriodEnd(schedule.Frequency, start)
+		if end.IsZero() {
+			break
+		}
+		scheduled := ScheduledPayDate(schedule, end)
+		out = append(out, CalendarPeriod{
+			ScheduleID:       schedule.ID,
+			StartDate:        start,
+			EndDate:          end,
+			PayDate:          ShiftToWorkingDay(scheduled, holidays),
+			ScheduledPayDate: scheduled,
+		})
+		start = end.AddDate(0, 0, 1)
+	}
+	return out
+}
+
+// FirstPeriodStart returns the start of the period containing date for a
+// schedule without periods: the Monday of the week for weekly and bi-weekly
+// schedules, the 1st or 16th for semi-monthly and the 1st for monthly.
+func FirstPeriodStart(frequency string, date time.Time) time.Time {
+	date = dateOnly(date)
+	switch frequency {
+	case ScheduleFrequencyWeekly, ScheduleFrequencyBiweekly:
+		offset := (int(date.Weekday()) + 6) % 7
+		return date.AddDate(0, 0, -offset)
+	case ScheduleFrequencySemiMonthly:
+		if date.Day() > 15 {
+			return time.Date(date.Year(), date.Month(), 16, 0, 0, 0, 0, time.UTC)
+		}
+	}
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// CurrentPeriodStart moves start forward a whole period at a time until the
+// period it begins ends on or after now, so a schedule whose periods stopped
+// in the past resumes with the current period on its own cadence.
+func CurrentPeriodStart(frequency string, start, now time.Time) time.Time {
+	start = dateOnly(start)
+	now = dateOnly(now)
+	for {
+		end := PeriodEnd(frequency, start)
+		if end.IsZero() || !end.Before(now) {
+			return start
+		}
+		start = end.AddDate(0, 0, 1)
+	}
+}
+
+// PeriodEnd returns the last day of the period starting on start. Semi-monthly
+// periods end on the 15th or the last day of the month; monthly periods end the
+// day before the same date next month, or at the end of next month when it is
+// too short to have that date.
+func PeriodEnd(frequency string, start time.Time) time.Time {
+	switch frequency {
+	case ScheduleFrequencyWeekly:
+		return start.AddDate(0, 0, 6)
+	case ScheduleFrequencyBiweekly:
+		return start.AddDate(0, 0, 13)
+	case ScheduleFrequencySemiMonthly:
+		if start.Day() <= 15 {
+			return time.Date(start.Year(), start.Month(), 15, 0, 0, 0, 0, time.UTC)
+		}
+		return lastDayOfMonth(start)
+	case ScheduleFrequencyMonthly:
+		next := start.AddDate(0, 0, 1-start.Day()).AddDate(0, 1, 0)
+		if last := lastDayOfMonth(next); start.Day() > last.Day() {
+			return last
+		}
+		return dayOfMonth(next, start.Day()).AddDate(0, 0, -1)
+	}
+	return time.Time{}
+}
+
+// ScheduledPayDate returns the pay date for a period ending on end before any
+// weekend or holiday shift. A pay day of 0 pays on the period end date. For
+// monthly schedules the pay day is the day of the end date's month, capped at
+// its last day; for weekly and bi-weekly schedules it is the ISO weekday
+// (1 Monday to 7 Sunday) following the end date. For semi-monthly schedules
+// it is the number of days after the end date.
+func ScheduledPayDate(schedule Schedule, end time.Time) time.Time {
+	if schedule.PayDay <= 0 {
+		return end
+	}
+	switch schedule.Frequency {
+	case ScheduleFrequencyMonthly:
+		return dayOfMonth(end, schedule.PayDay)
+	case ScheduleFrequencySemiMonthly:
+		return end.AddDate(0, 0, schedule.PayDay)
+	case ScheduleFrequencyWeekly, ScheduleFrequencyBiweekly:
+		weekday := time.Weekday(schedule.PayDay % 7)
+		days := (int(weekday) - int(end.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return end.AddDate(0, 0, days)
+	}
+	return end
+}
+
+// ShiftToWorkingDay moves a date falling on a weekend or holiday to the
+// previous working day.
+func ShiftToWorkingDay(date time.Time, holidays map[string]bool) time.Time {
+	for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday || holidays[date.Format("2006-01-02")] {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+func dateOnly(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func lastDayOfMonth(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// dayOfMonth returns the given day in value's month, capped at the month end.
+func dayOfMonth(value time.Time, day int) time.Time {
+	last := lastDayOfMonth(value)
+	if day > last.Day() {
+		return last
+	}
+	return time.Date(value.Year(), value.Month(), day, 0, 0, 0, 0, time.UTC)
+}
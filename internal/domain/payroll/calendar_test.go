@@ -0,0 +1,94 @@
+package payroll
+
+import "testing"
+
+func TestProjectPeriodsMonthlyShiftsPayDateOffWeekendsAndHolidays(t *testing.T) {
+	schedule := Schedule{ID: "schedule-1", Frequency: ScheduleFrequencyMonthly, PayDay: 25}
+	// 2026-04-25 is a Saturday; 2026-05-25 is a Monday holiday.
+	holidays := map[string]bool{"2026-05-25": true}
+
+	periods := ProjectPeriods(schedule, mustDate("2026-04-01"), mustDate("2026-06-30"), holidays)
+	if len(periods) != 3 {
+		t.Fatalf("expected three monthly periods, got %+v", periods)
+	}
+	if !periods[0].StartDate.Equal(mustDate("2026-04-01")) || !periods[0].EndDate.Equal(mustDate("2026-04-30")) {
+		t.Fatalf("unexpected first period %+v", periods[0])
+	}
+	if !periods[0].ScheduledPayDate.Equal(mustDate("2026-04-25")) || !periods[0].PayDate.Equal(mustDate("2026-04-24")) {
+		t.Fatalf("expected Saturday pay date to move to Friday, got %+v", periods[0])
+	}
+	if !periods[1].PayDate.Equal(mustDate("2026-05-22")) {
+		t.Fatalf("expected holiday Monday to move back past the weekend, got %s", periods[1].PayDate.Format("2006-01-02"))
+	}
+	if !periods[2].PayDate.Equal(mustDate("2026-06-25")) {
+		t.Fatalf("expected unshifted June pay date, got %s", periods[2].PayDate.Format("2006-01-02"))
+	}
+}
+
+func TestProjectPeriodsSemiMonthlyAndShortMonths(t *testing.T) {
+	schedule := Schedule{Frequency: ScheduleFrequencySemiMonthly}
+	periods := ProjectPeriods(schedule, mustDate("2026-02-01"), mustDate("2026-02-28"), nil)
+	if len(periods) != 2 {
+		t.Fatalf("expected two semi-monthly periods, got %+v", periods)
+	}
+	if !periods[0].EndDate.Equal(mustDate("2026-02-15")) || !periods[1].StartDate.Equal(mustDate("2026-02-16")) || !periods[1].EndDate.Equal(mustDate("2026-02-28")) {
+		t.Fatalf("unexpected semi-monthly periods %+v", periods)
+	}
+	// 2026-02-15 is a Sunday.
+	if !periods[0].PayDate.Equal(mustDate("2026-02-13")) {
+		t.Fatalf("expected pay on the Friday before the 15th, got %s", periods[0].PayDate.Format("2006-01-02"))
+	}
+
+	monthly := Schedule{Frequency: ScheduleFrequencyMonthly, PayDay: 31}
+	if end := PeriodEnd(monthly.Frequency, mustDate("2026-01-31")); !end.Equal(mustDate("2026-02-28")) {
+		t.Fatalf("expected a period starting on the 31st to end with February, got %s", end.Format("2006-01-02"))
+	}
+	if pay := ScheduledPayDate(monthly, mustDate("2026-04-30")); !pay.Equal(mustDate("2026-04-30")) {
+		t.Fatalf("expected pay day 31 to cap at the month end, got %s", pay.Format("2006-01-02"))
+	}
+}
+
+func TestProjectPeriodsWeeklyPaysOnFollowingWeekday(t *testing.T) {
+	schedule := Schedule{Frequency: ScheduleFrequencyBiweekly, PayDay: 5}
+	start := FirstPeriodStart(schedule.Frequency, mustDate("2026-03-04"))
+	if !start.Equal(mustDate("2026-03-02")) {
+		t.Fatalf("expected periods to start on Monday, got %s", start.Format("2006-01-02"))
+	}
+
+	periods := ProjectPeriods(schedule, start, mustDate("2026-03-31"), nil)
+	if len(periods) != 3 {
+		t.Fatalf("expected three bi-weekly periods, got %+v", periods)
+	}
+	if !periods[0].EndDate.Equal(mustDate("2026-03-15")) || !periods[0].PayDate.Equal(mustDate("2026-03-20")) {
+		t.Fatalf("expected pay on the Friday after the period ends, got %+v", periods[0])
+	}
+	if !periods[1].StartDate.Equal(mustDate("2026-03-16")) {
+		t.Fatalf("expected consecutive periods, got %+v", periods[1])
+	}
+}
+
+func TestScheduledPayDateSemiMonthlyPaysDaysAfterPeriodEnd(t *testing.T) {
+	schedule := Schedule{Frequency: ScheduleFrequencySemiMonthly, PayDay: 5}
+	periods := ProjectPeriods(schedule, mustDate("2026-03-01"), mustDate("2026-03-31"), nil)
+	if len(periods) != 2 {
+		t.Fatalf("expected two semi-monthly periods, got %+v", periods)
+	}
+	if !periods[0].PayDate.Equal(mustDate("2026-03-20")) {
+		t.Fatalf("expected pay five days after the 15th, got %s", periods[0].PayDate.Format("2006-01-02"))
+	}
+	// 2026-04-05 is a Sunday.
+	if !periods[1].ScheduledPayDate.Equal(mustDate("2026-04-05")) || !periods[1].PayDate.Equal(mustDate("2026-04-03")) {
+		t.Fatalf("expected the month-end period to pay on the Friday before the 5th, got %+v", periods[1])
+	}
+}
+
+func TestCurrentPeriodStartSkipsStalePeriodsOnCadence(t *testing.T) {
+	// The schedule's last bi-weekly period ended on 2025-01-05.
+	start := CurrentPeriodStart(ScheduleFrequencyBiweekly, mustDate("2025-01-06"), mustDate("2026-03-10"))
+	if !start.Equal(mustDate("2026-03-02")) {
+		t.Fatalf("expected the bi-weekly period containing today, got %s", start.Format("2006-01-02"))
+	}
+	if start := CurrentPeriodStart(ScheduleFrequencyMonthly, mustDate("2026-04-01"), mustDate("2026-03-04")); !start.Equal(mustDate("2026-04-01")) {
+		t.Fatalf("expected a future start to be kept, got %s", start.Format("2006-01-02"))
+	}
+}
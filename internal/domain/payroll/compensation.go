@@ -0,0 +1,184 @@
+package payroll
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"hrm/internal/domain/leave"
+)
+
+// Compensation is one effective-dated salary, currency and pay group record.
+// A record applies from EffectiveFrom until the next record takes over.
+type Compensation struct {
+	ID            string    `json:"id"`
+	EmployeeID    string    `json:"employeeId"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	Salary        *float64  `json:"salary,omitempty"`
+	SalaryEnc     []byte    `json:"-"`
+	Currency      string    `json:"currency"`
+	PayGroupID    string    `json:"payGroupId,omitempty"`
+	ScheduleID    string    `json:"-"`
+	Reason        string    `json:"reason,omitempty"`
+	CreatedBy     string    `json:"createdBy,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// SalarySegment is a stretch of a period paid at one salary.
+type SalarySegment struct {
+	From   time.Time
+	To     time.Time
+	Salary float64
+}
+
+func (s *Service) ListCompensation(ctx context.Context, tenantID, employeeID string) ([]Compensation, error) {
+	records, err := s.store.ListCompensation(ctx, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		s.decryptCompensation(&records[i])
+	}
+	return records, nil
+}
+
+// SaveCompensation records a compensation change, replacing any record with
+// the same effective date. The employee record is updated when the change is
+// already in force.
+func (s *Service) SaveCompensation(ctx context.Context, tenantID string, record Compensation) (string, error) {
+	if record.Salary != nil && s.crypto != nil && s.crypto.Configured() {
+		encrypted, err := s.crypto.EncryptString(strconv.FormatFloat(*record.Salary, 'f', 2, 64))
+		if err != nil {
+			return "", err
+		}
+		record.SalaryEnc = encrypted
+		record.Salary = nil
+	}
+	return s.store.SaveCompensation(ctx, tenantID, record)
+}
+
+// compensationHistory loads the records relevant to a period for every
+// employee, with salaries decrypted.
+func (s *Service) compensationHistory(ctx context.Context, tenantID string, period PeriodDetails) (map[string][]Compensation, error) {
+	history, err := s.store.ListCompensationForPeriod(ctx, tenantID, period.StartDate, period.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, records := range history {
+		for i := range records {
+			s.decryptCompensation(&records[i])
+		}
+	}
+	return history, nil
+}
+
+func (s *Service) decryptCompensation(record *Compensation) {
+	if s.crypto != nil && s.crypto.Configured() && len(record.SalaryEnc) > 0 {
+		if decrypted, err := s.crypto.DecryptString(record.SalaryEnc); err == nil {
+			if parsed, err := strconv.ParseFloat(decrypted, 64); err == nil {
+				record.Salary = &parsed
+			}
+		}
+	}
+	record.SalaryEnc = nil
+}
+
+// CompensationAt returns the record in force on the given day. The earliest
+// record also covers any days before it, so history seeded from the current
+// salary applies to older periods.
+func CompensationAt(history []Compensation, on time.Time) (Compensation, bool) {
+	if len(history) == 0 {
+		return Compensation{}, false
+	}
+	current := history[0]
+	for _, record := range history[1:] {
+		if record.EffectiveFrom.After(on) {
+			break
+		}
+		current = record
+	}
+	return current, true
+}
+
+// SalarySegments splits the period at each compensation change and clips it to
+// the days the employee was employed. Without history the whole employed part
+// of the period is paid at fallback.
+func SalarySegments(periodStart, periodEnd time.Time, hired, left *time.Time, history []Compensation, fallback float64) []SalarySegment {
+	from, to := periodStart, periodEnd
+	if hired != nil && hired.After(from) {
+		from = *hired
+	}
+	if left != nil && left.Before(to) {
+		to = *left
+	}
+	if to.Before(from) {
+		return nil
+	}
+	if len(history) == 0 {
+		return []SalarySegment{{From: from, To: to, Salary: fallback}}
+	}
+
+	sorted := make([]Compensation, len(history))
+	copy(sorted, history)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EffectiveFrom.Before(sorted[j].EffectiveFrom)
+	})
+
+	var segments []SalarySegment
+	start := from
+	for start.Before(to) || start.Equal(to) {
+		record, _ := CompensationAt(sorted, start)
+		end := to
+		for _, next := range sorted {
+			if next.EffectiveFrom.After(start) {
+				if boundary := next.EffectiveFrom.AddDate(0, 0, -1); boundary.Before(end) {
+					end = boundary
+				}
+				break
+			}
+		}
+		salary := 0.0
+		if record.Salary != nil {
+			salary = *record.Salary
+		}
+		if n := len(segments); n > 0 && segments[n-1].Salary == salary {
+			segments[n-1].To = end
+		} else {
+			segments = append(segments, SalarySegment{From: start, To: end, Salary: salary})
+		}
+		start = end.AddDate(0, 0, 1)
+	}
+	return segments
+}
+
+// ProratedSalaryLines pays each segment for its share of the period's calendar
+// days. A single segment spanning the whole period is returned as the plain
+// base salary with no lines, matching an unprorated run.
+func ProratedSalaryLines(periodStart, periodEnd time.Time, segments []SalarySegment) (float64, []InputLine) {
+	if len(segments) == 1 && segments[0].From.Equal(periodStart) && segments[0].To.Equal(periodEnd) {
+		return segments[0].Salary, nil
+	}
+	periodDays, err := leave.CalculateDays(periodStart, periodEnd)
+	if err != nil || periodDays <= 0 {
+		return 0, nil
+	}
+
+	lines := make([]InputLine, 0, len(segments))
+	for _, segment := range segments {
+		days, err := leave.CalculateDays(segment.From, segment.To)
+		if err != nil || days <= 0 || segment.Salary == 0 {
+			continue
+		}
+		lines = append(lines, InputLine{
+			Type:        ElementTypeEarning,
+			Amount:      segment.Salary * days / periodDays,
+			Taxable:     true,
+			Source:      ResultSourceSalary,
+			Code:        ResultCodeBaseSalary,
+			Description: fmt.Sprintf("Base salary %s to %s (%.0f/%.0f days)", segment.From.Format("2006-01-02"), segment.To.Format("2006-01-02"), days, periodDays),
+		})
+	}
+	return 0, lines
+}
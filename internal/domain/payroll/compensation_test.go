@@ -0,0 +1,85 @@
+package payroll
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func compensationRecord(from string, salary float64) Compensation {
+	return Compensation{EffectiveFrom: mustDate(from), Salary: &salary, Currency: "USD"}
+}
+
+func mustDate(value string) time.Time {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func TestSalarySegmentsSplitsAtMidPeriodRaise(t *testing.T) {
+	start, end := mustDate("2026-04-01"), mustDate("2026-04-30")
+	history := []Compensation{compensationRecord("2025-01-01", 3000), compensationRecord("2026-04-16", 3600)}
+
+	segments := SalarySegments(start, end, nil, nil, history, 0)
+	if len(segments) != 2 {
+		t.Fatalf("expected two segments, got %+v", segments)
+	}
+	if !segments[0].To.Equal(mustDate("2026-04-15")) || segments[0].Salary != 3000 {
+		t.Fatalf("unexpected first segment %+v", segments[0])
+	}
+	if !segments[1].From.Equal(mustDate("2026-04-16")) || !segments[1].To.Equal(end) || segments[1].Salary != 3600 {
+		t.Fatalf("unexpected second segment %+v", segments[1])
+	}
+
+	base, lines := ProratedSalaryLines(start, end, segments)
+	if base != 0 || len(lines) != 2 {
+		t.Fatalf("expected prorated lines instead of a base salary, got %.2f %+v", base, lines)
+	}
+	total := lines[0].Amount + lines[1].Amount
+	if math.Abs(total-3300) > 0.001 {
+		t.Fatalf("expected 15 days at each rate to pay 3300, got %.2f", total)
+	}
+}
+
+func TestSalarySegmentsClipToEmployment(t *testing.T) {
+	start, end := mustDate("2026-04-01"), mustDate("2026-04-30")
+	hired, left := mustDate("2026-04-11"), mustDate("2026-04-20")
+
+	segments := SalarySegments(start, end, &hired, &left, nil, 3000)
+	if len(segments) != 1 || !segments[0].From.Equal(hired) || !segments[0].To.Equal(left) {
+		t.Fatalf("expected one segment limited to employment, got %+v", segments)
+	}
+	_, lines := ProratedSalaryLines(start, end, segments)
+	if len(lines) != 1 || math.Abs(lines[0].Amount-1000) > 0.001 {
+		t.Fatalf("expected 10 of 30 days paid, got %+v", lines)
+	}
+
+	before := mustDate("2026-03-31")
+	if segments := SalarySegments(start, end, nil, &before, nil, 3000); len(segments) != 0 {
+		t.Fatalf("expected no pay after termination, got %+v", segments)
+	}
+}
+
+func TestProratedSalaryLinesFullPeriodKeepsBaseSalary(t *testing.T) {
+	start, end := mustDate("2026-04-01"), mustDate("2026-04-30")
+	history := []Compensation{compensationRecord("2026-01-01", 3000), compensationRecord("2026-05-01", 3600)}
+
+	segments := SalarySegments(start, end, nil, nil, history, 0)
+	base, lines := ProratedSalaryLines(start, end, segments)
+	if base != 3000 || len(lines) != 0 {
+		t.Fatalf("expected unprorated base of 3000, got %.2f %+v", base, lines)
+	}
+}
+
+func TestCompensationAtUsesEarliestRecordForOlderDates(t *testing.T) {
+	history := []Compensation{compensationRecord("2026-01-01", 3000), compensationRecord("2026-06-01", 3600)}
+
+	if record, ok := CompensationAt(history, mustDate("2025-06-30")); !ok || *record.Salary != 3000 {
+		t.Fatalf("expected earliest record before history starts, got %+v", record)
+	}
+	if record, _ := CompensationAt(history, mustDate("2026-06-01")); *record.Salary != 3600 {
+		t.Fatalf("expected change to apply on its effective date, got %+v", record)
+	}
+}
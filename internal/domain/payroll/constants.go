@@ -1,17 +1,103 @@
 package payroll
 
 const (
-	PeriodStatusDraft     = "draft"
-	PeriodStatusReviewed  = "reviewed"
-	PeriodStatusFinalized = "finalized"
+	PeriodStatusDraft           = "draft"
+	PeriodStatusReviewed        = "reviewed"
+	PeriodStatusPendingApproval = "pending_approval"
+	PeriodStatusApproved        = "approved"
+	PeriodStatusFinalized       = "finalized"
+
+	ApprovalDecisionApproved = "approved"
+	ApprovalDecisionRejected = "rejected"
+
+	ScheduleFrequencyWeekly      = "weekly"
+	ScheduleFrequencyBiweekly    = "biweekly"
+	ScheduleFrequencySemiMonthly = "semimonthly"
+	ScheduleFrequencyMonthly     = "monthly"
+
+	PeriodRunRegular     = "regular"
+	PeriodRunBonus       = "bonus"
+	PeriodRunTermination = "termination"
+	PeriodRunCorrection  = "correction"
 
 	WarningMissingBank = "missing_bank_account"
 	WarningNegativeNet = "negative_net"
 	WarningNetVariance = "net_variance"
+	WarningRecurring   = "recurring_deduction_limited"
 
 	InputSourceManual = "manual"
 	InputSourceImport = "import"
 
 	ElementTypeEarning   = "earning"
 	ElementTypeDeduction = "deduction"
+
+	CalcTypeFixed      = "fixed"
+	CalcTypePctOfBase  = "pct_of_base"
+	CalcTypeUnitsXRate = "units_x_rate"
+	CalcTypePctOfGross = "pct_of_gross"
+	CalcTypeTiered     = "tiered"
+	CalcTypeFormula    = "formula"
+
+	TaxKindIncomeTax          = "income_tax"
+	TaxKindSocialContribution = "social_contribution"
+
+	ResultLineEarning              = "earning"
+	ResultLineDeduction            = "deduction"
+	ResultLineEmployerContribution = "employer_contribution"
+
+	ResultSourceSalary      = "salary"
+	ResultSourceElement     = "element"
+	ResultSourceAdjustment  = "adjustment"
+	ResultSourceUnpaidLeave = "unpaid_leave"
+	ResultSourceTax         = "tax"
+	ResultSourceRetro       = "retro"
+	ResultSourceRecurring   = "recurring"
+
+	ResultCodeBaseSalary   = "base_salary"
+	ResultCodeAdjustment   = "adjustment"
+	ResultCodeUnpaidLeave  = "unpaid_leave"
+	ResultCodeRetroPay     = "retro_pay"
+	ResultCodeRetroUntaxed = "retro_pay_untaxed"
+
+	ResultCodeRecurringEarning = "recurring_earning"
+	ResultCodeRecurringUntaxed = "recurring_earning_untaxed"
+
+	RecurringKindLoan        = "loan"
+	RecurringKindAdvance     = "advance"
+	RecurringKindGarnishment = "garnishment"
+	RecurringKindUnionDues   = "union_dues"
+	RecurringKindDeduction   = "deduction"
+	RecurringKindEarning     = "earning"
+
+	RecurringStatusActive    = "active"
+	RecurringStatusCompleted = "completed"
+	RecurringStatusCancelled = "cancelled"
+
+	RetroTriggerCompensation = "compensation"
+	RetroTriggerInput        = "input"
+	RetroTriggerAdjustment   = "adjustment"
+
+	JobTypePayrollRun = "payroll_run"
+
+	RunEmployeeCompleted = "completed"
+	RunEmployeeFailed    = "failed"
+
+	AccumulatorTotal = "total"
+
+	JournalFormatCSV  = "csv"
+	JournalFormatXero = "xero"
+	JournalFormatJSON = "json"
+
+	PaymentFormatSEPA  = "sepa"
+	PaymentFormatNACHA = "nacha"
+
+	PaymentIssueInvalidIBAN    = "invalid_iban"
+	PaymentIssueInvalidRouting = "invalid_routing_number"
+	PaymentIssueInvalidAccount = "invalid_account_number"
+	PaymentIssueCurrency       = "unsupported_currency"
+	PaymentIssueNonPositiveNet = "non_positive_net"
+
+	// DefaultReportingCurrency applies only when a tenant has neither chosen
+	// a reporting currency nor any employee with a currency.
+	DefaultReportingCurrency = "USD"
 )
@@ -0,0 +1,226 @@
+package payroll
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ExchangeRate converts one unit of FromCurrency into Rate units of
+// ToCurrency from EffectiveDate until a later rate for the same pair.
+type ExchangeRate struct {
+	ID            string    `json:"id"`
+	FromCurrency  string    `json:"fromCurrency"`
+	ToCurrency    string    `json:"toCurrency"`
+	Rate          float64   `json:"rate"`
+	EffectiveDate time.Time `json:"effectiveDate"`
+	CreatedBy     string    `json:"createdBy,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// Settings holds tenant-wide payroll options. Summaries, previews and journal
+// exports are reported in ReportingCurrency. The tax year starts on
+// TaxYearStartDay of TaxYearStartMonth; zero values mean 1 January.
+type Settings struct {
+	ReportingCurrency string `json:"reportingCurrency"`
+	TaxYearStartMonth int    `json:"taxYearStartMonth"`
+	TaxYearStartDay   int    `json:"taxYearStartDay"`
+}
+
+// TaxYearStart returns the first day of the tax year containing date.
+func (s Settings) TaxYearStart(date time.Time) time.Time {
+	month, day := time.Month(s.TaxYearStartMonth), s.TaxYearStartDay
+	if month < time.January || day < 1 {
+		month, day = time.January, 1
+	}
+	date = dateOnly(date)
+	start := time.Date(date.Year(), month, day, 0, 0, 0, 0, time.UTC)
+	if date.Before(start) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start
+}
+
+// TaxYear returns the tax year containing date, numbered by the calendar year
+// it starts in.
+func (s Settings) TaxYear(date time.Time) int {
+	return s.TaxYearStart(date).Year()
+}
+
+// ValidTaxYearStart reports whether month and day name a day every year has,
+// so 29 February is rejected.
+func ValidTaxYearStart(month, day int) bool {
+	if month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	return day <= lastDayOfMonth(time.Date(2023, time.Month(month), 1, 0, 0, 0, 0, time.UTC)).Day()
+}
+
+// CurrencyTotals are a period's results in one pay currency, together with
+// the rate used to convert them to the reporting currency.
+type CurrencyTotals struct {
+	Currency        string  `json:"currency"`
+	TotalGross      float64 `json:"totalGross"`
+	TotalDeductions float64 `json:"totalDeductions"`
+	TotalNet        float64 `json:"totalNet"`
+	EmployeeCount   int     `json:"employeeCount"`
+	Rate            float64 `json:"rate"`
+}
+
+// NormalizeCurrency upper-cases and trims an ISO 4217 currency code.
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidCurrency reports whether code is a three-letter currency code.
+func ValidCurrency(code string) bool {
+	return currencyCodePattern.MatchString(code)
+}
+
+// Converter converts amounts into a reporting currency using the rates in
+// force on a given date.
+type Converter struct {
+	Currency string
+	Date     time.Time
+	rates    []ExchangeRate
+}
+
+func NewConverter(currency string, date time.Time, rates []ExchangeRate) Converter {
+	currency = NormalizeCurrency(currency)
+	if currency == "" {
+		currency = DefaultReportingCurrency
+	}
+	return Converter{Currency: currency, Date: dateOnly(date), rates: rates}
+}
+
+// Rate returns the multiplier from currency to the reporting currency: the
+// latest rate for the pair effective on or before the converter's date, or
+// the inverse of the latest rate recorded the other way round when that is
+// more recent. Amounts without a currency are taken to be in the reporting
+// currency already.
+func (c Converter) Rate(currency string) (float64, error) {
+	from := NormalizeCurrency(currency)
+	if from == "" || from == c.Currency {
+		return 1, nil
+	}
+	var direct, inverse *ExchangeRate
+	for i := range c.rates {
+		rate := &c.rates[i]
+		if rate.Rate <= 0 || dateOnly(rate.EffectiveDate).After(c.Date) {
+			continue
+		}
+		fromCurrency, toCurrency := NormalizeCurrency(rate.FromCurrency), NormalizeCurrency(rate.ToCurrency)
+		switch {
+		case fromCurrency == from && toCurrency == c.Currency:
+			if direct == nil || rate.EffectiveDate.After(direct.EffectiveDate) {
+				direct = rate
+			}
+		case fromCurrency == c.Currency && toCurrency == from:
+			if inverse == nil || rate.EffectiveDate.After(inverse.EffectiveDate) {
+				inverse = rate
+			}
+		}
+	}
+	switch {
+	case direct != nil && (inverse == nil || !inverse.EffectiveDate.After(direct.EffectiveDate)):
+		return direct.Rate, nil
+	case inverse != nil:
+		return 1 / inverse.Rate, nil
+	}
+	return 0, fmt.Errorf("%w: %s to %s on %s", ErrExchangeRateMissing, from, c.Currency, c.Date.Format("2006-01-02"))
+}
+
+// Convert returns amount in the reporting currency, rounded to cents.
+func (c Converter) Convert(amount float64, currency string) (float64, error) {
+	rate, err := c.Rate(currency)
+	if err != nil {
+		return 0, err
+	}
+	return roundCents(amount * rate), nil
+}
+
+// ConvertTotals converts per-currency totals and returns their sum in the
+// reporting currency, recording the rate used on each entry.
+func (c Converter) ConvertTotals(byCurrency []CurrencyTotals) (Totals, error) {
+	var totals Totals
+	for i := range byCurrency {
+		rate, err := c.Rate(byCurrency[i].Currency)
+		if err != nil {
+			return Totals{}, err
+		}
+		byCurrency[i].Rate = rate
+		totals.Gross += byCurrency[i].TotalGross * rate
+		totals.Deductions += byCurrency[i].TotalDeductions * rate
+		totals.Net += byCurrency[i].TotalNet * rate
+	}
+	return roundTotals(totals), nil
+}
+
+// convertLines returns a copy of lines with every amount multiplied by rate.
+func convertLines(lines []ResultLine, rate float64) []ResultLine {
+	out := make([]ResultLine, len(lines))
+	for i, line := range lines {
+		line.Amount = roundCents(line.Amount * rate)
+		out[i] = line
+	}
+	return out
+}
+
+// Settings returns the tenant's payroll settings. Until a reporting currency
+// is chosen, the currency most employees are paid in is reported, so a
+// single-currency tenant needs no exchange rates.
+func (s *Service) Settings(ctx context.Context, tenantID string) (Settings, error) {
+	settings, err := s.store.Settings(ctx, tenantID)
+	if err != nil {
+		return Settings{}, err
+	}
+	if settings.TaxYearStartMonth == 0 || settings.TaxYearStartDay == 0 {
+		settings.TaxYearStartMonth, settings.TaxYearStartDay = 1, 1
+	}
+	if settings.ReportingCurrency != "" {
+		return settings, nil
+	}
+	currency, err := s.store.PrevailingCurrency(ctx, tenantID)
+	if err != nil {
+		return Settings{}, err
+	}
+	if currency == "" {
+		currency = DefaultReportingCurrency
+	}
+	settings.ReportingCurrency = NormalizeCurrency(currency)
+	return settings, nil
+}
+
+func (s *Service) UpsertSettings(ctx context.Context, tenantID string, settings Settings) error {
+	return s.store.UpsertSettings(ctx, tenantID, settings)
+}
+
+func (s *Service) ListExchangeRates(ctx context.Context, tenantID string) ([]ExchangeRate, error) {
+	return s.store.ListExchangeRates(ctx, tenantID)
+}
+
+func (s *Service) UpsertExchangeRate(ctx context.Context, tenantID string, rate ExchangeRate) (string, error) {
+	return s.store.UpsertExchangeRate(ctx, tenantID, rate)
+}
+
+func (s *Service) DeleteExchangeRate(ctx context.Context, tenantID, rateID string) error {
+	return s.store.DeleteExchangeRate(ctx, tenantID, rateID)
+}
+
+// Converter loads the tenant's reporting currency and exchange rates for
+// converting amounts as of date.
+func (s *Service) Converter(ctx context.Context, tenantID string, date time.Time) (Converter, error) {
+	settings, err := s.Settings(ctx, tenantID)
+	if err != nil {
+		return Converter{}, err
+	}
+	rates, err := s.store.ListExchangeRates(ctx, tenantID)
+	if err != nil {
+		return Converter{}, err
+	}
+	return NewConverter(settings.ReportingCurrency, date, rates), nil
+}
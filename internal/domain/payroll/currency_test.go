@@ -0,0 +1,148 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestConverterUsesLatestRateOnOrBeforeDate(t *testing.T) {
+	rates := []ExchangeRate{
+		{FromCurrency: "EUR", ToCurrency: "USD", Rate: 1.05, EffectiveDate: mustDate("2026-01-01")},
+		{FromCurrency: "EUR", ToCurrency: "USD", Rate: 1.10, EffectiveDate: mustDate("2026-03-01")},
+		{FromCurrency: "EUR", ToCurrency: "USD", Rate: 1.20, EffectiveDate: mustDate("2026-04-01")},
+		{FromCurrency: "USD", ToCurrency: "GBP", Rate: 0.8, EffectiveDate: mustDate("2026-01-01")},
+	}
+	converter := NewConverter("usd", mustDate("2026-03-31"), rates)
+
+	if rate, err := converter.Rate("eur"); err != nil || rate != 1.10 {
+		t.Fatalf("expected the March rate of 1.10, got %v (%v)", rate, err)
+	}
+	if rate, err := converter.Rate("GBP"); err != nil || rate != 1.25 {
+		t.Fatalf("expected the inverse of 0.8, got %v (%v)", rate, err)
+	}
+	if rate, err := converter.Rate(""); err != nil || rate != 1 {
+		t.Fatalf("expected amounts without a currency to be left as they are, got %v (%v)", rate, err)
+	}
+	if _, err := converter.Rate("LKR"); !errors.Is(err, ErrExchangeRateMissing) {
+		t.Fatalf("expected a missing rate error, got %v", err)
+	}
+	if _, err := NewConverter("USD", mustDate("2025-12-31"), rates).Rate("EUR"); !errors.Is(err, ErrExchangeRateMissing) {
+		t.Fatalf("expected rates effective later to be ignored, got %v", err)
+	}
+}
+
+func TestConvertTotalsSumsCurrenciesInReportingCurrency(t *testing.T) {
+	converter := NewConverter("USD", mustDate("2026-01-31"), []ExchangeRate{
+		{FromCurrency: "EUR", ToCurrency: "USD", Rate: 1.1, EffectiveDate: mustDate("2026-01-01")},
+		{FromCurrency: "USD", ToCurrency: "LKR", Rate: 300, EffectiveDate: mustDate("2026-01-01")},
+	})
+	byCurrency := []CurrencyTotals{
+		{Currency: "EUR", TotalGross: 1000, TotalDeductions: 200, TotalNet: 800},
+		{Currency: "LKR", TotalGross: 300000, TotalDeductions: 30000, TotalNet: 270000},
+		{Currency: "USD", TotalGross: 500, TotalDeductions: 100, TotalNet: 400},
+	}
+
+	totals, err := converter.ConvertTotals(byCurrency)
+	if err != nil {
+		t.Fatalf("convert totals: %v", err)
+	}
+	if totals.Gross != 2600 || totals.Deductions != 420 || totals.Net != 2180 {
+		t.Fatalf("expected 2600 / 420 / 2180, got %+v", totals)
+	}
+	if byCurrency[0].Rate != 1.1 || byCurrency[2].Rate != 1 {
+		t.Fatalf("expected the rates used to be recorded, got %+v", byCurrency)
+	}
+}
+
+type journalStore struct {
+	StoreAPI
+	employees []JournalEmployee
+	lines     map[string][]ResultLine
+	rates     []ExchangeRate
+}
+
+func (s *journalStore) GetPeriodDetails(context.Context, string, string) (PeriodDetails, error) {
+	return PeriodDetails{StartDate: mustDate("2026-01-01"), EndDate: mustDate("2026-01-31")}, nil
+}
+
+func (s *journalStore) JournalEmployees(context.Context, string, string) ([]JournalEmployee, error) {
+	return s.employees, nil
+}
+
+func (s *journalStore) ListPeriodResultLines(context.Context, string, string) (map[string][]ResultLine, error) {
+	return s.lines, nil
+}
+
+func (s *journalStore) Settings(context.Context, string) (Settings, error) {
+	return Settings{ReportingCurrency: "EUR"}, nil
+}
+
+func (s *journalStore) ListExchangeRates(context.Context, string) ([]ExchangeRate, error) {
+	return s.rates, nil
+}
+
+func TestPeriodJournalConvertsToReportingCurrency(t *testing.T) {
+	store := &journalStore{
+		employees: []JournalEmployee{
+			{EmployeeID: "e1", Currency: "EUR"},
+			{EmployeeID: "e2", Currency: "USD"},
+		},
+		lines: map[string][]ResultLine{
+			"e1": {
+				{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 1000},
+				{LineType: ResultLineDeduction, Code: "income_tax", Description: "Income tax", Amount: 100},
+			},
+			"e2": {
+				{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 2000},
+				{LineType: ResultLineDeduction, Code: "income_tax", Description: "Income tax", Amount: 333.33},
+			},
+		},
+		rates: []ExchangeRate{{FromCurrency: "USD", ToCurrency: "EUR", Rate: 0.9, EffectiveDate: mustDate("2026-01-15")}},
+	}
+	svc := NewService(store, nil)
+
+	journal, _, err := svc.PeriodJournal(context.Background(), "t1", "p1", "")
+	if err != nil {
+		t.Fatalf("period journal: %v", err)
+	}
+	if journal.Currency != "EUR" || journal.TotalDebit != 2800 || journal.TotalCredit != 2800 {
+		t.Fatalf("expected a balanced EUR journal of 2800, got %s %.2f / %.2f", journal.Currency, journal.TotalDebit, journal.TotalCredit)
+	}
+	if store.lines["e2"][0].Amount != 2000 {
+		t.Fatal("expected the stored result lines to be left unconverted")
+	}
+
+	store.rates = nil
+	if _, _, err := svc.PeriodJournal(context.Background(), "t1", "p1", ""); !errors.Is(err, ErrExchangeRateMissing) {
+		t.Fatalf("expected a missing rate to block the export, got %v", err)
+	}
+}
+
+type unsetSettingsStore struct {
+	journalStore
+}
+
+func (s *unsetSettingsStore) Settings(context.Context, string) (Settings, error) {
+	return Settings{}, nil
+}
+
+func (s *unsetSettingsStore) PrevailingCurrency(context.Context, string) (string, error) {
+	return "LKR", nil
+}
+
+func TestPeriodJournalDefaultsToEmployeesCurrency(t *testing.T) {
+	store := &unsetSettingsStore{journalStore{
+		employees: []JournalEmployee{{EmployeeID: "e1", Currency: "LKR"}},
+		lines: map[string][]ResultLine{
+			"e1": {{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 150000}},
+		},
+	}}
+	journal, _, err := NewService(store, nil).PeriodJournal(context.Background(), "t1", "p1", "")
+	if err != nil {
+		t.Fatalf("expected a single-currency tenant to need no rates, got %v", err)
+	}
+	if journal.Currency != "LKR" {
+		t.Fatalf("expected the journal in the employees' currency, got %s", journal.Currency)
+	}
+}
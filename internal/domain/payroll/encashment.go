@@ -0,0 +1,65 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"hrm/internal/domain/leave"
+)
+
+// QuoteLeavePayout prices a day of encashed leave for an employee and picks
+// the payroll period that pays it: the earliest draft regular period on the
+// employee's schedule that ends on or after the given day. The daily rate is
+// the salary in force at the end of that period divided by the employee's
+// scheduled working days in it, the rate unpaid leave is deducted at.
+func (s *Service) QuoteLeavePayout(ctx context.Context, tenantID, employeeID string, on time.Time) (leave.PayoutQuote, error) {
+	return s.quotePayout(ctx, tenantID, employeeID, on, false)
+}
+
+// QuoteTerminationPayout prices a leaver's encashed leave like
+// QuoteLeavePayout, but only in the draft period covering their end date:
+// later periods no longer include the employee.
+func (s *Service) QuoteTerminationPayout(ctx context.Context, tenantID, employeeID string, endDate time.Time) (leave.PayoutQuote, error) {
+	return s.quotePayout(ctx, tenantID, employeeID, endDate, true)
+}
+
+func (s *Service) quotePayout(ctx context.Context, tenantID, employeeID string, on time.Time, covering bool) (leave.PayoutQuote, error) {
+	var quote leave.PayoutQuote
+	employee, err := s.store.EmployeePayrollData(ctx, tenantID, employeeID)
+	if err != nil {
+		return quote, err
+	}
+	periodID, period, err := s.store.NextOpenPeriod(ctx, tenantID, employee.GroupScheduleID, on)
+	if errors.Is(err, ErrPeriodNotFound) {
+		return quote, leave.ErrNoPayrollPeriod
+	}
+	if err != nil {
+		return quote, err
+	}
+	if covering && period.StartDate.After(on) {
+		return quote, leave.ErrNoPayrollPeriod
+	}
+
+	if employee.Compensation, err = s.ListCompensation(ctx, tenantID, employeeID); err != nil {
+		return quote, err
+	}
+	if current, ok := CompensationAt(employee.Compensation, period.EndDate); ok {
+		employee.Currency = current.Currency
+	}
+	schedule, err := s.store.WorkSchedule(ctx, tenantID, employeeID, period.StartDate, period.EndDate)
+	if err != nil {
+		return quote, err
+	}
+	workingDays, err := leave.WorkingDays(period.StartDate, period.EndDate, schedule)
+	if err != nil {
+		return quote, err
+	}
+	if workingDays <= 0 {
+		return quote, leave.ErrNoWorkingDays
+	}
+	quote.PeriodID = periodID
+	quote.DailyRate = roundCents(s.salaryAt(employee, period.EndDate) / workingDays)
+	quote.Currency = employee.Currency
+	return quote, nil
+}
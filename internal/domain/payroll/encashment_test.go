@@ -0,0 +1,79 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hrm/internal/domain/leave"
+)
+
+type payoutStore struct {
+	StoreAPI
+	salary       float64
+	scheduleID   string
+	compensation []Compensation
+	noPeriod     bool
+}
+
+func (s *payoutStore) EmployeePayrollData(_ context.Context, _, employeeID string) (EmployeePayrollData, error) {
+	return EmployeePayrollData{EmployeeID: employeeID, SalaryPlain: &s.salary, Currency: "USD", GroupScheduleID: s.scheduleID}, nil
+}
+
+func (s *payoutStore) NextOpenPeriod(_ context.Context, _, scheduleID string, _ time.Time) (string, PeriodDetails, error) {
+	if s.noPeriod || scheduleID != s.scheduleID {
+		return "", PeriodDetails{}, ErrPeriodNotFound
+	}
+	return "period-1", PeriodDetails{
+		Status:     PeriodStatusDraft,
+		StartDate:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+		ScheduleID: scheduleID,
+		RunType:    PeriodRunRegular,
+	}, nil
+}
+
+func (s *payoutStore) ListCompensation(context.Context, string, string) ([]Compensation, error) {
+	return s.compensation, nil
+}
+
+func (s *payoutStore) WorkSchedule(context.Context, string, string, time.Time, time.Time) (leave.WorkSchedule, error) {
+	return leave.WorkSchedule{Pattern: leave.StandardWorkPattern(), Holidays: map[string]bool{"2026-01-01": true}}, nil
+}
+
+func TestQuoteLeavePayoutUsesPeriodDailyRate(t *testing.T) {
+	raised := 4620.0
+	store := &payoutStore{salary: 4200, scheduleID: "s1"}
+	service := NewService(store, nil)
+	on := time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	quote, err := service.QuoteLeavePayout(context.Background(), "t1", "emp-1", on)
+	if err != nil {
+		t.Fatalf("quote leave payout: %v", err)
+	}
+	if quote.PeriodID != "period-1" || quote.DailyRate != 200 || quote.Currency != "USD" {
+		t.Fatalf("expected 4200 over 21 working days, got %+v", quote)
+	}
+
+	store.compensation = []Compensation{{EffectiveFrom: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), Salary: &raised, Currency: "EUR"}}
+	quote, err = service.QuoteLeavePayout(context.Background(), "t1", "emp-1", on)
+	if err != nil {
+		t.Fatalf("quote leave payout: %v", err)
+	}
+	if quote.DailyRate != 220 || quote.Currency != "EUR" {
+		t.Fatalf("expected the salary in force at the period end, got %+v", quote)
+	}
+
+	if _, err := service.QuoteTerminationPayout(context.Background(), "t1", "emp-1", time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)); !errors.Is(err, leave.ErrNoPayrollPeriod) {
+		t.Fatalf("expected a leaver to be paid only in the period covering their end date, got %v", err)
+	}
+	if quote, err := service.QuoteTerminationPayout(context.Background(), "t1", "emp-1", on); err != nil || quote.PeriodID != "period-1" {
+		t.Fatalf("expected the period covering the end date, got %+v and %v", quote, err)
+	}
+
+	store.noPeriod = true
+	if _, err := service.QuoteLeavePayout(context.Background(), "t1", "emp-1", on); !errors.Is(err, leave.ErrNoPayrollPeriod) {
+		t.Fatalf("expected no payroll period, got %v", err)
+	}
+}
@@ -3,7 +3,31 @@ package payroll
 import "errors"
 
 var (
-	ErrPeriodNotFound       = errors.New("payroll period not found")
-	ErrFinalizeInvalidState = errors.New("payroll period must be reviewed before finalize")
-	ErrFinalizeNoResults    = errors.New("payroll period has no payroll results")
+	ErrPeriodNotFound          = errors.New("payroll period not found")
+	ErrFinalizeInvalidState    = errors.New("payroll period must be approved before finalize")
+	ErrFinalizeNoResults       = errors.New("payroll period has no payroll results")
+	ErrRunFinalized            = errors.New("payroll period already finalized")
+	ErrRunIncomplete           = errors.New("payroll run incomplete")
+	ErrRunNotResumable         = errors.New("payroll run cannot be resumed")
+	ErrElementCycle            = errors.New("pay element dependencies form a cycle")
+	ErrInvalidExpression       = errors.New("invalid pay element expression")
+	ErrExpressionDivision      = errors.New("pay element expression divides by zero")
+	ErrSubmitInvalidState      = errors.New("payroll period must be reviewed before it is submitted for approval")
+	ErrApprovalInvalidState    = errors.New("payroll period is not awaiting approval")
+	ErrApprovalSameUser        = errors.New("payroll period must be approved by a user other than the one who ran or submitted it")
+	ErrReopenInvalidState      = errors.New("only finalized periods can be reopened")
+	ErrReopenHasOffCycle       = errors.New("period has finalized off-cycle runs and cannot be reopened")
+	ErrReopenHasRetro          = errors.New("period has been corrected by retro pay in a later finalized period and cannot be reopened")
+	ErrOffCycleOriginal        = errors.New("off-cycle runs must reference a finalized regular period")
+	ErrOffCycleEmployees       = errors.New("off-cycle run references unknown employees")
+	ErrJournalUnbalanced       = errors.New("payroll journal does not balance")
+	ErrJournalTemplateNotFound = errors.New("journal template not found")
+	ErrPaymentSettings         = errors.New("payment settings incomplete for format")
+	ErrPaymentRoutingNumber    = errors.New("payment settings routing number is not a valid ABA routing number")
+	ErrEmployeeNotFound        = errors.New("employee not found")
+	ErrRecurringNotFound       = errors.New("recurring payroll item not found")
+	ErrScheduleNotFound        = errors.New("pay schedule not found")
+	ErrScheduleFrequency       = errors.New("pay schedule frequency must be weekly, biweekly, semimonthly or monthly")
+	ErrExchangeRateMissing     = errors.New("no exchange rate to the reporting currency")
+	ErrExchangeRateNotFound    = errors.New("exchange rate not found")
 )
@@ -0,0 +1,317 @@
+package payroll
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxExpressionLength bounds the length of a formula element's expression.
+const maxExpressionLength = 500
+
+// Expression variables: the employee's base salary for the period, the
+// input's units, its rate (or the element's), its amount, and gross, the
+// base salary plus the earnings the element depends on.
+const (
+	ExpressionBase   = "base"
+	ExpressionUnits  = "units"
+	ExpressionRate   = "rate"
+	ExpressionAmount = "amount"
+	ExpressionGross  = "gross"
+)
+
+var expressionVariables = map[string]bool{
+	ExpressionBase:   true,
+	ExpressionUnits:  true,
+	ExpressionRate:   true,
+	ExpressionAmount: true,
+	ExpressionGross:  true,
+}
+
+// expressionFunctions maps the functions an expression may call to their
+// number of arguments.
+var expressionFunctions = map[string]int{
+	"min": 2,
+	"max": 2,
+}
+
+// formula is a parsed element expression. It only combines numbers and the
+// expression variables with + - * /, parentheses and min/max, so evaluating
+// it cannot reach anything outside the payroll run.
+type formula struct {
+	root expression
+	vars map[string]bool
+}
+
+type expression interface {
+	eval(vars map[string]float64) (float64, error)
+}
+
+type numberExpr float64
+
+type variableExpr string
+
+type negateExpr struct {
+	operand expression
+}
+
+type binaryExpr struct {
+	op          byte
+	left, right expression
+}
+
+type callExpr struct {
+	name string
+	args []expression
+}
+
+// ValidateExpression reports whether src is a valid formula expression.
+func ValidateExpression(src string) error {
+	_, err := parseFormula(src)
+	return err
+}
+
+func parseFormula(src string) (formula, error) {
+	if strings.TrimSpace(src) == "" {
+		return formula{}, fmt.Errorf("%w: expression is empty", ErrInvalidExpression)
+	}
+	if len(src) > maxExpressionLength {
+		return formula{}, fmt.Errorf("%w: expression is longer than %d characters", ErrInvalidExpression, maxExpressionLength)
+	}
+	p := &exprParser{src: src, vars: map[string]bool{}}
+	root, err := p.parseSum()
+	if err != nil {
+		return formula{}, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return formula{}, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return formula{root: root, vars: p.vars}, nil
+}
+
+func (f formula) uses(name string) bool {
+	return f.vars[name]
+}
+
+func (f formula) eval(vars map[string]float64) (float64, error) {
+	value, err := f.root.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("%w: result is not a finite number", ErrInvalidExpression)
+	}
+	return value, nil
+}
+
+type exprParser struct {
+	src  string
+	pos  int
+	vars map[string]bool
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidExpression, fmt.Sprintf(format, args...), p.pos+1)
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *exprParser) parseSum() (expression, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseProduct() (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (expression, error) {
+	if p.peek() == '-' {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negateExpr{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (expression, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, p.errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		inner, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return inner, nil
+	case isDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		text := p.src[start:p.pos]
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid number %q", text)
+		}
+		return numberExpr(value), nil
+	case isLetter(c):
+		start := p.pos
+		for p.pos < len(p.src) && (isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		name := strings.ToLower(p.src[start:p.pos])
+		if p.peek() == '(' {
+			return p.parseCall(name, start)
+		}
+		if !expressionVariables[name] {
+			p.pos = start
+			return nil, p.errorf("unknown variable %q", name)
+		}
+		p.vars[name] = true
+		return variableExpr(name), nil
+	}
+	return nil, p.errorf("unexpected %q", c)
+}
+
+func (p *exprParser) parseCall(name string, start int) (expression, error) {
+	arity, ok := expressionFunctions[name]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function %q", name)
+	}
+	p.pos++
+	var args []expression
+	for {
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if p.peek() != ')' {
+		return nil, p.errorf("missing )")
+	}
+	p.pos++
+	if len(args) != arity {
+		p.pos = start
+		return nil, p.errorf("%s takes %d arguments", name, arity)
+	}
+	return callExpr{name: name, args: args}, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
+
+func (e numberExpr) eval(map[string]float64) (float64, error) {
+	return float64(e), nil
+}
+
+func (e variableExpr) eval(vars map[string]float64) (float64, error) {
+	return vars[string(e)], nil
+}
+
+func (e negateExpr) eval(vars map[string]float64) (float64, error) {
+	value, err := e.operand.eval(vars)
+	return -value, err
+}
+
+func (e binaryExpr) eval(vars map[string]float64) (float64, error) {
+	left, err := e.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	right, err := e.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch e.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	}
+	if right == 0 {
+		return 0, ErrExpressionDivision
+	}
+	return left / right, nil
+}
+
+func (e callExpr) eval(vars map[string]float64) (float64, error) {
+	values := make([]float64, len(e.args))
+	for i, arg := range e.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = value
+	}
+	if e.name == "min" {
+		return math.Min(values[0], values[1]), nil
+	}
+	return math.Max(values[0], values[1]), nil
+}
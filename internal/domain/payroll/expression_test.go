@@ -0,0 +1,56 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFormulaEvaluatesWithPrecedence(t *testing.T) {
+	cases := map[string]float64{
+		"base + units * rate":      2000 + 10*3,
+		"(base + units) * rate":    (2000 + 10) * 3,
+		"-units + 2 * -rate":       -10 - 6,
+		"max(min(base, 500), 100)": 500,
+		"gross / 4 - .5":           1000 - 0.5,
+		"Base * 0.01":              20,
+	}
+	vars := map[string]float64{ExpressionBase: 2000, ExpressionUnits: 10, ExpressionRate: 3, ExpressionGross: 4000}
+	for src, want := range cases {
+		parsed, err := parseFormula(src)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", src, err)
+		}
+		got, err := parsed.eval(vars)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", src, err)
+		}
+		if got != want {
+			t.Fatalf("%q: expected %v, got %v", src, want, got)
+		}
+	}
+
+	parsed, err := parseFormula("base * 0.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.uses(ExpressionGross) || !parsed.uses(ExpressionBase) {
+		t.Fatalf("expected only base to be used, got %v", parsed.vars)
+	}
+}
+
+func TestValidateExpressionRejectsUnsafeInput(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"salary * 2",
+		"exec(1)",
+		"min(1)",
+		"base +",
+		"(base",
+		"base; units",
+		"1..2",
+	} {
+		if err := ValidateExpression(src); !errors.Is(err, ErrInvalidExpression) {
+			t.Fatalf("%q: expected invalid expression, got %v", src, err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package payroll
+
+import "fmt"
+
+// ElementInput is a period input joined with the configuration of the element
+// it belongs to, before any formula has been applied.
+type ElementInput struct {
+	ElementID   string
+	Name        string
+	ElementType string
+	CalcType    string
+	Taxable     bool
+	Amount      float64
+	Units       float64
+	Rate        float64
+	ElementRate float64
+	Tiers       []TaxBracket
+	DependsOn   []string
+	Expression  string
+}
+
+func (in ElementInput) rate() float64 {
+	if in.Rate > 0 {
+		return in.Rate
+	}
+	return in.ElementRate
+}
+
+// EvaluateElements applies each element's formula and returns one input line
+// per period input, in the order the inputs were given. Percentage-of-gross
+// and formula elements are evaluated after the earnings they depend on;
+// without explicit dependencies, percentage-of-gross elements and formulas
+// using gross depend on every other earning that is not itself gross-based.
+func EvaluateElements(baseSalary float64, inputs []ElementInput) ([]InputLine, error) {
+	byElement := map[string][]int{}
+	var elementIDs []string
+	formulas := map[string]formula{}
+	for i, input := range inputs {
+		if _, ok := byElement[input.ElementID]; !ok {
+			elementIDs = append(elementIDs, input.ElementID)
+			if input.CalcType == CalcTypeFormula {
+				parsed, err := parseFormula(input.Expression)
+				if err != nil {
+					return nil, fmt.Errorf("element %s: %w", input.Name, err)
+				}
+				formulas[input.ElementID] = parsed
+			}
+		}
+		byElement[input.ElementID] = append(byElement[input.ElementID], i)
+	}
+	grossBased := func(id string) bool {
+		element := inputs[byElement[id][0]]
+		return element.CalcType == CalcTypePctOfGross || (element.CalcType == CalcTypeFormula && formulas[id].uses(ExpressionGross))
+	}
+
+	deps := map[string][]string{}
+	for _, id := range elementIDs {
+		element := inputs[byElement[id][0]]
+		if element.CalcType != CalcTypePctOfGross && element.CalcType != CalcTypeFormula {
+			continue
+		}
+		if len(element.DependsOn) > 0 {
+			for _, dep := range element.DependsOn {
+				if _, ok := byElement[dep]; ok {
+					deps[id] = append(deps[id], dep)
+				}
+			}
+			continue
+		}
+		if !grossBased(id) {
+			continue
+		}
+		for _, other := range elementIDs {
+			candidate := inputs[byElement[other][0]]
+			if other != id && !grossBased(other) && candidate.ElementType == ElementTypeEarning {
+				deps[id] = append(deps[id], other)
+			}
+		}
+	}
+
+	order, err := orderElements(elementIDs, deps)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := make([]float64, len(inputs))
+	totals := map[string]float64{}
+	grossBasis := func(id string) float64 {
+		basis := baseSalary
+		for _, dep := range deps[id] {
+			if inputs[byElement[dep][0]].ElementType == ElementTypeEarning {
+				basis += totals[dep]
+			}
+		}
+		return basis
+	}
+	for _, id := range order {
+		for _, i := range byElement[id] {
+			input := inputs[i]
+			var amount float64
+			switch input.CalcType {
+			case CalcTypePctOfBase:
+				amount = baseSalary * input.rate()
+			case CalcTypeUnitsXRate:
+				amount = input.Units * input.rate()
+			case CalcTypePctOfGross:
+				amount = grossBasis(id) * input.rate()
+			case CalcTypeFormula:
+				value, err := formulas[id].eval(map[string]float64{
+					ExpressionBase:   baseSalary,
+					ExpressionUnits:  input.Units,
+					ExpressionRate:   input.rate(),
+					ExpressionAmount: input.Amount,
+					ExpressionGross:  grossBasis(id),
+				})
+				if err != nil {
+					return nil, fmt.Errorf("element %s: %w", input.Name, err)
+				}
+				amount = value
+			case CalcTypeTiered:
+				amount = progressiveTax(input.Units, input.Tiers)
+			default:
+				amount = input.Amount
+			}
+			amounts[i] = roundCents(amount)
+			totals[id] += amounts[i]
+		}
+	}
+
+	lines := make([]InputLine, 0, len(inputs))
+	for i, input := range inputs {
+		lines = append(lines, InputLine{
+			Type:        input.ElementType,
+			Amount:      amounts[i],
+			Taxable:     input.Taxable && input.ElementType == ElementTypeEarning,
+			Source:      ResultSourceElement,
+			SourceID:    input.ElementID,
+			Code:        input.Name,
+			Description: input.Name,
+		})
+	}
+	return lines, nil
+}
+
+// orderElements sorts element IDs so every element follows its dependencies,
+// keeping the original order where there is no constraint.
+func orderElements(ids []string, deps map[string][]string) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	order := make([]string, 0, len(ids))
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return ErrElementCycle
+		case done:
+			return nil
+		}
+		state[id] = visiting
+		for _, dep := range deps[id] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[id] = done
+		order = append(order, id)
+		return nil
+	}
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,93 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvaluateElementsFormulas(t *testing.T) {
+	inputs := []ElementInput{
+		{ElementID: "fixed", Name: "Allowance", ElementType: ElementTypeEarning, CalcType: CalcTypeFixed, Amount: 100, Taxable: true},
+		{ElementID: "pension", Name: "Pension", ElementType: ElementTypeDeduction, CalcType: CalcTypePctOfBase, ElementRate: 0.05},
+		{ElementID: "overtime", Name: "Overtime", ElementType: ElementTypeEarning, CalcType: CalcTypeUnitsXRate, Units: 10, ElementRate: 25, Rate: 30},
+		{ElementID: "commission", Name: "Commission", ElementType: ElementTypeEarning, CalcType: CalcTypeTiered, Units: 15000, Tiers: []TaxBracket{
+			{UpTo: floatPtr(10000), Rate: 0.02},
+			{Rate: 0.05},
+		}},
+	}
+
+	lines, err := EvaluateElements(2000, inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []float64{100, 100, 300, 450}
+	for i, line := range lines {
+		if line.Amount != want[i] {
+			t.Fatalf("line %d (%s): expected %v, got %v", i, line.Code, want[i], line.Amount)
+		}
+	}
+	if !lines[0].Taxable || lines[1].Taxable {
+		t.Fatalf("expected only taxable earnings to be flagged, got %+v", lines)
+	}
+}
+
+func TestEvaluateElementsPctOfGrossFollowsDependencies(t *testing.T) {
+	inputs := []ElementInput{
+		{ElementID: "holiday", Name: "Holiday pay", ElementType: ElementTypeEarning, CalcType: CalcTypePctOfGross, ElementRate: 0.1},
+		{ElementID: "overtime", Name: "Overtime", ElementType: ElementTypeEarning, CalcType: CalcTypeUnitsXRate, Units: 4, Rate: 50},
+		{ElementID: "bonus", Name: "Bonus", ElementType: ElementTypeEarning, CalcType: CalcTypeFixed, Amount: 300},
+		{ElementID: "union", Name: "Union", ElementType: ElementTypeDeduction, CalcType: CalcTypePctOfGross, ElementRate: 0.01, DependsOn: []string{"holiday"}},
+	}
+
+	lines, err := EvaluateElements(1000, inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines[0].Amount != 150 {
+		t.Fatalf("expected holiday pay on base+overtime+bonus to be 150, got %v", lines[0].Amount)
+	}
+	if lines[3].Amount != 11.5 {
+		t.Fatalf("expected union dues on base+holiday pay to be 11.5, got %v", lines[3].Amount)
+	}
+}
+
+func TestEvaluateElementsDetectsCycles(t *testing.T) {
+	inputs := []ElementInput{
+		{ElementID: "a", ElementType: ElementTypeEarning, CalcType: CalcTypePctOfGross, DependsOn: []string{"b"}},
+		{ElementID: "b", ElementType: ElementTypeEarning, CalcType: CalcTypePctOfGross, DependsOn: []string{"a"}},
+	}
+	if _, err := EvaluateElements(1000, inputs); !errors.Is(err, ErrElementCycle) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+func TestEvaluateElementsExpressionFormulas(t *testing.T) {
+	inputs := []ElementInput{
+		{ElementID: "cap", Name: "Capped commission", ElementType: ElementTypeEarning, CalcType: CalcTypeFormula, Expression: "min(gross * 0.1, 250)", DependsOn: []string{"sales"}},
+		{ElementID: "sales", Name: "Sales bonus", ElementType: ElementTypeEarning, CalcType: CalcTypeFixed, Amount: 500},
+		{ElementID: "overtime", Name: "Overtime", ElementType: ElementTypeEarning, CalcType: CalcTypeFormula, Expression: "units * rate * 1.5", Units: 4, ElementRate: 20},
+		{ElementID: "holiday", Name: "Holiday pay", ElementType: ElementTypeEarning, CalcType: CalcTypeFormula, Expression: "(gross - base) / 10"},
+	}
+
+	lines, err := EvaluateElements(2000, inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines[0].Amount != 250 {
+		t.Fatalf("expected commission capped at 250, got %v", lines[0].Amount)
+	}
+	if lines[2].Amount != 120 {
+		t.Fatalf("expected overtime at time and a half, got %v", lines[2].Amount)
+	}
+	// Holiday pay uses gross without explicit dependencies, so it follows
+	// every earning that does not itself use gross: the sales bonus and
+	// overtime.
+	if lines[3].Amount != 62 {
+		t.Fatalf("expected holiday pay on the bonus and overtime, got %v", lines[3].Amount)
+	}
+
+	inputs = []ElementInput{{ElementID: "bad", Name: "Bad", ElementType: ElementTypeEarning, CalcType: CalcTypeFormula, Expression: "amount / units"}}
+	if _, err := EvaluateElements(1000, inputs); !errors.Is(err, ErrExpressionDivision) {
+		t.Fatalf("expected division by zero error, got %v", err)
+	}
+}
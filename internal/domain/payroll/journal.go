@@ -0,0 +1,292 @@
+package payroll
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// JournalConfig is the typed view of a journal template's config_json. Pay
+// lines are posted to Accounts[sourceID] or Accounts[code] when mapped and to
+// the default account for their line type otherwise.
+type JournalConfig struct {
+	Format                   string                       `json:"format,omitempty"`
+	ExpenseAccount           string                       `json:"expenseAccount,omitempty"`
+	DeductionAccount         string                       `json:"deductionAccount,omitempty"`
+	CashAccount              string                       `json:"cashAccount,omitempty"`
+	EmployerExpenseAccount   string                       `json:"employerExpenseAccount,omitempty"`
+	EmployerLiabilityAccount string                       `json:"employerLiabilityAccount,omitempty"`
+	DefaultCostCentre        string                       `json:"defaultCostCentre,omitempty"`
+	Accounts                 map[string]string            `json:"accounts,omitempty"`
+	Departments              map[string]JournalDepartment `json:"departments,omitempty"`
+	Headers                  []string                     `json:"headers,omitempty"`
+}
+
+type JournalDepartment struct {
+	CostCentre     string `json:"costCentre,omitempty"`
+	ExpenseAccount string `json:"expenseAccount,omitempty"`
+}
+
+type JournalLine struct {
+	Account    string  `json:"account"`
+	CostCentre string  `json:"costCentre,omitempty"`
+	Debit      float64 `json:"debit"`
+	Credit     float64 `json:"credit"`
+	Memo       string  `json:"memo"`
+}
+
+type Journal struct {
+	PeriodID    string        `json:"periodId"`
+	Date        time.Time     `json:"date"`
+	Currency    string        `json:"currency,omitempty"`
+	Lines       []JournalLine `json:"lines"`
+	TotalDebit  float64       `json:"totalDebit"`
+	TotalCredit float64       `json:"totalCredit"`
+}
+
+// JournalEmployee carries an employee's result lines together with the
+// department used to pick cost centres and expense accounts and the currency
+// the lines are in.
+type JournalEmployee struct {
+	EmployeeID   string
+	DepartmentID string
+	Currency     string
+	Gross        float64
+	Deductions   float64
+	Lines        []ResultLine
+}
+
+type JournalExport struct {
+	ID          string    `json:"id"`
+	PeriodID    string    `json:"periodId"`
+	TemplateID  string    `json:"templateId,omitempty"`
+	Format      string    `json:"format"`
+	Currency    string    `json:"currency,omitempty"`
+	TotalDebit  float64   `json:"totalDebit"`
+	TotalCredit float64   `json:"totalCredit"`
+	LineCount   int       `json:"lineCount"`
+	ExportedBy  string    `json:"exportedBy,omitempty"`
+	ExportedAt  time.Time `json:"exportedAt"`
+}
+
+const (
+	defaultExpenseAccount   = "Payroll Expense"
+	defaultDeductionAccount = "Payroll Deductions"
+	defaultCashAccount      = "Payroll Cash"
+)
+
+// ParseJournalConfig decodes a template config, filling in the default
+// accounts the export used before templates could map individual lines.
+func ParseJournalConfig(raw map[string]any) (JournalConfig, error) {
+	var cfg JournalConfig
+	if len(raw) > 0 {
+		encoded, err := json.Marshal(raw)
+		if err != nil {
+			return cfg, err
+		}
+		if err := json.Unmarshal(encoded, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+	if cfg.Format == "" {
+		cfg.Format = JournalFormatCSV
+	}
+	if cfg.ExpenseAccount == "" {
+		cfg.ExpenseAccount = defaultExpenseAccount
+	}
+	if cfg.DeductionAccount == "" {
+		cfg.DeductionAccount = defaultDeductionAccount
+	}
+	if cfg.CashAccount == "" {
+		cfg.CashAccount = defaultCashAccount
+	}
+	if cfg.EmployerExpenseAccount == "" {
+		cfg.EmployerExpenseAccount = cfg.ExpenseAccount
+	}
+	if cfg.EmployerLiabilityAccount == "" {
+		cfg.EmployerLiabilityAccount = cfg.DeductionAccount
+	}
+	return cfg, nil
+}
+
+// BuildJournal posts every result line as balanced double entry: earnings are
+// debited to expense, deductions credited to liabilities, employer
+// contributions debited to expense and credited to a liability, and the net
+// difference credited to cash. Identical postings are merged.
+func BuildJournal(cfg JournalConfig, periodID string, date time.Time, employees []JournalEmployee) (Journal, error) {
+	journal := Journal{PeriodID: periodID, Date: date, Lines: []JournalLine{}}
+	index := map[string]int{}
+	post := func(account, costCentre, memo string, debit, credit float64) {
+		if debit < 0 || credit < 0 {
+			debit, credit = -credit, -debit
+		}
+		if debit == 0 && credit == 0 {
+			return
+		}
+		side := "D"
+		if credit != 0 {
+			side = "C"
+		}
+		key := account + "\x00" + costCentre + "\x00" + memo + "\x00" + side
+		if i, ok := index[key]; ok {
+			journal.Lines[i].Debit += debit
+			journal.Lines[i].Credit += credit
+			return
+		}
+		index[key] = len(journal.Lines)
+		journal.Lines = append(journal.Lines, JournalLine{Account: account, CostCentre: costCentre, Debit: debit, Credit: credit, Memo: memo})
+	}
+
+	for _, employee := range employees {
+		department := cfg.Departments[employee.DepartmentID]
+		costCentre := department.CostCentre
+		if costCentre == "" {
+			costCentre = cfg.DefaultCostCentre
+		}
+		expenseAccount := department.ExpenseAccount
+		if expenseAccount == "" {
+			expenseAccount = cfg.ExpenseAccount
+		}
+
+		net := 0.0
+		for _, line := range employee.Lines {
+			memo := line.Description
+			if line.Source == ResultSourceAdjustment {
+				memo = "Adjustments"
+			}
+			switch line.LineType {
+			case ResultLineEarning:
+				post(cfg.lineAccount(line, expenseAccount), costCentre, memo, line.Amount, 0)
+				net += line.Amount
+			case ResultLineDeduction:
+				post(cfg.lineAccount(line, cfg.DeductionAccount), "", memo, 0, line.Amount)
+				net -= line.Amount
+			case ResultLineEmployerContribution:
+				post(cfg.EmployerExpenseAccount, costCentre, memo+" (employer)", line.Amount, 0)
+				post(cfg.lineAccount(line, cfg.EmployerLiabilityAccount), "", memo+" (employer)", 0, line.Amount)
+			}
+		}
+		post(cfg.CashAccount, "", "Net pay", 0, net)
+	}
+
+	for i := range journal.Lines {
+		journal.Lines[i].Debit = roundCents(journal.Lines[i].Debit)
+		journal.Lines[i].Credit = roundCents(journal.Lines[i].Credit)
+		journal.TotalDebit += journal.Lines[i].Debit
+		journal.TotalCredit += journal.Lines[i].Credit
+	}
+	journal.TotalDebit = roundCents(journal.TotalDebit)
+	journal.TotalCredit = roundCents(journal.TotalCredit)
+	if math.Abs(journal.TotalDebit-journal.TotalCredit) >= 0.005 {
+		return journal, fmt.Errorf("%w: debit %.2f, credit %.2f", ErrJournalUnbalanced, journal.TotalDebit, journal.TotalCredit)
+	}
+	return journal, nil
+}
+
+func (cfg JournalConfig) lineAccount(line ResultLine, fallback string) string {
+	if account := cfg.Accounts[line.SourceID]; line.SourceID != "" && account != "" {
+		return account
+	}
+	if account := cfg.Accounts[line.Code]; account != "" {
+		return account
+	}
+	return fallback
+}
+
+// JournalTable renders the journal as rows for the generic CSV or the Xero
+// manual journal import format.
+func JournalTable(journal Journal, format string, headers []string) ([]string, [][]string) {
+	date := journal.Date.Format("2006-01-02")
+	if format == JournalFormatXero {
+		narration := "Payroll " + date
+		records := make([][]string, 0, len(journal.Lines))
+		for _, line := range journal.Lines {
+			amount := line.Debit - line.Credit
+			trackingName := ""
+			if line.CostCentre != "" {
+				trackingName = "Cost Centre"
+			}
+			records = append(records, []string{narration, date, line.Memo, line.Account, "Tax Exempt", fmt.Sprintf("%.2f", amount), trackingName, line.CostCentre})
+		}
+		return []string{"*Narration", "*Date", "Description", "*AccountCode", "*TaxRate", "*Amount", "TrackingName1", "TrackingOption1"}, records
+	}
+
+	columns := []string{"date", "account", "cost_centre", "debit", "credit", "memo"}
+	if len(headers) == len(columns) {
+		columns = headers
+	}
+	records := make([][]string, 0, len(journal.Lines))
+	for _, line := range journal.Lines {
+		debit, credit := "", ""
+		if line.Debit != 0 {
+			debit = fmt.Sprintf("%.2f", line.Debit)
+		}
+		if line.Credit != 0 {
+			credit = fmt.Sprintf("%.2f", line.Credit)
+		}
+		records = append(records, []string{date, line.Account, line.CostCentre, debit, credit, line.Memo})
+	}
+	return columns, records
+}
+
+// PeriodJournal builds the journal for a period using the given template, or
+// the default accounts when templateID is empty. Every line is converted to
+// the reporting currency at the rates in force on the journal date, so a
+// period paid in several currencies posts as one balanced journal.
+func (s *Service) PeriodJournal(ctx context.Context, tenantID, periodID, templateID string) (Journal, JournalConfig, error) {
+	raw := map[string]any{}
+	if templateID != "" {
+		var err error
+		raw, err = s.JournalTemplateConfig(ctx, tenantID, templateID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Journal{}, JournalConfig{}, ErrJournalTemplateNotFound
+		}
+		if err != nil {
+			return Journal{}, JournalConfig{}, err
+		}
+	}
+	cfg, err := ParseJournalConfig(raw)
+	if err != nil {
+		return Journal{}, cfg, err
+	}
+
+	period, err := s.store.GetPeriodDetails(ctx, tenantID, periodID)
+	if err != nil {
+		return Journal{}, cfg, err
+	}
+	employees, err := s.store.JournalEmployees(ctx, tenantID, periodID)
+	if err != nil {
+		return Journal{}, cfg, err
+	}
+	lines, err := s.store.ListPeriodResultLines(ctx, tenantID, periodID)
+	if err != nil {
+		return Journal{}, cfg, err
+	}
+	converter, err := s.Converter(ctx, tenantID, period.EndDate)
+	if err != nil {
+		return Journal{}, cfg, err
+	}
+	for i := range employees {
+		employees[i].Lines = lines[employees[i].EmployeeID]
+		if len(employees[i].Lines) == 0 {
+			// Results calculated before itemisation only carry totals.
+			employees[i].Lines = []ResultLine{
+				{LineType: ResultLineEarning, Code: "gross", Description: "Gross pay", Amount: employees[i].Gross},
+				{LineType: ResultLineDeduction, Code: "deductions", Description: "Deductions", Amount: employees[i].Deductions},
+			}
+		}
+		rate, err := converter.Rate(employees[i].Currency)
+		if err != nil {
+			return Journal{}, cfg, err
+		}
+		employees[i].Lines = convertLines(employees[i].Lines, rate)
+	}
+	journal, err := BuildJournal(cfg, periodID, period.EndDate, employees)
+	journal.Currency = converter.Currency
+	return journal, cfg, err
+}
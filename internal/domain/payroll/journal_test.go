@@ -0,0 +1,69 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildJournalBalancesWithCostCentres(t *testing.T) {
+	cfg, err := ParseJournalConfig(map[string]any{
+		"accounts": map[string]any{"bonus": "6010", "income_tax": "2110"},
+		"departments": map[string]any{
+			"d-eng": map[string]any{"costCentre": "ENG", "expenseAccount": "6100"},
+		},
+		"defaultCostCentre": "GEN",
+	})
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	employees := []JournalEmployee{
+		{EmployeeID: "e1", DepartmentID: "d-eng", Lines: []ResultLine{
+			{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 3000},
+			{LineType: ResultLineEarning, Code: "bonus", Description: "Bonus", Amount: 500},
+			{LineType: ResultLineDeduction, Code: "income_tax", Description: "Income tax", Amount: 700},
+			{LineType: ResultLineEmployerContribution, Code: "pension", Description: "Pension", Amount: 150},
+		}},
+		{EmployeeID: "e2", Lines: []ResultLine{
+			{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 2000},
+			{LineType: ResultLineDeduction, Code: "income_tax", Description: "Income tax", Amount: 300},
+		}},
+	}
+
+	journal, err := BuildJournal(cfg, "p1", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), employees)
+	if err != nil {
+		t.Fatalf("build journal: %v", err)
+	}
+	if journal.TotalDebit != 5650 || journal.TotalCredit != 5650 {
+		t.Fatalf("expected balanced 5650 totals, got %.2f / %.2f", journal.TotalDebit, journal.TotalCredit)
+	}
+
+	find := func(account, costCentre string) JournalLine {
+		for _, line := range journal.Lines {
+			if line.Account == account && line.CostCentre == costCentre {
+				return line
+			}
+		}
+		t.Fatalf("missing posting %s/%s in %+v", account, costCentre, journal.Lines)
+		return JournalLine{}
+	}
+	if line := find("6100", "ENG"); line.Debit != 3000 {
+		t.Fatalf("expected department expense account debit, got %+v", line)
+	}
+	if line := find("6010", "ENG"); line.Debit != 500 {
+		t.Fatalf("expected mapped bonus account debit, got %+v", line)
+	}
+	if line := find(defaultExpenseAccount, "GEN"); line.Debit != 2000 {
+		t.Fatalf("expected default cost centre for unmapped department, got %+v", line)
+	}
+	if line := find("2110", ""); line.Credit != 1000 {
+		t.Fatalf("expected merged income tax credit, got %+v", line)
+	}
+	if line := find(defaultCashAccount, ""); line.Credit != 4500 {
+		t.Fatalf("expected net pay credit of 4500, got %+v", line)
+	}
+
+	headers, records := JournalTable(journal, JournalFormatXero, nil)
+	if headers[3] != "*AccountCode" || len(records) != len(journal.Lines) {
+		t.Fatalf("unexpected xero table %v with %d records", headers, len(records))
+	}
+}
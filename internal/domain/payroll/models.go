@@ -3,11 +3,12 @@ package payroll
 import "time"
 
 type Schedule struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Frequency string    `json:"frequency"`
-	PayDay    int       `json:"payDay"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Frequency     string    `json:"frequency"`
+	PayDay        int       `json:"payDay"`
+	HolidayRegion string    `json:"holidayRegion,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
 }
 
 type Group struct {
@@ -18,12 +19,16 @@ type Group struct {
 }
 
 type Element struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	ElementType string  `json:"elementType"`
-	CalcType    string  `json:"calcType"`
-	Amount      float64 `json:"amount"`
-	Taxable     bool    `json:"taxable"`
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	ElementType string       `json:"elementType"`
+	CalcType    string       `json:"calcType"`
+	Amount      float64      `json:"amount"`
+	Taxable     bool         `json:"taxable"`
+	Rate        float64      `json:"rate"`
+	Tiers       []TaxBracket `json:"tiers"`
+	DependsOn   []string     `json:"dependsOn"`
+	Expression  string       `json:"expression,omitempty"`
 }
 
 type JournalTemplate struct {
@@ -33,11 +38,23 @@ type JournalTemplate struct {
 }
 
 type Period struct {
-	ID         string    `json:"id"`
-	ScheduleID string    `json:"scheduleId"`
-	StartDate  time.Time `json:"startDate"`
-	EndDate    time.Time `json:"endDate"`
-	Status     string    `json:"status"`
+	ID               string     `json:"id"`
+	ScheduleID       string     `json:"scheduleId"`
+	StartDate        time.Time  `json:"startDate"`
+	EndDate          time.Time  `json:"endDate"`
+	Status           string     `json:"status"`
+	RunType          string     `json:"runType"`
+	OriginalPeriodID string     `json:"originalPeriodId,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+	PayDate          *time.Time `json:"payDate,omitempty"`
+	RunBy            string     `json:"runBy,omitempty"`
+	ResumedBy        string     `json:"resumedBy,omitempty"`
+	SubmittedBy      string     `json:"submittedBy,omitempty"`
+	SubmittedAt      *time.Time `json:"submittedAt,omitempty"`
+	DecidedBy        string     `json:"decidedBy,omitempty"`
+	DecidedAt        *time.Time `json:"decidedAt,omitempty"`
+	Decision         string     `json:"decision,omitempty"`
+	DecisionComment  string     `json:"decisionComment,omitempty"`
 }
 
 type Input struct {
@@ -83,31 +100,79 @@ type RegisterRow struct {
 	Deductions float64
 	Net        float64
 	Currency   string
+	Lines      []ResultLine
 }
 
+// PeriodSummary totals a period's results in the reporting currency and
+// breaks them down by the currency each employee was paid in.
 type PeriodSummary struct {
-	TotalGross      float64        `json:"totalGross"`
-	TotalDeductions float64        `json:"totalDeductions"`
-	TotalNet        float64        `json:"totalNet"`
-	EmployeeCount   int            `json:"employeeCount"`
-	Warnings        map[string]int `json:"warnings"`
+	ReportingCurrency string           `json:"reportingCurrency"`
+	TotalGross        float64          `json:"totalGross"`
+	TotalDeductions   float64          `json:"totalDeductions"`
+	TotalNet          float64          `json:"totalNet"`
+	EmployeeCount     int              `json:"employeeCount"`
+	ByCurrency        []CurrencyTotals `json:"byCurrency"`
+	Warnings          map[string]int   `json:"warnings"`
 }
 
 type PeriodDetails struct {
-	Status     string
-	StartDate  time.Time
-	EndDate    time.Time
-	ScheduleID string
+	Status           string
+	StartDate        time.Time
+	EndDate          time.Time
+	ScheduleID       string
+	RunType          string
+	OriginalPeriodID string
 }
 
 type EmployeePayrollData struct {
 	EmployeeID      string
+	FirstName       string
+	LastName        string
 	SalaryPlain     *float64
 	SalaryEnc       []byte
 	Currency        string
 	BankPlain       string
 	BankEnc         []byte
 	GroupScheduleID string
+	StartDate       *time.Time
+	EndDate         *time.Time
+	Compensation    []Compensation
+}
+
+type ResultLine struct {
+	LineType    string  `json:"lineType"`
+	Source      string  `json:"source"`
+	SourceID    string  `json:"sourceId,omitempty"`
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Base        float64 `json:"base"`
+	Amount      float64 `json:"amount"`
+}
+
+// ResultRecord is what a payroll run stores for one employee. Retro items are
+// only replaced when ReplaceRetro is set, which off-cycle runs leave unset.
+type ResultRecord struct {
+	EmployeeID   string
+	Gross        float64
+	TaxableGross float64
+	Deductions   float64
+	Net          float64
+	Currency     string
+	WarningsJSON []byte
+	Lines        []ResultLine
+	Retro        []RetroItem
+	ReplaceRetro bool
+}
+
+type EmployeeResult struct {
+	PeriodID   string       `json:"periodId"`
+	EmployeeID string       `json:"employeeId"`
+	Gross      float64      `json:"gross"`
+	Deductions float64      `json:"deductions"`
+	Net        float64      `json:"net"`
+	Currency   string       `json:"currency"`
+	Warnings   []string     `json:"warnings"`
+	Lines      []ResultLine `json:"lines"`
 }
 
 type LeaveWindow struct {
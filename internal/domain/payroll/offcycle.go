@@ -0,0 +1,114 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OffCycleRequest describes a bonus, termination or correction run paid
+// against a finalized regular period without reopening it.
+type OffCycleRequest struct {
+	RunType          string
+	OriginalPeriodID string
+	Reason           string
+	PayDate          *time.Time
+	EmployeeIDs      []string
+}
+
+// OffCycle reports whether the period is an off-cycle run. Periods created
+// before run types existed are regular.
+func (p PeriodDetails) OffCycle() bool {
+	return p.RunType != "" && p.RunType != PeriodRunRegular
+}
+
+// CreateOffCyclePeriod opens a draft off-cycle period that shares the
+// original period's schedule and dates and covers only the given employees.
+func (s *Service) CreateOffCyclePeriod(ctx context.Context, tenantID string, request OffCycleRequest) (string, error) {
+	original, err := s.store.GetPeriodDetails(ctx, tenantID, request.OriginalPeriodID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrPeriodNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if original.OffCycle() || original.Status != PeriodStatusFinalized {
+		return "", ErrOffCycleOriginal
+	}
+	return s.store.CreateOffCyclePeriod(ctx, tenantID, original, request)
+}
+
+// CalculateOffCycle pays only the off-cycle inputs. baseTaxable is what the
+// employee was already taxed on for the original period; taxes are the
+// difference between the taxes due on baseTaxable plus the new taxable pay and
+// on baseTaxable alone, so a bonus is taxed at the marginal rate and a
+// correction can produce negative delta lines.
+func CalculateOffCycle(baseTaxable float64, inputs []InputLine, taxRules []TaxRule) Calculation {
+	calc := Calculation{TaxableGross: TaxableGross(baseTaxable, inputs) - baseTaxable}
+	calc.Taxes = deltaTaxes(ComputeTaxes(taxRules, baseTaxable), ComputeTaxes(taxRules, baseTaxable+calc.TaxableGross))
+
+	all := make([]InputLine, 0, len(inputs)+len(calc.Taxes))
+	all = append(all, inputs...)
+	for _, tax := range calc.Taxes {
+		if tax.Employee != 0 {
+			all = append(all, InputLine{
+				Type:        ElementTypeDeduction,
+				Amount:      tax.Employee,
+				Source:      ResultSourceTax,
+				SourceID:    tax.RuleID,
+				Code:        tax.Code,
+				Description: tax.Name,
+			})
+		}
+	}
+	calc.Gross, calc.Deductions, calc.Net = ComputePayroll(0, all)
+
+	calc.Lines = ResultLines(0, inputs)
+	for _, tax := range calc.Taxes {
+		if tax.Employee != 0 {
+			calc.Lines = append(calc.Lines, ResultLine{
+				LineType: ResultLineDeduction, Source: ResultSourceTax, SourceID: tax.RuleID,
+				Code: tax.Code, Description: tax.Name, Base: tax.Base, Amount: tax.Employee,
+			})
+		}
+		if tax.Employer != 0 {
+			calc.Lines = append(calc.Lines, ResultLine{
+				LineType: ResultLineEmployerContribution, Source: ResultSourceTax, SourceID: tax.RuleID,
+				Code: tax.Code, Description: tax.Name, Base: tax.Base, Amount: tax.Employer,
+			})
+		}
+	}
+	return calc
+}
+
+// deltaTaxes subtracts before from after per rule, keeping rules that only
+// appear on one side.
+func deltaTaxes(before, after []TaxLine) []TaxLine {
+	previous := make(map[string]TaxLine, len(before))
+	for _, line := range before {
+		previous[line.RuleID+":"+line.Code] = line
+	}
+
+	out := make([]TaxLine, 0, len(after))
+	for _, line := range after {
+		key := line.RuleID + ":" + line.Code
+		prior := previous[key]
+		delete(previous, key)
+		line.Base = roundCents(line.Base - prior.Base)
+		line.Employee = roundCents(line.Employee - prior.Employee)
+		line.Employer = roundCents(line.Employer - prior.Employer)
+		if line.Employee != 0 || line.Employer != 0 {
+			out = append(out, line)
+		}
+	}
+	for _, line := range before {
+		if _, ok := previous[line.RuleID+":"+line.Code]; !ok {
+			continue
+		}
+		line.Base, line.Employee, line.Employer = -line.Base, -line.Employee, -line.Employer
+		out = append(out, line)
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package payroll
+
+import "testing"
+
+func offCycleRules() []TaxRule {
+	limit := 3000.0
+	return []TaxRule{
+		{ID: "it", Code: "income_tax", Name: "Income tax", Kind: TaxKindIncomeTax, Brackets: []TaxBracket{{UpTo: &limit, Rate: 0.1}, {Rate: 0.4}}},
+		{ID: "ss", Code: "social", Name: "Social security", Kind: TaxKindSocialContribution, EmployeeRate: 0.05, EmployerRate: 0.1},
+	}
+}
+
+func TestCalculateOffCycleTaxesBonusAtMarginalRate(t *testing.T) {
+	bonus := []InputLine{{Type: ElementTypeEarning, Amount: 1000, Taxable: true, Code: "bonus", Description: "Bonus"}}
+
+	calc := CalculateOffCycle(3000, bonus, offCycleRules())
+	if calc.Gross != 1000 || calc.TaxableGross != 1000 {
+		t.Fatalf("expected only the bonus as gross, got %+v", calc)
+	}
+	// 1000 above the 3000 bracket at 40% plus 5% social security.
+	if calc.Deductions != 450 || calc.Net != 550 {
+		t.Fatalf("expected 450 deductions and 550 net, got %.2f / %.2f", calc.Deductions, calc.Net)
+	}
+	for _, line := range calc.Lines {
+		if line.Code == ResultCodeBaseSalary {
+			t.Fatal("off-cycle runs must not pay base salary")
+		}
+		if line.LineType == ResultLineEmployerContribution && line.Amount != 100 {
+			t.Fatalf("expected employer delta of 100, got %+v", line)
+		}
+	}
+}
+
+func TestCalculateOffCycleCorrectionProducesNegativeDelta(t *testing.T) {
+	recovery := []InputLine{{Type: ElementTypeDeduction, Amount: 500, Taxable: true, Code: "overpayment", Description: "Overpayment recovery"}}
+
+	calc := CalculateOffCycle(4000, recovery, offCycleRules())
+	if calc.TaxableGross != -500 {
+		t.Fatalf("expected taxable gross to drop by 500, got %.2f", calc.TaxableGross)
+	}
+	// Taxes fall by 200 income tax and 25 social security.
+	if calc.Deductions != 275 || calc.Net != -275 {
+		t.Fatalf("expected 275 deductions and -275 net, got %.2f / %.2f", calc.Deductions, calc.Net)
+	}
+	var taxDelta float64
+	for _, line := range calc.Lines {
+		if line.Source == ResultSourceTax && line.LineType == ResultLineDeduction {
+			taxDelta += line.Amount
+		}
+	}
+	if taxDelta != -225 {
+		t.Fatalf("expected tax lines to refund 225, got %.2f", taxDelta)
+	}
+}
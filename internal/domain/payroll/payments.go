@@ -0,0 +1,434 @@
+package payroll
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type PaymentSettings struct {
+	DebtorName      string `json:"debtorName"`
+	DebtorIBAN      string `json:"debtorIban"`
+	DebtorBIC       string `json:"debtorBic"`
+	CompanyID       string `json:"companyId"`
+	RoutingNumber   string `json:"routingNumber"`
+	AccountNumber   string `json:"accountNumber"`
+	DestinationName string `json:"destinationName"`
+
+	DebtorIBANEnc    []byte `json:"-"`
+	AccountNumberEnc []byte `json:"-"`
+}
+
+// Masked returns the settings with the debtor IBAN and account number reduced
+// to their last four characters, for API responses, audit records and logs.
+func (p PaymentSettings) Masked() PaymentSettings {
+	p.DebtorIBAN = MaskAccount(p.DebtorIBAN)
+	p.AccountNumber = MaskAccount(p.AccountNumber)
+	p.DebtorIBANEnc = nil
+	p.AccountNumberEnc = nil
+	return p
+}
+
+// MaskAccount hides all but the last four characters of an account number.
+func MaskAccount(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
+
+type Payment struct {
+	EmployeeID string
+	Name       string
+	Amount     float64
+	Currency   string
+	IBAN       string
+	Routing    string
+	Account    string
+}
+
+type PaymentException struct {
+	EmployeeID string  `json:"employeeId"`
+	Name       string  `json:"name"`
+	Net        float64 `json:"net"`
+	Reason     string  `json:"reason"`
+}
+
+type PaymentBatch struct {
+	Payments   []Payment
+	Exceptions []PaymentException
+}
+
+type PaymentRow struct {
+	EmployeeID string
+	FirstName  string
+	LastName   string
+	Net        float64
+	Currency   string
+	BankPlain  string
+	BankEnc    []byte
+}
+
+// MissingFields lists the settings a payment file in the given format needs
+// but the tenant has not configured yet.
+func (p PaymentSettings) MissingFields(format string) []string {
+	var missing []string
+	if strings.TrimSpace(p.DebtorName) == "" {
+		missing = append(missing, "debtorName")
+	}
+	switch format {
+	case PaymentFormatSEPA:
+		if p.DebtorIBAN == "" {
+			missing = append(missing, "debtorIban")
+		}
+	case PaymentFormatNACHA:
+		if p.CompanyID == "" {
+			missing = append(missing, "companyId")
+		}
+		if p.RoutingNumber == "" {
+			missing = append(missing, "routingNumber")
+		}
+		if p.AccountNumber == "" {
+			missing = append(missing, "accountNumber")
+		}
+	}
+	return missing
+}
+
+var (
+	bicPattern       = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	accountPattern   = regexp.MustCompile(`^[A-Za-z0-9]{1,17}$`)
+	usAccountPattern = regexp.MustCompile(`^(\d{9})\s*[:/ ]\s*([A-Za-z0-9]{1,17})$`)
+)
+
+// PaymentBatch splits a finalized period's net pay into payable entries and
+// exceptions explaining why an employee could not be included.
+func (s *Service) PaymentBatch(ctx context.Context, tenantID, periodID, format string) (PaymentBatch, error) {
+	rows, err := s.store.PaymentRows(ctx, tenantID, periodID)
+	if err != nil {
+		return PaymentBatch{}, err
+	}
+
+	batch := PaymentBatch{Payments: []Payment{}, Exceptions: []PaymentException{}}
+	for _, row := range rows {
+		name := strings.TrimSpace(row.FirstName + " " + row.LastName)
+		bank := strings.TrimSpace(s.decryptOptional(row.BankPlain, row.BankEnc))
+		payment := Payment{EmployeeID: row.EmployeeID, Name: name, Amount: roundCents(row.Net), Currency: row.Currency}
+		reason := ""
+		switch {
+		case bank == "":
+			reason = WarningMissingBank
+		case payment.Amount <= 0:
+			reason = PaymentIssueNonPositiveNet
+		case format == PaymentFormatSEPA:
+			payment.IBAN = NormalizeIBAN(bank)
+			if !strings.EqualFold(row.Currency, "EUR") {
+				reason = PaymentIssueCurrency
+			} else if !ValidIBAN(payment.IBAN) {
+				reason = PaymentIssueInvalidIBAN
+			}
+		case format == PaymentFormatNACHA:
+			routing, account, ok := ParseUSAccount(bank)
+			payment.Routing, payment.Account = routing, account
+			if !strings.EqualFold(row.Currency, "USD") {
+				reason = PaymentIssueCurrency
+			} else if !ok {
+				reason = PaymentIssueInvalidAccount
+			} else if !ValidRoutingNumber(routing) {
+				reason = PaymentIssueInvalidRouting
+			}
+		}
+		if reason != "" {
+			batch.Exceptions = append(batch.Exceptions, PaymentException{EmployeeID: row.EmployeeID, Name: name, Net: row.Net, Reason: reason})
+			continue
+		}
+		batch.Payments = append(batch.Payments, payment)
+	}
+	return batch, nil
+}
+
+func NormalizeIBAN(raw string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(raw), ""))
+}
+
+// ValidIBAN checks the length and ISO 13616 mod-97 checksum of a normalised IBAN.
+func ValidIBAN(iban string) bool {
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	for i, r := range iban {
+		if i < 2 && !unicode.IsUpper(r) {
+			return false
+		}
+		if (i == 2 || i == 3) && !unicode.IsDigit(r) {
+			return false
+		}
+		if !unicode.IsUpper(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	rearranged := iban[4:] + iban[:4]
+	var digits strings.Builder
+	for _, r := range rearranged {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		} else {
+			digits.WriteString(fmt.Sprint(int(r-'A') + 10))
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func ValidBIC(bic string) bool {
+	return bicPattern.MatchString(bic)
+}
+
+// ValidRoutingNumber checks an ABA routing number against its 3-7-1 checksum.
+func ValidRoutingNumber(routing string) bool {
+	if len(routing) != 9 {
+		return false
+	}
+	weights := []int{3, 7, 1, 3, 7, 1, 3, 7, 1}
+	sum := 0
+	for i, r := range routing {
+		if r < '0' || r > '9' {
+			return false
+		}
+		sum += int(r-'0') * weights[i]
+	}
+	return sum%10 == 0
+}
+
+func ValidAccountNumber(account string) bool {
+	return accountPattern.MatchString(account)
+}
+
+// ParseUSAccount splits a stored US bank account of the form
+// "<routing>:<account>" (a slash or space also separates them).
+func ParseUSAccount(raw string) (string, string, bool) {
+	match := usAccountPattern.FindStringSubmatch(strings.TrimSpace(raw))
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+type sepaDocument struct {
+	XMLName xml.Name     `xml:"Document"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Init    sepaInitiate `xml:"CstmrCdtTrfInitn"`
+}
+
+type sepaInitiate struct {
+	GroupHeader sepaGroupHeader `xml:"GrpHdr"`
+	PaymentInfo sepaPaymentInfo `xml:"PmtInf"`
+}
+
+type sepaGroupHeader struct {
+	MessageID       string    `xml:"MsgId"`
+	CreatedAt       string    `xml:"CreDtTm"`
+	NumberOfTxs     int       `xml:"NbOfTxs"`
+	ControlSum      string    `xml:"CtrlSum"`
+	InitiatingParty sepaParty `xml:"InitgPty"`
+}
+
+type sepaParty struct {
+	Name string `xml:"Nm"`
+}
+
+type sepaAccount struct {
+	IBAN string `xml:"Id>IBAN"`
+}
+
+type sepaAgent struct {
+	BIC   string `xml:"FinInstnId>BIC,omitempty"`
+	Other string `xml:"FinInstnId>Othr>Id,omitempty"`
+}
+
+type sepaPaymentInfo struct {
+	ID              string            `xml:"PmtInfId"`
+	Method          string            `xml:"PmtMtd"`
+	NumberOfTxs     int               `xml:"NbOfTxs"`
+	ControlSum      string            `xml:"CtrlSum"`
+	ServiceLevel    string            `xml:"PmtTpInf>SvcLvl>Cd"`
+	CategoryPurpose string            `xml:"PmtTpInf>CtgyPurp>Cd"`
+	ExecutionDate   string            `xml:"ReqdExctnDt"`
+	Debtor          sepaParty         `xml:"Dbtr"`
+	DebtorAccount   sepaAccount       `xml:"DbtrAcct"`
+	DebtorAgent     sepaAgent         `xml:"DbtrAgt"`
+	ChargeBearer    string            `xml:"ChrgBr"`
+	Transfers       []sepaTransaction `xml:"CdtTrfTxInf"`
+}
+
+type sepaAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type sepaTransaction struct {
+	EndToEndID     string      `xml:"PmtId>EndToEndId"`
+	Amount         sepaAmount  `xml:"Amt>InstdAmt"`
+	Creditor       sepaParty   `xml:"Cdtr"`
+	CreditorAcct   sepaAccount `xml:"CdtrAcct"`
+	RemittanceInfo string      `xml:"RmtInf>Ustrd"`
+}
+
+// BuildSEPA renders a pain.001.001.03 credit transfer initiation for the payments.
+func BuildSEPA(settings PaymentSettings, payments []Payment, messageID, remittance string, executionDate, now time.Time) ([]byte, error) {
+	total := 0.0
+	transfers := make([]sepaTransaction, 0, len(payments))
+	for i, payment := range payments {
+		total += payment.Amount
+		transfers = append(transfers, sepaTransaction{
+			EndToEndID:     truncate(fmt.Sprintf("%s-%d", messageID, i+1), 35),
+			Amount:         sepaAmount{Currency: "EUR", Value: fmt.Sprintf("%.2f", payment.Amount)},
+			Creditor:       sepaParty{Name: truncate(payment.Name, 70)},
+			CreditorAcct:   sepaAccount{IBAN: payment.IBAN},
+			RemittanceInfo: truncate(remittance, 140),
+		})
+	}
+	agent := sepaAgent{BIC: settings.DebtorBIC}
+	if agent.BIC == "" {
+		agent.Other = "NOTPROVIDED"
+	}
+	controlSum := fmt.Sprintf("%.2f", roundCents(total))
+
+	doc := sepaDocument{
+		Xmlns: "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03",
+		Init: sepaInitiate{
+			GroupHeader: sepaGroupHeader{
+				MessageID:       truncate(messageID, 35),
+				CreatedAt:       now.UTC().Format("2006-01-02T15:04:05"),
+				NumberOfTxs:     len(payments),
+				ControlSum:      controlSum,
+				InitiatingParty: sepaParty{Name: truncate(asciiOnly(settings.DebtorName), 70)},
+			},
+			PaymentInfo: sepaPaymentInfo{
+				ID:              truncate(messageID, 35),
+				Method:          "TRF",
+				NumberOfTxs:     len(payments),
+				ControlSum:      controlSum,
+				ServiceLevel:    "SEPA",
+				CategoryPurpose: "SALA",
+				ExecutionDate:   executionDate.Format("2006-01-02"),
+				Debtor:          sepaParty{Name: truncate(asciiOnly(settings.DebtorName), 70)},
+				DebtorAccount:   sepaAccount{IBAN: NormalizeIBAN(settings.DebtorIBAN)},
+				DebtorAgent:     agent,
+				ChargeBearer:    "SLEV",
+				Transfers:       transfers,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// BuildNACHA renders a single PPD credit batch in the fixed-width NACHA format.
+// When the tenant has configured its own account number the batch is balanced
+// with an offsetting debit to that account, as some ODFIs require. A stored
+// routing number that is not a valid ABA number returns
+// ErrPaymentRoutingNumber rather than a malformed file.
+func BuildNACHA(settings PaymentSettings, payments []Payment, effectiveDate, now time.Time) ([]byte, error) {
+	if !ValidRoutingNumber(settings.RoutingNumber) {
+		return nil, ErrPaymentRoutingNumber
+	}
+	odfi := settings.RoutingNumber[:8]
+	serviceClass := "220"
+	if settings.AccountNumber != "" {
+		serviceClass = "200"
+	}
+
+	records := []string{
+		"1" + "01" + " " + settings.RoutingNumber + padLeft(settings.CompanyID, 10) +
+			now.Format("060102") + now.Format("1504") + "A" + "094" + "10" + "1" +
+			padRight(strings.ToUpper(asciiOnly(settings.DestinationName)), 23) +
+			padRight(strings.ToUpper(asciiOnly(settings.DebtorName)), 23) + padRight("", 8),
+		"5" + serviceClass + padRight(strings.ToUpper(asciiOnly(settings.DebtorName)), 16) + padRight("", 20) +
+			padLeft(settings.CompanyID, 10) + "PPD" + padRight("PAYROLL", 10) +
+			strings.ToUpper(effectiveDate.Format("Jan 06")) + effectiveDate.Format("060102") +
+			"   " + "1" + odfi + fmt.Sprintf("%07d", 1),
+	}
+
+	var entryHash, creditCents, debitCents int64
+	entries := 0
+	addEntry := func(transactionCode, routing, account string, cents int64, id, name string) {
+		entries++
+		rdfi, _ := strconv.ParseInt(routing[:8], 10, 64)
+		entryHash += rdfi
+		records = append(records, "6"+transactionCode+routing+padRight(account, 17)+
+			fmt.Sprintf("%010d", cents)+padRight(id, 15)+padRight(strings.ToUpper(asciiOnly(name)), 22)+
+			"  "+"0"+odfi+fmt.Sprintf("%07d", entries))
+	}
+	for _, payment := range payments {
+		cents := int64(math.Round(payment.Amount * 100))
+		creditCents += cents
+		addEntry("22", payment.Routing, payment.Account, cents, payment.EmployeeID, payment.Name)
+	}
+	if settings.AccountNumber != "" && creditCents > 0 {
+		debitCents = creditCents
+		addEntry("27", settings.RoutingNumber, settings.AccountNumber, debitCents, settings.CompanyID, settings.DebtorName)
+	}
+
+	hash := fmt.Sprintf("%010d", entryHash%10000000000)
+	records = append(records, "8"+serviceClass+fmt.Sprintf("%06d", entries)+hash+
+		fmt.Sprintf("%012d", debitCents)+fmt.Sprintf("%012d", creditCents)+
+		padLeft(settings.CompanyID, 10)+padRight("", 25)+odfi+fmt.Sprintf("%07d", 1))
+	blocks := (len(records) + 1 + 9) / 10
+	records = append(records, "9"+fmt.Sprintf("%06d", 1)+fmt.Sprintf("%06d", blocks)+
+		fmt.Sprintf("%08d", entries)+hash+fmt.Sprintf("%012d", debitCents)+fmt.Sprintf("%012d", creditCents)+
+		padRight("", 39))
+	for len(records)%10 != 0 {
+		records = append(records, strings.Repeat("9", 94))
+	}
+	return []byte(strings.Join(records, "\n") + "\n"), nil
+}
+
+func padRight(value string, width int) string {
+	value = truncate(value, width)
+	return value + strings.Repeat(" ", width-utf8.RuneCountInString(value))
+}
+
+func padLeft(value string, width int) string {
+	value = truncate(value, width)
+	return strings.Repeat(" ", width-utf8.RuneCountInString(value)) + value
+}
+
+// truncate cuts value to at most width characters, never splitting one.
+func truncate(value string, width int) string {
+	if utf8.RuneCountInString(value) <= width {
+		return value
+	}
+	return string([]rune(value)[:width])
+}
+
+func asciiOnly(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= 32 && r < 127 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
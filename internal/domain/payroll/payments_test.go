@@ -0,0 +1,201 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	cryptoutil "hrm/internal/platform/crypto"
+)
+
+type paymentStore struct {
+	StoreAPI
+	rows []PaymentRow
+}
+
+func (s *paymentStore) PaymentRows(context.Context, string, string) ([]PaymentRow, error) {
+	return s.rows, nil
+}
+
+func TestValidIBANAndRoutingNumber(t *testing.T) {
+	if !ValidIBAN(NormalizeIBAN("de89 3704 0044 0532 0130 00")) {
+		t.Fatal("expected DE89 example IBAN to be valid")
+	}
+	if ValidIBAN("DE88370400440532013000") {
+		t.Fatal("expected IBAN with wrong check digits to be rejected")
+	}
+	if !ValidRoutingNumber("021000021") {
+		t.Fatal("expected 021000021 to be a valid routing number")
+	}
+	if ValidRoutingNumber("021000022") || ValidRoutingNumber("12345") {
+		t.Fatal("expected invalid routing numbers to be rejected")
+	}
+	routing, account, ok := ParseUSAccount("021000021:000123456789")
+	if !ok || routing != "021000021" || account != "000123456789" {
+		t.Fatalf("unexpected parse %q %q %v", routing, account, ok)
+	}
+}
+
+func TestPaymentBatchListsExceptions(t *testing.T) {
+	store := &paymentStore{rows: []PaymentRow{
+		{EmployeeID: "e1", FirstName: "Ada", LastName: "Lovelace", Net: 2500, Currency: "EUR", BankPlain: "DE89370400440532013000"},
+		{EmployeeID: "e2", FirstName: "No", LastName: "Bank", Net: 1000, Currency: "EUR"},
+		{EmployeeID: "e3", FirstName: "Bad", LastName: "Iban", Net: 1000, Currency: "EUR", BankPlain: "DE00370400440532013000"},
+		{EmployeeID: "e4", FirstName: "Dollar", LastName: "Paid", Net: 1000, Currency: "USD", BankPlain: "DE89370400440532013000"},
+	}}
+	batch, err := NewService(store, nil).PaymentBatch(context.Background(), "t1", "p1", PaymentFormatSEPA)
+	if err != nil {
+		t.Fatalf("payment batch: %v", err)
+	}
+	if len(batch.Payments) != 1 || batch.Payments[0].IBAN != "DE89370400440532013000" {
+		t.Fatalf("expected one payable employee, got %+v", batch.Payments)
+	}
+	reasons := map[string]string{}
+	for _, exception := range batch.Exceptions {
+		reasons[exception.EmployeeID] = exception.Reason
+	}
+	if reasons["e2"] != WarningMissingBank || reasons["e3"] != PaymentIssueInvalidIBAN || reasons["e4"] != PaymentIssueCurrency {
+		t.Fatalf("unexpected exceptions %+v", batch.Exceptions)
+	}
+}
+
+func TestBuildSEPAControlSum(t *testing.T) {
+	settings := PaymentSettings{DebtorName: "Acme GmbH", DebtorIBAN: "DE89370400440532013000"}
+	payments := []Payment{
+		{EmployeeID: "e1", Name: "Ada Lovelace", Amount: 1200.10, IBAN: "GB82WEST12345698765432"},
+		{EmployeeID: "e2", Name: "Alan Turing", Amount: 800.25, IBAN: "DE89370400440532013000"},
+	}
+	now := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	out, err := BuildSEPA(settings, payments, "MSG1", "Salary 2026-01", now.AddDate(0, 0, 2), now)
+	if err != nil {
+		t.Fatalf("build sepa: %v", err)
+	}
+	doc := string(out)
+	for _, want := range []string{
+		"urn:iso:std:iso:20022:tech:xsd:pain.001.001.03",
+		"<NbOfTxs>2</NbOfTxs>",
+		"<CtrlSum>2000.35</CtrlSum>",
+		"<ReqdExctnDt>2026-02-03</ReqdExctnDt>",
+		`<InstdAmt Ccy="EUR">800.25</InstdAmt>`,
+		"<Id>NOTPROVIDED</Id>",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Fatalf("expected %q in SEPA document:\n%s", want, doc)
+		}
+	}
+}
+
+func TestBuildNACHARecords(t *testing.T) {
+	settings := PaymentSettings{DebtorName: "Acme Inc", CompanyID: "1234567890", RoutingNumber: "021000021", AccountNumber: "99887766", DestinationName: "Chase"}
+	payments := []Payment{
+		{EmployeeID: "e1", Name: "Ada Lovelace", Amount: 1200.10, Routing: "011000015", Account: "12345"},
+		{EmployeeID: "e2", Name: "Alan Turing", Amount: 800.25, Routing: "021000021", Account: "67890"},
+	}
+	now := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	file, err := BuildNACHA(settings, payments, now.AddDate(0, 0, 2), now)
+	if err != nil {
+		t.Fatalf("build nacha: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(file), "\n"), "\n")
+	if len(lines) != 10 {
+		t.Fatalf("expected file padded to one block of 10 records, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) != 94 {
+			t.Fatalf("record %d has length %d: %q", i, len(line), line)
+		}
+	}
+	if !strings.HasPrefix(lines[2], "622011000015") || !strings.HasPrefix(lines[4], "627021000021") {
+		t.Fatalf("unexpected entry records %q / %q", lines[2], lines[4])
+	}
+	// 01100001 + 02100002 + 02100002 (offset) = 05300005
+	control := lines[5]
+	if control[:4] != "8200" || control[4:10] != "000003" || control[10:20] != "0005300005" {
+		t.Fatalf("unexpected batch control %q", control)
+	}
+	if control[20:32] != "000000200035" || control[32:44] != "000000200035" {
+		t.Fatalf("expected balanced debit and credit totals, got %q", control)
+	}
+	if lines[6][:1] != "9" || lines[9] != strings.Repeat("9", 94) {
+		t.Fatalf("unexpected file control or padding %q / %q", lines[6], lines[9])
+	}
+}
+
+func TestBuildNACHAKeepsRecordWidthForNonASCIINames(t *testing.T) {
+	settings := PaymentSettings{DebtorName: "Société Générale Façades", CompanyID: "1234567890", RoutingNumber: "021000021", DestinationName: "Banque Crédit"}
+	payments := []Payment{{EmployeeID: "e1", Name: "José Müller", Amount: 100, Routing: "011000015", Account: "12345"}}
+	now := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	file, err := BuildNACHA(settings, payments, now, now)
+	if err != nil {
+		t.Fatalf("build nacha: %v", err)
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(string(file), "\n"), "\n") {
+		if len(line) != 94 {
+			t.Fatalf("record %d has length %d: %q", i, len(line), line)
+		}
+	}
+	if got := truncate("Müller", 2); got != "Mü" {
+		t.Fatalf("expected truncation by characters, got %q", got)
+	}
+}
+
+func TestBuildNACHARejectsInvalidRoutingNumber(t *testing.T) {
+	payments := []Payment{{EmployeeID: "e1", Name: "Ada Lovelace", Amount: 100, Routing: "011000015", Account: "12345"}}
+	now := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	for _, routing := range []string{"", "0210", "021000022"} {
+		settings := PaymentSettings{DebtorName: "Acme Inc", CompanyID: "1234567890", RoutingNumber: routing, AccountNumber: "99887766"}
+		if _, err := BuildNACHA(settings, payments, now, now); !errors.Is(err, ErrPaymentRoutingNumber) {
+			t.Fatalf("routing %q: expected ErrPaymentRoutingNumber, got %v", routing, err)
+		}
+	}
+}
+
+func TestPaymentSettingsMasked(t *testing.T) {
+	masked := PaymentSettings{DebtorIBAN: "DE89370400440532013000", AccountNumber: "99887766"}.Masked()
+	if masked.DebtorIBAN != "******************3000" || masked.AccountNumber != "****7766" {
+		t.Fatalf("unexpected masked settings %+v", masked)
+	}
+}
+
+type paymentSettingsStore struct {
+	StoreAPI
+	settings PaymentSettings
+}
+
+func (s *paymentSettingsStore) PaymentSettings(context.Context, string) (PaymentSettings, error) {
+	return s.settings, nil
+}
+
+func TestPaymentSettingsAreMaskedOutsidePaymentFiles(t *testing.T) {
+	crypto, err := cryptoutil.New("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("crypto: %v", err)
+	}
+	ibanEnc, err := crypto.EncryptString("DE89370400440532013000")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	accountEnc, err := crypto.EncryptString("99887766")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	svc := NewService(&paymentSettingsStore{settings: PaymentSettings{DebtorName: "Acme", DebtorIBANEnc: ibanEnc, AccountNumberEnc: accountEnc}}, crypto)
+
+	shown, err := svc.PaymentSettings(context.Background(), "t1")
+	if err != nil {
+		t.Fatalf("payment settings: %v", err)
+	}
+	if shown.DebtorIBAN != "******************3000" || shown.AccountNumber != "****7766" || shown.DebtorName != "Acme" {
+		t.Fatalf("expected masked settings, got %+v", shown)
+	}
+
+	file, err := svc.PaymentFileSettings(context.Background(), "t1")
+	if err != nil {
+		t.Fatalf("payment file settings: %v", err)
+	}
+	if file.DebtorIBAN != "DE89370400440532013000" || file.AccountNumber != "99887766" {
+		t.Fatalf("expected decrypted settings for payment files, got %+v", file)
+	}
+}
@@ -0,0 +1,215 @@
+package payroll
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"hrm/internal/domain/leave"
+)
+
+// maxLogoBytes keeps logos small enough to embed in every generated payslip.
+const maxLogoBytes = 512 * 1024
+
+type PayslipTemplate struct {
+	ID                string    `json:"id"`
+	Name              string    `json:"name"`
+	CompanyName       string    `json:"companyName"`
+	CompanyAddress    string    `json:"companyAddress"`
+	Logo              []byte    `json:"-"`
+	LogoType          string    `json:"logoType,omitempty"`
+	HasLogo           bool      `json:"hasLogo"`
+	Footer            string    `json:"footer"`
+	ShowLeaveBalances bool      `json:"showLeaveBalances"`
+	ShowYTD           bool      `json:"showYtd"`
+	IsDefault         bool      `json:"isDefault"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// PayslipLeaveBalance is a leave balance as printed on a payslip. Available
+// is what is left after used and pending leave, in Unit.
+type PayslipLeaveBalance struct {
+	LeaveType string  `json:"leaveType"`
+	Available float64 `json:"available"`
+	Used      float64 `json:"used"`
+	Unit      string  `json:"unit"`
+}
+
+// DefaultPayslipTemplate is used when the tenant has not marked a template as
+// default, and matches the layout payslips had before templates existed.
+func DefaultPayslipTemplate() PayslipTemplate {
+	return PayslipTemplate{Name: "Default", ShowLeaveBalances: true, ShowYTD: true}
+}
+
+// DecodeLogo accepts a PNG or JPEG logo as raw base64 or a data URL and returns
+// the image bytes with the gofpdf image type.
+func DecodeLogo(raw string) ([]byte, string, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "data:") {
+		if comma := strings.Index(raw, ","); comma >= 0 {
+			raw = raw[comma+1:]
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("logo must be base64 encoded")
+	}
+	if len(data) > maxLogoBytes {
+		return nil, "", fmt.Errorf("logo must be at most %d KB", maxLogoBytes/1024)
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return data, "PNG", nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return data, "JPG", nil
+	}
+	return nil, "", fmt.Errorf("logo must be a PNG or JPEG image")
+}
+
+// RenderPayslip lays out a payslip PDF from the template: branding header,
+// itemised earnings and deductions with year-to-date columns, leave balances
+// and the footer.
+func RenderPayslip(data PayslipPDFData, template PayslipTemplate) ([]byte, error) {
+	pdf := newBrandedPDF(template)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.Cell(0, 10, payslipTitle(data.RunType))
+	pdf.Ln(12)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.Cell(0, 8, fmt.Sprintf("Employee: %s %s", data.FirstName, data.LastName))
+	pdf.Ln(7)
+	pdf.Cell(0, 8, fmt.Sprintf("Email: %s", data.Email))
+	pdf.Ln(7)
+	pdf.Cell(0, 8, fmt.Sprintf("Period: %s to %s", data.StartDate.Format("2006-01-02"), data.EndDate.Format("2006-01-02")))
+	pdf.Ln(10)
+
+	ytd := map[string]float64{}
+	for _, line := range data.YTDLines {
+		ytd[lineKey(line)] += line.Amount
+	}
+	writePayslipLines(pdf, "Earnings", data.Lines, ResultLineEarning, data.Currency, ytd, template.ShowYTD)
+	writePayslipLines(pdf, "Deductions", data.Lines, ResultLineDeduction, data.Currency, ytd, template.ShowYTD)
+	writePayslipLines(pdf, "Employer contributions", data.Lines, ResultLineEmployerContribution, data.Currency, ytd, template.ShowYTD)
+
+	totals := []struct {
+		label        string
+		current, ytd float64
+	}{
+		{"Gross", data.Gross, data.YTD.Gross},
+		{"Deductions", data.Deductions, data.YTD.Deductions},
+		{"Net", data.Net, data.YTD.Net},
+	}
+	pdf.SetFont("Helvetica", "B", 12)
+	for _, total := range totals {
+		writePayslipRow(pdf, total.label, total.current, total.ytd, data.Currency, template.ShowYTD)
+	}
+
+	if template.ShowLeaveBalances && len(data.LeaveBalances) > 0 {
+		pdf.Ln(5)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.Cell(0, 8, "Leave balances")
+		pdf.Ln(7)
+		pdf.SetFont("Helvetica", "", 11)
+		for _, balance := range data.LeaveBalances {
+			pdf.CellFormat(100, 7, balance.LeaveType, "", 0, "L", false, 0, "")
+			unit := balance.Unit
+			if unit == "" {
+				unit = leave.UnitDays
+			}
+			pdf.CellFormat(45, 7, fmt.Sprintf("%.2f %s left", balance.Available, unit), "", 0, "R", false, 0, "")
+			pdf.CellFormat(0, 7, fmt.Sprintf("%.2f %s used", balance.Used, unit), "", 1, "R", false, 0, "")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// payslipTitle labels off-cycle payslips, which only show the amounts paid
+// or corrected by that run.
+func payslipTitle(runType string) string {
+	switch runType {
+	case PeriodRunBonus:
+		return "Payslip - bonus payment"
+	case PeriodRunTermination:
+		return "Payslip - termination payment"
+	case PeriodRunCorrection:
+		return "Payslip - correction (difference only)"
+	}
+	return "Payslip"
+}
+
+// newBrandedPDF starts an A4 document with the template's logo, company
+// details and footer applied.
+func newBrandedPDF(template PayslipTemplate) *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	if template.Footer != "" {
+		pdf.SetFooterFunc(func() {
+			pdf.SetY(-18)
+			pdf.SetFont("Helvetica", "I", 8)
+			pdf.MultiCell(0, 4, template.Footer, "", "C", false)
+		})
+	}
+	pdf.AddPage()
+
+	top := pdf.GetY()
+	if len(template.Logo) > 0 {
+		options := gofpdf.ImageOptions{ImageType: template.LogoType}
+		pdf.RegisterImageOptionsReader("logo", options, bytes.NewReader(template.Logo))
+		pdf.ImageOptions("logo", 10, top, 0, 18, false, options, 0, "")
+	}
+	if template.CompanyName != "" || template.CompanyAddress != "" {
+		pdf.SetXY(110, top)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(90, 6, template.CompanyName, "", 2, "R", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(90, 4, template.CompanyAddress, "", "R", false)
+	}
+	if len(template.Logo) > 0 || template.CompanyName != "" || template.CompanyAddress != "" {
+		pdf.SetY(top + 24)
+	}
+	return pdf
+}
+
+func writePayslipLines(pdf *gofpdf.Fpdf, title string, lines []ResultLine, lineType, currency string, ytd map[string]float64, showYTD bool) {
+	var matched []ResultLine
+	for _, line := range lines {
+		if line.LineType == lineType {
+			matched = append(matched, line)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(100, 8, title, "", 0, "L", false, 0, "")
+	if showYTD {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(45, 8, "Current", "", 0, "R", false, 0, "")
+		pdf.CellFormat(0, 8, "Year to date", "", 0, "R", false, 0, "")
+	}
+	pdf.Ln(7)
+	pdf.SetFont("Helvetica", "", 11)
+	for _, line := range matched {
+		writePayslipRow(pdf, line.Description, line.Amount, ytd[lineKey(line)], currency, showYTD)
+	}
+	pdf.Ln(3)
+}
+
+func writePayslipRow(pdf *gofpdf.Fpdf, label string, current, ytd float64, currency string, showYTD bool) {
+	if !showYTD {
+		pdf.CellFormat(120, 7, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, fmt.Sprintf("%.2f %s", current, currency), "", 1, "R", false, 0, "")
+		return
+	}
+	pdf.CellFormat(100, 7, label, "", 0, "L", false, 0, "")
+	pdf.CellFormat(45, 7, fmt.Sprintf("%.2f %s", current, currency), "", 0, "R", false, 0, "")
+	pdf.CellFormat(0, 7, fmt.Sprintf("%.2f %s", ytd, currency), "", 1, "R", false, 0, "")
+}
@@ -0,0 +1,59 @@
+package payroll
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestDecodeLogoValidatesImageType(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	logo, logoType, err := DecodeLogo("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if err != nil || logoType != "PNG" || !bytes.Equal(logo, buf.Bytes()) {
+		t.Fatalf("expected png logo, got %q %v", logoType, err)
+	}
+	if _, _, err := DecodeLogo(base64.StdEncoding.EncodeToString([]byte("GIF89a"))); err == nil {
+		t.Fatal("expected unsupported image type to be rejected")
+	}
+	if _, _, err := DecodeLogo("not base64!"); err == nil {
+		t.Fatal("expected invalid base64 to be rejected")
+	}
+}
+
+func TestRenderPayslipWithBranding(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	data := PayslipPDFData{
+		FirstName: "Ada", LastName: "Lovelace", Currency: "EUR",
+		Gross: 3000, Deductions: 600, Net: 2400,
+		StartDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		Lines: []ResultLine{
+			{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 3000},
+			{LineType: ResultLineDeduction, Code: "income_tax", Description: "Income tax", Amount: 600},
+		},
+		YTD:           Totals{Gross: 9000, Deductions: 1800, Net: 7200},
+		YTDLines:      []ResultLine{{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Amount: 9000}},
+		LeaveBalances: []PayslipLeaveBalance{{LeaveType: "Annual", Available: 12, Used: 3, Unit: "days"}, {LeaveType: "TOIL", Available: 7.5, Unit: "hours"}},
+	}
+	template := PayslipTemplate{
+		CompanyName: "Acme GmbH", CompanyAddress: "1 Main St\nBerlin", Footer: "Confidential",
+		Logo: buf.Bytes(), LogoType: "PNG", ShowYTD: true, ShowLeaveBalances: true,
+	}
+
+	content, err := RenderPayslip(data, template)
+	if err != nil {
+		t.Fatalf("render payslip: %v", err)
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF")) {
+		t.Fatal("expected PDF output")
+	}
+}
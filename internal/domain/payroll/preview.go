@@ -0,0 +1,211 @@
+package payroll
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// netVarianceThreshold is the share of the previous net pay that a new net may
+// move by before it is flagged with WarningNetVariance.
+const netVarianceThreshold = 0.5
+
+type Totals struct {
+	Gross      float64 `json:"gross"`
+	Deductions float64 `json:"deductions"`
+	Net        float64 `json:"net"`
+}
+
+type LineChange struct {
+	LineType    string  `json:"lineType"`
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Previous    float64 `json:"previous"`
+	Current     float64 `json:"current"`
+	Change      float64 `json:"change"`
+	NetImpact   float64 `json:"netImpact"`
+}
+
+type PreviewEmployee struct {
+	EmployeeID      string       `json:"employeeId"`
+	FirstName       string       `json:"firstName"`
+	LastName        string       `json:"lastName"`
+	Currency        string       `json:"currency"`
+	Current         Totals       `json:"current"`
+	Previous        *Totals      `json:"previous,omitempty"`
+	Diff            *Totals      `json:"diff,omitempty"`
+	Warnings        []string     `json:"warnings"`
+	VarianceReasons []LineChange `json:"varianceReasons,omitempty"`
+	Lines           []ResultLine `json:"lines"`
+	Error           string       `json:"error,omitempty"`
+}
+
+// Preview totals are in the reporting currency; each employee's own figures
+// stay in the currency they are paid in.
+type Preview struct {
+	PeriodID         string            `json:"periodId"`
+	PreviousPeriodID string            `json:"previousPeriodId,omitempty"`
+	Currency         string            `json:"currency"`
+	Totals           Totals            `json:"totals"`
+	PreviousTotals   *Totals           `json:"previousTotals,omitempty"`
+	Employees        []PreviewEmployee `json:"employees"`
+}
+
+// PreviewPeriod runs the same calculation as RunPeriod without persisting
+// anything and compares each employee with the last finalized period.
+func (s *Service) PreviewPeriod(ctx context.Context, tenantID, periodID string) (Preview, error) {
+	preview := Preview{PeriodID: periodID, Employees: []PreviewEmployee{}}
+
+	period, err := s.store.GetPeriodDetails(ctx, tenantID, periodID)
+	if err != nil {
+		return preview, err
+	}
+	employees, err := s.runEmployees(ctx, tenantID, periodID, period)
+	if err != nil {
+		return preview, err
+	}
+	taxRules, err := s.EffectiveTaxRules(ctx, tenantID, period.EndDate)
+	if err != nil {
+		return preview, err
+	}
+	previousID, baseline, err := s.varianceBaseline(ctx, tenantID, periodID)
+	if err != nil {
+		return preview, err
+	}
+	// Both periods are converted at this period's rates so the totals
+	// compare like with like.
+	converter, err := s.Converter(ctx, tenantID, period.EndDate)
+	if err != nil {
+		return preview, err
+	}
+	preview.Currency = converter.Currency
+	previousLines := map[string][]ResultLine{}
+	if previousID != "" {
+		preview.PreviousPeriodID = previousID
+		preview.PreviousTotals = &Totals{}
+		if previousLines, err = s.store.ListPeriodResultLines(ctx, tenantID, previousID); err != nil {
+			return preview, err
+		}
+	}
+
+	for _, employee := range employees {
+		entry := PreviewEmployee{
+			EmployeeID: employee.EmployeeID,
+			FirstName:  employee.FirstName,
+			LastName:   employee.LastName,
+			Currency:   employee.Currency,
+			Warnings:   []string{},
+			Lines:      []ResultLine{},
+		}
+		previous, hasPrevious := baseline[employee.EmployeeID]
+		calc, err := s.calculateEmployee(ctx, tenantID, periodID, period, employee, taxRules, previous.Net)
+		if err != nil {
+			entry.Error = err.Error()
+			preview.Employees = append(preview.Employees, entry)
+			continue
+		}
+
+		entry.Current = Totals{Gross: calc.Gross, Deductions: calc.Deductions, Net: calc.Net}
+		if calc.Warnings != nil {
+			entry.Warnings = calc.Warnings
+		}
+		entry.Lines = calc.Lines
+		if hasPrevious {
+			entry.Previous = &Totals{Gross: previous.Gross, Deductions: previous.Deductions, Net: previous.Net}
+			entry.Diff = &Totals{
+				Gross:      roundCents(calc.Gross - previous.Gross),
+				Deductions: roundCents(calc.Deductions - previous.Deductions),
+				Net:        roundCents(calc.Net - previous.Net),
+			}
+			if NetVarianceExceeded(previous.Net, calc.Net) {
+				entry.VarianceReasons = DiffLines(previousLines[employee.EmployeeID], calc.Lines)
+			}
+			rate, err := converter.Rate(previous.Currency)
+			if err != nil {
+				return preview, err
+			}
+			preview.PreviousTotals.Gross += previous.Gross * rate
+			preview.PreviousTotals.Deductions += previous.Deductions * rate
+			preview.PreviousTotals.Net += previous.Net * rate
+		}
+		rate, err := converter.Rate(calc.Currency)
+		if err != nil {
+			return preview, err
+		}
+		preview.Totals.Gross += calc.Gross * rate
+		preview.Totals.Deductions += calc.Deductions * rate
+		preview.Totals.Net += calc.Net * rate
+		preview.Employees = append(preview.Employees, entry)
+	}
+
+	preview.Totals = roundTotals(preview.Totals)
+	if preview.PreviousTotals != nil {
+		rounded := roundTotals(*preview.PreviousTotals)
+		preview.PreviousTotals = &rounded
+	}
+	return preview, nil
+}
+
+func NetVarianceExceeded(previousNet, net float64) bool {
+	if previousNet <= 0 {
+		return false
+	}
+	return math.Abs(net-previousNet)/previousNet > netVarianceThreshold
+}
+
+// DiffLines compares earnings and deductions by code and returns every line
+// that changed, largest effect on net pay first.
+func DiffLines(previous, current []ResultLine) []LineChange {
+	changes := map[string]*LineChange{}
+	var keys []string
+	collect := func(lines []ResultLine, current bool) {
+		for _, line := range lines {
+			if line.LineType != ResultLineEarning && line.LineType != ResultLineDeduction {
+				continue
+			}
+			key := lineKey(line)
+			change, ok := changes[key]
+			if !ok {
+				change = &LineChange{LineType: line.LineType, Code: line.Code, Description: line.Description}
+				changes[key] = change
+				keys = append(keys, key)
+			}
+			if current {
+				change.Current += line.Amount
+				change.Description = line.Description
+			} else {
+				change.Previous += line.Amount
+			}
+		}
+	}
+	collect(previous, false)
+	collect(current, true)
+
+	out := make([]LineChange, 0, len(keys))
+	for _, key := range keys {
+		change := changes[key]
+		change.Previous = roundCents(change.Previous)
+		change.Current = roundCents(change.Current)
+		change.Change = roundCents(change.Current - change.Previous)
+		if change.Change == 0 {
+			continue
+		}
+		change.NetImpact = change.Change
+		if change.LineType == ResultLineDeduction {
+			change.NetImpact = -change.Change
+		}
+		out = append(out, *change)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return math.Abs(out[i].NetImpact) > math.Abs(out[j].NetImpact)
+	})
+	return out
+}
+
+func roundTotals(totals Totals) Totals {
+	return Totals{
+		Gross:      roundCents(totals.Gross),
+		Deductions: roundCents(totals.Deductions),
+		Net:        roundCents(totals.Net),
+	}
+}
@@ -0,0 +1,40 @@
+package payroll
+
+import "testing"
+
+func TestNetVarianceExceeded(t *testing.T) {
+	if NetVarianceExceeded(0, 5000) {
+		t.Fatal("expected no variance without a previous net")
+	}
+	if NetVarianceExceeded(1000, 1500) {
+		t.Fatal("expected a 50% move to stay within the threshold")
+	}
+	if !NetVarianceExceeded(1000, 400) {
+		t.Fatal("expected a 60% drop to be flagged")
+	}
+}
+
+func TestDiffLinesOrdersByNetImpact(t *testing.T) {
+	previous := []ResultLine{
+		{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 1000},
+		{LineType: ResultLineDeduction, Code: "paye", Description: "Income tax", Amount: 100},
+		{LineType: ResultLineEmployerContribution, Code: "ssc", Description: "Social security", Amount: 50},
+	}
+	current := []ResultLine{
+		{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 1000},
+		{LineType: ResultLineEarning, Code: "bonus", Description: "Bonus", Amount: 2000},
+		{LineType: ResultLineDeduction, Code: "paye", Description: "Income tax", Amount: 700},
+		{LineType: ResultLineEmployerContribution, Code: "ssc", Description: "Social security", Amount: 80},
+	}
+
+	changes := DiffLines(previous, current)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Code != "bonus" || changes[0].NetImpact != 2000 {
+		t.Fatalf("expected bonus first, got %+v", changes[0])
+	}
+	if changes[1].Code != "paye" || changes[1].Change != 600 || changes[1].NetImpact != -600 {
+		t.Fatalf("expected tax increase to reduce net by 600, got %+v", changes[1])
+	}
+}
@@ -0,0 +1,165 @@
+package payroll
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// RecurringItem is an earning or deduction applied to every regular period
+// between its start and end dates: a loan repayment, salary advance, court
+// garnishment, union dues or a standing allowance. When TotalAmount is set the
+// item stops once that much has been paid through finalized periods.
+// Pending is what runs of other periods not yet finalized have already
+// taken; it is only loaded for payroll runs.
+type RecurringItem struct {
+	ID           string     `json:"id"`
+	EmployeeID   string     `json:"employeeId"`
+	Kind         string     `json:"kind"`
+	Description  string     `json:"description"`
+	Instalment   float64    `json:"instalment"`
+	TotalAmount  *float64   `json:"totalAmount,omitempty"`
+	PaidToDate   float64    `json:"paidToDate"`
+	Pending      float64    `json:"-"`
+	Outstanding  *float64   `json:"outstanding,omitempty"`
+	Taxable      bool       `json:"taxable"`
+	Priority     int        `json:"priority"`
+	ProtectedNet float64    `json:"protectedNet"`
+	StartDate    time.Time  `json:"startDate"`
+	EndDate      *time.Time `json:"endDate,omitempty"`
+	Status       string     `json:"status"`
+	Reference    string     `json:"reference,omitempty"`
+	CreatedBy    string     `json:"createdBy,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// Earning reports whether the item pays the employee rather than deducting
+// from their pay.
+func (item RecurringItem) Earning() bool {
+	return item.Kind == RecurringKindEarning
+}
+
+// Due returns the amount the item takes this period: the instalment, capped
+// at the outstanding balance when the item has a total. Amounts pending in
+// other periods count against the balance, so a total is never exceeded
+// while several periods are open.
+func (item RecurringItem) Due() float64 {
+	due := item.Instalment
+	if item.TotalAmount != nil {
+		due = math.Min(due, *item.TotalAmount-item.PaidToDate-item.Pending)
+	}
+	return math.Max(roundCents(due), 0)
+}
+
+// ValidRecurringKind reports whether kind is a supported recurring item kind.
+func ValidRecurringKind(kind string) bool {
+	switch kind {
+	case RecurringKindLoan, RecurringKindAdvance, RecurringKindGarnishment, RecurringKindUnionDues, RecurringKindDeduction, RecurringKindEarning:
+		return true
+	}
+	return false
+}
+
+// DefaultRecurringPriority orders garnishments ahead of voluntary deductions
+// when no priority is given.
+func DefaultRecurringPriority(kind string) int {
+	if kind == RecurringKindGarnishment {
+		return 10
+	}
+	return 100
+}
+
+func (s *Service) ListRecurringItems(ctx context.Context, tenantID, employeeID string) ([]RecurringItem, error) {
+	return s.store.ListRecurringItems(ctx, tenantID, employeeID)
+}
+
+func (s *Service) GetRecurringItem(ctx context.Context, tenantID, itemID string) (RecurringItem, error) {
+	return s.store.GetRecurringItem(ctx, tenantID, itemID)
+}
+
+func (s *Service) CreateRecurringItem(ctx context.Context, tenantID string, item RecurringItem) (string, error) {
+	return s.store.CreateRecurringItem(ctx, tenantID, item)
+}
+
+func (s *Service) UpdateRecurringItem(ctx context.Context, tenantID string, item RecurringItem) error {
+	return s.store.UpdateRecurringItem(ctx, tenantID, item)
+}
+
+// RecurringEarningLines turns the recurring earnings due this period into
+// input lines so they are taxed with the rest of the period's pay.
+func RecurringEarningLines(items []RecurringItem) []InputLine {
+	var lines []InputLine
+	for _, item := range items {
+		due := item.Due()
+		if !item.Earning() || due <= 0 {
+			continue
+		}
+		code := ResultCodeRecurringEarning
+		if !item.Taxable {
+			code = ResultCodeRecurringUntaxed
+		}
+		lines = append(lines, InputLine{
+			Type:        ElementTypeEarning,
+			Amount:      due,
+			Taxable:     item.Taxable,
+			Source:      ResultSourceRecurring,
+			SourceID:    item.ID,
+			Code:        code,
+			Description: item.Description,
+		})
+	}
+	return lines
+}
+
+// ApplyRecurringDeductions takes the recurring deductions due this period
+// from net pay, after tax, in priority order: lower priority values first and,
+// for equal priorities, garnishments before other deductions and older items
+// first. A deduction never takes net pay below zero or below the item's
+// protected net; the second return value reports whether any deduction was
+// reduced or skipped for that reason.
+func ApplyRecurringDeductions(calc Calculation, items []RecurringItem) (Calculation, bool) {
+	deductions := make([]RecurringItem, 0, len(items))
+	for _, item := range items {
+		if !item.Earning() {
+			deductions = append(deductions, item)
+		}
+	}
+	sort.SliceStable(deductions, func(i, j int) bool {
+		a, b := deductions[i], deductions[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if (a.Kind == RecurringKindGarnishment) != (b.Kind == RecurringKindGarnishment) {
+			return a.Kind == RecurringKindGarnishment
+		}
+		return a.StartDate.Before(b.StartDate)
+	})
+
+	limited := false
+	for _, item := range deductions {
+		due := item.Due()
+		if due <= 0 {
+			continue
+		}
+		available := roundCents(calc.Net - math.Max(item.ProtectedNet, 0))
+		amount := math.Min(due, math.Max(available, 0))
+		if amount < due {
+			limited = true
+		}
+		if amount <= 0 {
+			continue
+		}
+		calc.Deductions += amount
+		calc.Net -= amount
+		calc.Lines = append(calc.Lines, ResultLine{
+			LineType:    ResultLineDeduction,
+			Source:      ResultSourceRecurring,
+			SourceID:    item.ID,
+			Code:        item.Kind,
+			Description: item.Description,
+			Amount:      amount,
+		})
+	}
+	return calc, limited
+}
@@ -0,0 +1,66 @@
+package payroll
+
+import "testing"
+
+func TestRecurringItemDueCapsAtOutstandingBalance(t *testing.T) {
+	total := 1000.0
+	loan := RecurringItem{Kind: RecurringKindLoan, Instalment: 300, TotalAmount: &total, PaidToDate: 900}
+	if due := loan.Due(); due != 100 {
+		t.Fatalf("expected final instalment of 100, got %.2f", due)
+	}
+	loan.PaidToDate = 1000
+	if due := loan.Due(); due != 0 {
+		t.Fatalf("expected nothing due once repaid, got %.2f", due)
+	}
+	loan.PaidToDate = 600
+	loan.Pending = 300
+	if due := loan.Due(); due != 100 {
+		t.Fatalf("expected amounts pending in open periods to reduce the balance, got %.2f", due)
+	}
+	dues := RecurringItem{Kind: RecurringKindUnionDues, Instalment: 25}
+	if due := dues.Due(); due != 25 {
+		t.Fatalf("expected uncapped instalment, got %.2f", due)
+	}
+}
+
+func TestApplyRecurringDeductionsPrioritisesGarnishmentsAndProtectsNet(t *testing.T) {
+	calc := Calculation{Gross: 2000, Deductions: 400, Net: 1600}
+	items := []RecurringItem{
+		{ID: "loan", Kind: RecurringKindLoan, Description: "Car loan", Instalment: 500, Priority: 100},
+		{ID: "court", Kind: RecurringKindGarnishment, Description: "Court order", Instalment: 600, Priority: 10, ProtectedNet: 500},
+		{ID: "dues", Kind: RecurringKindUnionDues, Description: "Union dues", Instalment: 30, Priority: 100, ProtectedNet: 1000},
+		{ID: "allowance", Kind: RecurringKindEarning, Description: "Travel allowance", Instalment: 80},
+	}
+
+	result, limited := ApplyRecurringDeductions(calc, items)
+	if !limited {
+		t.Fatal("expected the protected net to limit a deduction")
+	}
+	if len(result.Lines) != 2 {
+		t.Fatalf("expected garnishment and loan lines only, got %+v", result.Lines)
+	}
+	if result.Lines[0].SourceID != "court" || result.Lines[0].Amount != 600 {
+		t.Fatalf("expected the garnishment to be taken first in full, got %+v", result.Lines[0])
+	}
+	if result.Lines[1].SourceID != "loan" || result.Lines[1].Amount != 500 {
+		t.Fatalf("expected the loan instalment next, got %+v", result.Lines[1])
+	}
+	// Net is now 500, below the 1000 protected by the union dues item.
+	if result.Net != 500 || result.Deductions != 1500 {
+		t.Fatalf("expected net 500 after deductions of 1500, got %.2f / %.2f", result.Net, result.Deductions)
+	}
+}
+
+func TestApplyRecurringDeductionsNeverTakesNetBelowZero(t *testing.T) {
+	result, limited := ApplyRecurringDeductions(Calculation{Net: 120}, []RecurringItem{
+		{ID: "advance", Kind: RecurringKindAdvance, Description: "Salary advance", Instalment: 200},
+	})
+	if !limited || len(result.Lines) != 1 || result.Lines[0].Amount != 120 || result.Net != 0 {
+		t.Fatalf("expected a partial instalment of 120, got %+v (limited %v)", result, limited)
+	}
+
+	lines := RecurringEarningLines([]RecurringItem{{ID: "allowance", Kind: RecurringKindEarning, Description: "Allowance", Instalment: 80, Taxable: true}})
+	if len(lines) != 1 || lines[0].Code != ResultCodeRecurringEarning || !lines[0].Taxable || lines[0].Type != ElementTypeEarning {
+		t.Fatalf("expected a taxable recurring earning line, got %+v", lines)
+	}
+}
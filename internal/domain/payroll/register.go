@@ -0,0 +1,107 @@
+package payroll
+
+import (
+	"fmt"
+	"sort"
+)
+
+var lineTypeOrder = map[string]int{
+	ResultLineEarning:              0,
+	ResultLineDeduction:            1,
+	ResultLineEmployerContribution: 2,
+}
+
+// RegisterTable pivots the register into one column per line type and code so
+// every figure on a row can be traced back to the element that produced it.
+func RegisterTable(rows []RegisterRow) ([]string, [][]string) {
+	type column struct {
+		key      string
+		lineType string
+		position int
+	}
+	columns := map[string]column{}
+	for _, row := range rows {
+		for _, line := range row.Lines {
+			key := lineKey(line)
+			if _, ok := columns[key]; !ok {
+				columns[key] = column{key: key, lineType: line.LineType, position: len(columns)}
+			}
+		}
+	}
+	ordered := make([]column, 0, len(columns))
+	for _, col := range columns {
+		ordered = append(ordered, col)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if lineTypeOrder[ordered[i].lineType] != lineTypeOrder[ordered[j].lineType] {
+			return lineTypeOrder[ordered[i].lineType] < lineTypeOrder[ordered[j].lineType]
+		}
+		return ordered[i].position < ordered[j].position
+	})
+
+	headers := []string{"employee_id", "first_name", "last_name"}
+	for _, col := range ordered {
+		headers = append(headers, col.key)
+	}
+	headers = append(headers, "gross", "deductions", "net", "currency")
+
+	records := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		amounts := map[string]float64{}
+		for _, line := range row.Lines {
+			amounts[lineKey(line)] += line.Amount
+		}
+		record := []string{row.EmployeeID, row.FirstName, row.LastName}
+		for _, col := range ordered {
+			if amount, ok := amounts[col.key]; ok {
+				record = append(record, fmt.Sprintf("%.2f", amount))
+			} else {
+				record = append(record, "")
+			}
+		}
+		record = append(record, fmt.Sprintf("%.2f", row.Gross), fmt.Sprintf("%.2f", row.Deductions), fmt.Sprintf("%.2f", row.Net), row.Currency)
+		records = append(records, record)
+	}
+	return headers, records
+}
+
+// SummariseLines totals result lines across employees by line type and code.
+func SummariseLines(lines map[string][]ResultLine) []ResultLine {
+	totals := map[string]*ResultLine{}
+	var keys []string
+	for _, employeeLines := range lines {
+		for _, line := range employeeLines {
+			key := lineKey(line)
+			total, ok := totals[key]
+			if !ok {
+				total = &ResultLine{LineType: line.LineType, Source: line.Source, SourceID: line.SourceID, Code: line.Code, Description: line.Description}
+				if line.Source == ResultSourceAdjustment {
+					total.Description = "Adjustments"
+				}
+				totals[key] = total
+				keys = append(keys, key)
+			}
+			total.Base += line.Base
+			total.Amount += line.Amount
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := totals[keys[i]], totals[keys[j]]
+		if lineTypeOrder[a.LineType] != lineTypeOrder[b.LineType] {
+			return lineTypeOrder[a.LineType] < lineTypeOrder[b.LineType]
+		}
+		return keys[i] < keys[j]
+	})
+	out := make([]ResultLine, 0, len(keys))
+	for _, key := range keys {
+		total := totals[key]
+		total.Base = roundCents(total.Base)
+		total.Amount = roundCents(total.Amount)
+		out = append(out, *total)
+	}
+	return out
+}
+
+func lineKey(line ResultLine) string {
+	return line.LineType + ":" + line.Code
+}
@@ -0,0 +1,63 @@
+package payroll
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisterTablePivotsLinesByCode(t *testing.T) {
+	rows := []RegisterRow{
+		{
+			EmployeeID: "e1", FirstName: "Ada", LastName: "Lovelace", Gross: 1100, Deductions: 110, Net: 990, Currency: "USD",
+			Lines: []ResultLine{
+				{LineType: ResultLineDeduction, Code: "paye", Amount: 110},
+				{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Amount: 1000},
+				{LineType: ResultLineEarning, Code: "bonus", Amount: 100},
+			},
+		},
+		{
+			EmployeeID: "e2", FirstName: "Alan", LastName: "Turing", Gross: 900, Deductions: 90, Net: 810, Currency: "USD",
+			Lines: []ResultLine{
+				{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Amount: 900},
+				{LineType: ResultLineDeduction, Code: "paye", Amount: 90},
+			},
+		},
+	}
+
+	headers, records := RegisterTable(rows)
+	wantHeaders := []string{"employee_id", "first_name", "last_name", "earning:base_salary", "earning:bonus", "deduction:paye", "gross", "deductions", "net", "currency"}
+	if !reflect.DeepEqual(headers, wantHeaders) {
+		t.Fatalf("unexpected headers: %v", headers)
+	}
+	wantSecond := []string{"e2", "Alan", "Turing", "900.00", "", "90.00", "900.00", "90.00", "810.00", "USD"}
+	if !reflect.DeepEqual(records[1], wantSecond) {
+		t.Fatalf("unexpected record: %v", records[1])
+	}
+}
+
+func TestSummariseLinesTotalsAcrossEmployees(t *testing.T) {
+	lines := map[string][]ResultLine{
+		"e1": {
+			{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 1000},
+			{LineType: ResultLineEarning, Source: ResultSourceAdjustment, Code: ResultCodeAdjustment, Description: "Back pay", Amount: 50},
+		},
+		"e2": {
+			{LineType: ResultLineEarning, Code: ResultCodeBaseSalary, Description: "Base salary", Amount: 900.1},
+			{LineType: ResultLineEmployerContribution, Code: "ssc", Description: "Social security", Amount: 20},
+		},
+	}
+
+	totals := SummariseLines(lines)
+	if len(totals) != 3 {
+		t.Fatalf("expected 3 summary lines, got %d", len(totals))
+	}
+	if totals[0].Code != ResultCodeAdjustment || totals[0].Description != "Adjustments" {
+		t.Fatalf("expected adjustments first, got %+v", totals[0])
+	}
+	if totals[1].Code != ResultCodeBaseSalary || totals[1].Amount != 1900.1 {
+		t.Fatalf("expected base salary total 1900.1, got %+v", totals[1])
+	}
+	if totals[2].LineType != ResultLineEmployerContribution {
+		t.Fatalf("expected employer contribution last, got %+v", totals[2])
+	}
+}
@@ -0,0 +1,157 @@
+package payroll
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// RetroCandidate is a finalized regular period that back-dated changes have
+// touched for one employee since it was last settled.
+type RetroCandidate struct {
+	EmployeeID string    `json:"employeeId"`
+	FirstName  string    `json:"firstName,omitempty"`
+	LastName   string    `json:"lastName,omitempty"`
+	PeriodID   string    `json:"periodId"`
+	StartDate  time.Time `json:"startDate"`
+	EndDate    time.Time `json:"endDate"`
+	Triggers   []string  `json:"triggers"`
+}
+
+// RetroItem records the recalculation of one finalized period for one
+// employee and the difference paid through a later period. Pay is gross less
+// non-tax deductions.
+type RetroItem struct {
+	ID                  string    `json:"id,omitempty"`
+	PeriodID            string    `json:"periodId"`
+	EmployeeID          string    `json:"employeeId"`
+	FirstName           string    `json:"firstName,omitempty"`
+	LastName            string    `json:"lastName,omitempty"`
+	OriginalPeriodID    string    `json:"originalPeriodId"`
+	OriginalStartDate   time.Time `json:"originalStartDate"`
+	OriginalEndDate     time.Time `json:"originalEndDate"`
+	Triggers            []string  `json:"triggers"`
+	PreviousPay         float64   `json:"previousPay"`
+	RecalculatedPay     float64   `json:"recalculatedPay"`
+	PreviousTaxable     float64   `json:"previousTaxable"`
+	RecalculatedTaxable float64   `json:"recalculatedTaxable"`
+	Delta               float64   `json:"delta"`
+	TaxableDelta        float64   `json:"taxableDelta"`
+	CreatedAt           time.Time `json:"createdAt,omitempty"`
+}
+
+func (s *Service) ListRetroCandidates(ctx context.Context, tenantID string) ([]RetroCandidate, error) {
+	return s.store.ListRetroCandidates(ctx, tenantID, "", time.Now().UTC())
+}
+
+func (s *Service) ListRetroItems(ctx context.Context, tenantID, periodID string) ([]RetroItem, error) {
+	return s.store.ListRetroItems(ctx, tenantID, periodID)
+}
+
+// retroItems recalculates, in memory, each finalized period affected by
+// back-dated changes for the employee and returns the differences to pay in
+// this period. Taxes are not recalculated; the retro lines are taxed with the
+// rest of the current period.
+func (s *Service) retroItems(ctx context.Context, tenantID, periodID string, period PeriodDetails, employee EmployeePayrollData) ([]RetroItem, []InputLine, error) {
+	candidates, err := s.store.ListRetroCandidates(ctx, tenantID, employee.EmployeeID, period.StartDate)
+	if err != nil || len(candidates) == 0 {
+		return nil, nil, err
+	}
+
+	history, err := s.ListCompensation(ctx, tenantID, employee.EmployeeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].EffectiveFrom.Before(history[j].EffectiveFrom)
+	})
+	employee.Compensation = history
+
+	items := make([]RetroItem, 0, len(candidates))
+	var lines []InputLine
+	for _, candidate := range candidates {
+		original := PeriodDetails{
+			Status:    PeriodStatusFinalized,
+			StartDate: candidate.StartDate,
+			EndDate:   candidate.EndDate,
+			RunType:   PeriodRunRegular,
+		}
+		baseSalary, inputs, err := s.payInputs(ctx, tenantID, candidate.PeriodID, original, employee)
+		if err != nil {
+			return nil, nil, err
+		}
+		backdated, err := s.store.ListBackdatedAdjustmentLines(ctx, tenantID, employee.EmployeeID, candidate.PeriodID, candidate.StartDate, candidate.EndDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load back-dated adjustments: %w", err)
+		}
+		inputs = append(inputs, backdated...)
+
+		previousPay, previousTaxable, err := s.store.RetroSettled(ctx, tenantID, candidate.PeriodID, employee.EmployeeID, periodID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load settled pay: %w", err)
+		}
+		gross, deductions, _ := ComputePayroll(baseSalary, inputs)
+		item := NewRetroItem(candidate, previousPay, roundCents(gross-deductions), previousTaxable, roundCents(TaxableGross(baseSalary, inputs)))
+		item.PeriodID = periodID
+		items = append(items, item)
+		lines = append(lines, RetroLines(item)...)
+	}
+	return items, lines, nil
+}
+
+// NewRetroItem compares what was settled for a period with its recalculation.
+func NewRetroItem(candidate RetroCandidate, previousPay, recalculatedPay, previousTaxable, recalculatedTaxable float64) RetroItem {
+	return RetroItem{
+		EmployeeID:          candidate.EmployeeID,
+		OriginalPeriodID:    candidate.PeriodID,
+		OriginalStartDate:   candidate.StartDate,
+		OriginalEndDate:     candidate.EndDate,
+		Triggers:            candidate.Triggers,
+		PreviousPay:         previousPay,
+		RecalculatedPay:     recalculatedPay,
+		PreviousTaxable:     previousTaxable,
+		RecalculatedTaxable: recalculatedTaxable,
+		Delta:               roundCents(recalculatedPay - previousPay),
+		TaxableDelta:        roundCents(recalculatedTaxable - previousTaxable),
+	}
+}
+
+// RetroLines turns a retro item into input lines for the paying period: the
+// taxable part of the difference and, when non-taxable pay also moved, the
+// rest. Negative differences become deductions.
+func RetroLines(item RetroItem) []InputLine {
+	description := fmt.Sprintf("Retro pay for %s to %s", item.OriginalStartDate.Format("2006-01-02"), item.OriginalEndDate.Format("2006-01-02"))
+	untaxed := roundCents(item.Delta - item.TaxableDelta)
+
+	var lines []InputLine
+	for _, part := range []struct {
+		amount  float64
+		taxable bool
+		code    string
+		suffix  string
+	}{
+		{item.TaxableDelta, true, ResultCodeRetroPay, ""},
+		{untaxed, false, ResultCodeRetroUntaxed, " (non-taxable)"},
+	} {
+		if math.Abs(part.amount) < 0.005 {
+			continue
+		}
+		line := InputLine{
+			Type:        ElementTypeEarning,
+			Amount:      part.amount,
+			Taxable:     part.taxable,
+			Source:      ResultSourceRetro,
+			SourceID:    item.OriginalPeriodID,
+			Code:        part.code,
+			Description: description + part.suffix,
+		}
+		if part.amount < 0 {
+			line.Type = ElementTypeDeduction
+			line.Amount = -part.amount
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,63 @@
+package payroll
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRetroLinesSplitTaxableAndNegativeDifferences(t *testing.T) {
+	candidate := RetroCandidate{EmployeeID: "e1", PeriodID: "p0", StartDate: mustDate("2025-12-01"), EndDate: mustDate("2025-12-31")}
+
+	lines := RetroLines(NewRetroItem(candidate, 3000, 3250, 3000, 3200))
+	if len(lines) != 2 {
+		t.Fatalf("expected taxable and non-taxable lines, got %+v", lines)
+	}
+	if lines[0].Code != ResultCodeRetroPay || !lines[0].Taxable || lines[0].Amount != 200 || lines[0].SourceID != "p0" {
+		t.Fatalf("unexpected taxable retro line %+v", lines[0])
+	}
+	if lines[1].Code != ResultCodeRetroUntaxed || lines[1].Taxable || lines[1].Amount != 50 {
+		t.Fatalf("unexpected non-taxable retro line %+v", lines[1])
+	}
+
+	recovery := RetroLines(NewRetroItem(candidate, 3000, 2900, 3000, 2900))
+	if len(recovery) != 1 || recovery[0].Type != ElementTypeDeduction || recovery[0].Amount != 100 || !recovery[0].Taxable {
+		t.Fatalf("expected a taxable deduction of 100, got %+v", recovery)
+	}
+
+	if settled := RetroLines(NewRetroItem(candidate, 3000, 3000, 3000, 3000)); len(settled) != 0 {
+		t.Fatalf("expected no lines once settled, got %+v", settled)
+	}
+}
+
+func TestRunPeriodPaysRetroForBackdatedRaise(t *testing.T) {
+	salary := 3720.0
+	store := &runStore{
+		employees: []EmployeePayrollData{{EmployeeID: "e1", SalaryPlain: &salary}},
+		compensation: map[string][]Compensation{
+			"e1": {compensationRecord("2025-01-01", 3100), compensationRecord("2025-12-16", 3720)},
+		},
+		retro: map[string][]RetroCandidate{
+			"e1": {{EmployeeID: "e1", PeriodID: "p0", StartDate: mustDate("2025-12-01"), EndDate: mustDate("2025-12-31"), Triggers: []string{RetroTriggerCompensation}}},
+		},
+		settled: map[string][2]float64{"p0": {3100, 3100}},
+	}
+	svc := NewService(store, nil)
+
+	if _, err := svc.RunPeriod(context.Background(), "t1", "p1", nil, nil); err != nil {
+		t.Fatalf("expected run to succeed, got %v", err)
+	}
+	var retro *ResultLine
+	for i, line := range store.lines["e1"] {
+		if line.Source == ResultSourceRetro {
+			retro = &store.lines["e1"][i]
+		}
+	}
+	// 16 of December's 31 days were at the higher rate: 620 * 16 / 31.
+	if retro == nil || retro.Code != ResultCodeRetroPay || retro.Amount != 320 || retro.SourceID != "p0" {
+		t.Fatalf("expected a 320 retro line for p0, got %+v", store.lines["e1"])
+	}
+	items := store.retroItems["e1"]
+	if len(items) != 1 || items[0].PeriodID != "p1" || items[0].Delta != 320 || items[0].RecalculatedPay != 3420 {
+		t.Fatalf("unexpected retro items %+v", items)
+	}
+}
@@ -0,0 +1,390 @@
+package payroll
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"hrm/internal/domain/core"
+	"hrm/internal/domain/leave"
+)
+
+type EmployeeRunStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RunProgress is persisted to job_runs.details_json while a payroll run is in
+// flight so clients can poll it and failed employees can be retried later.
+type RunProgress struct {
+	PeriodID    string                       `json:"periodId"`
+	ResumedFrom string                       `json:"resumedFrom,omitempty"`
+	Total       int                          `json:"total"`
+	Processed   int                          `json:"processed"`
+	Failed      int                          `json:"failed"`
+	Employees   map[string]EmployeeRunStatus `json:"employees"`
+}
+
+type employeeCalculation struct {
+	Calculation
+	Currency string
+	Warnings []string
+	Retro    []RetroItem
+}
+
+// RunPeriod calculates every eligible employee in the period, carrying forward
+// employees already completed by the previous run when one is supplied. A
+// failure for one employee is recorded and the run moves on to the next.
+func (s *Service) RunPeriod(ctx context.Context, tenantID, periodID string, previous *RunProgress, report func(RunProgress)) (RunProgress, error) {
+	progress := RunProgress{PeriodID: periodID, Employees: map[string]EmployeeRunStatus{}}
+	if previous != nil {
+		progress.ResumedFrom = previous.ResumedFrom
+	}
+
+	period, err := s.store.GetPeriodDetails(ctx, tenantID, periodID)
+	if err != nil {
+		return progress, err
+	}
+	if period.Status == PeriodStatusFinalized {
+		return progress, ErrRunFinalized
+	}
+	employees, err := s.runEmployees(ctx, tenantID, periodID, period)
+	if err != nil {
+		return progress, err
+	}
+	taxRules, err := s.EffectiveTaxRules(ctx, tenantID, period.EndDate)
+	if err != nil {
+		return progress, err
+	}
+	_, baseline, err := s.varianceBaseline(ctx, tenantID, periodID)
+	if err != nil {
+		return progress, err
+	}
+
+	progress.Total = len(employees)
+	for _, employee := range employees {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+		if previous != nil && previous.Employees[employee.EmployeeID].Status == RunEmployeeCompleted {
+			progress.Employees[employee.EmployeeID] = previous.Employees[employee.EmployeeID]
+			progress.Processed++
+			continue
+		}
+
+		if err := s.runEmployee(ctx, tenantID, periodID, period, employee, taxRules, baseline[employee.EmployeeID].Net); err != nil {
+			slog.Warn("payroll run employee failed", "periodId", periodID, "employeeId", employee.EmployeeID, "err", err)
+			progress.Employees[employee.EmployeeID] = EmployeeRunStatus{Status: RunEmployeeFailed, Error: err.Error()}
+			progress.Failed++
+		} else {
+			progress.Employees[employee.EmployeeID] = EmployeeRunStatus{Status: RunEmployeeCompleted}
+			progress.Processed++
+		}
+		if report != nil {
+			report(progress)
+		}
+	}
+
+	if progress.Failed > 0 {
+		return progress, fmt.Errorf("%w: %d of %d employees failed", ErrRunIncomplete, progress.Failed, progress.Total)
+	}
+	if err := s.store.UpdatePeriodStatus(ctx, tenantID, periodID, PeriodStatusReviewed); err != nil {
+		return progress, err
+	}
+	return progress, nil
+}
+
+// ResumableRun loads the progress of a failed run so RunPeriod can skip the
+// employees it already completed.
+func (s *Service) ResumableRun(ctx context.Context, tenantID, periodID, runID string) (*RunProgress, error) {
+	status, detailsJSON, err := s.store.PayrollRun(ctx, tenantID, runID)
+	if err != nil {
+		return nil, err
+	}
+	var progress RunProgress
+	if err := json.Unmarshal(detailsJSON, &progress); err != nil {
+		return nil, err
+	}
+	if status != "failed" || progress.PeriodID != periodID {
+		return nil, ErrRunNotResumable
+	}
+	progress.ResumedFrom = runID
+	return &progress, nil
+}
+
+// runEmployees returns the employees on the period's schedule who were
+// employed during it, or the employees selected for an off-cycle run whatever
+// their current status. Pay group and currency come from the compensation
+// record in force at the end of the period.
+func (s *Service) runEmployees(ctx context.Context, tenantID, periodID string, period PeriodDetails) ([]EmployeePayrollData, error) {
+	var employees []EmployeePayrollData
+	var err error
+	if period.OffCycle() {
+		employees, err = s.store.ListPeriodEmployees(ctx, tenantID, periodID)
+	} else {
+		employees, err = s.store.ListActiveEmployeesForRun(ctx, tenantID, core.EmployeeStatusActive, period.StartDate, period.EndDate)
+	}
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.compensationHistory(ctx, tenantID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	eligible := make([]EmployeePayrollData, 0, len(employees))
+	for _, employee := range employees {
+		employee.Compensation = history[employee.EmployeeID]
+		if current, ok := CompensationAt(employee.Compensation, period.EndDate); ok {
+			employee.Currency = current.Currency
+			employee.GroupScheduleID = current.ScheduleID
+		}
+		if !period.OffCycle() && employee.GroupScheduleID != "" && employee.GroupScheduleID != period.ScheduleID {
+			continue
+		}
+		eligible = append(eligible, employee)
+	}
+	return eligible, nil
+}
+
+// varianceBaseline returns the results of the last finalized period on the same
+// schedule, which net variance warnings and previews are measured against.
+func (s *Service) varianceBaseline(ctx context.Context, tenantID, periodID string) (string, map[string]EmployeeResult, error) {
+	previousID, err := s.store.PreviousFinalizedPeriodID(ctx, tenantID, periodID)
+	if err != nil || previousID == "" {
+		return "", map[string]EmployeeResult{}, err
+	}
+	results, err := s.store.PeriodResults(ctx, tenantID, previousID)
+	if err != nil {
+		return "", nil, err
+	}
+	return previousID, results, nil
+}
+
+func (s *Service) runEmployee(ctx context.Context, tenantID, periodID string, period PeriodDetails, employee EmployeePayrollData, taxRules []TaxRule, previousNet float64) error {
+	calc, err := s.calculateEmployee(ctx, tenantID, periodID, period, employee, taxRules, previousNet)
+	if err != nil {
+		return err
+	}
+	warningsJSON, err := json.Marshal(calc.Warnings)
+	if err != nil {
+		slog.Warn("warnings marshal failed", "err", err)
+		warningsJSON = []byte("[]")
+	}
+	record := ResultRecord{
+		EmployeeID:   employee.EmployeeID,
+		Gross:        calc.Gross,
+		TaxableGross: roundCents(calc.TaxableGross),
+		Deductions:   calc.Deductions,
+		Net:          calc.Net,
+		Currency:     calc.Currency,
+		WarningsJSON: warningsJSON,
+		Lines:        calc.Lines,
+		Retro:        calc.Retro,
+		ReplaceRetro: !period.OffCycle(),
+	}
+	if err := s.store.SaveEmployeeResult(ctx, tenantID, periodID, record); err != nil {
+		return fmt.Errorf("persist result: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) calculateEmployee(ctx context.Context, tenantID, periodID string, period PeriodDetails, employee EmployeePayrollData, taxRules []TaxRule, previousNet float64) (employeeCalculation, error) {
+	baseSalary, inputs, err := s.payInputs(ctx, tenantID, periodID, period, employee)
+	if err != nil {
+		return employeeCalculation{}, err
+	}
+	bankAccount := s.decryptOptional(employee.BankPlain, employee.BankEnc)
+
+	calc := employeeCalculation{Currency: employee.Currency}
+	if period.OffCycle() {
+		// Off-cycle runs pay only their own inputs, taxed on top of what the
+		// employee was already paid for the original period.
+		baseTaxable, err := s.store.OffCycleTaxableBase(ctx, tenantID, period.OriginalPeriodID, periodID, employee.EmployeeID)
+		if err != nil {
+			return employeeCalculation{}, fmt.Errorf("load original result: %w", err)
+		}
+		calc.Calculation = CalculateOffCycle(baseTaxable, inputs, taxRules)
+	} else {
+		retro, retroLines, err := s.retroItems(ctx, tenantID, periodID, period, employee)
+		if err != nil {
+			return employeeCalculation{}, fmt.Errorf("calculate retro pay: %w", err)
+		}
+		calc.Retro = retro
+		inputs = append(inputs, retroLines...)
+
+		recurring, err := s.store.ListRecurringForRun(ctx, tenantID, employee.EmployeeID, periodID, period.StartDate, period.EndDate)
+		if err != nil {
+			return employeeCalculation{}, fmt.Errorf("load recurring items: %w", err)
+		}
+		inputs = append(inputs, RecurringEarningLines(recurring)...)
+		var limited bool
+		calc.Calculation, limited = ApplyRecurringDeductions(Calculate(baseSalary, inputs, taxRules), recurring)
+		if limited {
+			calc.Warnings = append(calc.Warnings, WarningRecurring)
+		}
+	}
+	if bankAccount == "" {
+		calc.Warnings = append(calc.Warnings, WarningMissingBank)
+	}
+	if calc.Net < 0 {
+		calc.Warnings = append(calc.Warnings, WarningNegativeNet)
+	}
+	if NetVarianceExceeded(previousNet, calc.Net) {
+		calc.Warnings = append(calc.Warnings, WarningNetVariance)
+	}
+	return calc, nil
+}
+
+// payInputs builds the pre-tax pay for one employee and period: base salary
+// (or prorated salary lines), element inputs, adjustments and, for regular
+// periods, the unpaid leave deduction.
+func (s *Service) payInputs(ctx context.Context, tenantID, periodID string, period PeriodDetails, employee EmployeePayrollData) (float64, []InputLine, error) {
+	// salary is the rate in force at the end of the period; baseSalary and
+	// salaryLines pay it, or each rate in turn, for the days employed.
+	salary := s.salaryAt(employee, period.EndDate)
+
+	baseSalary, salaryLines := salary, []InputLine(nil)
+	var segments []SalarySegment
+	if !period.OffCycle() {
+		segments = SalarySegments(period.StartDate, period.EndDate, employee.StartDate, employee.EndDate, employee.Compensation, salary)
+		baseSalary, salaryLines = ProratedSalaryLines(period.StartDate, period.EndDate, segments)
+	}
+	paidBase := baseSalary
+	for _, line := range salaryLines {
+		paidBase += line.Amount
+	}
+
+	elementInputs, err := s.store.ListElementInputs(ctx, periodID, employee.EmployeeID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("load inputs: %w", err)
+	}
+	inputs, err := EvaluateElements(paidBase, elementInputs)
+	if err != nil {
+		return 0, nil, fmt.Errorf("evaluate elements: %w", err)
+	}
+	inputs = append(salaryLines, inputs...)
+	adjustments, err := s.store.ListAdjustmentLines(ctx, tenantID, periodID, employee.EmployeeID, period.StartDate, period.EndDate)
+	if err != nil {
+		return 0, nil, fmt.Errorf("load adjustments: %w", err)
+	}
+	inputs = append(inputs, adjustments...)
+
+	if !period.OffCycle() {
+		leaveWindows, err := s.store.ListUnpaidLeaves(ctx, tenantID, employee.EmployeeID, period.StartDate, period.EndDate, leave.StatusApproved)
+		if err != nil {
+			return 0, nil, fmt.Errorf("load unpaid leave: %w", err)
+		}
+		if len(leaveWindows) > 0 {
+			schedule, err := s.store.WorkSchedule(ctx, tenantID, employee.EmployeeID, period.StartDate, period.EndDate)
+			if err != nil {
+				return 0, nil, fmt.Errorf("load work schedule: %w", err)
+			}
+			inputs = append(inputs, unpaidLeaveLines(segments, period, leaveWindows, schedule)...)
+		}
+	}
+	return baseSalary, inputs, nil
+}
+
+// salaryAt returns the salary in force on the given day: the compensation
+// record for that day, or else the salary on the employee record.
+func (s *Service) salaryAt(employee EmployeePayrollData, on time.Time) float64 {
+	salary := 0.0
+	if employee.SalaryPlain != nil {
+		salary = *employee.SalaryPlain
+	}
+	if s.crypto != nil && s.crypto.Configured() && len(employee.SalaryEnc) > 0 {
+		if decrypted, err := s.crypto.DecryptString(employee.SalaryEnc); err == nil {
+			if parsed, err := strconv.ParseFloat(decrypted, 64); err == nil {
+				salary = parsed
+			}
+		}
+	}
+	if current, ok := CompensationAt(employee.Compensation, on); ok && current.Salary != nil {
+		salary = *current.Salary
+	}
+	return salary
+}
+
+// decryptOptional prefers the encrypted column when encryption is configured
+// and falls back to the legacy plaintext value otherwise.
+func (s *Service) decryptOptional(plain string, encrypted []byte) string {
+	if s.crypto != nil && s.crypto.Configured() && len(encrypted) > 0 {
+		if decrypted, err := s.crypto.DecryptString(encrypted); err == nil {
+			return decrypted
+		}
+	}
+	return plain
+}
+
+// unpaidLeaveLines deducts salary for unpaid leave in proportion to the
+// scheduled working days it covers, so rest days and holidays inside a leave
+// window cost nothing. Like the salary lines, each salary segment is priced
+// at its own rate, so leave is deducted at the salary in force on the day.
+// A single segment spanning the whole period gives one line without dates.
+func unpaidLeaveLines(segments []SalarySegment, period PeriodDetails, windows []LeaveWindow, schedule leave.WorkSchedule) []InputLine {
+	periodDays, err := leave.WorkingDays(period.StartDate, period.EndDate, schedule)
+	if err != nil || periodDays <= 0 {
+		return nil
+	}
+	whole := len(segments) == 1 && segments[0].From.Equal(period.StartDate) && segments[0].To.Equal(period.EndDate)
+
+	var lines []InputLine
+	for _, segment := range segments {
+		unpaidDays := unpaidDaysBetween(segment.From, segment.To, windows, schedule)
+		deduction := (segment.Salary / periodDays) * unpaidDays
+		if unpaidDays <= 0 || deduction <= 0 {
+			continue
+		}
+		description := fmt.Sprintf("Unpaid leave (%.1f days)", unpaidDays)
+		if !whole {
+			description = fmt.Sprintf("Unpaid leave %s to %s (%.1f days)", segment.From.Format("2006-01-02"), segment.To.Format("2006-01-02"), unpaidDays)
+		}
+		lines = append(lines, InputLine{
+			Type:        ElementTypeDeduction,
+			Amount:      deduction,
+			Taxable:     true,
+			Source:      ResultSourceUnpaidLeave,
+			Code:        ResultCodeUnpaidLeave,
+			Description: description,
+		})
+	}
+	return lines
+}
+
+// unpaidDaysBetween counts the scheduled working days of the leave windows
+// falling from from to to. A half-day boundary counts half when it falls in
+// the range.
+func unpaidDaysBetween(from, to time.Time, windows []LeaveWindow, schedule leave.WorkSchedule) float64 {
+	var unpaidDays float64
+	for _, window := range windows {
+		overlapStart := window.StartDate
+		if from.After(overlapStart) {
+			overlapStart = from
+		}
+		overlapEnd := window.EndDate
+		if to.Before(overlapEnd) {
+			overlapEnd = to
+		}
+		if overlapEnd.Before(overlapStart) {
+			continue
+		}
+		days, err := leave.WorkingDays(overlapStart, overlapEnd, schedule)
+		if err != nil {
+			continue
+		}
+		if window.StartHalf && overlapStart.Equal(window.StartDate) {
+			days -= schedule.WorkingFraction(overlapStart) / 2
+		}
+		if window.EndHalf && overlapEnd.Equal(window.EndDate) {
+			days -= schedule.WorkingFraction(overlapEnd) / 2
+		}
+		if days > 0 {
+			unpaidDays += days
+		}
+	}
+	return unpaidDays
+}
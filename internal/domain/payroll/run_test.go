@@ -0,0 +1,207 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hrm/internal/domain/leave"
+)
+
+type runStore struct {
+	StoreAPI
+	employees []EmployeePayrollData
+	failFor   map[string]bool
+	upserted  []string
+	status    string
+
+	compensation map[string][]Compensation
+	retro        map[string][]RetroCandidate
+	settled      map[string][2]float64
+	lines        map[string][]ResultLine
+	retroItems   map[string][]RetroItem
+	recurring    map[string][]RecurringItem
+}
+
+func (s *runStore) GetPeriodDetails(context.Context, string, string) (PeriodDetails, error) {
+	return PeriodDetails{
+		Status:     PeriodStatusDraft,
+		StartDate:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+		ScheduleID: "s1",
+	}, nil
+}
+
+func (s *runStore) ListActiveEmployeesForRun(context.Context, string, string, time.Time, time.Time) ([]EmployeePayrollData, error) {
+	return s.employees, nil
+}
+
+func (s *runStore) ListCompensationForPeriod(context.Context, string, time.Time, time.Time) (map[string][]Compensation, error) {
+	return s.compensation, nil
+}
+
+func (s *runStore) ListCompensation(_ context.Context, _, employeeID string) ([]Compensation, error) {
+	return s.compensation[employeeID], nil
+}
+
+func (s *runStore) ListRetroCandidates(_ context.Context, _, employeeID string, _ time.Time) ([]RetroCandidate, error) {
+	return s.retro[employeeID], nil
+}
+
+func (s *runStore) ListBackdatedAdjustmentLines(context.Context, string, string, string, time.Time, time.Time) ([]InputLine, error) {
+	return nil, nil
+}
+
+func (s *runStore) RetroSettled(_ context.Context, _, originalPeriodID, _, _ string) (float64, float64, error) {
+	settled := s.settled[originalPeriodID]
+	return settled[0], settled[1], nil
+}
+
+func (s *runStore) ListRecurringForRun(_ context.Context, _, employeeID, _ string, _, _ time.Time) ([]RecurringItem, error) {
+	return s.recurring[employeeID], nil
+}
+
+func (s *runStore) ListTaxRules(context.Context, string) ([]TaxRule, error) {
+	return nil, nil
+}
+
+func (s *runStore) ListElementInputs(_ context.Context, _, employeeID string) ([]ElementInput, error) {
+	if s.failFor[employeeID] {
+		return nil, errors.New("inputs unavailable")
+	}
+	return nil, nil
+}
+
+func (s *runStore) ListAdjustmentLines(context.Context, string, string, string, time.Time, time.Time) ([]InputLine, error) {
+	return nil, nil
+}
+
+func (s *runStore) ListUnpaidLeaves(context.Context, string, string, time.Time, time.Time, string) ([]LeaveWindow, error) {
+	return nil, nil
+}
+
+func (s *runStore) PreviousFinalizedPeriodID(context.Context, string, string) (string, error) {
+	return "", nil
+}
+
+func (s *runStore) SaveEmployeeResult(_ context.Context, _, _ string, record ResultRecord) error {
+	s.upserted = append(s.upserted, record.EmployeeID)
+	if s.lines == nil {
+		s.lines = map[string][]ResultLine{}
+	}
+	s.lines[record.EmployeeID] = record.Lines
+	if record.ReplaceRetro {
+		if s.retroItems == nil {
+			s.retroItems = map[string][]RetroItem{}
+		}
+		s.retroItems[record.EmployeeID] = record.Retro
+	}
+	return nil
+}
+
+func (s *runStore) UpdatePeriodStatus(_ context.Context, _, _, status string) error {
+	s.status = status
+	return nil
+}
+
+func TestRunPeriodContinuesPastFailuresAndResumes(t *testing.T) {
+	salary := 3100.0
+	store := &runStore{
+		employees: []EmployeePayrollData{
+			{EmployeeID: "e1", SalaryPlain: &salary},
+			{EmployeeID: "e2", SalaryPlain: &salary},
+			{EmployeeID: "e3", SalaryPlain: &salary, GroupScheduleID: "other"},
+		},
+		failFor: map[string]bool{"e1": true},
+	}
+	svc := NewService(store, nil)
+
+	reports := 0
+	progress, err := svc.RunPeriod(context.Background(), "t1", "p1", nil, func(RunProgress) { reports++ })
+	if !errors.Is(err, ErrRunIncomplete) {
+		t.Fatalf("expected incomplete run, got %v", err)
+	}
+	if progress.Total != 2 || progress.Processed != 1 || progress.Failed != 1 || reports != 2 {
+		t.Fatalf("unexpected progress %+v after %d reports", progress, reports)
+	}
+	if progress.Employees["e1"].Status != RunEmployeeFailed || store.status != "" {
+		t.Fatalf("expected e1 failed and period untouched, got %+v / %q", progress.Employees["e1"], store.status)
+	}
+
+	store.failFor = nil
+	store.upserted = nil
+	progress.ResumedFrom = "run-1"
+	resumed, err := svc.RunPeriod(context.Background(), "t1", "p1", &progress, nil)
+	if err != nil {
+		t.Fatalf("expected resumed run to succeed, got %v", err)
+	}
+	if len(store.upserted) != 1 || store.upserted[0] != "e1" {
+		t.Fatalf("expected only e1 to be recomputed, got %v", store.upserted)
+	}
+	if resumed.Processed != 2 || resumed.ResumedFrom != "run-1" || store.status != PeriodStatusReviewed {
+		t.Fatalf("unexpected resumed progress %+v with period status %q", resumed, store.status)
+	}
+}
+
+func TestUnpaidLeaveLinesProrateOverlap(t *testing.T) {
+	period := PeriodDetails{
+		StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+	}
+	windows := []LeaveWindow{{
+		StartDate: time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		EndHalf:   true,
+	}}
+	whole := func(salary float64) []SalarySegment {
+		return []SalarySegment{{From: period.StartDate, To: period.EndDate, Salary: salary}}
+	}
+
+	lines := unpaidLeaveLines(whole(1000), period, windows, leave.WorkSchedule{})
+	if len(lines) != 1 {
+		t.Fatal("expected unpaid leave deduction")
+	}
+	if lines[0].Amount != 150 || lines[0].Code != ResultCodeUnpaidLeave || lines[0].Description != "Unpaid leave (1.5 days)" {
+		t.Fatalf("expected 1.5 days deducted as 150, got %+v", lines[0])
+	}
+	if lines := unpaidLeaveLines(whole(0), period, windows, leave.WorkSchedule{}); len(lines) != 0 {
+		t.Fatal("expected no deduction without salary")
+	}
+
+	// Monday to Friday with New Year's Day off: the period has six working
+	// days and the leave covers half of Friday 2 January.
+	schedule := leave.WorkSchedule{Pattern: leave.StandardWorkPattern(), Holidays: map[string]bool{"2026-01-01": true}}
+	lines = unpaidLeaveLines(whole(600), period, windows, schedule)
+	if len(lines) != 1 || lines[0].Amount != 50 {
+		t.Fatalf("expected half of one of six working days deducted as 50, got %+v", lines)
+	}
+}
+
+func TestUnpaidLeaveLinesUseRateInForceEachDay(t *testing.T) {
+	period := PeriodDetails{
+		StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+	}
+	// A raise from 1000 to 2000 on 6 January, with unpaid leave from 4 to 7
+	// January: two days at the old rate and two at the new one.
+	segments := []SalarySegment{
+		{From: period.StartDate, To: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Salary: 1000},
+		{From: time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC), To: period.EndDate, Salary: 2000},
+	}
+	windows := []LeaveWindow{{
+		StartDate: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
+	}}
+
+	lines := unpaidLeaveLines(segments, period, windows, leave.WorkSchedule{})
+	if len(lines) != 2 {
+		t.Fatalf("expected a deduction per salary segment, got %+v", lines)
+	}
+	if lines[0].Amount != 200 || lines[1].Amount != 400 {
+		t.Fatalf("expected 2 days at 100 and 2 days at 200, got %v and %v", lines[0].Amount, lines[1].Amount)
+	}
+	if lines[1].Description != "Unpaid leave 2026-01-06 to 2026-01-10 (2.0 days)" {
+		t.Fatalf("unexpected description %q", lines[1].Description)
+	}
+}
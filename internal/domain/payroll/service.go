@@ -2,12 +2,9 @@ package payroll
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
 
-	"github.com/jung-kurt/gofpdf"
-
 	cryptoutil "hrm/internal/platform/crypto"
 )
 
@@ -21,44 +18,24 @@ func NewService(store StoreAPI, crypto *cryptoutil.Service) *Service {
 }
 
 func (s *Service) GeneratePayslipPDF(ctx context.Context, tenantID, periodID, employeeID, payslipID string) (string, error) {
-	data, err := s.store.PayslipPDFData(ctx, tenantID, periodID, employeeID)
+	content, err := s.RenderPayslipPDF(ctx, tenantID, periodID, employeeID, "")
 	if err != nil {
 		return "", err
 	}
 
-	if err := os.MkdirAll("storage/payslips", 0o755); err != nil {
-		return "", err
-	}
-	filePath := filepath.Join("storage/payslips", payslipID+".pdf")
-
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-	pdf.SetFont("Helvetica", "B", 16)
-	pdf.Cell(40, 10, "Payslip")
-	pdf.Ln(12)
-	pdf.SetFont("Helvetica", "", 12)
-	pdf.Cell(0, 8, fmt.Sprintf("Employee: %s %s", data.FirstName, data.LastName))
-	pdf.Ln(7)
-	pdf.Cell(0, 8, fmt.Sprintf("Email: %s", data.Email))
-	pdf.Ln(7)
-	pdf.Cell(0, 8, fmt.Sprintf("Period: %s to %s", data.StartDate.Format("2006-01-02"), data.EndDate.Format("2006-01-02")))
-	pdf.Ln(10)
-	pdf.Cell(0, 8, fmt.Sprintf("Gross: %.2f %s", data.Gross, data.Currency))
-	pdf.Ln(7)
-	pdf.Cell(0, 8, fmt.Sprintf("Deductions: %.2f %s", data.Deductions, data.Currency))
-	pdf.Ln(7)
-	pdf.Cell(0, 8, fmt.Sprintf("Net: %.2f %s", data.Net, data.Currency))
+	return s.writePDF("storage/payslips", payslipID, content)
+}
 
-	if err := pdf.OutputFileAndClose(filePath); err != nil {
+// writePDF stores a generated document, encrypting it at rest when a key is
+// configured, and returns the path recorded against the document.
+func (s *Service) writePDF(dir, name string, content []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return "", err
 	}
+	filePath := filepath.Join(dir, name+".pdf")
 
 	if s.crypto != nil && s.crypto.Configured() {
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			return "", err
-		}
-		encrypted, err := s.crypto.Encrypt(data)
+		encrypted, err := s.crypto.Encrypt(content)
 		if err != nil {
 			return "", err
 		}
@@ -66,11 +43,37 @@ func (s *Service) GeneratePayslipPDF(ctx context.Context, tenantID, periodID, em
 		if err := os.WriteFile(encryptedPath, encrypted, 0o600); err != nil {
 			return "", err
 		}
-		if err := os.Remove(filePath); err != nil {
-			return "", err
-		}
 		return encryptedPath, nil
 	}
 
+	if err := os.WriteFile(filePath, content, 0o644); err != nil {
+		return "", err
+	}
 	return filePath, nil
 }
+
+// RenderPayslipPDF renders an employee's payslip for the period with the given
+// template, or the tenant's default template when templateID is empty.
+func (s *Service) RenderPayslipPDF(ctx context.Context, tenantID, periodID, employeeID, templateID string) ([]byte, error) {
+	data, err := s.store.PayslipPDFData(ctx, tenantID, periodID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := s.payslipTemplate(ctx, tenantID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return RenderPayslip(data, template)
+}
+
+func (s *Service) payslipTemplate(ctx context.Context, tenantID, templateID string) (PayslipTemplate, error) {
+	if templateID != "" {
+		return s.store.PayslipTemplate(ctx, tenantID, templateID)
+	}
+	template, ok, err := s.store.DefaultPayslipTemplate(ctx, tenantID)
+	if err != nil || !ok {
+		return DefaultPayslipTemplate(), err
+	}
+	return template, nil
+}
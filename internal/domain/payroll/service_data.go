@@ -10,8 +10,8 @@ func (s *Service) ListSchedules(ctx context.Context, tenantID string) ([]Schedul
 	return s.store.ListSchedules(ctx, tenantID)
 }
 
-func (s *Service) CreateSchedule(ctx context.Context, tenantID, name, frequency string, payDay int) (string, error) {
-	return s.store.CreateSchedule(ctx, tenantID, name, frequency, payDay)
+func (s *Service) CreateSchedule(ctx context.Context, tenantID, name, frequency string, payDay int, holidayRegion string) (string, error) {
+	return s.store.CreateSchedule(ctx, tenantID, name, frequency, payDay, holidayRegion)
 }
 
 func (s *Service) ListGroups(ctx context.Context, tenantID string) ([]Group, error) {
@@ -93,28 +93,36 @@ func (s *Service) UpdateJobRun(ctx context.Context, runID, status string, detail
 	return s.store.UpdateJobRun(ctx, runID, status, detailsJSON)
 }
 
-func (s *Service) ListActiveEmployeesForRun(ctx context.Context, tenantID, status string) ([]EmployeePayrollData, error) {
-	return s.store.ListActiveEmployeesForRun(ctx, tenantID, status)
+func (s *Service) ActivePayrollRunID(ctx context.Context, tenantID, periodID string) (string, error) {
+	return s.store.ActivePayrollRunID(ctx, tenantID, periodID)
 }
 
-func (s *Service) ListInputLines(ctx context.Context, periodID, employeeID string) ([]InputLine, error) {
-	return s.store.ListInputLines(ctx, periodID, employeeID)
+func (s *Service) ListActiveEmployeesForRun(ctx context.Context, tenantID, status string, periodStart, periodEnd time.Time) ([]EmployeePayrollData, error) {
+	return s.store.ListActiveEmployeesForRun(ctx, tenantID, status, periodStart, periodEnd)
 }
 
-func (s *Service) ListAdjustmentAmounts(ctx context.Context, tenantID, periodID, employeeID string, periodStart, periodEnd time.Time) ([]float64, error) {
-	return s.store.ListAdjustmentAmounts(ctx, tenantID, periodID, employeeID, periodStart, periodEnd)
+func (s *Service) ListAdjustmentLines(ctx context.Context, tenantID, periodID, employeeID string, periodStart, periodEnd time.Time) ([]InputLine, error) {
+	return s.store.ListAdjustmentLines(ctx, tenantID, periodID, employeeID, periodStart, periodEnd)
 }
 
 func (s *Service) ListUnpaidLeaves(ctx context.Context, tenantID, employeeID string, periodStart, periodEnd time.Time, status string) ([]LeaveWindow, error) {
 	return s.store.ListUnpaidLeaves(ctx, tenantID, employeeID, periodStart, periodEnd, status)
 }
 
-func (s *Service) LatestNet(ctx context.Context, tenantID, employeeID string) (float64, error) {
-	return s.store.LatestNet(ctx, tenantID, employeeID)
+func (s *Service) UpsertPayrollResult(ctx context.Context, tenantID, periodID, employeeID string, gross, taxableGross, deductions, net float64, currency string, warningsJSON []byte) error {
+	return s.store.UpsertPayrollResult(ctx, tenantID, periodID, employeeID, gross, taxableGross, deductions, net, currency, warningsJSON)
 }
 
-func (s *Service) UpsertPayrollResult(ctx context.Context, tenantID, periodID, employeeID string, gross, deductions, net float64, currency string, warningsJSON []byte) error {
-	return s.store.UpsertPayrollResult(ctx, tenantID, periodID, employeeID, gross, deductions, net, currency, warningsJSON)
+func (s *Service) EmployeeResult(ctx context.Context, tenantID, periodID, employeeID string) (EmployeeResult, error) {
+	result, err := s.store.PayrollResult(ctx, tenantID, periodID, employeeID)
+	if err != nil {
+		return result, err
+	}
+	result.Lines, err = s.store.ListResultLines(ctx, tenantID, periodID, employeeID)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
 func (s *Service) UpdatePeriodStatus(ctx context.Context, tenantID, periodID, status string) error {
@@ -172,6 +180,23 @@ func (s *Service) PeriodSummary(ctx context.Context, tenantID, periodID string)
 			summary.Warnings[key]++
 		}
 	}
+
+	// Amounts are converted at the rates in force on the last day of the
+	// period, the same date the journal is posted on.
+	period, err := s.store.GetPeriodDetails(ctx, tenantID, periodID)
+	if err != nil {
+		return summary, err
+	}
+	converter, err := s.Converter(ctx, tenantID, period.EndDate)
+	if err != nil {
+		return summary, err
+	}
+	summary.ReportingCurrency = converter.Currency
+	totals, err := converter.ConvertTotals(summary.ByCurrency)
+	if err != nil {
+		return summary, err
+	}
+	summary.TotalGross, summary.TotalDeductions, summary.TotalNet = totals.Gross, totals.Deductions, totals.Net
 	return summary, nil
 }
 
@@ -188,11 +213,22 @@ func (s *Service) DeletePayslipsForPeriod(ctx context.Context, tenantID, periodI
 }
 
 func (s *Service) RegisterRows(ctx context.Context, tenantID, periodID string) ([]RegisterRow, error) {
-	return s.store.RegisterRows(ctx, tenantID, periodID)
+	rows, err := s.store.RegisterRows(ctx, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.store.ListPeriodResultLines(ctx, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Lines = lines[rows[i].EmployeeID]
+	}
+	return rows, nil
 }
 
-func (s *Service) PeriodTotals(ctx context.Context, tenantID, periodID string) (float64, float64, float64, error) {
-	return s.store.PeriodTotals(ctx, tenantID, periodID)
+func (s *Service) PeriodResultLines(ctx context.Context, tenantID, periodID string) (map[string][]ResultLine, error) {
+	return s.store.ListPeriodResultLines(ctx, tenantID, periodID)
 }
 
 func (s *Service) JournalTemplateConfig(ctx context.Context, tenantID, templateID string) (map[string]any, error) {
@@ -221,3 +257,95 @@ func (s *Service) PayslipPeriodID(ctx context.Context, tenantID, payslipID strin
 func (s *Service) PayslipEmployeePeriod(ctx context.Context, tenantID, payslipID string) (string, string, error) {
 	return s.store.PayslipEmployeePeriod(ctx, tenantID, payslipID)
 }
+
+func (s *Service) ListTaxRules(ctx context.Context, tenantID string) ([]TaxRule, error) {
+	return s.store.ListTaxRules(ctx, tenantID)
+}
+
+func (s *Service) CreateTaxRule(ctx context.Context, tenantID string, rule TaxRule) (string, error) {
+	return s.store.CreateTaxRule(ctx, tenantID, rule)
+}
+
+func (s *Service) EffectiveTaxRules(ctx context.Context, tenantID string, on time.Time) ([]TaxRule, error) {
+	rules, err := s.store.ListTaxRules(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return EffectiveTaxRules(rules, on), nil
+}
+
+func (s *Service) ReplaceResultLines(ctx context.Context, tenantID, periodID, employeeID string, lines []ResultLine) error {
+	return s.store.ReplaceResultLines(ctx, tenantID, periodID, employeeID, lines)
+}
+
+// PaymentSettings returns the tenant's payment settings for display, with the
+// debtor IBAN and account number masked to their last four characters.
+func (s *Service) PaymentSettings(ctx context.Context, tenantID string) (PaymentSettings, error) {
+	settings, err := s.PaymentFileSettings(ctx, tenantID)
+	if err != nil {
+		return PaymentSettings{}, err
+	}
+	return settings.Masked(), nil
+}
+
+// PaymentFileSettings returns the tenant's payment settings with the debtor
+// IBAN and account number decrypted. It is only for building SEPA and NACHA
+// files; anything shown to users goes through PaymentSettings.
+func (s *Service) PaymentFileSettings(ctx context.Context, tenantID string) (PaymentSettings, error) {
+	settings, err := s.store.PaymentSettings(ctx, tenantID)
+	if err != nil {
+		return settings, err
+	}
+	if s.crypto != nil && s.crypto.Configured() {
+		if len(settings.DebtorIBANEnc) > 0 {
+			if settings.DebtorIBAN, err = s.crypto.DecryptString(settings.DebtorIBANEnc); err != nil {
+				return PaymentSettings{}, err
+			}
+		}
+		if len(settings.AccountNumberEnc) > 0 {
+			if settings.AccountNumber, err = s.crypto.DecryptString(settings.AccountNumberEnc); err != nil {
+				return PaymentSettings{}, err
+			}
+		}
+	}
+	settings.DebtorIBANEnc, settings.AccountNumberEnc = nil, nil
+	return settings, nil
+}
+
+// UpsertPaymentSettings saves the tenant's payment settings, storing the
+// debtor IBAN and account number encrypted when encryption is configured.
+func (s *Service) UpsertPaymentSettings(ctx context.Context, tenantID string, settings PaymentSettings) error {
+	settings.DebtorIBANEnc, settings.AccountNumberEnc = nil, nil
+	if s.crypto != nil && s.crypto.Configured() {
+		var err error
+		if settings.DebtorIBAN != "" {
+			if settings.DebtorIBANEnc, err = s.crypto.EncryptString(settings.DebtorIBAN); err != nil {
+				return err
+			}
+			settings.DebtorIBAN = ""
+		}
+		if settings.AccountNumber != "" {
+			if settings.AccountNumberEnc, err = s.crypto.EncryptString(settings.AccountNumber); err != nil {
+				return err
+			}
+			settings.AccountNumber = ""
+		}
+	}
+	return s.store.UpsertPaymentSettings(ctx, tenantID, settings)
+}
+
+func (s *Service) CreateJournalExport(ctx context.Context, tenantID string, export JournalExport) (string, error) {
+	return s.store.CreateJournalExport(ctx, tenantID, export)
+}
+
+func (s *Service) ListJournalExports(ctx context.Context, tenantID, periodID string) ([]JournalExport, error) {
+	return s.store.ListJournalExports(ctx, tenantID, periodID)
+}
+
+func (s *Service) ListPayslipTemplates(ctx context.Context, tenantID string) ([]PayslipTemplate, error) {
+	return s.store.ListPayslipTemplates(ctx, tenantID)
+}
+
+func (s *Service) SavePayslipTemplate(ctx context.Context, tenantID string, template PayslipTemplate, replaceLogo bool) (string, error) {
+	return s.store.SavePayslipTemplate(ctx, tenantID, template, replaceLogo)
+}
@@ -1,11 +1,24 @@
 package payroll
 
-import "hrm/internal/platform/querier"
+import (
+	"context"
+	"time"
+
+	"hrm/internal/domain/leave"
+	"hrm/internal/platform/querier"
+)
 
 type Store struct {
 	DB querier.Querier
+	// Schedules loads the work patterns and holidays unpaid leave and
+	// encashments are prorated on.
+	Schedules WorkScheduleLoader
+}
+
+type WorkScheduleLoader interface {
+	WorkSchedule(ctx context.Context, tenantID, employeeID string, from, to time.Time) (leave.WorkSchedule, error)
 }
 
-func NewStore(db querier.Querier) *Store {
-	return &Store{DB: db}
+func NewStore(db querier.Querier, schedules WorkScheduleLoader) *Store {
+	return &Store{DB: db, Schedules: schedules}
 }
@@ -0,0 +1,240 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// applyAccumulators adds (sign 1) or removes (sign -1) a period's results and
+// result lines from the per-employee accumulators of the period's tax year
+// inside tx. Amounts are kept apart by the currency they were paid in.
+func applyAccumulators(ctx context.Context, tx pgx.Tx, tenantID, periodID string, sign int) error {
+	if _, err := tx.Exec(ctx, `
+    INSERT INTO payroll_accumulators (tenant_id, employee_id, tax_year, currency, line_type, code, description, amount, periods)
+    SELECT r.tenant_id, r.employee_id, p.tax_year, COALESCE(r.currency, ''), $4, t.code, t.description, $3 * t.amount, $3
+    FROM payroll_results r
+    JOIN payroll_periods p ON r.period_id = p.id
+    CROSS JOIN LATERAL (VALUES
+      ('gross', 'Gross pay', r.gross),
+      ('taxable_gross', 'Taxable gross', r.taxable_gross),
+      ('deductions', 'Deductions', r.deductions),
+      ('net', 'Net pay', r.net),
+      ('employer_cost', 'Employer cost', r.gross + COALESCE((
+        SELECT SUM(l.amount) FROM payroll_result_lines l
+        WHERE l.period_id = r.period_id AND l.employee_id = r.employee_id AND l.line_type = $5
+      ), 0))
+    ) AS t(code, description, amount)
+    WHERE r.tenant_id = $1 AND r.period_id = $2
+    ON CONFLICT (tenant_id, employee_id, tax_year, currency, line_type, code)
+    DO UPDATE SET amount = payroll_accumulators.amount + EXCLUDED.amount,
+                  periods = payroll_accumulators.periods + EXCLUDED.periods,
+                  updated_at = now()
+  `, tenantID, periodID, sign, AccumulatorTotal, ResultLineEmployerContribution); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+    INSERT INTO payroll_accumulators (tenant_id, employee_id, tax_year, currency, line_type, code, description, amount, periods)
+    SELECT l.tenant_id, l.employee_id, p.tax_year, COALESCE(r.currency, ''), l.line_type, l.code, MAX(l.description), $3 * SUM(l.amount), $3
+    FROM payroll_result_lines l
+    JOIN payroll_periods p ON l.period_id = p.id
+    JOIN payroll_results r ON r.period_id = l.period_id AND r.employee_id = l.employee_id
+    WHERE l.tenant_id = $1 AND l.period_id = $2
+    GROUP BY l.tenant_id, l.employee_id, p.tax_year, r.currency, l.line_type, l.code
+    ON CONFLICT (tenant_id, employee_id, tax_year, currency, line_type, code)
+    DO UPDATE SET amount = payroll_accumulators.amount + EXCLUDED.amount,
+                  periods = payroll_accumulators.periods + EXCLUDED.periods,
+                  description = EXCLUDED.description,
+                  updated_at = now()
+  `, tenantID, periodID, sign)
+	return err
+}
+
+// ReopenPeriod moves a finalized period back to draft and reverses its
+// contribution to the accumulators in the same transaction. Periods that
+// finalized off-cycle runs or retro pay were calculated against stay closed.
+func (s *Store) ReopenPeriod(ctx context.Context, tenantID, periodID string) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var status string
+	if err := tx.QueryRow(ctx, `
+    SELECT status
+    FROM payroll_periods
+    WHERE tenant_id = $1 AND id = $2
+    FOR UPDATE
+  `, tenantID, periodID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPeriodNotFound
+		}
+		return err
+	}
+	if status != PeriodStatusFinalized {
+		return ErrReopenInvalidState
+	}
+	var offCycleRuns int
+	if err := tx.QueryRow(ctx, `
+    SELECT COUNT(1)
+    FROM payroll_periods
+    WHERE tenant_id = $1 AND original_period_id = $2 AND status = $3
+  `, tenantID, periodID, PeriodStatusFinalized).Scan(&offCycleRuns); err != nil {
+		return err
+	}
+	if offCycleRuns > 0 {
+		return ErrReopenHasOffCycle
+	}
+	var retroSettlements int
+	if err := tx.QueryRow(ctx, `
+    SELECT COUNT(1)
+    FROM payroll_retro_items ri
+    JOIN payroll_periods p ON ri.period_id = p.id
+    WHERE ri.tenant_id = $1 AND ri.original_period_id = $2 AND p.status = $3
+  `, tenantID, periodID, PeriodStatusFinalized).Scan(&retroSettlements); err != nil {
+		return err
+	}
+	if retroSettlements > 0 {
+		return ErrReopenHasRetro
+	}
+
+	if err := applyAccumulators(ctx, tx, tenantID, periodID, -1); err != nil {
+		return err
+	}
+	if err := applyRecurringBalances(ctx, tx, tenantID, periodID, -1); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+    UPDATE payroll_periods
+    SET status = $1, run_by = NULL, resumed_by = NULL, submitted_by = NULL, submitted_at = NULL,
+        decided_by = NULL, decided_at = NULL, decision = NULL, decision_comment = NULL, tax_year = NULL
+    WHERE id = $2 AND tenant_id = $3
+  `, PeriodStatusDraft, periodID, tenantID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (s *Store) ListAccumulators(ctx context.Context, tenantID, employeeID string, taxYear int) ([]Accumulator, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT tax_year, currency, line_type, code, description, amount, periods, updated_at
+    FROM payroll_accumulators
+    WHERE tenant_id = $1 AND employee_id = $2 AND tax_year = $3
+    ORDER BY currency, line_type, code
+  `, tenantID, employeeID, taxYear)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Accumulator, 0)
+	for rows.Next() {
+		var acc Accumulator
+		if err := rows.Scan(&acc.TaxYear, &acc.Currency, &acc.LineType, &acc.Code, &acc.Description, &acc.Amount, &acc.Periods, &acc.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, acc)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) AccumulatorEmployees(ctx context.Context, tenantID string, taxYear int) ([]string, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT DISTINCT employee_id
+    FROM payroll_accumulators
+    WHERE tenant_id = $1 AND tax_year = $2 AND line_type = $3 AND periods > 0
+  `, tenantID, taxYear, AccumulatorTotal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) AnnualStatementData(ctx context.Context, tenantID, employeeID string, taxYear int) (AnnualStatementData, error) {
+	data := AnnualStatementData{TaxYear: taxYear}
+	if err := s.DB.QueryRow(ctx, `
+    SELECT e.first_name, e.last_name, e.email
+    FROM employees e
+    WHERE e.tenant_id = $1 AND e.id = $2
+  `, tenantID, employeeID).Scan(&data.FirstName, &data.LastName, &data.Email); err != nil {
+		return AnnualStatementData{}, err
+	}
+	accumulators, err := s.ListAccumulators(ctx, tenantID, employeeID, taxYear)
+	if err != nil {
+		return AnnualStatementData{}, err
+	}
+	data.Accumulators = accumulators
+	return data, nil
+}
+
+func (s *Store) UpsertAnnualStatement(ctx context.Context, tenantID, employeeID string, taxYear int, fileURL string) (string, error) {
+	var id string
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO payroll_annual_statements (tenant_id, employee_id, tax_year, file_url)
+    VALUES ($1,$2,$3,$4)
+    ON CONFLICT (tenant_id, employee_id, tax_year)
+    DO UPDATE SET file_url = EXCLUDED.file_url, generated_at = now()
+    RETURNING id
+  `, tenantID, employeeID, taxYear, fileURL).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *Store) ListAnnualStatements(ctx context.Context, tenantID, employeeID string) ([]AnnualStatement, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, employee_id, tax_year, generated_at
+    FROM payroll_annual_statements
+    WHERE tenant_id = $1 AND ($2 = '' OR employee_id::text = $2)
+    ORDER BY tax_year DESC, generated_at DESC
+  `, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]AnnualStatement, 0)
+	for rows.Next() {
+		var statement AnnualStatement
+		if err := rows.Scan(&statement.ID, &statement.EmployeeID, &statement.TaxYear, &statement.GeneratedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, statement)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) AnnualStatementFile(ctx context.Context, tenantID, statementID string) (string, string, error) {
+	var employeeID, fileURL string
+	if err := s.DB.QueryRow(ctx, `
+    SELECT employee_id, COALESCE(file_url, '')
+    FROM payroll_annual_statements
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, statementID).Scan(&employeeID, &fileURL); err != nil {
+		return "", "", err
+	}
+	return employeeID, fileURL, nil
+}
@@ -0,0 +1,153 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"hrm/internal/domain/core"
+)
+
+func (s *Store) MarkPeriodRun(ctx context.Context, tenantID, periodID, userID string, resumed bool) error {
+	runBy := "run_by = $2, resumed_by = NULL"
+	if resumed {
+		runBy = "resumed_by = $2"
+	}
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE payroll_periods
+    SET status = $1, `+runBy+`, submitted_by = NULL, submitted_at = NULL,
+        decided_by = NULL, decided_at = NULL, decision = NULL, decision_comment = NULL
+    WHERE tenant_id = $3 AND id = $4 AND status <> $5
+  `, PeriodStatusDraft, nullIfEmpty(userID), tenantID, periodID, PeriodStatusFinalized)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRunFinalized
+	}
+	return nil
+}
+
+func (s *Store) SubmitPeriod(ctx context.Context, tenantID, periodID, userID string) (PeriodApproval, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return PeriodApproval{}, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	approval, err := lockPeriodApproval(ctx, tx, tenantID, periodID)
+	if err != nil {
+		return PeriodApproval{}, err
+	}
+	if approval.Status != PeriodStatusReviewed {
+		return PeriodApproval{}, ErrSubmitInvalidState
+	}
+
+	if _, err := tx.Exec(ctx, `
+    UPDATE payroll_periods
+    SET status = $1, submitted_by = $2, submitted_at = now(),
+        decided_by = NULL, decided_at = NULL, decision = NULL, decision_comment = NULL
+    WHERE tenant_id = $3 AND id = $4
+  `, PeriodStatusPendingApproval, userID, tenantID, periodID); err != nil {
+		return PeriodApproval{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return PeriodApproval{}, err
+	}
+	committed = true
+	approval.Status = PeriodStatusPendingApproval
+	approval.SubmittedBy = userID
+	return approval, nil
+}
+
+// DecidePeriod records the approver's decision under a row lock so two
+// approvers cannot decide the same submission.
+func (s *Store) DecidePeriod(ctx context.Context, tenantID, periodID, userID, onBehalfOf, decision, comment string) (PeriodApproval, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return PeriodApproval{}, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	approval, err := lockPeriodApproval(ctx, tx, tenantID, periodID)
+	if err != nil {
+		return PeriodApproval{}, err
+	}
+	if err := CheckApprover(approval, userID, onBehalfOf); err != nil {
+		return PeriodApproval{}, err
+	}
+
+	status := PeriodStatusApproved
+	if decision == ApprovalDecisionRejected {
+		status = PeriodStatusDraft
+	}
+	if _, err := tx.Exec(ctx, `
+    UPDATE payroll_periods
+    SET status = $1, decided_by = $2, decided_at = now(), decision = $3, decision_comment = $4
+    WHERE tenant_id = $5 AND id = $6
+  `, status, userID, decision, nullIfEmpty(comment), tenantID, periodID); err != nil {
+		return PeriodApproval{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return PeriodApproval{}, err
+	}
+	committed = true
+	approval.Status = status
+	approval.DecidedBy = userID
+	approval.Decision = decision
+	approval.Comment = comment
+	return approval, nil
+}
+
+func lockPeriodApproval(ctx context.Context, tx pgx.Tx, tenantID, periodID string) (PeriodApproval, error) {
+	approval := PeriodApproval{PeriodID: periodID}
+	if err := tx.QueryRow(ctx, `
+    SELECT status, COALESCE(run_by::text, ''), COALESCE(resumed_by::text, ''), COALESCE(submitted_by::text, '')
+    FROM payroll_periods
+    WHERE tenant_id = $1 AND id = $2
+    FOR UPDATE
+  `, tenantID, periodID).Scan(&approval.Status, &approval.RunBy, &approval.ResumedBy, &approval.SubmittedBy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PeriodApproval{}, ErrPeriodNotFound
+		}
+		return PeriodApproval{}, err
+	}
+	return approval, nil
+}
+
+func (s *Store) UserIDsWithPermission(ctx context.Context, tenantID, permission string) ([]string, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT DISTINCT u.id
+    FROM users u
+    JOIN role_permissions rp ON rp.role_id = u.role_id
+    JOIN permissions p ON rp.permission_id = p.id
+    WHERE u.tenant_id = $1 AND p.key = $2 AND u.status = $3
+  `, tenantID, permission, core.UserStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
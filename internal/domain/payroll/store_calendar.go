@@ -0,0 +1,129 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) GetSchedule(ctx context.Context, tenantID, scheduleID string) (Schedule, error) {
+	var schedule Schedule
+	err := s.DB.QueryRow(ctx, `
+    SELECT id, name, frequency, COALESCE(pay_day, 0), COALESCE(holiday_region, ''), created_at
+    FROM pay_schedules
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, scheduleID).Scan(&schedule.ID, &schedule.Name, &schedule.Frequency, &schedule.PayDay, &schedule.HolidayRegion, &schedule.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Schedule{}, ErrScheduleNotFound
+	}
+	return schedule, err
+}
+
+// LastRegularPeriodEnd returns the end date of the schedule's latest regular
+// period, or nil when it has none.
+func (s *Store) LastRegularPeriodEnd(ctx context.Context, tenantID, scheduleID string) (*time.Time, error) {
+	var end *time.Time
+	err := s.DB.QueryRow(ctx, `
+    SELECT MAX(end_date)
+    FROM payroll_periods
+    WHERE tenant_id = $1 AND schedule_id = $2 AND run_type = $3
+  `, tenantID, scheduleID, PeriodRunRegular).Scan(&end)
+	return end, err
+}
+
+// ListScheduleCalendar returns the schedule's regular periods overlapping the
+// range, with the stored pay date when one was set.
+func (s *Store) ListScheduleCalendar(ctx context.Context, tenantID, scheduleID string, from, to time.Time) ([]CalendarPeriod, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, status, start_date, end_date, pay_date
+    FROM payroll_periods
+    WHERE tenant_id = $1 AND schedule_id = $2 AND run_type = $3
+      AND end_date >= $4 AND start_date <= $5
+    ORDER BY start_date
+  `, tenantID, scheduleID, PeriodRunRegular, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CalendarPeriod
+	for rows.Next() {
+		period := CalendarPeriod{ScheduleID: scheduleID}
+		var payDate *time.Time
+		if err := rows.Scan(&period.PeriodID, &period.Status, &period.StartDate, &period.EndDate, &payDate); err != nil {
+			return nil, err
+		}
+		if payDate != nil {
+			period.PayDate = *payDate
+		}
+		out = append(out, period)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) ListHolidayDates(ctx context.Context, tenantID, region string, from, to time.Time) ([]time.Time, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT DISTINCT date
+    FROM holidays
+    WHERE tenant_id = $1 AND date BETWEEN $2 AND $3
+      AND (COALESCE(region, '') = '' OR region = $4)
+  `, tenantID, from, to, region)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []time.Time
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		out = append(out, date)
+	}
+	return out, rows.Err()
+}
+
+// CreateCalendarPeriods inserts generated draft periods with their pay dates,
+// skipping any that would overlap an existing regular period of the schedule,
+// and returns how many were created.
+func (s *Store) CreateCalendarPeriods(ctx context.Context, tenantID, scheduleID string, periods []CalendarPeriod) (int, error) {
+	if len(periods) == 0 {
+		return 0, nil
+	}
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	created := 0
+	for _, period := range periods {
+		tag, err := tx.Exec(ctx, `
+      INSERT INTO payroll_periods (tenant_id, schedule_id, start_date, end_date, status, run_type, pay_date)
+      SELECT $1, $2, $3, $4, $5, $6, $7
+      WHERE NOT EXISTS (
+        SELECT 1 FROM payroll_periods
+        WHERE tenant_id = $1 AND schedule_id = $2 AND run_type = $6
+          AND start_date <= $4 AND end_date >= $3
+      )
+    `, tenantID, scheduleID, period.StartDate, period.EndDate, PeriodStatusDraft, PeriodRunRegular, period.PayDate)
+		if err != nil {
+			return 0, err
+		}
+		created += int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	committed = true
+	return created, nil
+}
@@ -0,0 +1,132 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) ListCompensation(ctx context.Context, tenantID, employeeID string) ([]Compensation, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT c.id, c.employee_id, c.effective_from, c.salary, c.salary_enc, COALESCE(pg.currency, c.currency),
+           COALESCE(c.pay_group_id::text, ''), COALESCE(pg.schedule_id::text, ''), COALESCE(c.reason, ''),
+           COALESCE(c.created_by::text, ''), c.created_at
+    FROM employee_compensation c
+    LEFT JOIN pay_groups pg ON c.pay_group_id = pg.id
+    WHERE c.tenant_id = $1 AND c.employee_id = $2
+    ORDER BY c.effective_from DESC
+  `, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Compensation
+	for rows.Next() {
+		record, err := scanCompensation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, record)
+	}
+	return out, rows.Err()
+}
+
+// ListCompensationForPeriod returns, per employee, the record in force on the
+// first day of the period followed by every change that takes effect inside it.
+func (s *Store) ListCompensationForPeriod(ctx context.Context, tenantID string, periodStart, periodEnd time.Time) (map[string][]Compensation, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT c.id, c.employee_id, c.effective_from, c.salary, c.salary_enc, COALESCE(pg.currency, c.currency),
+           COALESCE(c.pay_group_id::text, ''), COALESCE(pg.schedule_id::text, ''), COALESCE(c.reason, ''),
+           COALESCE(c.created_by::text, ''), c.created_at
+    FROM employee_compensation c
+    LEFT JOIN pay_groups pg ON c.pay_group_id = pg.id
+    WHERE c.tenant_id = $1
+      AND c.effective_from <= $3
+      AND c.effective_from >= COALESCE((
+        SELECT MAX(prior.effective_from)
+        FROM employee_compensation prior
+        WHERE prior.employee_id = c.employee_id AND prior.effective_from <= $2
+      ), '-infinity'::date)
+    ORDER BY c.employee_id, c.effective_from
+  `, tenantID, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string][]Compensation{}
+	for rows.Next() {
+		record, err := scanCompensation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[record.EmployeeID] = append(out[record.EmployeeID], record)
+	}
+	return out, rows.Err()
+}
+
+// SaveCompensation upserts the record for its effective date and, when the
+// newest record in force today changed, copies it onto the employee row so the
+// profile shows the current salary.
+func (s *Store) SaveCompensation(ctx context.Context, tenantID string, record Compensation) (string, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var id string
+	if err := tx.QueryRow(ctx, `
+    INSERT INTO employee_compensation (tenant_id, employee_id, effective_from, salary, salary_enc, currency, pay_group_id, reason, created_by)
+    SELECT $1, id, $3, $4, $5, $6, $7, $8, $9
+    FROM employees
+    WHERE tenant_id = $1 AND id = $2
+    ON CONFLICT (employee_id, effective_from)
+    DO UPDATE SET salary = EXCLUDED.salary, salary_enc = EXCLUDED.salary_enc, currency = EXCLUDED.currency,
+                  pay_group_id = EXCLUDED.pay_group_id, reason = EXCLUDED.reason, created_by = EXCLUDED.created_by,
+                  created_at = now()
+    RETURNING id
+  `, tenantID, record.EmployeeID, record.EffectiveFrom, record.Salary, record.SalaryEnc, record.Currency,
+		nullIfEmpty(record.PayGroupID), nullIfEmpty(record.Reason), nullIfEmpty(record.CreatedBy)).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrEmployeeNotFound
+		}
+		return "", err
+	}
+
+	if _, err := tx.Exec(ctx, `
+    UPDATE employees e
+    SET salary = c.salary, salary_enc = c.salary_enc, currency = c.currency, pay_group_id = c.pay_group_id, updated_at = now()
+    FROM (
+      SELECT salary, salary_enc, currency, pay_group_id
+      FROM employee_compensation
+      WHERE tenant_id = $1 AND employee_id = $2 AND effective_from <= CURRENT_DATE
+      ORDER BY effective_from DESC
+      LIMIT 1
+    ) c
+    WHERE e.tenant_id = $1 AND e.id = $2
+  `, tenantID, record.EmployeeID); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	committed = true
+	return id, nil
+}
+
+func scanCompensation(rows pgx.Rows) (Compensation, error) {
+	var record Compensation
+	err := rows.Scan(&record.ID, &record.EmployeeID, &record.EffectiveFrom, &record.Salary, &record.SalaryEnc, &record.Currency,
+		&record.PayGroupID, &record.ScheduleID, &record.Reason, &record.CreatedBy, &record.CreatedAt)
+	return record, err
+}
@@ -0,0 +1,111 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) Settings(ctx context.Context, tenantID string) (Settings, error) {
+	var settings Settings
+	err := s.DB.QueryRow(ctx, `
+    SELECT reporting_currency, tax_year_start_month, tax_year_start_day
+    FROM payroll_settings
+    WHERE tenant_id = $1
+  `, tenantID).Scan(&settings.ReportingCurrency, &settings.TaxYearStartMonth, &settings.TaxYearStartDay)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Settings{}, nil
+	}
+	return settings, err
+}
+
+// PrevailingCurrency returns the currency most of the tenant's employees are
+// paid in, or "" when no employee has one.
+func (s *Store) PrevailingCurrency(ctx context.Context, tenantID string) (string, error) {
+	var currency string
+	err := s.DB.QueryRow(ctx, `
+    SELECT currency
+    FROM employees
+    WHERE tenant_id = $1 AND COALESCE(currency, '') <> ''
+    GROUP BY currency
+    ORDER BY count(*) DESC, currency
+    LIMIT 1
+  `, tenantID).Scan(&currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return currency, err
+}
+
+func (s *Store) UpsertSettings(ctx context.Context, tenantID string, settings Settings) error {
+	month, day := settings.TaxYearStartMonth, settings.TaxYearStartDay
+	if month == 0 || day == 0 {
+		month, day = 1, 1
+	}
+	_, err := s.DB.Exec(ctx, `
+    INSERT INTO payroll_settings (tenant_id, reporting_currency, tax_year_start_month, tax_year_start_day)
+    VALUES ($1,$2,$3,$4)
+    ON CONFLICT (tenant_id) DO UPDATE SET
+      reporting_currency = EXCLUDED.reporting_currency,
+      tax_year_start_month = EXCLUDED.tax_year_start_month,
+      tax_year_start_day = EXCLUDED.tax_year_start_day,
+      updated_at = now()
+  `, tenantID, settings.ReportingCurrency, month, day)
+	return err
+}
+
+func (s *Store) ListExchangeRates(ctx context.Context, tenantID string) ([]ExchangeRate, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, from_currency, to_currency, rate, effective_date, COALESCE(created_by::text, ''), created_at
+    FROM payroll_exchange_rates
+    WHERE tenant_id = $1
+    ORDER BY from_currency, to_currency, effective_date DESC
+  `, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ExchangeRate
+	for rows.Next() {
+		var rate ExchangeRate
+		if err := rows.Scan(&rate.ID, &rate.FromCurrency, &rate.ToCurrency, &rate.Rate, &rate.EffectiveDate, &rate.CreatedBy, &rate.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, rate)
+	}
+	return out, rows.Err()
+}
+
+// UpsertExchangeRate records a rate for a currency pair, replacing any rate
+// already recorded for the same pair and effective date.
+func (s *Store) UpsertExchangeRate(ctx context.Context, tenantID string, rate ExchangeRate) (string, error) {
+	var id string
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO payroll_exchange_rates (tenant_id, from_currency, to_currency, rate, effective_date, created_by)
+    VALUES ($1,$2,$3,$4,$5,$6)
+    ON CONFLICT (tenant_id, from_currency, to_currency, effective_date) DO UPDATE SET
+      rate = EXCLUDED.rate,
+      created_by = EXCLUDED.created_by,
+      created_at = now()
+    RETURNING id
+  `, tenantID, rate.FromCurrency, rate.ToCurrency, rate.Rate, rate.EffectiveDate, nullIfEmpty(rate.CreatedBy)).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *Store) DeleteExchangeRate(ctx context.Context, tenantID, rateID string) error {
+	tag, err := s.DB.Exec(ctx, `
+    DELETE FROM payroll_exchange_rates
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, rateID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrExchangeRateNotFound
+	}
+	return nil
+}
@@ -9,11 +9,13 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+
+	"hrm/internal/domain/leave"
 )
 
 func (s *Store) ListSchedules(ctx context.Context, tenantID string) ([]Schedule, error) {
 	rows, err := s.DB.Query(ctx, `
-    SELECT id, name, frequency, pay_day, created_at
+    SELECT id, name, frequency, COALESCE(pay_day, 0), COALESCE(holiday_region, ''), created_at
     FROM pay_schedules
     WHERE tenant_id = $1
   `, tenantID)
@@ -25,7 +27,7 @@ func (s *Store) ListSchedules(ctx context.Context, tenantID string) ([]Schedule,
 	var schedules []Schedule
 	for rows.Next() {
 		var schedule Schedule
-		if err := rows.Scan(&schedule.ID, &schedule.Name, &schedule.Frequency, &schedule.PayDay, &schedule.CreatedAt); err != nil {
+		if err := rows.Scan(&schedule.ID, &schedule.Name, &schedule.Frequency, &schedule.PayDay, &schedule.HolidayRegion, &schedule.CreatedAt); err != nil {
 			return nil, err
 		}
 		schedules = append(schedules, schedule)
@@ -33,13 +35,13 @@ func (s *Store) ListSchedules(ctx context.Context, tenantID string) ([]Schedule,
 	return schedules, nil
 }
 
-func (s *Store) CreateSchedule(ctx context.Context, tenantID, name, frequency string, payDay int) (string, error) {
+func (s *Store) CreateSchedule(ctx context.Context, tenantID, name, frequency string, payDay int, holidayRegion string) (string, error) {
 	var id string
 	err := s.DB.QueryRow(ctx, `
-    INSERT INTO pay_schedules (tenant_id, name, frequency, pay_day)
-    VALUES ($1,$2,$3,$4)
+    INSERT INTO pay_schedules (tenant_id, name, frequency, pay_day, holiday_region)
+    VALUES ($1,$2,$3,$4,$5)
     RETURNING id
-  `, tenantID, name, frequency, payDay).Scan(&id)
+  `, tenantID, name, frequency, payDay, nullIfEmpty(holidayRegion)).Scan(&id)
 	if err != nil {
 		return "", err
 	}
@@ -83,7 +85,7 @@ func (s *Store) CreateGroup(ctx context.Context, tenantID, name, scheduleID, cur
 
 func (s *Store) ListElements(ctx context.Context, tenantID string) ([]Element, error) {
 	rows, err := s.DB.Query(ctx, `
-    SELECT id, name, element_type, calc_type, amount, taxable
+    SELECT id, name, element_type, calc_type, amount, taxable, rate, tiers_json, depends_on_json, COALESCE(expression, '')
     FROM pay_elements
     WHERE tenant_id = $1
   `, tenantID)
@@ -95,21 +97,45 @@ func (s *Store) ListElements(ctx context.Context, tenantID string) ([]Element, e
 	var elements []Element
 	for rows.Next() {
 		var element Element
-		if err := rows.Scan(&element.ID, &element.Name, &element.ElementType, &element.CalcType, &element.Amount, &element.Taxable); err != nil {
+		var tiersJSON, dependsOnJSON []byte
+		if err := rows.Scan(&element.ID, &element.Name, &element.ElementType, &element.CalcType, &element.Amount, &element.Taxable, &element.Rate, &tiersJSON, &dependsOnJSON, &element.Expression); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(tiersJSON, &element.Tiers); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(dependsOnJSON, &element.DependsOn); err != nil {
 			return nil, err
 		}
 		elements = append(elements, element)
 	}
-	return elements, nil
+	return elements, rows.Err()
 }
 
 func (s *Store) CreateElement(ctx context.Context, tenantID string, element Element) (string, error) {
+	tiers := element.Tiers
+	if tiers == nil {
+		tiers = []TaxBracket{}
+	}
+	tiersJSON, err := json.Marshal(tiers)
+	if err != nil {
+		return "", err
+	}
+	dependsOn := element.DependsOn
+	if dependsOn == nil {
+		dependsOn = []string{}
+	}
+	dependsOnJSON, err := json.Marshal(dependsOn)
+	if err != nil {
+		return "", err
+	}
+
 	var id string
 	if err := s.DB.QueryRow(ctx, `
-    INSERT INTO pay_elements (tenant_id, name, element_type, calc_type, amount, taxable)
-    VALUES ($1,$2,$3,$4,$5,$6)
+    INSERT INTO pay_elements (tenant_id, name, element_type, calc_type, amount, taxable, rate, tiers_json, depends_on_json, expression)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
     RETURNING id
-  `, tenantID, element.Name, element.ElementType, element.CalcType, element.Amount, element.Taxable).Scan(&id); err != nil {
+  `, tenantID, element.Name, element.ElementType, element.CalcType, element.Amount, element.Taxable, element.Rate, tiersJSON, dependsOnJSON, nullIfEmpty(element.Expression)).Scan(&id); err != nil {
 		return "", err
 	}
 	return id, nil
@@ -164,10 +190,13 @@ func (s *Store) CountPeriods(ctx context.Context, tenantID string) (int, error)
 
 func (s *Store) ListPeriods(ctx context.Context, tenantID string, limit, offset int) ([]Period, error) {
 	rows, err := s.DB.Query(ctx, `
-    SELECT id, schedule_id, start_date, end_date, status
+    SELECT id, schedule_id, start_date, end_date, status, run_type,
+           COALESCE(original_period_id::text, ''), COALESCE(reason, ''), pay_date,
+           COALESCE(run_by::text, ''), COALESCE(resumed_by::text, ''), COALESCE(submitted_by::text, ''), submitted_at,
+           COALESCE(decided_by::text, ''), decided_at, COALESCE(decision, ''), COALESCE(decision_comment, '')
     FROM payroll_periods
     WHERE tenant_id = $1
-    ORDER BY start_date DESC
+    ORDER BY start_date DESC, created_at DESC
     LIMIT $2 OFFSET $3
   `, tenantID, limit, offset)
 	if err != nil {
@@ -178,7 +207,8 @@ func (s *Store) ListPeriods(ctx context.Context, tenantID string, limit, offset
 	var periods []Period
 	for rows.Next() {
 		var period Period
-		if err := rows.Scan(&period.ID, &period.ScheduleID, &period.StartDate, &period.EndDate, &period.Status); err != nil {
+		if err := rows.Scan(&period.ID, &period.ScheduleID, &period.StartDate, &period.EndDate, &period.Status, &period.RunType, &period.OriginalPeriodID, &period.Reason, &period.PayDate,
+			&period.RunBy, &period.ResumedBy, &period.SubmittedBy, &period.SubmittedAt, &period.DecidedBy, &period.DecidedAt, &period.Decision, &period.DecisionComment); err != nil {
 			return nil, err
 		}
 		periods = append(periods, period)
@@ -257,13 +287,32 @@ func (s *Store) FindEmployeeIDByUserID(ctx context.Context, tenantID, userID str
 func (s *Store) GetPeriodDetails(ctx context.Context, tenantID, periodID string) (PeriodDetails, error) {
 	var details PeriodDetails
 	err := s.DB.QueryRow(ctx, `
-    SELECT status, start_date, end_date, schedule_id
+    SELECT status, start_date, end_date, schedule_id, run_type, COALESCE(original_period_id::text, '')
     FROM payroll_periods
     WHERE tenant_id = $1 AND id = $2
-  `, tenantID, periodID).Scan(&details.Status, &details.StartDate, &details.EndDate, &details.ScheduleID)
+  `, tenantID, periodID).Scan(&details.Status, &details.StartDate, &details.EndDate, &details.ScheduleID, &details.RunType, &details.OriginalPeriodID)
 	return details, err
 }
 
+// NextOpenPeriod returns the earliest draft regular period ending on or after
+// the given day, on the schedule when one is given, or ErrPeriodNotFound.
+func (s *Store) NextOpenPeriod(ctx context.Context, tenantID, scheduleID string, on time.Time) (string, PeriodDetails, error) {
+	var id string
+	var details PeriodDetails
+	err := s.DB.QueryRow(ctx, `
+    SELECT id, status, start_date, end_date, schedule_id, run_type, COALESCE(original_period_id::text, '')
+    FROM payroll_periods
+    WHERE tenant_id = $1 AND status = $2 AND run_type = $3 AND end_date >= $4
+      AND ($5::text = '' OR schedule_id::text = $5)
+    ORDER BY start_date, end_date
+    LIMIT 1
+  `, tenantID, PeriodStatusDraft, PeriodRunRegular, on, scheduleID).Scan(&id, &details.Status, &details.StartDate, &details.EndDate, &details.ScheduleID, &details.RunType, &details.OriginalPeriodID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", details, ErrPeriodNotFound
+	}
+	return id, details, err
+}
+
 func (s *Store) CreateJobRun(ctx context.Context, tenantID, jobType string) (string, error) {
 	var runID string
 	if err := s.DB.QueryRow(ctx, `
@@ -287,61 +336,165 @@ func (s *Store) UpdateJobRun(ctx context.Context, runID, status string, detailsJ
 	return execErr
 }
 
-func (s *Store) ListActiveEmployeesForRun(ctx context.Context, tenantID, status string) ([]EmployeePayrollData, error) {
+func (s *Store) PayrollRun(ctx context.Context, tenantID, runID string) (string, []byte, error) {
+	var status string
+	var detailsJSON []byte
+	err := s.DB.QueryRow(ctx, `
+    SELECT status, COALESCE(details_json, '{}'::jsonb)
+    FROM job_runs
+    WHERE tenant_id = $1 AND id = $2 AND job_type = $3
+  `, tenantID, runID, JobTypePayrollRun).Scan(&status, &detailsJSON)
+	return status, detailsJSON, err
+}
+
+func (s *Store) ActivePayrollRunID(ctx context.Context, tenantID, periodID string) (string, error) {
+	var runID string
+	err := s.DB.QueryRow(ctx, `
+    SELECT id
+    FROM job_runs
+    WHERE tenant_id = $1 AND job_type = $2 AND status IN ('queued', 'running')
+      AND details_json->>'periodId' = $3
+    ORDER BY started_at DESC
+    LIMIT 1
+  `, tenantID, JobTypePayrollRun, periodID).Scan(&runID)
+	return runID, err
+}
+
+// ListActiveEmployeesForRun returns employees employed at some point in the
+// period: active ones plus those who left during it.
+func (s *Store) ListActiveEmployeesForRun(ctx context.Context, tenantID, status string, periodStart, periodEnd time.Time) ([]EmployeePayrollData, error) {
 	rows, err := s.DB.Query(ctx, `
     SELECT e.id,
+           e.first_name,
+           e.last_name,
            e.salary,
            e.salary_enc,
            COALESCE(pg.currency, e.currency),
            COALESCE(e.bank_account, ''),
            e.bank_account_enc,
-           COALESCE(pg.schedule_id::text, '')
+           COALESCE(pg.schedule_id::text, ''),
+           e.start_date,
+           e.end_date
     FROM employees e
     LEFT JOIN pay_groups pg ON e.pay_group_id = pg.id
-    WHERE e.tenant_id = $1 AND e.status = $2
-  `, tenantID, status)
+    WHERE e.tenant_id = $1
+      AND (e.status = $2 OR e.end_date >= $3)
+      AND (e.start_date IS NULL OR e.start_date <= $4)
+      AND (e.end_date IS NULL OR e.end_date >= $3)
+  `, tenantID, status, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	return scanEmployeePayrollData(rows)
+}
+
+// ListPeriodEmployees returns the employees selected for an off-cycle period,
+// including ones who have since left.
+func (s *Store) ListPeriodEmployees(ctx context.Context, tenantID, periodID string) ([]EmployeePayrollData, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT e.id,
+           e.first_name,
+           e.last_name,
+           e.salary,
+           e.salary_enc,
+           COALESCE(pg.currency, e.currency),
+           COALESCE(e.bank_account, ''),
+           e.bank_account_enc,
+           COALESCE(pg.schedule_id::text, ''),
+           e.start_date,
+           e.end_date
+    FROM payroll_period_employees pe
+    JOIN employees e ON pe.employee_id = e.id
+    LEFT JOIN pay_groups pg ON e.pay_group_id = pg.id
+    WHERE pe.tenant_id = $1 AND pe.period_id = $2
+  `, tenantID, periodID)
 	if err != nil {
 		return nil, err
 	}
+	return scanEmployeePayrollData(rows)
+}
+
+// EmployeePayrollData returns one employee's pay details, whether or not they
+// are still active.
+func (s *Store) EmployeePayrollData(ctx context.Context, tenantID, employeeID string) (EmployeePayrollData, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT e.id,
+           e.first_name,
+           e.last_name,
+           e.salary,
+           e.salary_enc,
+           COALESCE(pg.currency, e.currency),
+           COALESCE(e.bank_account, ''),
+           e.bank_account_enc,
+           COALESCE(pg.schedule_id::text, ''),
+           e.start_date,
+           e.end_date
+    FROM employees e
+    LEFT JOIN pay_groups pg ON e.pay_group_id = pg.id
+    WHERE e.tenant_id = $1 AND e.id = $2
+  `, tenantID, employeeID)
+	if err != nil {
+		return EmployeePayrollData{}, err
+	}
+	employees, err := scanEmployeePayrollData(rows)
+	if err != nil {
+		return EmployeePayrollData{}, err
+	}
+	if len(employees) == 0 {
+		return EmployeePayrollData{}, ErrEmployeeNotFound
+	}
+	return employees[0], nil
+}
+
+func scanEmployeePayrollData(rows pgx.Rows) ([]EmployeePayrollData, error) {
 	defer rows.Close()
 
 	var out []EmployeePayrollData
 	for rows.Next() {
 		var employee EmployeePayrollData
-		if err := rows.Scan(&employee.EmployeeID, &employee.SalaryPlain, &employee.SalaryEnc, &employee.Currency, &employee.BankPlain, &employee.BankEnc, &employee.GroupScheduleID); err != nil {
+		if err := rows.Scan(&employee.EmployeeID, &employee.FirstName, &employee.LastName, &employee.SalaryPlain, &employee.SalaryEnc, &employee.Currency, &employee.BankPlain, &employee.BankEnc, &employee.GroupScheduleID, &employee.StartDate, &employee.EndDate); err != nil {
 			return nil, err
 		}
 		out = append(out, employee)
 	}
-	return out, nil
+	return out, rows.Err()
 }
 
-func (s *Store) ListInputLines(ctx context.Context, periodID, employeeID string) ([]InputLine, error) {
+func (s *Store) ListElementInputs(ctx context.Context, periodID, employeeID string) ([]ElementInput, error) {
 	rows, err := s.DB.Query(ctx, `
-    SELECT pe.element_type, pi.amount
+    SELECT pe.id, pe.name, pe.element_type, pe.calc_type, pe.taxable, pi.amount,
+           COALESCE(pi.units, 0), COALESCE(pi.rate, 0), pe.rate, pe.tiers_json, pe.depends_on_json, COALESCE(pe.expression, '')
     FROM payroll_inputs pi
     JOIN pay_elements pe ON pi.element_id = pe.id
     WHERE pi.period_id = $1 AND pi.employee_id = $2
+    ORDER BY pi.created_at, pi.id
   `, periodID, employeeID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var inputs []InputLine
+	var inputs []ElementInput
 	for rows.Next() {
-		var line InputLine
-		if err := rows.Scan(&line.Type, &line.Amount); err != nil {
+		var input ElementInput
+		var tiersJSON, dependsOnJSON []byte
+		if err := rows.Scan(&input.ElementID, &input.Name, &input.ElementType, &input.CalcType, &input.Taxable, &input.Amount, &input.Units, &input.Rate, &input.ElementRate, &tiersJSON, &dependsOnJSON, &input.Expression); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(tiersJSON, &input.Tiers); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(dependsOnJSON, &input.DependsOn); err != nil {
 			return nil, err
 		}
-		inputs = append(inputs, line)
+		inputs = append(inputs, input)
 	}
-	return inputs, nil
+	return inputs, rows.Err()
 }
 
-func (s *Store) ListAdjustmentAmounts(ctx context.Context, tenantID, periodID, employeeID string, periodStart, periodEnd time.Time) ([]float64, error) {
+func (s *Store) ListAdjustmentLines(ctx context.Context, tenantID, periodID, employeeID string, periodStart, periodEnd time.Time) ([]InputLine, error) {
 	rows, err := s.DB.Query(ctx, `
-    SELECT amount
+    SELECT id, description, amount
     FROM payroll_adjustments
     WHERE tenant_id = $1 AND period_id = $2 AND employee_id = $3
       AND (effective_date IS NULL OR (effective_date >= $4 AND effective_date <= $5))
@@ -351,13 +504,22 @@ func (s *Store) ListAdjustmentAmounts(ctx context.Context, tenantID, periodID, e
 	}
 	defer rows.Close()
 
-	var out []float64
+	var out []InputLine
 	for rows.Next() {
+		line := InputLine{Source: ResultSourceAdjustment, Code: ResultCodeAdjustment}
 		var amount float64
-		if err := rows.Scan(&amount); err != nil {
+		if err := rows.Scan(&line.SourceID, &line.Description, &amount); err != nil {
 			continue
 		}
-		out = append(out, amount)
+		if amount >= 0 {
+			line.Type = ElementTypeEarning
+			line.Amount = amount
+			line.Taxable = true
+		} else {
+			line.Type = ElementTypeDeduction
+			line.Amount = -amount
+		}
+		out = append(out, line)
 	}
 	return out, nil
 }
@@ -390,30 +552,163 @@ func (s *Store) ListUnpaidLeaves(ctx context.Context, tenantID, employeeID strin
 	return out, nil
 }
 
-func (s *Store) LatestNet(ctx context.Context, tenantID, employeeID string) (float64, error) {
-	var previousNet float64
-	if err := s.DB.QueryRow(ctx, `
-    SELECT net
-    FROM payroll_results
-    WHERE tenant_id = $1 AND employee_id = $2
-    ORDER BY created_at DESC
+// WorkSchedule loads the employee's work pattern and holidays so unpaid
+// leave is prorated on the same working days leave requests are counted in.
+func (s *Store) WorkSchedule(ctx context.Context, tenantID, employeeID string, from, to time.Time) (leave.WorkSchedule, error) {
+	return s.Schedules.WorkSchedule(ctx, tenantID, employeeID, from, to)
+}
+
+func (s *Store) PreviousFinalizedPeriodID(ctx context.Context, tenantID, periodID string) (string, error) {
+	var previousID string
+	err := s.DB.QueryRow(ctx, `
+    SELECT prev.id
+    FROM payroll_periods cur
+    JOIN payroll_periods prev
+      ON prev.tenant_id = cur.tenant_id
+     AND prev.schedule_id = cur.schedule_id
+     AND prev.status = $3
+     AND prev.run_type = $4
+     AND prev.end_date < cur.start_date
+    WHERE cur.tenant_id = $1 AND cur.id = $2 AND cur.run_type = $4
+    ORDER BY prev.end_date DESC
     LIMIT 1
-  `, tenantID, employeeID).Scan(&previousNet); err != nil {
-		return 0, err
+  `, tenantID, periodID, PeriodStatusFinalized, PeriodRunRegular).Scan(&previousID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
 	}
-	return previousNet, nil
+	return previousID, err
 }
 
-func (s *Store) UpsertPayrollResult(ctx context.Context, tenantID, periodID, employeeID string, gross, deductions, net float64, currency string, warningsJSON []byte) error {
+func (s *Store) PeriodResults(ctx context.Context, tenantID, periodID string) (map[string]EmployeeResult, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT employee_id, gross, deductions, net, currency
+    FROM payroll_results
+    WHERE tenant_id = $1 AND period_id = $2
+  `, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := map[string]EmployeeResult{}
+	for rows.Next() {
+		result := EmployeeResult{PeriodID: periodID}
+		if err := rows.Scan(&result.EmployeeID, &result.Gross, &result.Deductions, &result.Net, &result.Currency); err != nil {
+			return nil, err
+		}
+		results[result.EmployeeID] = result
+	}
+	return results, nil
+}
+
+func (s *Store) UpsertPayrollResult(ctx context.Context, tenantID, periodID, employeeID string, gross, taxableGross, deductions, net float64, currency string, warningsJSON []byte) error {
 	_, err := s.DB.Exec(ctx, `
-    INSERT INTO payroll_results (tenant_id, period_id, employee_id, gross, deductions, net, currency, warnings_json)
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+    INSERT INTO payroll_results (tenant_id, period_id, employee_id, gross, taxable_gross, deductions, net, currency, warnings_json)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
     ON CONFLICT (period_id, employee_id)
-    DO UPDATE SET gross = EXCLUDED.gross, deductions = EXCLUDED.deductions, net = EXCLUDED.net, warnings_json = EXCLUDED.warnings_json
-  `, tenantID, periodID, employeeID, gross, deductions, net, currency, warningsJSON)
+    DO UPDATE SET gross = EXCLUDED.gross, taxable_gross = EXCLUDED.taxable_gross, deductions = EXCLUDED.deductions, net = EXCLUDED.net, warnings_json = EXCLUDED.warnings_json
+  `, tenantID, periodID, employeeID, gross, taxableGross, deductions, net, currency, warningsJSON)
 	return err
 }
 
+// SaveEmployeeResult stores a run's result for one employee together with its
+// lines and, unless the period is off-cycle, its retro items, all in one
+// transaction so a failed save never leaves a result without matching lines.
+func (s *Store) SaveEmployeeResult(ctx context.Context, tenantID, periodID string, record ResultRecord) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	txStore := &Store{DB: tx, Schedules: s.Schedules}
+	if err := txStore.UpsertPayrollResult(ctx, tenantID, periodID, record.EmployeeID, record.Gross, record.TaxableGross, record.Deductions, record.Net, record.Currency, record.WarningsJSON); err != nil {
+		return err
+	}
+	if err := txStore.ReplaceResultLines(ctx, tenantID, periodID, record.EmployeeID, record.Lines); err != nil {
+		return err
+	}
+	if record.ReplaceRetro {
+		if err := txStore.ReplaceRetroItems(ctx, tenantID, periodID, record.EmployeeID, record.Retro); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (s *Store) PayrollResult(ctx context.Context, tenantID, periodID, employeeID string) (EmployeeResult, error) {
+	result := EmployeeResult{PeriodID: periodID, EmployeeID: employeeID}
+	var warningsJSON []byte
+	if err := s.DB.QueryRow(ctx, `
+    SELECT gross, deductions, net, currency, warnings_json
+    FROM payroll_results
+    WHERE tenant_id = $1 AND period_id = $2 AND employee_id = $3
+  `, tenantID, periodID, employeeID).Scan(&result.Gross, &result.Deductions, &result.Net, &result.Currency, &warningsJSON); err != nil {
+		return EmployeeResult{}, err
+	}
+	if err := json.Unmarshal(warningsJSON, &result.Warnings); err != nil || result.Warnings == nil {
+		result.Warnings = []string{}
+	}
+	return result, nil
+}
+
+func (s *Store) ListResultLines(ctx context.Context, tenantID, periodID, employeeID string) ([]ResultLine, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT line_type, source, COALESCE(source_id::text, ''), code, description, base, amount
+    FROM payroll_result_lines
+    WHERE tenant_id = $1 AND period_id = $2 AND employee_id = $3
+    ORDER BY created_at, id
+  `, tenantID, periodID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]ResultLine, 0)
+	for rows.Next() {
+		var line ResultLine
+		if err := rows.Scan(&line.LineType, &line.Source, &line.SourceID, &line.Code, &line.Description, &line.Base, &line.Amount); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func (s *Store) ListPeriodResultLines(ctx context.Context, tenantID, periodID string) (map[string][]ResultLine, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT employee_id, line_type, source, COALESCE(source_id::text, ''), code, description, base, amount
+    FROM payroll_result_lines
+    WHERE tenant_id = $1 AND period_id = $2
+    ORDER BY employee_id, created_at, id
+  `, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string][]ResultLine{}
+	for rows.Next() {
+		var employeeID string
+		var line ResultLine
+		if err := rows.Scan(&employeeID, &line.LineType, &line.Source, &line.SourceID, &line.Code, &line.Description, &line.Base, &line.Amount); err != nil {
+			return nil, err
+		}
+		out[employeeID] = append(out[employeeID], line)
+	}
+	return out, nil
+}
+
 func (s *Store) UpdatePeriodStatus(ctx context.Context, tenantID, periodID, status string) error {
 	_, err := s.DB.Exec(ctx, `
     UPDATE payroll_periods SET status = $1 WHERE id = $2 AND tenant_id = $3
@@ -422,6 +717,11 @@ func (s *Store) UpdatePeriodStatus(ctx context.Context, tenantID, periodID, stat
 }
 
 func (s *Store) FinalizePeriod(ctx context.Context, tenantID, periodID string) error {
+	settings, err := s.Settings(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
 		return err
@@ -434,26 +734,27 @@ func (s *Store) FinalizePeriod(ctx context.Context, tenantID, periodID string) e
 	}()
 
 	var status string
+	var endDate time.Time
 	if err := tx.QueryRow(ctx, `
-    SELECT status
+    SELECT status, end_date
     FROM payroll_periods
     WHERE tenant_id = $1 AND id = $2
     FOR UPDATE
-  `, tenantID, periodID).Scan(&status); err != nil {
+  `, tenantID, periodID).Scan(&status, &endDate); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrPeriodNotFound
 		}
 		return err
 	}
-	if status != PeriodStatusReviewed {
+	if status != PeriodStatusApproved {
 		return ErrFinalizeInvalidState
 	}
 
 	if _, err := tx.Exec(ctx, `
     UPDATE payroll_periods
-    SET status = $1, finalized_at = now()
+    SET status = $1, finalized_at = now(), tax_year = $4
     WHERE id = $2 AND tenant_id = $3
-  `, PeriodStatusFinalized, periodID, tenantID); err != nil {
+  `, PeriodStatusFinalized, periodID, tenantID, settings.TaxYear(endDate)); err != nil {
 		return err
 	}
 
@@ -478,6 +779,12 @@ func (s *Store) FinalizePeriod(ctx context.Context, tenantID, periodID string) e
 	if payslipCount == 0 {
 		return ErrFinalizeNoResults
 	}
+	if err := applyAccumulators(ctx, tx, tenantID, periodID, 1); err != nil {
+		return err
+	}
+	if err := applyRecurringBalances(ctx, tx, tenantID, periodID, 1); err != nil {
+		return err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return err
@@ -629,12 +936,27 @@ func (s *Store) CreateAdjustment(ctx context.Context, tenantID, periodID, employ
 }
 
 func (s *Store) PeriodSummaryData(ctx context.Context, tenantID, periodID string) (PeriodSummary, [][]byte, error) {
-	var summary PeriodSummary
-	if err := s.DB.QueryRow(ctx, `
-    SELECT COALESCE(SUM(gross),0), COALESCE(SUM(deductions),0), COALESCE(SUM(net),0), COUNT(1)
+	summary := PeriodSummary{ByCurrency: []CurrencyTotals{}}
+	totals, err := s.DB.Query(ctx, `
+    SELECT UPPER(TRIM(currency)), SUM(gross), SUM(deductions), SUM(net), COUNT(1)
     FROM payroll_results
     WHERE tenant_id = $1 AND period_id = $2
-  `, tenantID, periodID).Scan(&summary.TotalGross, &summary.TotalDeductions, &summary.TotalNet, &summary.EmployeeCount); err != nil {
+    GROUP BY UPPER(TRIM(currency))
+    ORDER BY UPPER(TRIM(currency))
+  `, tenantID, periodID)
+	if err != nil {
+		return summary, nil, err
+	}
+	defer totals.Close()
+	for totals.Next() {
+		var entry CurrencyTotals
+		if err := totals.Scan(&entry.Currency, &entry.TotalGross, &entry.TotalDeductions, &entry.TotalNet, &entry.EmployeeCount); err != nil {
+			return summary, nil, err
+		}
+		summary.EmployeeCount += entry.EmployeeCount
+		summary.ByCurrency = append(summary.ByCurrency, entry)
+	}
+	if err := totals.Err(); err != nil {
 		return summary, nil, err
 	}
 
@@ -671,6 +993,12 @@ func (s *Store) PeriodStatus(ctx context.Context, tenantID, periodID string) (st
 }
 
 func (s *Store) DeleteResultsForPeriod(ctx context.Context, tenantID, periodID string) error {
+	if _, err := s.DB.Exec(ctx, "DELETE FROM payroll_retro_items WHERE tenant_id = $1 AND period_id = $2", tenantID, periodID); err != nil {
+		return err
+	}
+	if _, err := s.DB.Exec(ctx, "DELETE FROM payroll_result_lines WHERE tenant_id = $1 AND period_id = $2", tenantID, periodID); err != nil {
+		return err
+	}
 	_, err := s.DB.Exec(ctx, "DELETE FROM payroll_results WHERE tenant_id = $1 AND period_id = $2", tenantID, periodID)
 	return err
 }
@@ -704,18 +1032,6 @@ func (s *Store) RegisterRows(ctx context.Context, tenantID, periodID string) ([]
 	return out, nil
 }
 
-func (s *Store) PeriodTotals(ctx context.Context, tenantID, periodID string) (float64, float64, float64, error) {
-	var gross, deductions, net float64
-	if err := s.DB.QueryRow(ctx, `
-    SELECT COALESCE(SUM(gross),0), COALESCE(SUM(deductions),0), COALESCE(SUM(net),0)
-    FROM payroll_results
-    WHERE tenant_id = $1 AND period_id = $2
-  `, tenantID, periodID).Scan(&gross, &deductions, &net); err != nil {
-		return 0, 0, 0, err
-	}
-	return gross, deductions, net, nil
-}
-
 func (s *Store) JournalTemplateConfig(ctx context.Context, tenantID, templateID string) ([]byte, error) {
 	var raw []byte
 	if err := s.DB.QueryRow(ctx, `
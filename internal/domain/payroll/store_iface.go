@@ -3,11 +3,23 @@ package payroll
 import (
 	"context"
 	"time"
+
+	"hrm/internal/domain/leave"
 )
 
 type StoreAPI interface {
 	ListSchedules(ctx context.Context, tenantID string) ([]Schedule, error)
-	CreateSchedule(ctx context.Context, tenantID, name, frequency string, payDay int) (string, error)
+	GetSchedule(ctx context.Context, tenantID, scheduleID string) (Schedule, error)
+	LastRegularPeriodEnd(ctx context.Context, tenantID, scheduleID string) (*time.Time, error)
+	ListScheduleCalendar(ctx context.Context, tenantID, scheduleID string, from, to time.Time) ([]CalendarPeriod, error)
+	ListHolidayDates(ctx context.Context, tenantID, region string, from, to time.Time) ([]time.Time, error)
+	ListRecurringItems(ctx context.Context, tenantID, employeeID string) ([]RecurringItem, error)
+	ListRecurringForRun(ctx context.Context, tenantID, employeeID, periodID string, start, end time.Time) ([]RecurringItem, error)
+	GetRecurringItem(ctx context.Context, tenantID, itemID string) (RecurringItem, error)
+	CreateRecurringItem(ctx context.Context, tenantID string, item RecurringItem) (string, error)
+	UpdateRecurringItem(ctx context.Context, tenantID string, item RecurringItem) error
+	CreateCalendarPeriods(ctx context.Context, tenantID, scheduleID string, periods []CalendarPeriod) (int, error)
+	CreateSchedule(ctx context.Context, tenantID, name, frequency string, payDay int, holidayRegion string) (string, error)
 	ListGroups(ctx context.Context, tenantID string) ([]Group, error)
 	CreateGroup(ctx context.Context, tenantID, name, scheduleID, currency string) (string, error)
 	ListElements(ctx context.Context, tenantID string) ([]Element, error)
@@ -23,16 +35,34 @@ type StoreAPI interface {
 	FindEmployeeIDByEmail(ctx context.Context, tenantID, email string) (string, error)
 	FindEmployeeIDByUserID(ctx context.Context, tenantID, userID string) (string, error)
 	GetPeriodDetails(ctx context.Context, tenantID, periodID string) (PeriodDetails, error)
+	NextOpenPeriod(ctx context.Context, tenantID, scheduleID string, on time.Time) (string, PeriodDetails, error)
 	CreateJobRun(ctx context.Context, tenantID, jobType string) (string, error)
 	UpdateJobRun(ctx context.Context, runID, status string, detailsJSON []byte) error
-	ListActiveEmployeesForRun(ctx context.Context, tenantID, status string) ([]EmployeePayrollData, error)
-	ListInputLines(ctx context.Context, periodID, employeeID string) ([]InputLine, error)
-	ListAdjustmentAmounts(ctx context.Context, tenantID, periodID, employeeID string, periodStart, periodEnd time.Time) ([]float64, error)
+	PayrollRun(ctx context.Context, tenantID, runID string) (string, []byte, error)
+	ActivePayrollRunID(ctx context.Context, tenantID, periodID string) (string, error)
+	ListActiveEmployeesForRun(ctx context.Context, tenantID, status string, periodStart, periodEnd time.Time) ([]EmployeePayrollData, error)
+	ListPeriodEmployees(ctx context.Context, tenantID, periodID string) ([]EmployeePayrollData, error)
+	EmployeePayrollData(ctx context.Context, tenantID, employeeID string) (EmployeePayrollData, error)
+	ListElementInputs(ctx context.Context, periodID, employeeID string) ([]ElementInput, error)
+	ListAdjustmentLines(ctx context.Context, tenantID, periodID, employeeID string, periodStart, periodEnd time.Time) ([]InputLine, error)
 	ListUnpaidLeaves(ctx context.Context, tenantID, employeeID string, periodStart, periodEnd time.Time, status string) ([]LeaveWindow, error)
-	LatestNet(ctx context.Context, tenantID, employeeID string) (float64, error)
-	UpsertPayrollResult(ctx context.Context, tenantID, periodID, employeeID string, gross, deductions, net float64, currency string, warningsJSON []byte) error
+	WorkSchedule(ctx context.Context, tenantID, employeeID string, from, to time.Time) (leave.WorkSchedule, error)
+	PreviousFinalizedPeriodID(ctx context.Context, tenantID, periodID string) (string, error)
+	PeriodResults(ctx context.Context, tenantID, periodID string) (map[string]EmployeeResult, error)
+	UpsertPayrollResult(ctx context.Context, tenantID, periodID, employeeID string, gross, taxableGross, deductions, net float64, currency string, warningsJSON []byte) error
+	SaveEmployeeResult(ctx context.Context, tenantID, periodID string, record ResultRecord) error
+	PayrollResult(ctx context.Context, tenantID, periodID, employeeID string) (EmployeeResult, error)
+	ListResultLines(ctx context.Context, tenantID, periodID, employeeID string) ([]ResultLine, error)
+	ListPeriodResultLines(ctx context.Context, tenantID, periodID string) (map[string][]ResultLine, error)
 	UpdatePeriodStatus(ctx context.Context, tenantID, periodID, status string) error
 	FinalizePeriod(ctx context.Context, tenantID, periodID string) error
+	ReopenPeriod(ctx context.Context, tenantID, periodID string) error
+	ListAccumulators(ctx context.Context, tenantID, employeeID string, taxYear int) ([]Accumulator, error)
+	AccumulatorEmployees(ctx context.Context, tenantID string, taxYear int) ([]string, error)
+	AnnualStatementData(ctx context.Context, tenantID, employeeID string, taxYear int) (AnnualStatementData, error)
+	UpsertAnnualStatement(ctx context.Context, tenantID, employeeID string, taxYear int, fileURL string) (string, error)
+	ListAnnualStatements(ctx context.Context, tenantID, employeeID string) ([]AnnualStatement, error)
+	AnnualStatementFile(ctx context.Context, tenantID, statementID string) (string, string, error)
 	CreatePayslipsForPeriod(ctx context.Context, periodID string) error
 	ListPayslipIDs(ctx context.Context, tenantID, periodID string) ([]PayslipKey, error)
 	UpdatePayslipFileURL(ctx context.Context, payslipID, fileURL string) error
@@ -46,10 +76,42 @@ type StoreAPI interface {
 	DeleteResultsForPeriod(ctx context.Context, tenantID, periodID string) error
 	DeletePayslipsForPeriod(ctx context.Context, tenantID, periodID string) error
 	RegisterRows(ctx context.Context, tenantID, periodID string) ([]RegisterRow, error)
-	PeriodTotals(ctx context.Context, tenantID, periodID string) (float64, float64, float64, error)
 	JournalTemplateConfig(ctx context.Context, tenantID, templateID string) ([]byte, error)
 	PayslipInfo(ctx context.Context, tenantID, payslipID string) (string, string, error)
 	PayslipPeriodID(ctx context.Context, tenantID, payslipID string) (string, error)
 	PayslipEmployeePeriod(ctx context.Context, tenantID, payslipID string) (string, string, error)
 	PayslipPDFData(ctx context.Context, tenantID, periodID, employeeID string) (PayslipPDFData, error)
+	ListPayslipTemplates(ctx context.Context, tenantID string) ([]PayslipTemplate, error)
+	PayslipTemplate(ctx context.Context, tenantID, templateID string) (PayslipTemplate, error)
+	DefaultPayslipTemplate(ctx context.Context, tenantID string) (PayslipTemplate, bool, error)
+	SavePayslipTemplate(ctx context.Context, tenantID string, template PayslipTemplate, replaceLogo bool) (string, error)
+	ListTaxRules(ctx context.Context, tenantID string) ([]TaxRule, error)
+	CreateTaxRule(ctx context.Context, tenantID string, rule TaxRule) (string, error)
+	ReplaceResultLines(ctx context.Context, tenantID, periodID, employeeID string, lines []ResultLine) error
+	JournalEmployees(ctx context.Context, tenantID, periodID string) ([]JournalEmployee, error)
+	CreateJournalExport(ctx context.Context, tenantID string, export JournalExport) (string, error)
+	ListJournalExports(ctx context.Context, tenantID, periodID string) ([]JournalExport, error)
+	PaymentRows(ctx context.Context, tenantID, periodID string) ([]PaymentRow, error)
+	PaymentSettings(ctx context.Context, tenantID string) (PaymentSettings, error)
+	UpsertPaymentSettings(ctx context.Context, tenantID string, settings PaymentSettings) error
+	Settings(ctx context.Context, tenantID string) (Settings, error)
+	PrevailingCurrency(ctx context.Context, tenantID string) (string, error)
+	UpsertSettings(ctx context.Context, tenantID string, settings Settings) error
+	ListExchangeRates(ctx context.Context, tenantID string) ([]ExchangeRate, error)
+	UpsertExchangeRate(ctx context.Context, tenantID string, rate ExchangeRate) (string, error)
+	DeleteExchangeRate(ctx context.Context, tenantID, rateID string) error
+	CreateOffCyclePeriod(ctx context.Context, tenantID string, original PeriodDetails, request OffCycleRequest) (string, error)
+	OffCycleTaxableBase(ctx context.Context, tenantID, originalPeriodID, periodID, employeeID string) (float64, error)
+	ListCompensation(ctx context.Context, tenantID, employeeID string) ([]Compensation, error)
+	ListCompensationForPeriod(ctx context.Context, tenantID string, periodStart, periodEnd time.Time) (map[string][]Compensation, error)
+	SaveCompensation(ctx context.Context, tenantID string, record Compensation) (string, error)
+	ListRetroCandidates(ctx context.Context, tenantID, employeeID string, before time.Time) ([]RetroCandidate, error)
+	ListBackdatedAdjustmentLines(ctx context.Context, tenantID, employeeID, originalPeriodID string, periodStart, periodEnd time.Time) ([]InputLine, error)
+	RetroSettled(ctx context.Context, tenantID, originalPeriodID, employeeID, periodID string) (float64, float64, error)
+	ReplaceRetroItems(ctx context.Context, tenantID, periodID, employeeID string, items []RetroItem) error
+	ListRetroItems(ctx context.Context, tenantID, periodID string) ([]RetroItem, error)
+	MarkPeriodRun(ctx context.Context, tenantID, periodID, userID string, resumed bool) error
+	SubmitPeriod(ctx context.Context, tenantID, periodID, userID string) (PeriodApproval, error)
+	DecidePeriod(ctx context.Context, tenantID, periodID, userID, onBehalfOf, decision, comment string) (PeriodApproval, error)
+	UserIDsWithPermission(ctx context.Context, tenantID, permission string) ([]string, error)
 }
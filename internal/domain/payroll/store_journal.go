@@ -0,0 +1,65 @@
+package payroll
+
+import (
+	"context"
+)
+
+func (s *Store) JournalEmployees(ctx context.Context, tenantID, periodID string) ([]JournalEmployee, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT r.employee_id, COALESCE(e.department_id::text, ''), r.currency, r.gross, r.deductions
+    FROM payroll_results r
+    JOIN employees e ON r.employee_id = e.id
+    WHERE r.tenant_id = $1 AND r.period_id = $2
+    ORDER BY e.last_name, e.first_name
+  `, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []JournalEmployee
+	for rows.Next() {
+		var employee JournalEmployee
+		if err := rows.Scan(&employee.EmployeeID, &employee.DepartmentID, &employee.Currency, &employee.Gross, &employee.Deductions); err != nil {
+			return nil, err
+		}
+		out = append(out, employee)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) CreateJournalExport(ctx context.Context, tenantID string, export JournalExport) (string, error) {
+	var id string
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO journal_exports (tenant_id, period_id, template_id, format, currency, total_debit, total_credit, line_count, exported_by)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+    RETURNING id
+  `, tenantID, export.PeriodID, nullIfEmpty(export.TemplateID), export.Format, nullIfEmpty(export.Currency), export.TotalDebit, export.TotalCredit, export.LineCount, nullIfEmpty(export.ExportedBy)).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *Store) ListJournalExports(ctx context.Context, tenantID, periodID string) ([]JournalExport, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, period_id, COALESCE(template_id::text, ''), format, COALESCE(currency, ''), total_debit, total_credit, line_count,
+           COALESCE(exported_by::text, ''), exported_at
+    FROM journal_exports
+    WHERE tenant_id = $1 AND period_id = $2
+    ORDER BY exported_at DESC
+  `, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []JournalExport
+	for rows.Next() {
+		var export JournalExport
+		if err := rows.Scan(&export.ID, &export.PeriodID, &export.TemplateID, &export.Format, &export.Currency, &export.TotalDebit, &export.TotalCredit, &export.LineCount, &export.ExportedBy, &export.ExportedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, export)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,58 @@
+package payroll
+
+import "context"
+
+func (s *Store) CreateOffCyclePeriod(ctx context.Context, tenantID string, original PeriodDetails, request OffCycleRequest) (string, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var id string
+	if err := tx.QueryRow(ctx, `
+    INSERT INTO payroll_periods (tenant_id, schedule_id, start_date, end_date, run_type, original_period_id, reason, pay_date)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+    RETURNING id
+  `, tenantID, original.ScheduleID, original.StartDate, original.EndDate, request.RunType, request.OriginalPeriodID, request.Reason, request.PayDate).Scan(&id); err != nil {
+		return "", err
+	}
+
+	tag, err := tx.Exec(ctx, `
+    INSERT INTO payroll_period_employees (tenant_id, period_id, employee_id)
+    SELECT tenant_id, $2, id
+    FROM employees
+    WHERE tenant_id = $1 AND id = ANY($3::uuid[])
+  `, tenantID, id, request.EmployeeIDs)
+	if err != nil {
+		return "", err
+	}
+	if int(tag.RowsAffected()) != len(request.EmployeeIDs) {
+		return "", ErrOffCycleEmployees
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	committed = true
+	return id, nil
+}
+
+// OffCycleTaxableBase sums the employee's taxable gross across the original
+// period and the other finalized off-cycle runs against it.
+func (s *Store) OffCycleTaxableBase(ctx context.Context, tenantID, originalPeriodID, periodID, employeeID string) (float64, error) {
+	var base float64
+	err := s.DB.QueryRow(ctx, `
+    SELECT COALESCE(SUM(r.taxable_gross), 0)
+    FROM payroll_results r
+    JOIN payroll_periods p ON r.period_id = p.id
+    WHERE r.tenant_id = $1 AND r.employee_id = $4
+      AND (p.id = $2 OR (p.original_period_id = $2 AND p.id <> $3 AND p.status = $5))
+  `, tenantID, originalPeriodID, periodID, employeeID, PeriodStatusFinalized).Scan(&base)
+	return base, err
+}
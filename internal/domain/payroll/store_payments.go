@@ -0,0 +1,65 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) PaymentRows(ctx context.Context, tenantID, periodID string) ([]PaymentRow, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT e.id, e.first_name, e.last_name, r.net, r.currency,
+           COALESCE(e.bank_account, ''), e.bank_account_enc
+    FROM payroll_results r
+    JOIN employees e ON r.employee_id = e.id
+    WHERE r.tenant_id = $1 AND r.period_id = $2
+    ORDER BY e.last_name, e.first_name
+  `, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []PaymentRow
+	for rows.Next() {
+		var row PaymentRow
+		if err := rows.Scan(&row.EmployeeID, &row.FirstName, &row.LastName, &row.Net, &row.Currency, &row.BankPlain, &row.BankEnc); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) PaymentSettings(ctx context.Context, tenantID string) (PaymentSettings, error) {
+	var settings PaymentSettings
+	err := s.DB.QueryRow(ctx, `
+    SELECT debtor_name, debtor_iban, debtor_iban_enc, debtor_bic, company_id, routing_number, account_number, account_number_enc, destination_name
+    FROM payroll_payment_settings
+    WHERE tenant_id = $1
+  `, tenantID).Scan(&settings.DebtorName, &settings.DebtorIBAN, &settings.DebtorIBANEnc, &settings.DebtorBIC, &settings.CompanyID, &settings.RoutingNumber, &settings.AccountNumber, &settings.AccountNumberEnc, &settings.DestinationName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PaymentSettings{}, nil
+	}
+	return settings, err
+}
+
+func (s *Store) UpsertPaymentSettings(ctx context.Context, tenantID string, settings PaymentSettings) error {
+	_, err := s.DB.Exec(ctx, `
+    INSERT INTO payroll_payment_settings (tenant_id, debtor_name, debtor_iban, debtor_iban_enc, debtor_bic, company_id, routing_number, account_number, account_number_enc, destination_name)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+    ON CONFLICT (tenant_id) DO UPDATE SET
+      debtor_name = EXCLUDED.debtor_name,
+      debtor_iban = EXCLUDED.debtor_iban,
+      debtor_iban_enc = EXCLUDED.debtor_iban_enc,
+      debtor_bic = EXCLUDED.debtor_bic,
+      company_id = EXCLUDED.company_id,
+      routing_number = EXCLUDED.routing_number,
+      account_number = EXCLUDED.account_number,
+      account_number_enc = EXCLUDED.account_number_enc,
+      destination_name = EXCLUDED.destination_name,
+      updated_at = now()
+  `, tenantID, settings.DebtorName, settings.DebtorIBAN, settings.DebtorIBANEnc, settings.DebtorBIC, settings.CompanyID, settings.RoutingNumber, settings.AccountNumber, settings.AccountNumberEnc, settings.DestinationName)
+	return err
+}
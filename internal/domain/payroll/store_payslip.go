@@ -2,19 +2,27 @@ package payroll
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type PayslipPDFData struct {
-	FirstName  string
-	LastName   string
-	Email      string
-	Gross      float64
-	Deductions float64
-	Net        float64
-	Currency   string
-	StartDate  time.Time
-	EndDate    time.Time
+	FirstName     string
+	LastName      string
+	Email         string
+	Gross         float64
+	Deductions    float64
+	Net           float64
+	Currency      string
+	StartDate     time.Time
+	EndDate       time.Time
+	RunType       string
+	Lines         []ResultLine
+	YTD           Totals
+	YTDLines      []ResultLine
+	LeaveBalances []PayslipLeaveBalance
 }
 
 func (s *Store) PayslipPDFData(ctx context.Context, tenantID, periodID, employeeID string) (PayslipPDFData, error) {
@@ -22,14 +30,200 @@ func (s *Store) PayslipPDFData(ctx context.Context, tenantID, periodID, employee
 	err := s.DB.QueryRow(ctx, `
     SELECT e.first_name, e.last_name, e.email,
            r.gross, r.deductions, r.net, r.currency,
-           p.start_date, p.end_date
+           p.start_date, p.end_date, p.run_type
     FROM payroll_results r
     JOIN employees e ON r.employee_id = e.id
     JOIN payroll_periods p ON r.period_id = p.id
     WHERE r.tenant_id = $1 AND r.period_id = $2 AND r.employee_id = $3
-  `, tenantID, periodID, employeeID).Scan(&data.FirstName, &data.LastName, &data.Email, &data.Gross, &data.Deductions, &data.Net, &data.Currency, &data.StartDate, &data.EndDate)
+  `, tenantID, periodID, employeeID).Scan(&data.FirstName, &data.LastName, &data.Email, &data.Gross, &data.Deductions, &data.Net, &data.Currency, &data.StartDate, &data.EndDate, &data.RunType)
+	if err != nil {
+		return PayslipPDFData{}, err
+	}
+	data.Lines, err = s.ListResultLines(ctx, tenantID, periodID, employeeID)
 	if err != nil {
 		return PayslipPDFData{}, err
 	}
-	return data, nil
+
+	// Year to date covers finalized periods ending in the same tax year and
+	// paid in the same currency, plus the period being rendered, which may
+	// not be finalized yet.
+	settings, err := s.Settings(ctx, tenantID)
+	if err != nil {
+		return PayslipPDFData{}, err
+	}
+	yearStart := settings.TaxYearStart(data.EndDate)
+	if err := s.DB.QueryRow(ctx, `
+    SELECT COALESCE(SUM(r.gross),0), COALESCE(SUM(r.deductions),0), COALESCE(SUM(r.net),0)
+    FROM payroll_results r
+    JOIN payroll_periods p ON r.period_id = p.id
+    WHERE r.tenant_id = $1 AND r.employee_id = $2
+      AND p.end_date >= $6 AND p.end_date <= $3
+      AND (p.status = $4 OR p.id = $5)
+      AND COALESCE(r.currency, '') = $7
+  `, tenantID, employeeID, data.EndDate, PeriodStatusFinalized, periodID, yearStart, data.Currency).Scan(&data.YTD.Gross, &data.YTD.Deductions, &data.YTD.Net); err != nil {
+		return PayslipPDFData{}, err
+	}
+	rows, err := s.DB.Query(ctx, `
+    SELECT l.line_type, l.code, MAX(l.description), SUM(l.amount)
+    FROM payroll_result_lines l
+    JOIN payroll_periods p ON l.period_id = p.id
+    JOIN payroll_results r ON r.period_id = l.period_id AND r.employee_id = l.employee_id
+    WHERE l.tenant_id = $1 AND l.employee_id = $2
+      AND p.end_date >= $6 AND p.end_date <= $3
+      AND (p.status = $4 OR p.id = $5)
+      AND COALESCE(r.currency, '') = $7
+    GROUP BY l.line_type, l.code
+  `, tenantID, employeeID, data.EndDate, PeriodStatusFinalized, periodID, yearStart, data.Currency)
+	if err != nil {
+		return PayslipPDFData{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var line ResultLine
+		if err := rows.Scan(&line.LineType, &line.Code, &line.Description, &line.Amount); err != nil {
+			return PayslipPDFData{}, err
+		}
+		data.YTDLines = append(data.YTDLines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return PayslipPDFData{}, err
+	}
+
+	balances, err := s.DB.Query(ctx, `
+    SELECT lt.name, lb.balance - lb.used - lb.pending, lb.used, lt.unit
+    FROM leave_balances lb
+    JOIN leave_types lt ON lb.leave_type_id = lt.id
+    WHERE lb.tenant_id = $1 AND lb.employee_id = $2
+    ORDER BY lt.name
+  `, tenantID, employeeID)
+	if err != nil {
+		return PayslipPDFData{}, err
+	}
+	defer balances.Close()
+	for balances.Next() {
+		var balance PayslipLeaveBalance
+		if err := balances.Scan(&balance.LeaveType, &balance.Available, &balance.Used, &balance.Unit); err != nil {
+			return PayslipPDFData{}, err
+		}
+		data.LeaveBalances = append(data.LeaveBalances, balance)
+	}
+	return data, balances.Err()
+}
+
+const payslipTemplateColumns = `id, name, company_name, company_address, logo, logo_type, footer,
+           show_leave_balances, show_ytd, is_default, created_at, updated_at`
+
+func scanPayslipTemplate(row pgx.Row) (PayslipTemplate, error) {
+	var template PayslipTemplate
+	if err := row.Scan(&template.ID, &template.Name, &template.CompanyName, &template.CompanyAddress, &template.Logo, &template.LogoType, &template.Footer,
+		&template.ShowLeaveBalances, &template.ShowYTD, &template.IsDefault, &template.CreatedAt, &template.UpdatedAt); err != nil {
+		return PayslipTemplate{}, err
+	}
+	template.HasLogo = len(template.Logo) > 0
+	return template, nil
+}
+
+func (s *Store) ListPayslipTemplates(ctx context.Context, tenantID string) ([]PayslipTemplate, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT `+payslipTemplateColumns+`
+    FROM payslip_templates
+    WHERE tenant_id = $1
+    ORDER BY name
+  `, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := make([]PayslipTemplate, 0)
+	for rows.Next() {
+		template, err := scanPayslipTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+func (s *Store) PayslipTemplate(ctx context.Context, tenantID, templateID string) (PayslipTemplate, error) {
+	return scanPayslipTemplate(s.DB.QueryRow(ctx, `
+    SELECT `+payslipTemplateColumns+`
+    FROM payslip_templates
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, templateID))
+}
+
+func (s *Store) DefaultPayslipTemplate(ctx context.Context, tenantID string) (PayslipTemplate, bool, error) {
+	template, err := scanPayslipTemplate(s.DB.QueryRow(ctx, `
+    SELECT `+payslipTemplateColumns+`
+    FROM payslip_templates
+    WHERE tenant_id = $1 AND is_default
+  `, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PayslipTemplate{}, false, nil
+	}
+	if err != nil {
+		return PayslipTemplate{}, false, err
+	}
+	return template, true, nil
+}
+
+// SavePayslipTemplate inserts the template when it has no ID and updates it
+// otherwise. The stored logo is only overwritten when replaceLogo is set, and
+// marking a template as default clears the flag on the tenant's other templates.
+func (s *Store) SavePayslipTemplate(ctx context.Context, tenantID string, template PayslipTemplate, replaceLogo bool) (string, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if template.IsDefault {
+		if _, err := tx.Exec(ctx, `
+    UPDATE payslip_templates SET is_default = false
+    WHERE tenant_id = $1 AND is_default AND id::text <> $2
+  `, tenantID, template.ID); err != nil {
+			return "", err
+		}
+	}
+
+	id := template.ID
+	if id == "" {
+		if err := tx.QueryRow(ctx, `
+    INSERT INTO payslip_templates (tenant_id, name, company_name, company_address, logo, logo_type, footer, show_leave_balances, show_ytd, is_default)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+    RETURNING id
+  `, tenantID, template.Name, template.CompanyName, template.CompanyAddress, template.Logo, template.LogoType, template.Footer,
+			template.ShowLeaveBalances, template.ShowYTD, template.IsDefault).Scan(&id); err != nil {
+			return "", err
+		}
+	} else {
+		tag, err := tx.Exec(ctx, `
+    UPDATE payslip_templates
+    SET name = $3, company_name = $4, company_address = $5,
+        logo = CASE WHEN $6 THEN $7 ELSE logo END,
+        logo_type = CASE WHEN $6 THEN $8 ELSE logo_type END,
+        footer = $9, show_leave_balances = $10, show_ytd = $11, is_default = $12, updated_at = now()
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, id, template.Name, template.CompanyName, template.CompanyAddress, replaceLogo, template.Logo, template.LogoType,
+			template.Footer, template.ShowLeaveBalances, template.ShowYTD, template.IsDefault)
+		if err != nil {
+			return "", err
+		}
+		if tag.RowsAffected() == 0 {
+			return "", pgx.ErrNoRows
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	committed = true
+	return id, nil
 }
@@ -0,0 +1,168 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const recurringColumns = `id, employee_id, kind, description, instalment, total_amount, paid_to_date, taxable, priority, protected_net,
+           start_date, end_date, status, COALESCE(reference, ''), COALESCE(created_by::text, ''), created_at`
+
+func scanRecurringItem(row pgx.Row, extra ...any) (RecurringItem, error) {
+	var item RecurringItem
+	dest := []any{&item.ID, &item.EmployeeID, &item.Kind, &item.Description, &item.Instalment, &item.TotalAmount, &item.PaidToDate,
+		&item.Taxable, &item.Priority, &item.ProtectedNet, &item.StartDate, &item.EndDate, &item.Status, &item.Reference,
+		&item.CreatedBy, &item.CreatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return RecurringItem{}, err
+	}
+	if item.TotalAmount != nil {
+		outstanding := roundCents(*item.TotalAmount - item.PaidToDate)
+		item.Outstanding = &outstanding
+	}
+	return item, nil
+}
+
+func (s *Store) ListRecurringItems(ctx context.Context, tenantID, employeeID string) ([]RecurringItem, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT `+recurringColumns+`
+    FROM payroll_recurring_items
+    WHERE tenant_id = $1 AND employee_id = $2
+    ORDER BY start_date DESC, created_at DESC
+  `, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRecurringItems(rows)
+}
+
+// ListRecurringForRun returns the employee's active recurring items in force
+// during the period that still have a balance to pay, with the amounts the
+// results of other periods not yet finalized have taken as Pending.
+func (s *Store) ListRecurringForRun(ctx context.Context, tenantID, employeeID, periodID string, start, end time.Time) ([]RecurringItem, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT `+recurringColumns+`,
+           COALESCE((
+             SELECT SUM(l.amount)
+             FROM payroll_result_lines l
+             JOIN payroll_periods p ON p.id = l.period_id
+             WHERE l.tenant_id = $1 AND l.source = $6 AND l.source_id = payroll_recurring_items.id
+               AND l.period_id <> $7 AND p.status <> $8
+           ), 0)
+    FROM payroll_recurring_items
+    WHERE tenant_id = $1 AND employee_id = $2 AND status = $3
+      AND start_date <= $5 AND (end_date IS NULL OR end_date >= $4)
+      AND (total_amount IS NULL OR paid_to_date < total_amount)
+    ORDER BY priority, start_date, created_at
+  `, tenantID, employeeID, RecurringStatusActive, start, end, ResultSourceRecurring, periodID, PeriodStatusFinalized)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]RecurringItem, 0)
+	for rows.Next() {
+		var pending float64
+		item, err := scanRecurringItem(rows, &pending)
+		if err != nil {
+			return nil, err
+		}
+		item.Pending = pending
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetRecurringItem(ctx context.Context, tenantID, itemID string) (RecurringItem, error) {
+	item, err := scanRecurringItem(s.DB.QueryRow(ctx, `
+    SELECT `+recurringColumns+`
+    FROM payroll_recurring_items
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, itemID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return RecurringItem{}, ErrRecurringNotFound
+	}
+	return item, err
+}
+
+func (s *Store) CreateRecurringItem(ctx context.Context, tenantID string, item RecurringItem) (string, error) {
+	var id string
+	err := s.DB.QueryRow(ctx, `
+    INSERT INTO payroll_recurring_items (tenant_id, employee_id, kind, description, instalment, total_amount, taxable,
+      priority, protected_net, start_date, end_date, status, reference, created_by)
+    SELECT $1, e.id, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+    FROM employees e
+    WHERE e.tenant_id = $1 AND e.id = $2
+    RETURNING id
+  `, tenantID, item.EmployeeID, item.Kind, item.Description, item.Instalment, item.TotalAmount, item.Taxable,
+		item.Priority, item.ProtectedNet, item.StartDate, item.EndDate, RecurringStatusActive, nullIfEmpty(item.Reference), nullIfEmpty(item.CreatedBy)).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrEmployeeNotFound
+	}
+	return id, err
+}
+
+// UpdateRecurringItem changes the terms of an item. Amounts already paid are
+// kept; an item whose new total has been paid is marked completed.
+func (s *Store) UpdateRecurringItem(ctx context.Context, tenantID string, item RecurringItem) error {
+	tag, err := s.DB.Exec(ctx, `
+    UPDATE payroll_recurring_items
+    SET description = $3, instalment = $4, total_amount = $5, taxable = $6, priority = $7, protected_net = $8,
+        end_date = $9, reference = $10, updated_at = now(),
+        status = CASE
+          WHEN $11 = $12 THEN $12
+          WHEN $5::numeric IS NOT NULL AND paid_to_date >= $5::numeric THEN $13
+          ELSE $11
+        END
+    WHERE tenant_id = $1 AND id = $2
+  `, tenantID, item.ID, item.Description, item.Instalment, item.TotalAmount, item.Taxable, item.Priority, item.ProtectedNet,
+		item.EndDate, nullIfEmpty(item.Reference), item.Status, RecurringStatusCancelled, RecurringStatusCompleted)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRecurringNotFound
+	}
+	return nil
+}
+
+// applyRecurringBalances adds (sign 1) or removes (sign -1) the recurring
+// amounts paid in a period to each item's paid to date inside tx, completing
+// items whose total has been paid and reactivating them when it no longer is.
+func applyRecurringBalances(ctx context.Context, tx pgx.Tx, tenantID, periodID string, sign int) error {
+	_, err := tx.Exec(ctx, `
+    WITH paid AS (
+      SELECT source_id, SUM(amount) AS amount
+      FROM payroll_result_lines
+      WHERE tenant_id = $1 AND period_id = $2 AND source = $3 AND source_id IS NOT NULL
+      GROUP BY source_id
+    )
+    UPDATE payroll_recurring_items r
+    SET paid_to_date = r.paid_to_date + $4 * paid.amount,
+        updated_at = now(),
+        status = CASE
+          WHEN r.status = $5 THEN r.status
+          WHEN r.total_amount IS NOT NULL AND r.paid_to_date + $4 * paid.amount >= r.total_amount THEN $6
+          ELSE $7
+        END
+    FROM paid
+    WHERE r.tenant_id = $1 AND r.id = paid.source_id
+  `, tenantID, periodID, ResultSourceRecurring, sign, RecurringStatusCancelled, RecurringStatusCompleted, RecurringStatusActive)
+	return err
+}
+
+func scanRecurringItems(rows pgx.Rows) ([]RecurringItem, error) {
+	out := make([]RecurringItem, 0)
+	for rows.Next() {
+		item, err := scanRecurringItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
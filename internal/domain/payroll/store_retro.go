@@ -0,0 +1,218 @@
+package payroll
+
+import (
+	"context"
+	"time"
+)
+
+// ListRetroCandidates finds finalized regular periods ending before the given
+// date whose compensation, inputs or adjustments changed after the period was
+// last settled. Periods finalized before the employee's compensation history
+// began are never recalculated. An empty employeeID lists every employee.
+func (s *Store) ListRetroCandidates(ctx context.Context, tenantID, employeeID string, before time.Time) ([]RetroCandidate, error) {
+	rows, err := s.DB.Query(ctx, `
+    WITH settled AS (
+      SELECT r.employee_id, e.first_name, e.last_name, p.id AS period_id, p.start_date, p.end_date,
+             GREATEST(p.finalized_at, (
+               SELECT MAX(sp.finalized_at)
+               FROM payroll_retro_items ri
+               JOIN payroll_periods sp ON ri.period_id = sp.id
+               WHERE ri.original_period_id = p.id AND ri.employee_id = r.employee_id AND sp.status = $4
+             )) AS settled_at
+      FROM payroll_results r
+      JOIN payroll_periods p ON r.period_id = p.id
+      JOIN employees e ON r.employee_id = e.id
+      WHERE r.tenant_id = $1
+        AND ($2 = '' OR r.employee_id::text = $2)
+        AND p.status = $4 AND p.run_type = $5 AND p.end_date < $3
+        AND p.finalized_at > (
+          SELECT MIN(c.created_at) FROM employee_compensation c WHERE c.employee_id = r.employee_id
+        )
+    ), flagged AS (
+      SELECT s.*,
+             EXISTS (
+               SELECT 1 FROM employee_compensation c
+               WHERE c.employee_id = s.employee_id AND c.effective_from <= s.end_date
+                 AND c.created_at > s.settled_at AND c.reason IS DISTINCT FROM 'initial'
+             ) AS compensation_changed,
+             EXISTS (
+               SELECT 1 FROM payroll_inputs i
+               WHERE i.period_id = s.period_id AND i.employee_id = s.employee_id AND i.created_at > s.settled_at
+             ) AS inputs_changed,
+             EXISTS (
+               SELECT 1 FROM payroll_adjustments a
+               JOIN payroll_periods ap ON a.period_id = ap.id
+               WHERE a.employee_id = s.employee_id AND ap.run_type = $5 AND a.created_at > s.settled_at
+                 AND (a.effective_date BETWEEN s.start_date AND s.end_date
+                      OR (a.period_id = s.period_id AND a.effective_date IS NULL))
+             ) AS adjustments_changed
+      FROM settled s
+    )
+    SELECT employee_id, first_name, last_name, period_id, start_date, end_date,
+           compensation_changed, inputs_changed, adjustments_changed
+    FROM flagged
+    WHERE compensation_changed OR inputs_changed OR adjustments_changed
+    ORDER BY employee_id, start_date
+  `, tenantID, employeeID, before, PeriodStatusFinalized, PeriodRunRegular)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RetroCandidate
+	for rows.Next() {
+		var candidate RetroCandidate
+		var compensationChanged, inputsChanged, adjustmentsChanged bool
+		if err := rows.Scan(&candidate.EmployeeID, &candidate.FirstName, &candidate.LastName, &candidate.PeriodID, &candidate.StartDate, &candidate.EndDate,
+			&compensationChanged, &inputsChanged, &adjustmentsChanged); err != nil {
+			return nil, err
+		}
+		candidate.Triggers = []string{}
+		if compensationChanged {
+			candidate.Triggers = append(candidate.Triggers, RetroTriggerCompensation)
+		}
+		if inputsChanged {
+			candidate.Triggers = append(candidate.Triggers, RetroTriggerInput)
+		}
+		if adjustmentsChanged {
+			candidate.Triggers = append(candidate.Triggers, RetroTriggerAdjustment)
+		}
+		out = append(out, candidate)
+	}
+	return out, rows.Err()
+}
+
+// ListBackdatedAdjustmentLines returns adjustments entered on other regular
+// periods with an effective date inside the original period.
+func (s *Store) ListBackdatedAdjustmentLines(ctx context.Context, tenantID, employeeID, originalPeriodID string, periodStart, periodEnd time.Time) ([]InputLine, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT a.id, a.description, a.amount
+    FROM payroll_adjustments a
+    JOIN payroll_periods ap ON a.period_id = ap.id
+    WHERE a.tenant_id = $1 AND a.employee_id = $2 AND a.period_id <> $3 AND ap.run_type = $6
+      AND a.effective_date >= $4 AND a.effective_date <= $5
+    ORDER BY a.created_at, a.id
+  `, tenantID, employeeID, originalPeriodID, periodStart, periodEnd, PeriodRunRegular)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []InputLine
+	for rows.Next() {
+		line := InputLine{Source: ResultSourceAdjustment, Code: ResultCodeAdjustment}
+		var amount float64
+		if err := rows.Scan(&line.SourceID, &line.Description, &amount); err != nil {
+			return nil, err
+		}
+		if amount >= 0 {
+			line.Type = ElementTypeEarning
+			line.Amount = amount
+			line.Taxable = true
+		} else {
+			line.Type = ElementTypeDeduction
+			line.Amount = -amount
+		}
+		out = append(out, line)
+	}
+	return out, rows.Err()
+}
+
+// RetroSettled returns what has been paid so far for an original period: its
+// own pre-tax pay and taxable gross, excluding retro it paid for earlier
+// periods and recurring items, plus retro for it settled in other finalized
+// periods.
+func (s *Store) RetroSettled(ctx context.Context, tenantID, originalPeriodID, employeeID, periodID string) (float64, float64, error) {
+	var pay, taxable float64
+	err := s.DB.QueryRow(ctx, `
+    WITH signed AS (
+      SELECT l.period_id, l.source, l.source_id, l.code,
+             CASE l.line_type WHEN $5 THEN l.amount WHEN $6 THEN -l.amount ELSE 0 END AS amount
+      FROM payroll_result_lines l
+      WHERE l.tenant_id = $1 AND l.employee_id = $3
+        AND (l.period_id = $2 OR (l.source = $7 AND l.source_id = $2 AND l.period_id <> $4))
+    ), paid AS (
+      SELECT s.amount, s.code
+      FROM signed s
+      JOIN payroll_periods pp ON s.period_id = pp.id
+      WHERE s.period_id <> $2 AND pp.status = $9
+    )
+    SELECT
+      COALESCE((SELECT SUM(amount) FROM signed WHERE period_id = $2 AND source NOT IN ($7, $8, $11)), 0)
+        + COALESCE((SELECT SUM(amount) FROM paid), 0),
+      COALESCE((SELECT taxable_gross FROM payroll_results WHERE tenant_id = $1 AND period_id = $2 AND employee_id = $3), 0)
+        - COALESCE((SELECT SUM(amount) FROM signed WHERE period_id = $2 AND source = $7 AND code = $10), 0)
+        - COALESCE((SELECT SUM(amount) FROM signed WHERE period_id = $2 AND source = $11 AND code = $12), 0)
+        + COALESCE((SELECT SUM(amount) FROM paid WHERE code = $10), 0)
+  `, tenantID, originalPeriodID, employeeID, periodID, ResultLineEarning, ResultLineDeduction,
+		ResultSourceRetro, ResultSourceTax, PeriodStatusFinalized, ResultCodeRetroPay,
+		ResultSourceRecurring, ResultCodeRecurringEarning).Scan(&pay, &taxable)
+	return pay, taxable, err
+}
+
+func (s *Store) ReplaceRetroItems(ctx context.Context, tenantID, periodID, employeeID string, items []RetroItem) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err := tx.Exec(ctx, `
+    DELETE FROM payroll_retro_items
+    WHERE tenant_id = $1 AND period_id = $2 AND employee_id = $3
+  `, tenantID, periodID, employeeID); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if _, err := tx.Exec(ctx, `
+      INSERT INTO payroll_retro_items (tenant_id, period_id, employee_id, original_period_id, triggers,
+        previous_pay, recalculated_pay, previous_taxable, recalculated_taxable)
+      VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+    `, tenantID, periodID, employeeID, item.OriginalPeriodID, item.Triggers,
+			item.PreviousPay, item.RecalculatedPay, item.PreviousTaxable, item.RecalculatedTaxable); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (s *Store) ListRetroItems(ctx context.Context, tenantID, periodID string) ([]RetroItem, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT ri.id, ri.period_id, ri.employee_id, e.first_name, e.last_name, ri.original_period_id, op.start_date, op.end_date,
+           ri.triggers, ri.previous_pay, ri.recalculated_pay, ri.previous_taxable, ri.recalculated_taxable, ri.created_at
+    FROM payroll_retro_items ri
+    JOIN employees e ON ri.employee_id = e.id
+    JOIN payroll_periods op ON ri.original_period_id = op.id
+    WHERE ri.tenant_id = $1 AND ri.period_id = $2
+    ORDER BY e.last_name, e.first_name, op.start_date
+  `, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]RetroItem, 0)
+	for rows.Next() {
+		var item RetroItem
+		if err := rows.Scan(&item.ID, &item.PeriodID, &item.EmployeeID, &item.FirstName, &item.LastName, &item.OriginalPeriodID,
+			&item.OriginalStartDate, &item.OriginalEndDate, &item.Triggers, &item.PreviousPay, &item.RecalculatedPay,
+			&item.PreviousTaxable, &item.RecalculatedTaxable, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		item.Delta = roundCents(item.RecalculatedPay - item.PreviousPay)
+		item.TaxableDelta = roundCents(item.RecalculatedTaxable - item.PreviousTaxable)
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,94 @@
+package payroll
+
+import (
+	"context"
+	"encoding/json"
+)
+
+func (s *Store) ListTaxRules(ctx context.Context, tenantID string) ([]TaxRule, error) {
+	rows, err := s.DB.Query(ctx, `
+    SELECT id, code, name, kind, effective_from, brackets_json, allowance,
+           COALESCE(earnings_cap, 0), employee_rate, employer_rate, pre_tax, created_at
+    FROM payroll_tax_rules
+    WHERE tenant_id = $1
+    ORDER BY code, effective_from DESC
+  `, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []TaxRule
+	for rows.Next() {
+		var rule TaxRule
+		var bracketsJSON []byte
+		if err := rows.Scan(&rule.ID, &rule.Code, &rule.Name, &rule.Kind, &rule.EffectiveFrom, &bracketsJSON, &rule.Allowance, &rule.EarningsCap, &rule.EmployeeRate, &rule.EmployerRate, &rule.PreTax, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(bracketsJSON, &rule.Brackets); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (s *Store) CreateTaxRule(ctx context.Context, tenantID string, rule TaxRule) (string, error) {
+	brackets := rule.Brackets
+	if brackets == nil {
+		brackets = []TaxBracket{}
+	}
+	bracketsJSON, err := json.Marshal(brackets)
+	if err != nil {
+		return "", err
+	}
+	var earningsCap any
+	if rule.EarningsCap > 0 {
+		earningsCap = rule.EarningsCap
+	}
+
+	var id string
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO payroll_tax_rules (tenant_id, code, name, kind, effective_from, brackets_json, allowance, earnings_cap, employee_rate, employer_rate, pre_tax)
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+    RETURNING id
+  `, tenantID, rule.Code, rule.Name, rule.Kind, rule.EffectiveFrom, bracketsJSON, rule.Allowance, earningsCap, rule.EmployeeRate, rule.EmployerRate, rule.PreTax).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *Store) ReplaceResultLines(ctx context.Context, tenantID, periodID, employeeID string, lines []ResultLine) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err := tx.Exec(ctx, `
+    DELETE FROM payroll_result_lines
+    WHERE tenant_id = $1 AND period_id = $2 AND employee_id = $3
+  `, tenantID, periodID, employeeID); err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		if _, err := tx.Exec(ctx, `
+      INSERT INTO payroll_result_lines (tenant_id, period_id, employee_id, line_type, source, source_id, code, description, base, amount)
+      VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+    `, tenantID, periodID, employeeID, line.LineType, line.Source, nullIfEmpty(line.SourceID), line.Code, line.Description, line.Base, line.Amount); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
@@ -0,0 +1,192 @@
+package payroll
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+type TaxBracket struct {
+	UpTo *float64 `json:"upTo,omitempty"`
+	Rate float64  `json:"rate"`
+}
+
+type TaxRule struct {
+	ID            string       `json:"id"`
+	Code          string       `json:"code"`
+	Name          string       `json:"name"`
+	Kind          string       `json:"kind"`
+	EffectiveFrom time.Time    `json:"effectiveFrom"`
+	Brackets      []TaxBracket `json:"brackets"`
+	Allowance     float64      `json:"allowance"`
+	EarningsCap   float64      `json:"earningsCap"`
+	EmployeeRate  float64      `json:"employeeRate"`
+	EmployerRate  float64      `json:"employerRate"`
+	PreTax        bool         `json:"preTax"`
+	CreatedAt     time.Time    `json:"createdAt"`
+}
+
+type TaxLine struct {
+	RuleID   string
+	Code     string
+	Name     string
+	Kind     string
+	Base     float64
+	Employee float64
+	Employer float64
+}
+
+// EffectiveTaxRules keeps, per rule code, the latest version whose effective date is on or before the given date.
+func EffectiveTaxRules(rules []TaxRule, on time.Time) []TaxRule {
+	latest := map[string]TaxRule{}
+	for _, rule := range rules {
+		if rule.EffectiveFrom.After(on) {
+			continue
+		}
+		current, ok := latest[rule.Code]
+		if !ok || rule.EffectiveFrom.After(current.EffectiveFrom) {
+			latest[rule.Code] = rule
+		}
+	}
+	out := make([]TaxRule, 0, len(latest))
+	for _, rule := range latest {
+		out = append(out, rule)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Code < out[j].Code
+	})
+	return out
+}
+
+// TaxableGross returns the base salary plus taxable earnings, less deductions that are taken before tax.
+func TaxableGross(baseSalary float64, inputs []InputLine) float64 {
+	taxable := baseSalary
+	for _, input := range inputs {
+		if !input.Taxable {
+			continue
+		}
+		switch input.Type {
+		case ElementTypeEarning:
+			taxable += input.Amount
+		case ElementTypeDeduction:
+			taxable -= input.Amount
+		}
+	}
+	if taxable < 0 {
+		return 0
+	}
+	return taxable
+}
+
+// ComputeTaxes applies social contributions first so that pre-tax contributions
+// reduce the base used by income tax rules.
+func ComputeTaxes(rules []TaxRule, taxableGross float64) []TaxLine {
+	ordered := make([]TaxRule, len(rules))
+	copy(ordered, rules)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Kind == TaxKindSocialContribution && ordered[j].Kind != TaxKindSocialContribution
+	})
+
+	incomeBase := taxableGross
+	var lines []TaxLine
+	for _, rule := range ordered {
+		base := taxableGross
+		if rule.Kind == TaxKindIncomeTax {
+			base = incomeBase
+		}
+		base -= rule.Allowance
+		if base < 0 {
+			base = 0
+		}
+		if rule.EarningsCap > 0 && base > rule.EarningsCap {
+			base = rule.EarningsCap
+		}
+
+		employee := base * rule.EmployeeRate
+		if len(rule.Brackets) > 0 {
+			employee = progressiveTax(base, rule.Brackets)
+		}
+		line := TaxLine{
+			RuleID:   rule.ID,
+			Code:     rule.Code,
+			Name:     rule.Name,
+			Kind:     rule.Kind,
+			Base:     roundCents(base),
+			Employee: roundCents(employee),
+			Employer: roundCents(base * rule.EmployerRate),
+		}
+		if line.Employee == 0 && line.Employer == 0 {
+			continue
+		}
+		if rule.Kind == TaxKindSocialContribution && rule.PreTax {
+			incomeBase -= line.Employee
+			if incomeBase < 0 {
+				incomeBase = 0
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TaxResultLines(lines []TaxLine) []ResultLine {
+	out := make([]ResultLine, 0, len(lines)*2)
+	for _, line := range lines {
+		if line.Employee > 0 {
+			out = append(out, ResultLine{
+				LineType:    ResultLineDeduction,
+				Source:      ResultSourceTax,
+				SourceID:    line.RuleID,
+				Code:        line.Code,
+				Description: line.Name,
+				Base:        line.Base,
+				Amount:      line.Employee,
+			})
+		}
+		if line.Employer > 0 {
+			out = append(out, ResultLine{
+				LineType:    ResultLineEmployerContribution,
+				Source:      ResultSourceTax,
+				SourceID:    line.RuleID,
+				Code:        line.Code,
+				Description: line.Name,
+				Base:        line.Base,
+				Amount:      line.Employer,
+			})
+		}
+	}
+	return out
+}
+
+func progressiveTax(base float64, brackets []TaxBracket) float64 {
+	ordered := make([]TaxBracket, len(brackets))
+	copy(ordered, brackets)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].UpTo == nil {
+			return false
+		}
+		if ordered[j].UpTo == nil {
+			return true
+		}
+		return *ordered[i].UpTo < *ordered[j].UpTo
+	})
+
+	tax := 0.0
+	lower := 0.0
+	for _, bracket := range ordered {
+		upper := math.Inf(1)
+		if bracket.UpTo != nil {
+			upper = *bracket.UpTo
+		}
+		if base <= lower {
+			break
+		}
+		tax += (math.Min(base, upper) - lower) * bracket.Rate
+		lower = upper
+	}
+	return tax
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
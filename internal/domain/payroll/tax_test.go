@@ -0,0 +1,103 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+)
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func TestComputeTaxesProgressiveBrackets(t *testing.T) {
+	rules := []TaxRule{{
+		ID:   "r1",
+		Code: "paye",
+		Name: "Income tax",
+		Kind: TaxKindIncomeTax,
+		Brackets: []TaxBracket{
+			{UpTo: floatPtr(1000), Rate: 0},
+			{UpTo: floatPtr(3000), Rate: 0.2},
+			{Rate: 0.4},
+		},
+	}}
+
+	lines := ComputeTaxes(rules, 4000)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 tax line, got %d", len(lines))
+	}
+	if lines[0].Employee != 800 {
+		t.Fatalf("expected tax 800, got %v", lines[0].Employee)
+	}
+}
+
+func TestComputeTaxesAllowanceCapAndEmployerShare(t *testing.T) {
+	rules := []TaxRule{{
+		ID:           "r1",
+		Code:         "ssc",
+		Name:         "Social security",
+		Kind:         TaxKindSocialContribution,
+		Allowance:    500,
+		EarningsCap:  2000,
+		EmployeeRate: 0.1,
+		EmployerRate: 0.15,
+	}}
+
+	lines := ComputeTaxes(rules, 5000)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 tax line, got %d", len(lines))
+	}
+	if lines[0].Base != 2000 {
+		t.Fatalf("expected capped base 2000, got %v", lines[0].Base)
+	}
+	if lines[0].Employee != 200 || lines[0].Employer != 300 {
+		t.Fatalf("expected employee 200 and employer 300, got %v and %v", lines[0].Employee, lines[0].Employer)
+	}
+}
+
+func TestComputeTaxesPreTaxContributionReducesIncomeBase(t *testing.T) {
+	rules := []TaxRule{
+		{ID: "r1", Code: "paye", Name: "Income tax", Kind: TaxKindIncomeTax, EmployeeRate: 0.2},
+		{ID: "r2", Code: "pension", Name: "Pension", Kind: TaxKindSocialContribution, EmployeeRate: 0.05, PreTax: true},
+	}
+
+	lines := ComputeTaxes(rules, 2000)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 tax lines, got %d", len(lines))
+	}
+	if lines[0].Code != "pension" || lines[0].Employee != 100 {
+		t.Fatalf("expected pension 100 first, got %+v", lines[0])
+	}
+	if lines[1].Base != 1900 || lines[1].Employee != 380 {
+		t.Fatalf("expected income tax 380 on base 1900, got %+v", lines[1])
+	}
+}
+
+func TestEffectiveTaxRulesPicksLatestVersion(t *testing.T) {
+	rules := []TaxRule{
+		{ID: "old", Code: "paye", EffectiveFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "new", Code: "paye", EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "future", Code: "paye", EffectiveFrom: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "ssc", Code: "ssc", EffectiveFrom: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	effective := EffectiveTaxRules(rules, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC))
+	if len(effective) != 2 {
+		t.Fatalf("expected 2 effective rules, got %d", len(effective))
+	}
+	if effective[0].ID != "new" {
+		t.Fatalf("expected latest paye version, got %s", effective[0].ID)
+	}
+}
+
+func TestTaxableGrossExcludesNonTaxableLines(t *testing.T) {
+	inputs := []InputLine{
+		{Type: ElementTypeEarning, Amount: 200, Taxable: true},
+		{Type: ElementTypeEarning, Amount: 50},
+		{Type: ElementTypeDeduction, Amount: 100, Taxable: true},
+		{Type: ElementTypeDeduction, Amount: 30},
+	}
+	if got := TaxableGross(1000, inputs); got != 1100 {
+		t.Fatalf("expected taxable gross 1100, got %v", got)
+	}
+}
@@ -35,6 +35,11 @@ type Config struct {
 	RateLimitPerMinute      int
 	LeaveAccrualInterval    time.Duration
 	RetentionInterval       time.Duration
+	PayrollCalendarInterval time.Duration
+	LeaveReminderInterval   time.Duration
+	LeaveCarryOverInterval  time.Duration
+	LeaveCarryOverNotice    int
+	LeavePayoutInterval     time.Duration
 	PasswordResetTTL        time.Duration
 	MetricsEnabled          bool
 }
@@ -67,6 +72,11 @@ func Load() Config {
 		RateLimitPerMinute:      getEnvInt("RATE_LIMIT_PER_MINUTE", 60),
 		LeaveAccrualInterval:    getEnvDuration("LEAVE_ACCRUAL_INTERVAL", 24*time.Hour),
 		RetentionInterval:       getEnvDuration("RETENTION_INTERVAL", 24*time.Hour),
+		PayrollCalendarInterval: getEnvDuration("PAYROLL_CALENDAR_INTERVAL", 24*time.Hour),
+		LeaveReminderInterval:   getEnvDuration("LEAVE_APPROVAL_REMINDER_INTERVAL", 24*time.Hour),
+		LeaveCarryOverInterval:  getEnvDuration("LEAVE_CARRY_OVER_INTERVAL", 24*time.Hour),
+		LeaveCarryOverNotice:    getEnvInt("LEAVE_CARRY_OVER_NOTICE_DAYS", 30),
+		LeavePayoutInterval:     getEnvDuration("LEAVE_TERMINATION_PAYOUT_INTERVAL", 24*time.Hour),
 		PasswordResetTTL:        getEnvDuration("PASSWORD_RESET_TTL", 2*time.Hour),
 		MetricsEnabled:          getEnvBool("METRICS_ENABLED", true),
 	}
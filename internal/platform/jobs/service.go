@@ -2,50 +2,103 @@ package jobs
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"hrm/internal/domain/core"
 	"hrm/internal/domain/gdpr"
 	"hrm/internal/domain/leave"
+	"hrm/internal/domain/notifications"
+	"hrm/internal/domain/payroll"
 	"hrm/internal/platform/config"
+	cryptoutil "hrm/internal/platform/crypto"
 )
 
 const (
-	JobLeaveAccrual = "leave_accrual"
-	JobRetention    = "gdpr_retention"
+	JobLeaveAccrual      = "leave_accrual"
+	JobRetention         = "gdpr_retention"
+	JobPayrollCalendar   = "payroll_calendar"
+	JobLeaveReminders    = "leave_approval_reminders"
+	JobLeaveCarryOver    = "leave_carry_over"
+	JobLeaveTOILExpiry   = "leave_toil_expiry"
+	JobLeaveTerminations = "leave_termination_payouts"
+)
+
+// Runs held by an instance are heartbeated every heartbeatInterval; a queued
+// or running run whose heartbeat is older than staleRunAfter belongs to an
+// instance that has stopped, and is failed by whichever instance notices.
+const (
+	heartbeatInterval = 30 * time.Second
+	staleRunAfter     = 3 * heartbeatInterval
+)
+
+var (
+	ErrQueueFull = errors.New("job queue full")
+	ErrRunActive = errors.New("job run already active")
 )
 
 type Service struct {
-	DB    *pgxpool.Pool
-	Cfg   config.Config
-	queue chan job
+	DB         *pgxpool.Pool
+	Cfg        config.Config
+	Notify     *notifications.Service
+	Crypto     *cryptoutil.Service
+	queue      chan job
+	instanceID string
 }
 
 type job struct {
 	Type     string
 	TenantID string
+	RunID    string
 	Run      func(context.Context) (any, error)
 }
 
 func New(db *pgxpool.Pool, cfg config.Config) *Service {
 	return &Service{
-		DB:    db,
-		Cfg:   cfg,
-		queue: make(chan job, 128),
+		DB:         db,
+		Cfg:        cfg,
+		queue:      make(chan job, 128),
+		instanceID: newInstanceID(),
 	}
 }
 
+func newInstanceID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
 func (s *Service) Start(ctx context.Context) {
+	s.failInterruptedRuns(ctx)
 	go s.worker(ctx)
+	go s.heartbeat(ctx)
 	if s.Cfg.LeaveAccrualInterval > 0 {
 		go s.scheduleAccruals(ctx, s.Cfg.LeaveAccrualInterval)
 	}
 	if s.Cfg.RetentionInterval > 0 {
 		go s.scheduleRetention(ctx, s.Cfg.RetentionInterval)
 	}
+	if s.Cfg.PayrollCalendarInterval > 0 {
+		go s.schedulePayrollCalendars(ctx, s.Cfg.PayrollCalendarInterval)
+	}
+	if s.Cfg.LeaveReminderInterval > 0 && s.Notify != nil {
+		go s.scheduleLeaveReminders(ctx, s.Cfg.LeaveReminderInterval)
+	}
+	if s.Cfg.LeaveCarryOverInterval > 0 {
+		go s.scheduleLeaveCarryOver(ctx, s.Cfg.LeaveCarryOverInterval)
+	}
+	if s.Cfg.LeavePayoutInterval > 0 {
+		go s.scheduleLeaveTerminations(ctx, s.Cfg.LeavePayoutInterval)
+	}
 }
 
 func (s *Service) Enqueue(jobType, tenantID string, run func(context.Context) (any, error)) {
@@ -56,6 +109,43 @@ func (s *Service) Enqueue(jobType, tenantID string, run func(context.Context) (a
 	}
 }
 
+// EnqueueTracked records the run as queued before handing it to the worker so
+// callers can return the run ID for polling. The job may call progress to
+// persist intermediate details while it is still running. ErrRunActive is
+// returned when a unique index on job_runs refuses a second active run.
+func (s *Service) EnqueueTracked(ctx context.Context, jobType, tenantID string, details any, run func(ctx context.Context, progress func(any)) (any, error)) (string, error) {
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return "", err
+	}
+	runID := ""
+	if err := s.DB.QueryRow(ctx, `
+    INSERT INTO job_runs (tenant_id, job_type, status, details_json, instance_id, heartbeat_at)
+    VALUES ($1,$2,$3,$4,$5,now())
+    RETURNING id
+  `, tenantID, jobType, "queued", detailsJSON, s.instanceID).Scan(&runID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return "", ErrRunActive
+		}
+		return "", err
+	}
+
+	j := job{Type: jobType, TenantID: tenantID, RunID: runID}
+	j.Run = func(ctx context.Context) (any, error) {
+		return run(ctx, func(details any) {
+			s.updateRunDetails(ctx, runID, details)
+		})
+	}
+	select {
+	case s.queue <- j:
+		return runID, nil
+	default:
+		s.finishRun(ctx, runID, "failed", details)
+		return "", ErrQueueFull
+	}
+}
+
 func (s *Service) RunNow(ctx context.Context, jobType, tenantID string, run func(context.Context) (any, error)) (any, error) {
 	return s.runJob(ctx, job{Type: jobType, TenantID: tenantID, Run: run})
 }
@@ -74,12 +164,20 @@ func (s *Service) worker(ctx context.Context) {
 }
 
 func (s *Service) runJob(ctx context.Context, j job) (any, error) {
-	runID := ""
-	if err := s.DB.QueryRow(ctx, `
-    INSERT INTO job_runs (tenant_id, job_type, status)
-    VALUES ($1,$2,$3)
+	runID := j.RunID
+	if runID != "" {
+		if _, err := s.DB.Exec(ctx, `
+      UPDATE job_runs
+      SET status = $1, started_at = now()
+      WHERE id = $2
+    `, "running", runID); err != nil {
+			slog.Warn("job run start update failed", "err", err)
+		}
+	} else if err := s.DB.QueryRow(ctx, `
+    INSERT INTO job_runs (tenant_id, job_type, status, instance_id, heartbeat_at)
+    VALUES ($1,$2,$3,$4,now())
     RETURNING id
-  `, j.TenantID, j.Type, "running").Scan(&runID); err != nil {
+  `, j.TenantID, j.Type, "running", s.instanceID).Scan(&runID); err != nil {
 		slog.Warn("job run insert failed", "err", err)
 	}
 
@@ -88,21 +186,77 @@ func (s *Service) runJob(ctx context.Context, j job) (any, error) {
 	if err != nil {
 		status = "failed"
 	}
+	if runID != "" {
+		s.finishRun(ctx, runID, status, details)
+	}
+	return details, err
+}
+
+func (s *Service) finishRun(ctx context.Context, runID, status string, details any) {
 	detailsJSON, marshalErr := json.Marshal(details)
 	if marshalErr != nil {
 		slog.Warn("job details marshal failed", "err", marshalErr)
 		detailsJSON = []byte("{}")
 	}
-	if runID != "" {
-		if _, updErr := s.DB.Exec(ctx, `
-      UPDATE job_runs
-      SET status = $1, details_json = $2, completed_at = now()
-      WHERE id = $3
-    `, status, detailsJSON, runID); updErr != nil {
-			slog.Warn("job run update failed", "err", updErr)
+	if _, updErr := s.DB.Exec(ctx, `
+    UPDATE job_runs
+    SET status = $1, details_json = $2, completed_at = now()
+    WHERE id = $3
+  `, status, detailsJSON, runID); updErr != nil {
+		slog.Warn("job run update failed", "err", updErr)
+	}
+}
+
+func (s *Service) updateRunDetails(ctx context.Context, runID string, details any) {
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		slog.Warn("job progress marshal failed", "err", err)
+		return
+	}
+	if _, err := s.DB.Exec(ctx, `
+    UPDATE job_runs
+    SET details_json = $1
+    WHERE id = $2
+  `, detailsJSON, runID); err != nil {
+		slog.Warn("job progress update failed", "err", err)
+	}
+}
+
+// heartbeat keeps this instance's queued and running runs alive and fails
+// runs other instances stopped heartbeating.
+func (s *Service) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DB.Exec(ctx, `
+        UPDATE job_runs
+        SET heartbeat_at = now()
+        WHERE instance_id = $1 AND status IN ('queued', 'running')
+      `, s.instanceID); err != nil {
+				slog.Warn("job run heartbeat failed", "err", err)
+			}
+			s.failInterruptedRuns(ctx)
 		}
 	}
-	return details, err
+}
+
+// failInterruptedRuns marks runs whose instance stopped heartbeating as
+// failed; the queue is in-memory so they will never complete on their own.
+// Runs of instances that are still alive are left alone.
+func (s *Service) failInterruptedRuns(ctx context.Context) {
+	if _, err := s.DB.Exec(ctx, `
+    UPDATE job_runs
+    SET status = 'failed', completed_at = now()
+    WHERE status IN ('queued', 'running')
+      AND COALESCE(instance_id, '') <> $1
+      AND COALESCE(heartbeat_at, started_at) < now() - make_interval(secs => $2)
+  `, s.instanceID, staleRunAfter.Seconds()); err != nil {
+		slog.Warn("interrupted job runs update failed", "err", err)
+	}
 }
 
 func (s *Service) scheduleAccruals(ctx context.Context, interval time.Duration) {
@@ -169,6 +323,130 @@ func (s *Service) scheduleRetention(ctx context.Context, interval time.Duration)
 	}
 }
 
+// schedulePayrollCalendars keeps a year of regular payroll periods generated
+// ahead for every pay schedule.
+func (s *Service) schedulePayrollCalendars(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tenants, err := s.listTenants(ctx)
+			if err != nil {
+				slog.Warn("payroll calendar scheduler tenant lookup failed", "err", err)
+				continue
+			}
+			for _, tenantID := range tenants {
+				tenant := tenantID
+				service := payroll.NewService(payroll.NewStore(s.DB, leave.NewStore(s.DB)), nil)
+				s.Enqueue(JobPayrollCalendar, tenant, func(ctx context.Context) (any, error) {
+					return service.GenerateCalendars(ctx, tenant, time.Now().UTC())
+				})
+			}
+		}
+	}
+}
+
+// scheduleLeaveReminders reminds approvers of leave approval chain steps that
+// have waited longer than the interval, at most once per interval.
+func (s *Service) scheduleLeaveReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tenants, err := s.listTenants(ctx)
+			if err != nil {
+				slog.Warn("leave reminder scheduler tenant lookup failed", "err", err)
+				continue
+			}
+			for _, tenantID := range tenants {
+				tenant := tenantID
+				service := &leave.Service{Store: leave.NewStore(s.DB), Employees: core.NewStore(s.DB, nil)}
+				s.Enqueue(JobLeaveReminders, tenant, func(ctx context.Context) (any, error) {
+					return service.SendApprovalReminders(ctx, tenant, time.Now().Add(-interval), func(ctx context.Context, userID, title, body string) error {
+						return s.Notify.Create(ctx, tenant, userID, notifications.TypeLeaveReminder, title, body)
+					})
+				})
+			}
+		}
+	}
+}
+
+// scheduleLeaveCarryOver rolls leave balances over once each policy year has
+// ended, expires carried-forward days and warns employees before they expire.
+// Expired TOIL hours are removed from balances on the same schedule.
+func (s *Service) scheduleLeaveCarryOver(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tenants, err := s.listTenants(ctx)
+			if err != nil {
+				slog.Warn("leave carry-over scheduler tenant lookup failed", "err", err)
+				continue
+			}
+			for _, tenantID := range tenants {
+				tenant := tenantID
+				service := &leave.Service{Store: leave.NewStore(s.DB)}
+				s.Enqueue(JobLeaveCarryOver, tenant, func(ctx context.Context) (any, error) {
+					return service.ProcessYearEnd(ctx, tenant, time.Now(), s.Cfg.LeaveCarryOverNotice, s.balanceNotifier(tenant))
+				})
+				s.Enqueue(JobLeaveTOILExpiry, tenant, func(ctx context.Context) (any, error) {
+					expired, hours, err := service.ExpireTOIL(ctx, tenant, time.Now())
+					return map[string]any{"entriesExpired": expired, "hoursExpired": hours}, err
+				})
+			}
+		}
+	}
+}
+
+// scheduleLeaveTerminations pays out the encashable leave of employees whose
+// end date has been reached. It runs on its own interval so turning off
+// carry-over does not stop leavers being paid.
+func (s *Service) scheduleLeaveTerminations(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tenants, err := s.listTenants(ctx)
+			if err != nil {
+				slog.Warn("leave termination scheduler tenant lookup failed", "err", err)
+				continue
+			}
+			for _, tenantID := range tenants {
+				tenant := tenantID
+				leaveStore := leave.NewStore(s.DB)
+				payout := &leave.Service{Store: leaveStore, Payroll: payroll.NewService(payroll.NewStore(s.DB, leaveStore), s.Crypto)}
+				s.Enqueue(JobLeaveTerminations, tenant, func(ctx context.Context) (any, error) {
+					return payout.SettleTerminations(ctx, tenant, time.Now())
+				})
+			}
+		}
+	}
+}
+
+// balanceNotifier sends leave balance notices through the notification
+// service, or returns nil when notifications are not configured.
+func (s *Service) balanceNotifier(tenantID string) leave.BalanceNotifier {
+	if s.Notify == nil {
+		return nil
+	}
+	return func(ctx context.Context, userID, title, body string) error {
+		return s.Notify.Create(ctx, tenantID, userID, notifications.TypeLeaveCarryOver, title, body)
+	}
+}
+
 type retentionPolicy struct {
 	DataCategory  string
 	RetentionDays int
@@ -35,6 +35,10 @@ func Created(w http.ResponseWriter, data any, requestID string) {
 	WriteJSON(w, http.StatusCreated, Envelope{Success: true, Data: data, RequestID: requestID})
 }
 
+func Accepted(w http.ResponseWriter, data any, requestID string) {
+	WriteJSON(w, http.StatusAccepted, Envelope{Success: true, Data: data, RequestID: requestID})
+}
+
 func Fail(w http.ResponseWriter, status int, code, message, requestID string) {
 	FailWithDetails(w, status, code, message, nil, requestID)
 }
@@ -0,0 +1,160 @@
+package corehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/core"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type delegationPayload struct {
+	DelegatorID string `json:"delegatorId"`
+	DelegateID  string `json:"delegateId"`
+	Scope       string `json:"scope"`
+	StartDate   string `json:"startDate"`
+	EndDate     string `json:"endDate"`
+	Reason      string `json:"reason"`
+}
+
+// handleListDelegations lists the delegations the user gave or received; HR
+// sees every delegation in the tenant.
+func (h *Handler) handleListDelegations(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	userID := user.UserID
+	if user.RoleName == auth.RoleHR {
+		userID = ""
+	}
+	delegations, err := h.Service.ListDelegations(r.Context(), user.TenantID, userID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "delegation_list_failed", "failed to list delegations", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, delegations, middleware.GetRequestID(r.Context()))
+}
+
+// handleCreateDelegation lets a user hand their approvals to another user for
+// a date range. HR may create delegations for any user.
+func (h *Handler) handleCreateDelegation(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload delegationPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+
+	delegation := core.Delegation{
+		DelegatorID: strings.TrimSpace(payload.DelegatorID),
+		DelegateID:  strings.TrimSpace(payload.DelegateID),
+		Scope:       strings.ToLower(strings.TrimSpace(payload.Scope)),
+		Reason:      strings.TrimSpace(payload.Reason),
+		CreatedBy:   user.UserID,
+	}
+	if delegation.DelegatorID == "" {
+		delegation.DelegatorID = user.UserID
+	}
+	if delegation.Scope == "" {
+		delegation.Scope = core.DelegationScopeAll
+	}
+
+	validator := shared.NewValidator()
+	validator.Required("delegateId", delegation.DelegateID, "is required")
+	if delegation.DelegateID != "" && delegation.DelegateID == delegation.DelegatorID {
+		validator.Add("delegateId", "must differ from the delegator")
+	}
+	validator.Enum("scope", delegation.Scope, core.DelegationScopes(), "must be one of: all, leave, performance, payroll")
+	startDate, _ := validator.Date("startDate", payload.StartDate)
+	endDate, _ := validator.Date("endDate", payload.EndDate)
+	validator.DateOrder("startDate", startDate, "endDate", endDate)
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+	delegation.StartDate = startDate
+	delegation.EndDate = endDate
+
+	if delegation.DelegatorID != user.UserID && user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "only hr can delegate for another user", middleware.GetRequestID(r.Context()))
+		return
+	}
+	// Payroll approval can only be handed on by the approver themselves, so
+	// one payroll user cannot route another's approvals to an accomplice.
+	if delegation.DelegatorID != user.UserID && (delegation.Scope == core.DelegationScopePayroll || delegation.Scope == core.DelegationScopeAll) {
+		api.Fail(w, http.StatusForbidden, "forbidden", "payroll approvals can only be delegated by the approver", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	id, err := h.Service.CreateDelegation(r.Context(), user.TenantID, delegation)
+	if err != nil {
+		if errors.Is(err, core.ErrDelegateNotFound) {
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "delegateId", Reason: "must reference a user in this tenant"},
+			})
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "delegation_create_failed", "failed to create delegation", middleware.GetRequestID(r.Context()))
+		return
+	}
+	delegation.ID = id
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "core.delegation.create", "approval_delegation", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, delegation); err != nil {
+		slog.Warn("audit core.delegation.create failed", "err", err)
+	}
+	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
+
+// handleRevokeDelegation ends a delegation early. The delegator, the user who
+// created it and HR may revoke it.
+func (h *Handler) handleRevokeDelegation(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	delegationID := chi.URLParam(r, "delegationID")
+	before, err := h.Service.GetDelegation(r.Context(), user.TenantID, delegationID)
+	if err != nil {
+		if errors.Is(err, core.ErrDelegationNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "delegation not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "delegation_revoke_failed", "failed to revoke delegation", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR && user.UserID != before.DelegatorID && user.UserID != before.CreatedBy {
+		api.Fail(w, http.StatusForbidden, "forbidden", "not allowed", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	if err := h.Service.RevokeDelegation(r.Context(), user.TenantID, delegationID); err != nil {
+		if errors.Is(err, core.ErrDelegationNotFound) {
+			api.Fail(w, http.StatusBadRequest, "invalid_state", "delegation already revoked", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "delegation_revoke_failed", "failed to revoke delegation", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "core.delegation.revoke", "approval_delegation", delegationID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), before, map[string]string{"status": "revoked"}); err != nil {
+		slog.Warn("audit core.delegation.revoke failed", "err", err)
+	}
+	api.Success(w, map[string]string{"status": "revoked"}, middleware.GetRequestID(r.Context()))
+}
@@ -58,6 +58,11 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.With(middleware.RequirePermission(auth.PermOrgWrite, h.Service)).Put("/{departmentID}", h.handleUpdateDepartment)
 		r.With(middleware.RequirePermission(auth.PermOrgWrite, h.Service)).Delete("/{departmentID}", h.handleDeleteDepartment)
 	})
+	r.Route("/delegations", func(r chi.Router) {
+		r.Get("/", h.handleListDelegations)
+		r.Post("/", h.handleCreateDelegation)
+		r.Delete("/{delegationID}", h.handleRevokeDelegation)
+	})
 	r.With(middleware.RequirePermission(auth.PermOrgRead, h.Service)).Get("/permissions", h.handleListPermissions)
 	r.With(middleware.RequirePermission(auth.PermOrgRead, h.Service)).Get("/roles", h.handleListRoles)
 	r.With(middleware.RequirePermission(auth.PermOrgWrite, h.Service)).Put("/roles/{roleID}", h.handleUpdateRolePermissions)
@@ -225,6 +230,7 @@ func (h *Handler) handleUpdateEmployee(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	previousManagerID := existing.ManagerID
+	previousSalary, previousCurrency, previousPayGroupID := existing.Salary, existing.Currency, existing.PayGroupID
 
 	var payload core.Employee
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
@@ -274,6 +280,12 @@ func (h *Handler) handleUpdateEmployee(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if !sameSalary(previousSalary, payload.Salary) || previousCurrency != payload.Currency || previousPayGroupID != payload.PayGroupID {
+		if err := h.Service.RecordCompensationChange(r.Context(), user.TenantID, employeeID, user.UserID); err != nil {
+			slog.Warn("compensation history insert failed", "err", err)
+		}
+	}
+
 	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "core.employee.update", "employee", employeeID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, payload); err != nil {
 		slog.Warn("audit core.employee.update failed", "err", err)
 	}
@@ -640,3 +652,10 @@ func nullIfEmpty(value string) any {
 	}
 	return value
 }
+
+func sameSalary(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -51,6 +51,7 @@ func TestHRLeaveAndPayrollJourney(t *testing.T) {
 		t.Fatalf("failed to start app: %v", err)
 	}
 	defer app.Close()
+	startJobs(t, app)
 
 	ts := httptest.NewServer(app.Router)
 	defer ts.Close()
@@ -83,6 +84,9 @@ func TestHRLeaveAndPayrollJourney(t *testing.T) {
 		t.Fatalf("expected payroll status reviewed, got %s", runStatus)
 	}
 
+	systemAdminToken := login(t, client, ts.URL, cfg.SeedSystemAdminEmail, cfg.SeedSystemAdminPassword)
+	approvePayroll(t, client, ts.URL, token, createPayrollApprover(t, client, ts.URL, systemAdminToken), periodID)
+
 	finalStatus := finalizePayroll(t, client, ts.URL, token, periodID)
 	if finalStatus != "finalized" {
 		t.Fatalf("expected payroll status finalized, got %s", finalStatus)
@@ -337,8 +341,58 @@ func runPayroll(t *testing.T, client *http.Client, baseURL, token, periodID stri
 	if err := json.Unmarshal(resp.Data, &payload); err != nil {
 		t.Fatalf("failed to decode payroll run response: %v", err)
 	}
-	status, _ := payload["status"].(string)
-	return status
+	runID, _ := payload["runId"].(string)
+	if runID == "" {
+		t.Fatalf("expected payroll run id, got %v", payload)
+	}
+	run := waitForJobRun(t, client, baseURL, token, runID)
+	if status, _ := run["status"].(string); status != "completed" {
+		t.Fatalf("expected payroll run to complete, got %v", run)
+	}
+	return payrollPeriodStatus(t, client, baseURL, token, periodID)
+}
+
+func waitForJobRun(t *testing.T, client *http.Client, baseURL, token, runID string) map[string]any {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		resp := getJSON(t, client, baseURL+"/api/v1/reports/jobs/"+runID, token)
+		var run map[string]any
+		if err := json.Unmarshal(resp.Data, &run); err != nil {
+			t.Fatalf("failed to decode job run: %v", err)
+		}
+		if status, _ := run["status"].(string); status != "queued" && status != "running" {
+			return run
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job run %s did not finish in time", runID)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func payrollPeriodStatus(t *testing.T, client *http.Client, baseURL, token, periodID string) string {
+	t.Helper()
+	resp := getJSON(t, client, baseURL+"/api/v1/payroll/periods?limit=500", token)
+	var periods []map[string]any
+	if err := json.Unmarshal(resp.Data, &periods); err != nil {
+		t.Fatalf("failed to decode payroll periods: %v", err)
+	}
+	for _, period := range periods {
+		if period["id"] == periodID {
+			status, _ := period["status"].(string)
+			return status
+		}
+	}
+	t.Fatalf("payroll period %s not found", periodID)
+	return ""
+}
+
+func startJobs(t *testing.T, app *server.App) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	app.Jobs.Start(ctx)
 }
 
 func finalizePayroll(t *testing.T, client *http.Client, baseURL, token, periodID string) string {
@@ -352,6 +406,23 @@ func finalizePayroll(t *testing.T, client *http.Client, baseURL, token, periodID
 	return status
 }
 
+// approvePayroll submits a reviewed period as the maker and approves it as a
+// second HR user, since finalize requires a separate approver.
+func approvePayroll(t *testing.T, client *http.Client, baseURL, token, approverToken, periodID string) {
+	t.Helper()
+	postJSON(t, client, baseURL+"/api/v1/payroll/periods/"+periodID+"/submit", token, map[string]any{})
+	postJSON(t, client, baseURL+"/api/v1/payroll/periods/"+periodID+"/approve", approverToken, map[string]any{"comment": "checked"})
+}
+
+// createPayrollApprover creates an HR user other than the seeded admin and
+// returns their token.
+func createPayrollApprover(t *testing.T, client *http.Client, baseURL, systemAdminToken string) string {
+	t.Helper()
+	email := fmt.Sprintf("payroll-approver-%d@example.com", time.Now().UnixNano())
+	account := createUserAccountWithEmployee(t, client, baseURL, systemAdminToken, auth.RoleHR, email, "")
+	return login(t, client, baseURL, email, account.TempPassword)
+}
+
 func listPayslips(t *testing.T, client *http.Client, baseURL, token, employeeID string) []map[string]any {
 	t.Helper()
 	resp := getJSON(t, client, baseURL+"/api/v1/payroll/payslips?employeeId="+employeeID, token)
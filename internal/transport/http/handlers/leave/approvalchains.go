@@ -0,0 +1,175 @@
+package leavehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/leave"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type approvalChainPayload struct {
+	Name         string               `json:"name"`
+	LeaveTypeID  string               `json:"leaveTypeId"`
+	DepartmentID string               `json:"departmentId"`
+	Steps        []leave.ApprovalStep `json:"steps"`
+}
+
+func (h *Handler) handleListApprovalChains(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	chains, err := h.Service.ListApprovalChains(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "approval_chain_list_failed", "failed to list approval chains", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, chains, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleCreateApprovalChain(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	chain, ok := decodeApprovalChain(w, r)
+	if !ok {
+		return
+	}
+	id, err := h.Service.CreateApprovalChain(r.Context(), user.TenantID, chain)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			api.Fail(w, http.StatusConflict, "approval_chain_exists", "an approval chain with this name already exists", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "approval_chain_create_failed", "failed to create approval chain", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.approval_chain.create", "leave_approval_chain", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, chain); err != nil {
+		slog.Warn("audit leave.approval_chain.create failed", "err", err)
+	}
+	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleUpdateApprovalChain(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	chain, ok := decodeApprovalChain(w, r)
+	if !ok {
+		return
+	}
+	chain.ID = chi.URLParam(r, "chainID")
+	before, err := h.Service.GetApprovalChain(r.Context(), user.TenantID, chain.ID)
+	if err == nil {
+		err = h.Service.UpdateApprovalChain(r.Context(), user.TenantID, chain)
+	}
+	if err != nil {
+		if errors.Is(err, leave.ErrApprovalChainNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "approval chain not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			api.Fail(w, http.StatusConflict, "approval_chain_exists", "an approval chain with this name already exists", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "approval_chain_update_failed", "failed to update approval chain", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.approval_chain.update", "leave_approval_chain", chain.ID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), before, chain); err != nil {
+		slog.Warn("audit leave.approval_chain.update failed", "err", err)
+	}
+	api.Success(w, map[string]string{"id": chain.ID}, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleDeleteApprovalChain(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	chainID := chi.URLParam(r, "chainID")
+	before, err := h.Service.GetApprovalChain(r.Context(), user.TenantID, chainID)
+	if err == nil {
+		err = h.Service.DeleteApprovalChain(r.Context(), user.TenantID, chainID)
+	}
+	if err != nil {
+		if errors.Is(err, leave.ErrApprovalChainNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "approval chain not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "approval_chain_delete_failed", "failed to delete approval chain", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.approval_chain.delete", "leave_approval_chain", chainID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), before, nil); err != nil {
+		slog.Warn("audit leave.approval_chain.delete failed", "err", err)
+	}
+	api.Success(w, map[string]string{"status": "deleted"}, middleware.GetRequestID(r.Context()))
+}
+
+// decodeApprovalChain reads and validates an approval chain payload.
+func decodeApprovalChain(w http.ResponseWriter, r *http.Request) (leave.ApprovalChain, bool) {
+	var payload approvalChainPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return leave.ApprovalChain{}, false
+	}
+
+	chain := leave.ApprovalChain{
+		Name:         strings.TrimSpace(payload.Name),
+		LeaveTypeID:  strings.TrimSpace(payload.LeaveTypeID),
+		DepartmentID: strings.TrimSpace(payload.DepartmentID),
+		Steps:        payload.Steps,
+	}
+	for i := range chain.Steps {
+		chain.Steps[i].Approver = strings.TrimSpace(chain.Steps[i].Approver)
+		chain.Steps[i].UserID = strings.TrimSpace(chain.Steps[i].UserID)
+	}
+	validator := shared.NewValidator()
+	validator.Required("name", chain.Name, "is required")
+	if !chain.Valid() {
+		validator.Add("steps", "must list 1 to 10 steps; approver must be manager, department_head, hr or user, user steps need userId and minDays cannot be negative")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return leave.ApprovalChain{}, false
+	}
+	return chain, true
+}
@@ -0,0 +1,162 @@
+package leavehandler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/leave"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+var supportedFeedKinds = []string{leave.FeedMine, leave.FeedTeam, leave.FeedHolidays}
+
+type calendarFeedPayload struct {
+	Kind   string `json:"kind"`
+	Region string `json:"region"`
+}
+
+func (h *Handler) handleListCalendarFeeds(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	feeds, err := h.Service.ListCalendarFeeds(r.Context(), user.TenantID, user.UserID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "calendar_feed_list_failed", "failed to list calendar feeds", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, feeds, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleCreateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload calendarFeedPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+	feed := leave.CalendarFeed{Kind: strings.TrimSpace(payload.Kind), Region: strings.TrimSpace(payload.Region)}
+	validator := shared.NewValidator()
+	validator.Enum("kind", feed.Kind, supportedFeedKinds, "must be mine, team or holidays")
+	if feed.Region != "" && feed.Kind != leave.FeedHolidays {
+		validator.Add("region", "is only allowed for holiday feeds")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	feed, token, err := h.Service.CreateCalendarFeed(r.Context(), user.TenantID, user.UserID, user.RoleName, feed)
+	if err != nil {
+		if errors.Is(err, leave.ErrForbidden) {
+			api.Fail(w, http.StatusForbidden, "forbidden", "manager or hr required", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "calendar_feed_create_failed", "failed to create calendar feed", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.calendar_feed.create", "leave_calendar_feed", feed.ID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, feed); err != nil {
+		slog.Warn("audit leave.calendar_feed.create failed", "err", err)
+	}
+	api.Created(w, map[string]any{
+		"feed":  feed,
+		"token": token,
+		"url":   calendarFeedURL(r, token),
+	}, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleRevokeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	feedID := chi.URLParam(r, "feedID")
+	if err := h.Service.RevokeCalendarFeed(r.Context(), user.TenantID, user.UserID, feedID); err != nil {
+		if errors.Is(err, leave.ErrCalendarFeedNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "calendar feed not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "calendar_feed_revoke_failed", "failed to revoke calendar feed", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.calendar_feed.revoke", "leave_calendar_feed", feedID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, nil); err != nil {
+		slog.Warn("audit leave.calendar_feed.revoke failed", "err", err)
+	}
+	api.Success(w, map[string]string{"status": "revoked"}, middleware.GetRequestID(r.Context()))
+}
+
+// handleServeCalendarFeed serves a calendar feed to calendar apps, which
+// cannot send a JWT; the token in the path authenticates the request.
+func (h *Handler) handleServeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	feed, body, err := h.Service.RenderCalendarFeed(r.Context(), chi.URLParam(r, "token"), time.Now())
+	if err != nil {
+		if errors.Is(err, leave.ErrCalendarFeedNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "calendar feed not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "calendar_feed_failed", "failed to load calendar feed", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Service.TouchCalendarFeed(r.Context(), feed.ID); err != nil {
+		slog.Warn("calendar feed last used update failed", "feedId", feed.ID, "err", err)
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "inline; filename=leave-"+feed.Kind+".ics")
+	if _, err := w.Write(body); err != nil {
+		slog.Warn("calendar feed write failed", "feedId", feed.ID, "err", err)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// calendarFeedURL returns the subscription URL of a feed token, built from
+// the URL the feed was created at.
+func calendarFeedURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	return scheme + "://" + r.Host + strings.TrimSuffix(r.URL.Path, "/") + "/" + token + "/calendar.ics"
+}
@@ -0,0 +1,203 @@
+package leavehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/leave"
+	"hrm/internal/domain/notifications"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type encashmentPayload struct {
+	EmployeeID  string  `json:"employeeId"`
+	LeaveTypeID string  `json:"leaveTypeId"`
+	Amount      float64 `json:"amount"`
+	Reason      string  `json:"reason"`
+}
+
+// handleListEncashments lists leave encashments. HR sees every encashment or
+// one employee's; everyone else sees their own.
+func (h *Handler) handleListEncashments(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	employeeID := strings.TrimSpace(r.URL.Query().Get("employeeId"))
+	if user.RoleName != auth.RoleHR {
+		selfEmployeeID, err := h.Service.EmployeeIDByUserID(r.Context(), user.TenantID, user.UserID)
+		if err != nil {
+			slog.Warn("leave encashment self employee lookup failed", "err", err)
+		}
+		if selfEmployeeID == "" {
+			api.Success(w, []leave.Encashment{}, middleware.GetRequestID(r.Context()))
+			return
+		}
+		employeeID = selfEmployeeID
+	}
+
+	encashments, err := h.Service.ListEncashments(r.Context(), user.TenantID, employeeID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "encashment_list_failed", "failed to list leave encashments", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, encashments, middleware.GetRequestID(r.Context()))
+}
+
+// handleCreateEncashment requests a cash-out of unused leave. Employees
+// request for themselves; HR requests for any employee.
+func (h *Handler) handleCreateEncashment(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload encashmentPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+	encashment := leave.Encashment{
+		EmployeeID:  strings.TrimSpace(payload.EmployeeID),
+		LeaveTypeID: strings.TrimSpace(payload.LeaveTypeID),
+		Amount:      payload.Amount,
+		Reason:      strings.TrimSpace(payload.Reason),
+		Source:      leave.EncashmentSourceRequest,
+		RequestedBy: user.UserID,
+	}
+	if user.RoleName != auth.RoleHR {
+		id, err := h.Service.EmployeeIDByUserID(r.Context(), user.TenantID, user.UserID)
+		if err != nil || id == "" {
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				slog.Warn("leave encashment self employee lookup failed", "err", err)
+			}
+			api.Fail(w, http.StatusForbidden, "forbidden", "employee profile required", middleware.GetRequestID(r.Context()))
+			return
+		}
+		encashment.EmployeeID = id
+	}
+
+	validator := shared.NewValidator()
+	validator.Required("employeeId", encashment.EmployeeID, "is required")
+	validator.Required("leaveTypeId", encashment.LeaveTypeID, "is required")
+	if encashment.Amount <= 0 {
+		validator.Add("amount", "must be greater than 0")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	result, err := h.Service.RequestEncashment(r.Context(), user.TenantID, encashment)
+	if err != nil {
+		switch {
+		case errors.Is(err, leave.ErrLeaveTypeNotFound):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "leaveTypeId", Reason: "must reference an existing leave type"},
+			})
+		case errors.Is(err, leave.ErrNotEncashable):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "leaveTypeId", Reason: "must reference an encashable leave type"},
+			})
+		case errors.Is(err, leave.ErrInsufficientBalance):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "amount", Reason: "must not exceed the available balance"},
+			})
+		default:
+			api.Fail(w, http.StatusInternalServerError, "encashment_create_failed", "failed to request leave encashment", middleware.GetRequestID(r.Context()))
+		}
+		return
+	}
+
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.encashment.create", "leave_encashment", result.ID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, encashment); err != nil {
+		slog.Warn("audit leave.encashment.create failed", "err", err)
+	}
+	if h.Notify != nil {
+		for _, hrUserID := range result.HRUserIDs {
+			if err := h.Notify.Create(r.Context(), user.TenantID, hrUserID, notifications.TypeLeaveSubmitted, "Leave encashment requested", "A leave encashment is awaiting HR approval."); err != nil {
+				slog.Warn("leave encashment hr notification failed", "err", err)
+			}
+		}
+	}
+	api.Created(w, map[string]string{"id": result.ID, "status": leave.EncashmentPending}, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleApproveEncashment(w http.ResponseWriter, r *http.Request) {
+	h.decideEncashment(w, r, true)
+}
+
+func (h *Handler) handleRejectEncashment(w http.ResponseWriter, r *http.Request) {
+	h.decideEncashment(w, r, false)
+}
+
+// decideEncashment approves or rejects a pending encashment and tells the
+// employee. Approval pays the encashment in the next open payroll period.
+func (h *Handler) decideEncashment(w http.ResponseWriter, r *http.Request, approve bool) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	encashmentID := chi.URLParam(r, "encashmentID")
+	action, title, notifyType := "approve", "Leave encashment approved", notifications.TypeLeaveApproved
+	decide := func() (leave.EncashmentDecision, error) {
+		return h.Service.ApproveEncashment(r.Context(), user.TenantID, encashmentID, user.UserID, time.Now().UTC())
+	}
+	if !approve {
+		action, title, notifyType = "reject", "Leave encashment rejected", notifications.TypeLeaveRejected
+		decide = func() (leave.EncashmentDecision, error) {
+			return h.Service.RejectEncashment(r.Context(), user.TenantID, encashmentID, user.UserID)
+		}
+	}
+	decision, err := decide()
+	if err != nil {
+		switch {
+		case errors.Is(err, leave.ErrEncashmentNotFound):
+			api.Fail(w, http.StatusNotFound, "not_found", "leave encashment not found", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, leave.ErrInvalidState):
+			api.Fail(w, http.StatusBadRequest, "invalid_state", "leave encashment is not awaiting approval", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, leave.ErrInsufficientBalance):
+			api.Fail(w, http.StatusBadRequest, "insufficient_balance", "leave balance no longer covers the encashment", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, leave.ErrNoPayrollPeriod):
+			api.Fail(w, http.StatusConflict, "no_payroll_period", "no open payroll period to pay the encashment", middleware.GetRequestID(r.Context()))
+		default:
+			api.Fail(w, http.StatusInternalServerError, "encashment_decision_failed", "failed to decide leave encashment", middleware.GetRequestID(r.Context()))
+		}
+		return
+	}
+
+	auditAction := "leave.encashment." + action
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, auditAction, "leave_encashment", encashmentID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, decision.Encashment); err != nil {
+		slog.Warn("audit "+auditAction+" failed", "err", err)
+	}
+	if decision.EmployeeUser != "" && h.Notify != nil {
+		body := fmt.Sprintf("Your %s encashment of %.2f was %s.", decision.LeaveTypeName, decision.Encashment.Amount, decision.Encashment.Status)
+		if decision.Encashment.Status == leave.EncashmentApproved {
+			body = fmt.Sprintf("Your %s encashment of %.2f was approved and %.2f %s will be paid in your next payroll.", decision.LeaveTypeName, decision.Encashment.Amount, decision.Encashment.Value, decision.Encashment.Currency)
+		}
+		if err := h.Notify.Create(r.Context(), user.TenantID, decision.EmployeeUser, notifyType, title, body); err != nil {
+			slog.Warn("leave encashment decision notification failed", "err", err)
+		}
+	}
+	api.Success(w, decision.Encashment, middleware.GetRequestID(r.Context()))
+}
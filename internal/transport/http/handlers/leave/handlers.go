@@ -17,6 +17,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"hrm/internal/domain/audit"
 	"hrm/internal/domain/auth"
@@ -44,6 +45,8 @@ const (
 
 var supportedAccrualPeriods = []string{"weekly", "monthly", "yearly"}
 
+var supportedLeaveUnits = []string{leave.UnitDays, leave.UnitHours}
+
 func NewHandler(service *leave.Service, perms middleware.PermissionStore, notify *notifications.Service, auditSvc *audit.Service, jobsSvc *jobs.Service) *Handler {
 	return &Handler{Service: service, Perms: perms, Notify: notify, Audit: auditSvc, Jobs: jobsSvc}
 }
@@ -57,19 +60,46 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/holidays", h.handleListHolidays)
 		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/holidays", h.handleCreateHoliday)
 		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Delete("/holidays/{holidayID}", h.handleDeleteHoliday)
+		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/work-patterns", h.handleListWorkPatterns)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/work-patterns", h.handleCreateWorkPattern)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Put("/work-patterns/{patternID}", h.handleUpdateWorkPattern)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Put("/employees/{employeeID}/work-schedule", h.handleAssignEmployeeSchedule)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Put("/departments/{departmentID}/work-schedule", h.handleAssignDepartmentSchedule)
+		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/approval-chains", h.handleListApprovalChains)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/approval-chains", h.handleCreateApprovalChain)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Put("/approval-chains/{chainID}", h.handleUpdateApprovalChain)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Delete("/approval-chains/{chainID}", h.handleDeleteApprovalChain)
+		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/staffing-rules", h.handleListStaffingRules)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/staffing-rules", h.handleCreateStaffingRule)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Put("/staffing-rules/{ruleID}", h.handleUpdateStaffingRule)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Delete("/staffing-rules/{ruleID}", h.handleDeleteStaffingRule)
 		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/balances", h.handleListBalances)
 		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/balances/adjust", h.handleAdjustBalance)
 		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/accrual/run", h.handleRunAccruals)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/carry-over/run", h.handleRunCarryOver)
+		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/toil", h.handleListTOIL)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/toil", h.handleCreateTOIL)
+		r.With(middleware.RequirePermission(auth.PermLeaveApprove, h.Perms)).Post("/toil/{entryID}/approve", h.handleApproveTOIL)
+		r.With(middleware.RequirePermission(auth.PermLeaveApprove, h.Perms)).Post("/toil/{entryID}/reject", h.handleRejectTOIL)
+		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/encashments", h.handleListEncashments)
+		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/encashments", h.handleCreateEncashment)
+		r.With(middleware.RequirePermission(auth.PermLeaveApprove, h.Perms)).Post("/encashments/{encashmentID}/approve", h.handleApproveEncashment)
+		r.With(middleware.RequirePermission(auth.PermLeaveApprove, h.Perms)).Post("/encashments/{encashmentID}/reject", h.handleRejectEncashment)
 		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/requests", h.handleListRequests)
 		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/requests/{requestID}", h.handleGetRequest)
 		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/requests", h.handleCreateRequest)
 		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/requests/{requestID}/documents", h.handleUploadRequestDocument)
 		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/requests/{requestID}/documents/{documentID}/download", h.handleDownloadRequestDocument)
+		r.With(middleware.RequirePermission(auth.PermLeaveApprove, h.Perms)).Get("/requests/{requestID}/overlaps", h.handleRequestOverlap)
 		r.With(middleware.RequirePermission(auth.PermLeaveApprove, h.Perms)).Post("/requests/{requestID}/approve", h.handleApproveRequest)
 		r.With(middleware.RequirePermission(auth.PermLeaveApprove, h.Perms)).Post("/requests/{requestID}/reject", h.handleRejectRequest)
 		r.With(middleware.RequirePermission(auth.PermLeaveWrite, h.Perms)).Post("/requests/{requestID}/cancel", h.handleCancelRequest)
 		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/calendar", h.handleCalendar)
 		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/calendar/export", h.handleCalendarExport)
+		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/calendar-feeds", h.handleListCalendarFeeds)
+		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Post("/calendar-feeds", h.handleCreateCalendarFeed)
+		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Delete("/calendar-feeds/{feedID}", h.handleRevokeCalendarFeed)
+		r.Get("/calendar-feeds/{token}/calendar.ics", h.handleServeCalendarFeed)
 		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/reports/balances", h.handleReportBalances)
 		r.With(middleware.RequirePermission(auth.PermLeaveRead, h.Perms)).Get("/reports/usage", h.handleReportUsage)
 	})
@@ -105,6 +135,21 @@ func (h *Handler) handleCreateType(w http.ResponseWriter, r *http.Request) {
 		api.Fail(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", middleware.GetRequestID(r.Context()))
 		return
 	}
+	payload.Unit = strings.TrimSpace(payload.Unit)
+	if payload.Unit == "" {
+		payload.Unit = leave.UnitDays
+	}
+	validator := shared.NewValidator()
+	validator.Enum("unit", payload.Unit, supportedLeaveUnits, "must be days or hours")
+	if payload.TOIL && payload.Unit != leave.UnitHours {
+		validator.Add("toil", "requires an hourly leave type")
+	}
+	if payload.TOILExpiryDays < 0 || (!payload.TOIL && payload.TOILExpiryDays != 0) {
+		validator.Add("toilExpiryDays", "must be zero or more and only set for toil leave types")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
 
 	id, err := h.Service.CreateType(r.Context(), user.TenantID, payload)
 	if err != nil {
@@ -165,12 +210,30 @@ func (h *Handler) handleCreatePolicy(w http.ResponseWriter, r *http.Request) {
 	if payload.CarryOver < 0 {
 		validator.Add("carryOverLimit", "must be greater than or equal to 0")
 	}
+	if payload.CarryOverExpiryMonths < 0 || payload.CarryOverExpiryMonths > 12 {
+		validator.Add("carryOverExpiryMonths", "must be between 0 and 12")
+	}
+	if (payload.YearStartMonth != 0 || payload.YearStartDay != 0) && !leave.ValidYearStart(payload.YearStartMonth, payload.YearStartDay) {
+		validator.Add("yearStartDay", "yearStartMonth and yearStartDay must name a day every year has")
+	}
+	for i := range payload.AccrualBands {
+		payload.AccrualBands[i].EmploymentType = strings.TrimSpace(payload.AccrualBands[i].EmploymentType)
+		payload.AccrualBands[i].DepartmentID = strings.TrimSpace(payload.AccrualBands[i].DepartmentID)
+	}
+	if !leave.ValidAccrualBands(payload.AccrualBands) {
+		validator.Add("accrualBands", "must list at most 20 bands with minYears and rate of at least 0, maxYears of 0 or above minYears and a non-negative entitlement")
+	}
 	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
 		return
 	}
 
 	id, err := h.Service.CreatePolicy(r.Context(), user.TenantID, payload)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			api.Fail(w, http.StatusConflict, "leave_policy_exists", "the leave type already has a policy", middleware.GetRequestID(r.Context()))
+			return
+		}
 		api.Fail(w, http.StatusInternalServerError, "leave_policy_create_failed", "failed to create leave policy", middleware.GetRequestID(r.Context()))
 		return
 	}
@@ -422,6 +485,52 @@ func (h *Handler) handleRunAccruals(w http.ResponseWriter, r *http.Request) {
 	api.Success(w, summary, middleware.GetRequestID(r.Context()))
 }
 
+// handleRunCarryOver runs year-end carry-over processing immediately instead
+// of waiting for the scheduled job. Policy years already processed are left
+// alone, so the run is safe to repeat.
+func (h *Handler) handleRunCarryOver(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	noticeDays := 0
+	var notify leave.BalanceNotifier
+	if h.Jobs != nil {
+		noticeDays = h.Jobs.Cfg.LeaveCarryOverNotice
+	}
+	if h.Notify != nil {
+		notify = func(ctx context.Context, userID, title, body string) error {
+			return h.Notify.Create(ctx, user.TenantID, userID, notifications.TypeLeaveCarryOver, title, body)
+		}
+	}
+	run := func(runCtx context.Context) (any, error) {
+		return h.Service.ProcessYearEnd(runCtx, user.TenantID, time.Now(), noticeDays, notify)
+	}
+
+	var result any
+	var err error
+	if h.Jobs != nil {
+		result, err = h.Jobs.RunNow(r.Context(), jobs.JobLeaveCarryOver, user.TenantID, run)
+	} else {
+		result, err = run(r.Context())
+	}
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "carry_over_failed", "failed to run carry-over", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.carry_over.run", "leave_policy", "", middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, result); err != nil {
+		slog.Warn("audit leave.carry_over.run failed", "err", err)
+	}
+	api.Success(w, result, middleware.GetRequestID(r.Context()))
+}
+
 func (h *Handler) handleListRequests(w http.ResponseWriter, r *http.Request) {
 	user, ok := middleware.GetUser(r.Context())
 	if !ok {
@@ -430,7 +539,6 @@ func (h *Handler) handleListRequests(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var employeeID string
-	var managerEmployeeID string
 	if user.RoleName == auth.RoleEmployee {
 		if id, err := h.Service.EmployeeIDByUserID(r.Context(), user.TenantID, user.UserID); err == nil {
 			employeeID = id
@@ -443,21 +551,18 @@ func (h *Handler) handleListRequests(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+
+	var approverUserIDs []string
 	if user.RoleName == auth.RoleManager {
-		if id, err := h.Service.EmployeeIDByUserID(r.Context(), user.TenantID, user.UserID); err == nil {
-			managerEmployeeID = id
-		} else {
-			slog.Warn("leave requests manager lookup failed", "err", err)
-		}
-		if managerEmployeeID == "" {
-			w.Header().Set("X-Total-Count", "0")
-			api.Success(w, []leave.LeaveRequest{}, middleware.GetRequestID(r.Context()))
-			return
+		ids, err := h.Service.ApproverUserIDs(r.Context(), user.TenantID, user.UserID)
+		if err != nil {
+			slog.Warn("leave requests delegation lookup failed", "err", err)
 		}
+		approverUserIDs = ids
 	}
 
 	page := shared.ParsePagination(r, 100, 500)
-	result, err := h.Service.ListRequests(r.Context(), user.TenantID, user.RoleName, employeeID, managerEmployeeID, page.Limit, page.Offset)
+	result, err := h.Service.ListRequests(r.Context(), user.TenantID, user.RoleName, employeeID, approverUserIDs, page.Limit, page.Offset)
 	if err != nil {
 		api.Fail(w, http.StatusInternalServerError, "leave_requests_failed", "failed to list requests", middleware.GetRequestID(r.Context()))
 		return
@@ -493,13 +598,14 @@ func (h *Handler) handleGetRequest(w http.ResponseWriter, r *http.Request) {
 }
 
 type leaveRequestPayload struct {
-	EmployeeID  string `json:"employeeId"`
-	LeaveTypeID string `json:"leaveTypeId"`
-	StartDate   string `json:"startDate"`
-	EndDate     string `json:"endDate"`
-	StartHalf   bool   `json:"startHalf"`
-	EndHalf     bool   `json:"endHalf"`
-	Reason      string `json:"reason"`
+	EmployeeID  string  `json:"employeeId"`
+	LeaveTypeID string  `json:"leaveTypeId"`
+	StartDate   string  `json:"startDate"`
+	EndDate     string  `json:"endDate"`
+	StartHalf   bool    `json:"startHalf"`
+	EndHalf     bool    `json:"endHalf"`
+	Hours       float64 `json:"hours"`
+	Reason      string  `json:"reason"`
 }
 
 func decodeLeaveRequestPayload(r *http.Request) (leaveRequestPayload, []leave.LeaveRequestDocumentUpload, error) {
@@ -517,6 +623,12 @@ func decodeLeaveRequestPayload(r *http.Request) (leaveRequestPayload, []leave.Le
 		if err != nil {
 			return leaveRequestPayload{}, nil, fmt.Errorf("invalid endHalf value")
 		}
+		var hours float64
+		if raw := strings.TrimSpace(r.FormValue("hours")); raw != "" {
+			if hours, err = strconv.ParseFloat(raw, 64); err != nil {
+				return leaveRequestPayload{}, nil, fmt.Errorf("invalid hours value")
+			}
+		}
 
 		documents, err := parseMultipartDocuments(r.MultipartForm.File["documents"])
 		if err != nil {
@@ -530,6 +642,7 @@ func decodeLeaveRequestPayload(r *http.Request) (leaveRequestPayload, []leave.Le
 			EndDate:     strings.TrimSpace(r.FormValue("endDate")),
 			StartHalf:   startHalf,
 			EndHalf:     endHalf,
+			Hours:       hours,
 			Reason:      strings.TrimSpace(r.FormValue("reason")),
 		}, documents, nil
 	}
@@ -650,29 +763,77 @@ func (h *Handler) handleCreateRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	days, err := leave.CalculateRequestDays(startDate, endDate, payload.StartHalf, payload.EndHalf)
+	leaveType, err := h.Service.GetType(r.Context(), user.TenantID, payload.LeaveTypeID)
 	if err != nil {
+		if errors.Is(err, leave.ErrLeaveTypeNotFound) {
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "leaveTypeId", Reason: "must reference an existing leave type"},
+			})
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "leave_request_failed", "failed to load leave type", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if payload.Hours != 0 && !leaveType.Hourly() {
 		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
-			{Field: "startHalf", Reason: "invalid half-day combination for selected date range"},
-			{Field: "endHalf", Reason: "invalid half-day combination for selected date range"},
+			{Field: "hours", Reason: "is only allowed for hourly leave types"},
 		})
 		return
 	}
 
-	requiresDoc, err := h.Service.LeaveTypeRequiresDoc(r.Context(), user.TenantID, payload.LeaveTypeID)
+	var days, hours float64
+	if leaveType.Hourly() {
+		days, hours, err = h.Service.RequestHours(r.Context(), user.TenantID, payload.EmployeeID, startDate, endDate, payload.StartHalf, payload.EndHalf, payload.Hours)
+	} else {
+		days, err = h.Service.RequestDays(r.Context(), user.TenantID, payload.EmployeeID, startDate, endDate, payload.StartHalf, payload.EndHalf)
+	}
 	if err != nil {
-		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
-			{Field: "leaveTypeId", Reason: "must reference an existing leave type"},
-		})
+		switch {
+		case errors.Is(err, leave.ErrInvalidHalfDay):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "startHalf", Reason: "invalid half-day combination for selected date range"},
+				{Field: "endHalf", Reason: "invalid half-day combination for selected date range"},
+			})
+		case errors.Is(err, leave.ErrNoWorkingDays):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "endDate", Reason: "selected date range has no scheduled working days"},
+			})
+		case errors.Is(err, leave.ErrInvalidHours):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "hours", Reason: "must be positive, for a single day without half-day flags and within the hours scheduled that day"},
+			})
+		case errors.Is(err, leave.ErrEmployeeNotFound):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "employeeId", Reason: "must reference an existing employee"},
+			})
+		default:
+			api.Fail(w, http.StatusInternalServerError, "leave_request_failed", "failed to calculate leave days", middleware.GetRequestID(r.Context()))
+		}
 		return
 	}
+
+	requiresDoc := leaveType.RequiresDoc
 	if requiresDoc && len(documents) == 0 {
 		api.Fail(w, http.StatusBadRequest, "document_required", "supporting document is required for this leave type", middleware.GetRequestID(r.Context()))
 		return
 	}
 
-	result, err := h.Service.CreateRequest(r.Context(), user.TenantID, payload.EmployeeID, payload.LeaveTypeID, payload.Reason, startDate, endDate, payload.StartHalf, payload.EndHalf, days)
+	conflicts, err := h.Service.CheckStaffing(r.Context(), user.TenantID, payload.EmployeeID, startDate, endDate, []string{leave.StatusPending, leave.StatusPendingHR, leave.StatusApproved})
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "leave_request_failed", "failed to check staffing rules", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if leave.StaffingBlocked(conflicts) {
+		failStaffing(w, r, conflicts)
+		return
+	}
+
+	result, err := h.Service.CreateRequest(r.Context(), user.TenantID, payload.EmployeeID, payload.LeaveTypeID, payload.Reason, startDate, endDate, payload.StartHalf, payload.EndHalf, days, hours)
 	if err != nil {
+		if errors.Is(err, leave.ErrStaffingBlocked) {
+			failStaffing(w, r, result.StaffingConflicts)
+			return
+		}
 		api.Fail(w, http.StatusInternalServerError, "leave_request_failed", "failed to create request", middleware.GetRequestID(r.Context()))
 		return
 	}
@@ -696,16 +857,13 @@ func (h *Handler) handleCreateRequest(w http.ResponseWriter, r *http.Request) {
 		"endHalf":       payload.EndHalf,
 		"reason":        payload.Reason,
 		"days":          days,
+		"hours":         hours,
 		"documentCount": len(createdDocs),
 	}); err != nil {
 		slog.Warn("audit leave.request.create failed", "err", err)
 	}
 	if result.ManagerUserID != "" {
-		if h.Notify != nil {
-			if err := h.Notify.Create(r.Context(), user.TenantID, result.ManagerUserID, notifications.TypeLeaveSubmitted, "Leave request submitted", "A leave request is awaiting approval."); err != nil {
-				slog.Warn("leave submitted notification failed", "err", err)
-			}
-		}
+		h.notifyApprover(r.Context(), user.TenantID, result.ManagerUserID, "Leave request submitted", "A leave request is awaiting approval.")
 	}
 	if len(result.HRUserIDs) > 0 && h.Notify != nil {
 		for _, hrUserID := range result.HRUserIDs {
@@ -716,13 +874,16 @@ func (h *Handler) handleCreateRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	api.Created(w, map[string]any{
-		"id":          result.ID,
-		"status":      result.Status,
-		"days":        days,
-		"startHalf":   payload.StartHalf,
-		"endHalf":     payload.EndHalf,
-		"documents":   createdDocs,
-		"requiresDoc": requiresDoc,
+		"id":               result.ID,
+		"status":           result.Status,
+		"days":             days,
+		"hours":            hours,
+		"unit":             leaveType.Unit,
+		"startHalf":        payload.StartHalf,
+		"endHalf":          payload.EndHalf,
+		"documents":        createdDocs,
+		"requiresDoc":      requiresDoc,
+		"staffingWarnings": result.StaffingConflicts,
 	}, middleware.GetRequestID(r.Context()))
 }
 
@@ -846,15 +1007,42 @@ func (h *Handler) canAccessRequest(ctx context.Context, user auth.UserContext, r
 			return true, nil
 		}
 		allowed, err := h.Service.IsManagerOf(ctx, user.TenantID, selfEmployeeID, requestEmployeeID)
+		if err != nil || allowed {
+			return allowed, err
+		}
+		delegated, err := h.Service.DelegatedManagerIDs(ctx, user.TenantID, user.UserID)
 		if err != nil {
 			return false, err
 		}
-		return allowed, nil
+		for _, managerEmployeeID := range delegated {
+			if allowed, err := h.Service.IsManagerOf(ctx, user.TenantID, managerEmployeeID, requestEmployeeID); err == nil && allowed {
+				return true, nil
+			}
+		}
 	}
 
 	return false, nil
 }
 
+// notifyApprover notifies an approver, and anyone deciding their leave
+// approvals through a delegation, that a request awaits them.
+func (h *Handler) notifyApprover(ctx context.Context, tenantID, approverUserID, title, body string) {
+	if h.Notify == nil || approverUserID == "" {
+		return
+	}
+	recipients := []string{approverUserID}
+	if delegateID, err := h.Service.ActingDelegate(ctx, tenantID, approverUserID); err != nil {
+		slog.Warn("leave approver delegate lookup failed", "err", err)
+	} else if delegateID != "" && delegateID != approverUserID {
+		recipients = append(recipients, delegateID)
+	}
+	for _, recipient := range recipients {
+		if err := h.Notify.Create(ctx, tenantID, recipient, notifications.TypeLeaveSubmitted, title, body); err != nil {
+			slog.Warn("leave approver notification failed", "err", err)
+		}
+	}
+}
+
 func (h *Handler) handleApproveRequest(w http.ResponseWriter, r *http.Request) {
 	user, ok := middleware.GetUser(r.Context())
 	if !ok {
@@ -867,6 +1055,25 @@ func (h *Handler) handleApproveRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	requestID := chi.URLParam(r, "requestID")
+	req, err := h.Service.GetRequest(r.Context(), user.TenantID, requestID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			api.Fail(w, http.StatusNotFound, "not_found", "leave request not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "leave_approve_failed", "failed to load leave request", middleware.GetRequestID(r.Context()))
+		return
+	}
+	conflicts, err := h.Service.CheckStaffing(r.Context(), user.TenantID, req.EmployeeID, req.StartDate, req.EndDate, []string{leave.StatusApproved})
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "leave_approve_failed", "failed to check staffing rules", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if leave.StaffingBlocked(conflicts) {
+		failStaffing(w, r, conflicts)
+		return
+	}
+
 	result, err := h.Service.ApproveRequest(r.Context(), user.TenantID, requestID, user.UserID, user.RoleName)
 	if err != nil {
 		if errors.Is(err, leave.ErrHRApprovalRequired) {
@@ -877,15 +1084,24 @@ func (h *Handler) handleApproveRequest(w http.ResponseWriter, r *http.Request) {
 			api.Fail(w, http.StatusForbidden, "forbidden", "not allowed", middleware.GetRequestID(r.Context()))
 			return
 		}
+		if errors.Is(err, leave.ErrInvalidState) {
+			api.Fail(w, http.StatusBadRequest, "invalid_state", "leave request is not awaiting approval", middleware.GetRequestID(r.Context()))
+			return
+		}
+		if errors.Is(err, leave.ErrStaffingBlocked) {
+			failStaffing(w, r, result.StaffingConflicts)
+			return
+		}
 		api.Fail(w, http.StatusNotFound, "not_found", "leave request not found", middleware.GetRequestID(r.Context()))
 		return
 	}
 
-	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.request.approve", "leave_request", requestID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, map[string]any{"employeeId": result.EmployeeID}); err != nil {
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.request.approve", "leave_request", requestID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, decisionAudit(result.EmployeeID, result.OnBehalfOf)); err != nil {
 		slog.Warn("audit leave.request.approve failed", "err", err)
 	}
 
 	if !result.FinalApproval && h.Notify != nil {
+		h.notifyApprover(r.Context(), user.TenantID, result.NextApproverID, "Leave request awaiting approval", "A leave request is awaiting your approval.")
 		for _, hrUserID := range result.HRUserIDs {
 			if err := h.Notify.Create(r.Context(), user.TenantID, hrUserID, notifications.TypeLeaveSubmitted, "Leave request awaiting HR", "A leave request is awaiting HR approval."); err != nil {
 				slog.Warn("leave hr notification failed", "err", err)
@@ -903,7 +1119,7 @@ func (h *Handler) handleApproveRequest(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	api.Success(w, map[string]string{"status": result.Status}, middleware.GetRequestID(r.Context()))
+	api.Success(w, map[string]any{"status": result.Status, "staffingWarnings": conflicts}, middleware.GetRequestID(r.Context()))
 }
 
 func (h *Handler) handleRejectRequest(w http.ResponseWriter, r *http.Request) {
@@ -920,15 +1136,23 @@ func (h *Handler) handleRejectRequest(w http.ResponseWriter, r *http.Request) {
 	requestID := chi.URLParam(r, "requestID")
 	result, err := h.Service.RejectRequest(r.Context(), user.TenantID, requestID, user.UserID, user.RoleName)
 	if err != nil {
+		if errors.Is(err, leave.ErrHRApprovalRequired) {
+			api.Fail(w, http.StatusForbidden, "forbidden", "hr approval required", middleware.GetRequestID(r.Context()))
+			return
+		}
 		if errors.Is(err, leave.ErrForbidden) {
 			api.Fail(w, http.StatusForbidden, "forbidden", "not allowed", middleware.GetRequestID(r.Context()))
 			return
 		}
+		if errors.Is(err, leave.ErrInvalidState) {
+			api.Fail(w, http.StatusBadRequest, "invalid_state", "leave request is not awaiting approval", middleware.GetRequestID(r.Context()))
+			return
+		}
 		api.Fail(w, http.StatusNotFound, "not_found", "leave request not found", middleware.GetRequestID(r.Context()))
 		return
 	}
 
-	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.request.reject", "leave_request", requestID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, map[string]any{"employeeId": result.EmployeeID}); err != nil {
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.request.reject", "leave_request", requestID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, decisionAudit(result.EmployeeID, result.OnBehalfOf)); err != nil {
 		slog.Warn("audit leave.request.reject failed", "err", err)
 	}
 	if result.EmployeeUser != "" {
@@ -944,6 +1168,16 @@ func (h *Handler) handleRejectRequest(w http.ResponseWriter, r *http.Request) {
 	api.Success(w, map[string]string{"status": leave.StatusRejected}, middleware.GetRequestID(r.Context()))
 }
 
+// decisionAudit is the audit payload of an approval decision, naming the
+// approver a delegate decided for.
+func decisionAudit(employeeID, onBehalfOf string) map[string]any {
+	after := map[string]any{"employeeId": employeeID}
+	if onBehalfOf != "" {
+		after["onBehalfOf"] = onBehalfOf
+	}
+	return after
+}
+
 func (h *Handler) handleCancelRequest(w http.ResponseWriter, r *http.Request) {
 	user, ok := middleware.GetUser(r.Context())
 	if !ok {
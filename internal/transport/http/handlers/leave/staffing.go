@@ -0,0 +1,281 @@
+package leavehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/leave"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+var (
+	supportedStaffingKinds        = []string{leave.StaffingMaxAbsent, leave.StaffingBlackout}
+	supportedStaffingEnforcements = []string{leave.StaffingWarn, leave.StaffingBlock}
+)
+
+type staffingRulePayload struct {
+	Name         string `json:"name"`
+	Kind         string `json:"kind"`
+	DepartmentID string `json:"departmentId"`
+	ManagerID    string `json:"managerId"`
+	MaxAbsent    int    `json:"maxAbsent"`
+	StartDate    string `json:"startDate"`
+	EndDate      string `json:"endDate"`
+	Enforcement  string `json:"enforcement"`
+}
+
+func (h *Handler) handleListStaffingRules(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	rules, err := h.Service.ListStaffingRules(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "staffing_rule_list_failed", "failed to list staffing rules", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, rules, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleCreateStaffingRule(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	rule, ok := decodeStaffingRule(w, r)
+	if !ok {
+		return
+	}
+	id, err := h.Service.CreateStaffingRule(r.Context(), user.TenantID, rule)
+	if err != nil {
+		if failStaffingRuleWrite(w, r, err) {
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "staffing_rule_create_failed", "failed to create staffing rule", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.staffing_rule.create", "leave_staffing_rule", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, rule); err != nil {
+		slog.Warn("audit leave.staffing_rule.create failed", "err", err)
+	}
+	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleUpdateStaffingRule(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	rule, ok := decodeStaffingRule(w, r)
+	if !ok {
+		return
+	}
+	rule.ID = chi.URLParam(r, "ruleID")
+	before, err := h.Service.GetStaffingRule(r.Context(), user.TenantID, rule.ID)
+	if err == nil {
+		err = h.Service.UpdateStaffingRule(r.Context(), user.TenantID, rule)
+	}
+	if err != nil {
+		if errors.Is(err, leave.ErrStaffingRuleNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "staffing rule not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		if failStaffingRuleWrite(w, r, err) {
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "staffing_rule_update_failed", "failed to update staffing rule", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.staffing_rule.update", "leave_staffing_rule", rule.ID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), before, rule); err != nil {
+		slog.Warn("audit leave.staffing_rule.update failed", "err", err)
+	}
+	api.Success(w, map[string]string{"id": rule.ID}, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleDeleteStaffingRule(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	ruleID := chi.URLParam(r, "ruleID")
+	before, err := h.Service.GetStaffingRule(r.Context(), user.TenantID, ruleID)
+	if err == nil {
+		err = h.Service.DeleteStaffingRule(r.Context(), user.TenantID, ruleID)
+	}
+	if err != nil {
+		if errors.Is(err, leave.ErrStaffingRuleNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "staffing rule not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "staffing_rule_delete_failed", "failed to delete staffing rule", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.staffing_rule.delete", "leave_staffing_rule", ruleID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), before, nil); err != nil {
+		slog.Warn("audit leave.staffing_rule.delete failed", "err", err)
+	}
+	api.Success(w, map[string]string{"status": "deleted"}, middleware.GetRequestID(r.Context()))
+}
+
+// decodeStaffingRule reads and validates a staffing rule payload.
+func decodeStaffingRule(w http.ResponseWriter, r *http.Request) (leave.StaffingRule, bool) {
+	var payload staffingRulePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return leave.StaffingRule{}, false
+	}
+
+	rule := leave.StaffingRule{
+		Name:         strings.TrimSpace(payload.Name),
+		Kind:         strings.TrimSpace(payload.Kind),
+		DepartmentID: strings.TrimSpace(payload.DepartmentID),
+		ManagerID:    strings.TrimSpace(payload.ManagerID),
+		Enforcement:  strings.TrimSpace(payload.Enforcement),
+	}
+	if rule.Enforcement == "" {
+		rule.Enforcement = leave.StaffingWarn
+	}
+	validator := shared.NewValidator()
+	validator.Required("name", rule.Name, "is required")
+	validator.Enum("kind", rule.Kind, supportedStaffingKinds, "must be max_absent or blackout")
+	validator.Enum("enforcement", rule.Enforcement, supportedStaffingEnforcements, "must be warn or block")
+	if _, err := uuid.Parse(rule.DepartmentID); rule.DepartmentID != "" && err != nil {
+		validator.Add("departmentId", "must be a UUID")
+	}
+	if _, err := uuid.Parse(rule.ManagerID); rule.ManagerID != "" && err != nil {
+		validator.Add("managerId", "must be a UUID")
+	}
+	if rule.Kind == leave.StaffingMaxAbsent {
+		rule.MaxAbsent = payload.MaxAbsent
+	}
+	if rule.Kind == leave.StaffingBlackout {
+		startDate, startValid := validator.Date("startDate", payload.StartDate)
+		endDate, endValid := validator.Date("endDate", payload.EndDate)
+		if startValid && endValid {
+			rule.StartDate, rule.EndDate = &startDate, &endDate
+		}
+	}
+	if !validator.HasIssues() && !rule.Valid() {
+		validator.Add("rule", "max_absent rules need maxAbsent above 0; blackout rules need startDate on or before endDate")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return leave.StaffingRule{}, false
+	}
+	return rule, true
+}
+
+// failStaffingRuleWrite answers a staffing rule write the database refused
+// because the name is taken or the department or manager does not exist. It
+// reports false for any other error.
+func failStaffingRuleWrite(w http.ResponseWriter, r *http.Request, err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Code {
+	case "23505":
+		api.Fail(w, http.StatusConflict, "staffing_rule_exists", "a staffing rule with this name already exists", middleware.GetRequestID(r.Context()))
+		return true
+	case "23503":
+		field := "departmentId"
+		if strings.Contains(pgErr.ConstraintName, "manager_id") {
+			field = "managerId"
+		}
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: field, Reason: "does not exist"},
+		})
+		return true
+	}
+	return false
+}
+
+// handleRequestOverlap shows an approver the requester's team leave that
+// overlaps a request and the staffing rules approving it would break.
+func (h *Handler) handleRequestOverlap(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	requestID := chi.URLParam(r, "requestID")
+	req, err := h.Service.GetRequest(r.Context(), user.TenantID, requestID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			api.Fail(w, http.StatusNotFound, "not_found", "leave request not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "overlap_failed", "failed to load leave request", middleware.GetRequestID(r.Context()))
+		return
+	}
+	allowed, err := h.canAccessRequest(r.Context(), user, req.EmployeeID)
+	if err != nil {
+		slog.Warn("leave request overlap access check failed", "requestId", requestID, "err", err)
+	}
+	if !allowed {
+		api.Fail(w, http.StatusForbidden, "forbidden", "not allowed", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	overlap, err := h.Service.RequestOverlap(r.Context(), user.TenantID, req)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "overlap_failed", "failed to load overlapping leave", middleware.GetRequestID(r.Context()))
+		return
+	}
+	events := make([]map[string]any, 0, len(overlap.Entries))
+	for _, entry := range overlap.Entries {
+		events = append(events, map[string]any{
+			"id":          entry.ID,
+			"employeeId":  entry.EmployeeID,
+			"leaveTypeId": entry.LeaveTypeID,
+			"start":       entry.StartDate,
+			"end":         entry.EndDate,
+			"status":      entry.Status,
+		})
+	}
+	api.Success(w, map[string]any{
+		"entries":   events,
+		"conflicts": overlap.Conflicts,
+		"blocked":   leave.StaffingBlocked(overlap.Conflicts),
+	}, middleware.GetRequestID(r.Context()))
+}
+
+// failStaffing refuses a request that breaks a blocking staffing rule.
+func failStaffing(w http.ResponseWriter, r *http.Request, conflicts []leave.StaffingConflict) {
+	api.FailWithDetails(w, http.StatusConflict, "staffing_conflict", "leave breaks a staffing rule", conflicts, middleware.GetRequestID(r.Context()))
+}
@@ -0,0 +1,219 @@
+package leavehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/leave"
+	"hrm/internal/domain/notifications"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+var supportedTOILStatuses = []string{leave.TOILPending, leave.TOILApproved, leave.TOILRejected, leave.TOILExpired}
+
+type toilEntryPayload struct {
+	EmployeeID  string  `json:"employeeId"`
+	LeaveTypeID string  `json:"leaveTypeId"`
+	WorkDate    string  `json:"workDate"`
+	Hours       float64 `json:"hours"`
+	Reason      string  `json:"reason"`
+}
+
+// handleListTOIL lists TOIL ledger entries. Employees see their own entries,
+// managers also see their reports' entries and HR sees every entry or one
+// employee's.
+func (h *Handler) handleListTOIL(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	filter := leave.TOILFilter{Status: strings.TrimSpace(r.URL.Query().Get("status"))}
+	if filter.Status != "" {
+		validator := shared.NewValidator()
+		validator.Enum("status", filter.Status, supportedTOILStatuses, "must be pending, approved, rejected or expired")
+		if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+			return
+		}
+	}
+
+	if user.RoleName == auth.RoleHR {
+		filter.EmployeeID = strings.TrimSpace(r.URL.Query().Get("employeeId"))
+	} else {
+		selfEmployeeID, err := h.Service.EmployeeIDByUserID(r.Context(), user.TenantID, user.UserID)
+		if err != nil {
+			slog.Warn("leave toil self employee lookup failed", "err", err)
+		}
+		if selfEmployeeID == "" {
+			api.Success(w, []leave.TOILEntry{}, middleware.GetRequestID(r.Context()))
+			return
+		}
+		filter.EmployeeID = selfEmployeeID
+		if user.RoleName == auth.RoleManager {
+			filter.ManagerEmployeeIDs = append(filter.ManagerEmployeeIDs, selfEmployeeID)
+			delegated, err := h.Service.DelegatedManagerIDs(r.Context(), user.TenantID, user.UserID)
+			if err != nil {
+				slog.Warn("leave toil delegation lookup failed", "err", err)
+			}
+			filter.ManagerEmployeeIDs = append(filter.ManagerEmployeeIDs, delegated...)
+		}
+	}
+
+	entries, err := h.Service.ListTOILEntries(r.Context(), user.TenantID, filter)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "toil_list_failed", "failed to list toil entries", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, entries, middleware.GetRequestID(r.Context()))
+}
+
+// handleCreateTOIL records overtime worked for approval. Employees and
+// managers record their own overtime; HR records it for any employee.
+func (h *Handler) handleCreateTOIL(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload toilEntryPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+	entry := leave.TOILEntry{
+		EmployeeID:  strings.TrimSpace(payload.EmployeeID),
+		LeaveTypeID: strings.TrimSpace(payload.LeaveTypeID),
+		Hours:       payload.Hours,
+		Reason:      strings.TrimSpace(payload.Reason),
+		RequestedBy: user.UserID,
+	}
+	if user.RoleName != auth.RoleHR {
+		id, err := h.Service.EmployeeIDByUserID(r.Context(), user.TenantID, user.UserID)
+		if err != nil || id == "" {
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				slog.Warn("leave toil self employee lookup failed", "err", err)
+			}
+			api.Fail(w, http.StatusForbidden, "forbidden", "employee profile required", middleware.GetRequestID(r.Context()))
+			return
+		}
+		entry.EmployeeID = id
+	}
+
+	validator := shared.NewValidator()
+	validator.Required("employeeId", entry.EmployeeID, "is required")
+	validator.Required("leaveTypeId", entry.LeaveTypeID, "is required")
+	if workDate, ok := validator.Date("workDate", payload.WorkDate); ok {
+		entry.WorkDate = workDate
+	}
+	if entry.Hours <= 0 || entry.Hours > 24 {
+		validator.Add("hours", "must be greater than 0 and at most 24")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	result, err := h.Service.RecordTOIL(r.Context(), user.TenantID, entry)
+	if err != nil {
+		switch {
+		case errors.Is(err, leave.ErrLeaveTypeNotFound):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "leaveTypeId", Reason: "must reference an existing leave type"},
+			})
+		case errors.Is(err, leave.ErrNotTOILType):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "leaveTypeId", Reason: "must reference a toil leave type"},
+			})
+		case errors.Is(err, leave.ErrInvalidHours):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "hours", Reason: "must be greater than 0 and at most 24"},
+			})
+		default:
+			api.Fail(w, http.StatusInternalServerError, "toil_create_failed", "failed to record toil", middleware.GetRequestID(r.Context()))
+		}
+		return
+	}
+
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.toil.create", "toil_entry", result.ID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, entry); err != nil {
+		slog.Warn("audit leave.toil.create failed", "err", err)
+	}
+	h.notifyApprover(r.Context(), user.TenantID, result.ManagerUserID, "Overtime submitted", "Overtime for time off in lieu is awaiting approval.")
+	if h.Notify != nil {
+		for _, hrUserID := range result.HRUserIDs {
+			if err := h.Notify.Create(r.Context(), user.TenantID, hrUserID, notifications.TypeLeaveSubmitted, "Overtime awaiting HR", "Overtime for time off in lieu is awaiting HR approval."); err != nil {
+				slog.Warn("leave toil hr notification failed", "err", err)
+			}
+		}
+	}
+	api.Created(w, map[string]string{"id": result.ID, "status": leave.TOILPending}, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleApproveTOIL(w http.ResponseWriter, r *http.Request) {
+	h.decideTOIL(w, r, true)
+}
+
+func (h *Handler) handleRejectTOIL(w http.ResponseWriter, r *http.Request) {
+	h.decideTOIL(w, r, false)
+}
+
+// decideTOIL approves or rejects a pending TOIL entry and tells the employee.
+func (h *Handler) decideTOIL(w http.ResponseWriter, r *http.Request, approve bool) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleManager && user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "manager or hr required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	entryID := chi.URLParam(r, "entryID")
+	action, title, notifyType := "approve", "Overtime approved", notifications.TypeLeaveApproved
+	decide := h.Service.ApproveTOIL
+	if !approve {
+		action, title, notifyType = "reject", "Overtime rejected", notifications.TypeLeaveRejected
+		decide = h.Service.RejectTOIL
+	}
+	decision, err := decide(r.Context(), user.TenantID, entryID, user.UserID, user.RoleName)
+	if err != nil {
+		switch {
+		case errors.Is(err, leave.ErrTOILEntryNotFound):
+			api.Fail(w, http.StatusNotFound, "not_found", "toil entry not found", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, leave.ErrHRApprovalRequired):
+			api.Fail(w, http.StatusForbidden, "forbidden", "hr approval required", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, leave.ErrForbidden):
+			api.Fail(w, http.StatusForbidden, "forbidden", "not allowed", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, leave.ErrInvalidState):
+			api.Fail(w, http.StatusBadRequest, "invalid_state", "toil entry is not awaiting approval", middleware.GetRequestID(r.Context()))
+		default:
+			api.Fail(w, http.StatusInternalServerError, "toil_decision_failed", "failed to decide toil entry", middleware.GetRequestID(r.Context()))
+		}
+		return
+	}
+
+	auditAction := "leave.toil." + action
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, auditAction, "toil_entry", entryID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, decisionAudit(decision.Entry.EmployeeID, decision.OnBehalfOf)); err != nil {
+		slog.Warn("audit "+auditAction+" failed", "err", err)
+	}
+	if decision.EmployeeUser != "" && h.Notify != nil {
+		body := fmt.Sprintf("Your %.2f hours of overtime on %s were %s for %s.", decision.Entry.Hours, decision.Entry.WorkDate.Format("2006-01-02"), decision.Entry.Status, decision.LeaveTypeName)
+		if err := h.Notify.Create(r.Context(), user.TenantID, decision.EmployeeUser, notifyType, title, body); err != nil {
+			slog.Warn("leave toil decision notification failed", "err", err)
+		}
+	}
+	api.Success(w, decision.Entry, middleware.GetRequestID(r.Context()))
+}
@@ -0,0 +1,208 @@
+package leavehandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/leave"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type workPatternPayload struct {
+	Name        string    `json:"name"`
+	CycleStart  string    `json:"cycleStart"`
+	Days        []float64 `json:"days"`
+	HoursPerDay float64   `json:"hoursPerDay"`
+}
+
+type workSchedulePayload struct {
+	WorkPatternID string `json:"workPatternId"`
+	HolidayRegion string `json:"holidayRegion"`
+}
+
+func (h *Handler) handleListWorkPatterns(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	patterns, err := h.Service.ListWorkPatterns(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "work_pattern_list_failed", "failed to list work patterns", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, patterns, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleCreateWorkPattern(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	pattern, ok := decodeWorkPattern(w, r)
+	if !ok {
+		return
+	}
+	id, err := h.Service.CreateWorkPattern(r.Context(), user.TenantID, pattern)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			api.Fail(w, http.StatusConflict, "work_pattern_exists", "a work pattern with this name already exists", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "work_pattern_create_failed", "failed to create work pattern", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.work_pattern.create", "work_pattern", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, pattern); err != nil {
+		slog.Warn("audit leave.work_pattern.create failed", "err", err)
+	}
+	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleUpdateWorkPattern(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	pattern, ok := decodeWorkPattern(w, r)
+	if !ok {
+		return
+	}
+	pattern.ID = chi.URLParam(r, "patternID")
+	before, err := h.Service.GetWorkPattern(r.Context(), user.TenantID, pattern.ID)
+	if err == nil {
+		err = h.Service.UpdateWorkPattern(r.Context(), user.TenantID, pattern)
+	}
+	if err != nil {
+		if errors.Is(err, leave.ErrWorkPatternNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "work pattern not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			api.Fail(w, http.StatusConflict, "work_pattern_exists", "a work pattern with this name already exists", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "work_pattern_update_failed", "failed to update work pattern", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "leave.work_pattern.update", "work_pattern", pattern.ID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), before, pattern); err != nil {
+		slog.Warn("audit leave.work_pattern.update failed", "err", err)
+	}
+	api.Success(w, map[string]string{"id": pattern.ID}, middleware.GetRequestID(r.Context()))
+}
+
+// decodeWorkPattern reads and validates a work pattern payload. Seven-day
+// patterns without a cycle start begin on a Monday.
+func decodeWorkPattern(w http.ResponseWriter, r *http.Request) (leave.WorkPattern, bool) {
+	var payload workPatternPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return leave.WorkPattern{}, false
+	}
+
+	pattern := leave.WorkPattern{
+		Name:        strings.TrimSpace(payload.Name),
+		CycleStart:  leave.StandardWorkPattern().CycleStart,
+		Days:        payload.Days,
+		HoursPerDay: payload.HoursPerDay,
+	}
+	validator := shared.NewValidator()
+	validator.Required("name", pattern.Name, "is required")
+	if raw := strings.TrimSpace(payload.CycleStart); raw != "" {
+		if cycleStart, ok := validator.Date("cycleStart", raw); ok {
+			pattern.CycleStart = cycleStart
+		}
+	}
+	if pattern.HoursPerDay < 0 || pattern.HoursPerDay > 24 {
+		validator.Add("hoursPerDay", "must be between 0 and 24")
+	} else if !pattern.Valid() {
+		validator.Add("days", "must list 1 to 56 days, each between 0 and 1, with at least one working day")
+	}
+	if pattern.HoursPerDay == 0 {
+		pattern.HoursPerDay = leave.StandardHoursPerDay
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return leave.WorkPattern{}, false
+	}
+	return pattern, true
+}
+
+// handleAssignEmployeeSchedule sets the work pattern and holiday region used
+// to count an employee's leave days. Empty values fall back to the
+// employee's department.
+func (h *Handler) handleAssignEmployeeSchedule(w http.ResponseWriter, r *http.Request) {
+	h.assignSchedule(w, r, "employee", chi.URLParam(r, "employeeID"), h.Service.AssignEmployeeSchedule)
+}
+
+// handleAssignDepartmentSchedule sets the work pattern and holiday region for
+// a department's employees who have none of their own.
+func (h *Handler) handleAssignDepartmentSchedule(w http.ResponseWriter, r *http.Request) {
+	h.assignSchedule(w, r, "department", chi.URLParam(r, "departmentID"), h.Service.AssignDepartmentSchedule)
+}
+
+func (h *Handler) assignSchedule(w http.ResponseWriter, r *http.Request, entity, entityID string, assign func(ctx context.Context, tenantID, entityID, patternID, region string) error) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload workSchedulePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+	payload.WorkPatternID = strings.TrimSpace(payload.WorkPatternID)
+	payload.HolidayRegion = strings.TrimSpace(payload.HolidayRegion)
+
+	if err := assign(r.Context(), user.TenantID, entityID, payload.WorkPatternID, payload.HolidayRegion); err != nil {
+		switch {
+		case errors.Is(err, leave.ErrWorkPatternNotFound):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "workPatternId", Reason: "must reference an existing work pattern"},
+			})
+		case errors.Is(err, leave.ErrEmployeeNotFound), errors.Is(err, leave.ErrDepartmentNotFound):
+			api.Fail(w, http.StatusNotFound, "not_found", entity+" not found", middleware.GetRequestID(r.Context()))
+		default:
+			api.Fail(w, http.StatusInternalServerError, "work_schedule_update_failed", "failed to assign work schedule", middleware.GetRequestID(r.Context()))
+		}
+		return
+	}
+	action := "leave." + entity + "_schedule.update"
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, action, entity, entityID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, payload); err != nil {
+		slog.Warn("audit "+action+" failed", "err", err)
+	}
+	api.Success(w, payload, middleware.GetRequestID(r.Context()))
+}
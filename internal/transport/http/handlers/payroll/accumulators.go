@@ -0,0 +1,177 @@
+package payrollhandler
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+// selfEmployeeOnly returns false after writing a 403 when a non-HR user asks
+// for another employee's payroll data.
+func (h *Handler) selfEmployeeOnly(w http.ResponseWriter, r *http.Request, user auth.UserContext, employeeID string) bool {
+	if user.RoleName == auth.RoleHR {
+		return true
+	}
+	selfEmployeeID, err := h.Service.FindEmployeeIDByUserID(r.Context(), user.TenantID, user.UserID)
+	if err != nil && !isNoRowsError(err) {
+		slog.Warn("payroll self employee lookup failed", "err", err)
+	}
+	if selfEmployeeID == "" || selfEmployeeID != employeeID {
+		api.Fail(w, http.StatusForbidden, "forbidden", "employee can only access own payroll data", middleware.GetRequestID(r.Context()))
+		return false
+	}
+	return true
+}
+
+func (h *Handler) handleListAccumulators(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	employeeID := chi.URLParam(r, "employeeID")
+	var taxYear int
+	if raw := strings.TrimSpace(r.URL.Query().Get("year")); raw != "" {
+		parsed, ok := parseTaxYear(raw)
+		if !ok {
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "year", Reason: "must be a four digit year"},
+			})
+			return
+		}
+		taxYear = parsed
+	}
+	if !h.selfEmployeeOnly(w, r, user, employeeID) {
+		return
+	}
+	if taxYear == 0 {
+		settings, err := h.Service.Settings(r.Context(), user.TenantID)
+		if err != nil {
+			api.Fail(w, http.StatusInternalServerError, "payroll_settings_failed", "failed to load payroll settings", middleware.GetRequestID(r.Context()))
+			return
+		}
+		taxYear = settings.TaxYear(time.Now().UTC())
+	}
+
+	accumulators, err := h.Service.ListAccumulators(r.Context(), user.TenantID, employeeID, taxYear)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "accumulator_list_failed", "failed to list payroll accumulators", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, accumulators, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleGenerateAnnualStatements(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	taxYear, ok := parseTaxYear(chi.URLParam(r, "year"))
+	if !ok {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "year", Reason: "must be a four digit year"},
+		})
+		return
+	}
+
+	result, err := h.Service.GenerateAnnualStatements(r.Context(), user.TenantID, taxYear)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "annual_statement_failed", "failed to generate annual statements", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.annual_statements.generate", "payroll_annual_statement", strconv.Itoa(taxYear), middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, result); err != nil {
+		slog.Warn("audit payroll.annual_statements.generate failed", "err", err)
+	}
+	api.Success(w, result, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleListAnnualStatements(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	employeeID := strings.TrimSpace(r.URL.Query().Get("employeeId"))
+	if user.RoleName != auth.RoleHR {
+		selfEmployeeID, err := h.Service.FindEmployeeIDByUserID(r.Context(), user.TenantID, user.UserID)
+		if err != nil && !isNoRowsError(err) {
+			slog.Warn("annual statement self employee lookup failed", "err", err)
+		}
+		if selfEmployeeID == "" {
+			api.Success(w, []any{}, middleware.GetRequestID(r.Context()))
+			return
+		}
+		employeeID = selfEmployeeID
+	}
+
+	statements, err := h.Service.ListAnnualStatements(r.Context(), user.TenantID, employeeID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "annual_statement_list_failed", "failed to list annual statements", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, statements, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleDownloadAnnualStatement(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	employeeID, fileURL, err := h.Service.AnnualStatementFile(r.Context(), user.TenantID, chi.URLParam(r, "statementID"))
+	if err != nil {
+		api.Fail(w, http.StatusNotFound, "not_found", "annual statement not found", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if !h.selfEmployeeOnly(w, r, user, employeeID) {
+		return
+	}
+
+	if h.Crypto != nil && h.Crypto.Configured() && strings.HasSuffix(fileURL, ".enc") {
+		contents, err := os.ReadFile(fileURL)
+		if err != nil {
+			api.Fail(w, http.StatusInternalServerError, "annual_statement_missing", "annual statement not available", middleware.GetRequestID(r.Context()))
+			return
+		}
+		plain, err := h.Crypto.Decrypt(contents)
+		if err != nil {
+			api.Fail(w, http.StatusInternalServerError, "annual_statement_missing", "annual statement not available", middleware.GetRequestID(r.Context()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", "attachment; filename=annual-statement.pdf")
+		if _, err := w.Write(plain); err != nil {
+			slog.Warn("annual statement download write failed", "err", err)
+		}
+		return
+	}
+
+	http.ServeFile(w, r, fileURL)
+}
+
+func parseTaxYear(raw string) (int, bool) {
+	year, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || year < 1900 || year > 9999 {
+		return 0, false
+	}
+	return year, true
+}
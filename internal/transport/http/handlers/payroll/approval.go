@@ -0,0 +1,236 @@
+package payrollhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/core"
+	"hrm/internal/domain/notifications"
+	"hrm/internal/domain/payroll"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type payrollDecisionPayload struct {
+	Comment string `json:"comment"`
+}
+
+// handleSubmitPayroll sends a reviewed period to the approvers. The period can
+// only be finalized after another user with payroll.finalize approves it.
+func (h *Handler) handleSubmitPayroll(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	periodID := chi.URLParam(r, "periodID")
+	approval, err := h.Service.SubmitPeriod(r.Context(), user.TenantID, periodID, user.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, payroll.ErrPeriodNotFound):
+			api.Fail(w, http.StatusNotFound, "not_found", "payroll period not found", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrSubmitInvalidState):
+			api.Fail(w, http.StatusBadRequest, "invalid_state", err.Error(), middleware.GetRequestID(r.Context()))
+		default:
+			api.Fail(w, http.StatusInternalServerError, "payroll_submit_failed", "failed to submit payroll for approval", middleware.GetRequestID(r.Context()))
+		}
+		return
+	}
+
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.submit", "payroll_period", periodID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), map[string]string{"status": payroll.PeriodStatusReviewed}, approval); err != nil {
+		slog.Warn("audit payroll.submit failed", "err", err)
+	}
+
+	if h.Notify != nil {
+		approvers, err := h.Service.ApproverUserIDs(r.Context(), user.TenantID)
+		if err != nil {
+			slog.Warn("payroll approver lookup failed", "err", err)
+		}
+		body := fmt.Sprintf("%s was submitted for approval.", h.periodLabel(r, user.TenantID, periodID))
+		for _, approverID := range approvers {
+			if approval.MadeBy(approverID) {
+				continue
+			}
+			if err := h.Notify.Create(r.Context(), user.TenantID, approverID, notifications.TypePayrollApproval, "Payroll awaiting approval", body); err != nil {
+				slog.Warn("payroll approval notification failed", "err", err)
+			}
+			if h.Delegations == nil {
+				continue
+			}
+			delegateID, err := h.Delegations.ActingDelegate(r.Context(), user.TenantID, approverID, core.DelegationScopePayroll, time.Now().UTC())
+			if err != nil {
+				slog.Warn("payroll approval delegate lookup failed", "err", err)
+				continue
+			}
+			if delegateID == "" || approval.MadeBy(delegateID) {
+				continue
+			}
+			if err := h.Notify.Create(r.Context(), user.TenantID, delegateID, notifications.TypePayrollApproval, "Payroll awaiting approval", body); err != nil {
+				slog.Warn("payroll approval notification failed", "err", err)
+			}
+		}
+	}
+
+	api.Success(w, approval, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleApprovePayroll(w http.ResponseWriter, r *http.Request) {
+	h.decidePayroll(w, r, true)
+}
+
+func (h *Handler) handleRejectPayroll(w http.ResponseWriter, r *http.Request) {
+	h.decidePayroll(w, r, false)
+}
+
+// decidePayroll records the approver's decision. Approval allows finalize;
+// rejection sends the period back to draft and requires a comment. The route
+// carries no permission of its own: the caller decides either in their own
+// right or on behalf of an approver who delegated payroll to them, and a
+// delegated decision is audited with that approver as onBehalfOf.
+func (h *Handler) decidePayroll(w http.ResponseWriter, r *http.Request, approve bool) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	onBehalfOf := h.delegatedApprover(r, user)
+	if onBehalfOf == "" && !h.canFinalize(r, user) {
+		api.Fail(w, http.StatusForbidden, "forbidden", "insufficient permissions", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload payrollDecisionPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+	payload.Comment = strings.TrimSpace(payload.Comment)
+	if !approve {
+		validator := shared.NewValidator()
+		validator.Required("comment", payload.Comment, "is required when rejecting")
+		if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+			return
+		}
+	}
+
+	periodID := chi.URLParam(r, "periodID")
+	approval, err := h.Service.DecidePeriod(r.Context(), user.TenantID, periodID, user.UserID, onBehalfOf, approve, payload.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, payroll.ErrPeriodNotFound):
+			api.Fail(w, http.StatusNotFound, "not_found", "payroll period not found", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrApprovalInvalidState):
+			api.Fail(w, http.StatusBadRequest, "invalid_state", err.Error(), middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrApprovalSameUser):
+			api.Fail(w, http.StatusForbidden, "segregation_of_duties", err.Error(), middleware.GetRequestID(r.Context()))
+		default:
+			api.Fail(w, http.StatusInternalServerError, "payroll_decision_failed", "failed to record payroll decision", middleware.GetRequestID(r.Context()))
+		}
+		return
+	}
+
+	action, ntype, title := "payroll.approve", notifications.TypePayrollApproved, "Payroll approved"
+	if !approve {
+		action, ntype, title = "payroll.reject", notifications.TypePayrollRejected, "Payroll rejected"
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, action, "payroll_period", periodID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), map[string]string{"status": payroll.PeriodStatusPendingApproval}, decisionAudit(approval, onBehalfOf)); err != nil {
+		slog.Warn("audit "+action+" failed", "err", err)
+	}
+
+	if h.Notify != nil {
+		body := fmt.Sprintf("%s was %s.", h.periodLabel(r, user.TenantID, periodID), approval.Decision)
+		if approval.Comment != "" {
+			body += " Comment: " + approval.Comment
+		}
+		notified := map[string]bool{user.UserID: true}
+		for _, recipient := range []string{approval.RunBy, approval.ResumedBy, approval.SubmittedBy} {
+			if recipient == "" || notified[recipient] {
+				continue
+			}
+			notified[recipient] = true
+			if err := h.Notify.Create(r.Context(), user.TenantID, recipient, ntype, title, body); err != nil {
+				slog.Warn("payroll decision notification failed", "err", err)
+			}
+		}
+	}
+
+	api.Success(w, approval, middleware.GetRequestID(r.Context()))
+}
+
+// canFinalize reports whether the user decides payroll in their own right:
+// the hr role with payroll.finalize or system admin.
+func (h *Handler) canFinalize(r *http.Request, user auth.UserContext) bool {
+	if user.RoleName != auth.RoleHR {
+		return false
+	}
+	for _, permission := range []string{auth.PermPayrollFinalize, auth.PermSystemAdmin} {
+		allowed, err := h.Perms.HasPermission(r.Context(), user.RoleID, permission)
+		if err != nil {
+			slog.Warn("payroll decision permission lookup failed", "err", err)
+			return false
+		}
+		if allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// delegatedApprover returns the payroll approver the user decides for
+// through a delegation, or "".
+func (h *Handler) delegatedApprover(r *http.Request, user auth.UserContext) string {
+	if h.Delegations == nil {
+		return ""
+	}
+	delegators, err := h.Delegations.DelegatorsFor(r.Context(), user.TenantID, user.UserID, core.DelegationScopePayroll, time.Now().UTC())
+	if err != nil || len(delegators) == 0 {
+		return ""
+	}
+	approvers, err := h.Service.ApproverUserIDs(r.Context(), user.TenantID)
+	if err != nil {
+		slog.Warn("payroll approver lookup failed", "err", err)
+		return ""
+	}
+	for _, delegatorID := range delegators {
+		for _, approverID := range approvers {
+			if delegatorID == approverID {
+				return approverID
+			}
+		}
+	}
+	return ""
+}
+
+func decisionAudit(approval payroll.PeriodApproval, onBehalfOf string) any {
+	if onBehalfOf == "" {
+		return approval
+	}
+	return map[string]any{"approval": approval, "onBehalfOf": onBehalfOf}
+}
+
+// periodLabel describes a period for notification text, falling back to a
+// generic label when the period cannot be loaded.
+func (h *Handler) periodLabel(r *http.Request, tenantID, periodID string) string {
+	details, err := h.Service.GetPeriodDetails(r.Context(), tenantID, periodID)
+	if err != nil {
+		return "A payroll period"
+	}
+	return fmt.Sprintf("Payroll for %s to %s", details.StartDate.Format("2006-01-02"), details.EndDate.Format("2006-01-02"))
+}
@@ -0,0 +1,110 @@
+package payrollhandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/payroll"
+	"hrm/internal/platform/jobs"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+const maxCalendarRangeYears = 3
+
+// handleProjectCalendar lists a schedule's existing regular periods in the
+// requested range followed by the periods the calendar generator would create
+// next, with pay dates moved off weekends and holidays. The range defaults to
+// the year starting today.
+func (h *Handler) handleProjectCalendar(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if denyEmployeePayrollOperations(w, r, user.RoleName) {
+		return
+	}
+
+	from := time.Now().UTC().Truncate(24 * time.Hour)
+	to := from.AddDate(1, 0, 0)
+	validator := shared.NewValidator()
+	if raw := strings.TrimSpace(r.URL.Query().Get("from")); raw != "" {
+		if parsed, ok := validator.Date("from", raw); ok {
+			from = parsed
+			to = from.AddDate(1, 0, 0)
+		}
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("to")); raw != "" {
+		if parsed, ok := validator.Date("to", raw); ok {
+			to = parsed
+		}
+	}
+	validator.DateOrder("from", from, "to", to)
+	if to.After(from.AddDate(maxCalendarRangeYears, 0, 0)) {
+		validator.Add("to", "must be within three years of from")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	periods, err := h.Service.ProjectCalendar(r.Context(), user.TenantID, chi.URLParam(r, "scheduleID"), from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, payroll.ErrScheduleNotFound):
+			api.Fail(w, http.StatusNotFound, "not_found", "pay schedule not found", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrScheduleFrequency):
+			api.Fail(w, http.StatusBadRequest, "invalid_state", err.Error(), middleware.GetRequestID(r.Context()))
+		default:
+			api.Fail(w, http.StatusInternalServerError, "payroll_calendar_failed", "failed to project pay calendar", middleware.GetRequestID(r.Context()))
+		}
+		return
+	}
+	api.Success(w, periods, middleware.GetRequestID(r.Context()))
+}
+
+// handleGenerateCalendars runs the pay calendar generator for the tenant now
+// instead of waiting for the scheduled job.
+func (h *Handler) handleGenerateCalendars(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	now := time.Now().UTC()
+	var summary payroll.CalendarSummary
+	var err error
+	if h.Jobs != nil {
+		result, runErr := h.Jobs.RunNow(r.Context(), jobs.JobPayrollCalendar, user.TenantID, func(runCtx context.Context) (any, error) {
+			return h.Service.GenerateCalendars(runCtx, user.TenantID, now)
+		})
+		err = runErr
+		if s, ok := result.(payroll.CalendarSummary); ok {
+			summary = s
+		}
+	} else {
+		summary, err = h.Service.GenerateCalendars(r.Context(), user.TenantID, now)
+	}
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "payroll_calendar_failed", "failed to generate pay calendars", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.calendar.generate", "pay_schedule", "", middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, summary); err != nil {
+		slog.Warn("audit payroll.calendar.generate failed", "err", err)
+	}
+	api.Success(w, summary, middleware.GetRequestID(r.Context()))
+}
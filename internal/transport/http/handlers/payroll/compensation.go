@@ -0,0 +1,118 @@
+package payrollhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/payroll"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type compensationPayload struct {
+	EffectiveFrom string   `json:"effectiveFrom"`
+	Salary        *float64 `json:"salary"`
+	Currency      string   `json:"currency"`
+	PayGroupID    string   `json:"payGroupId"`
+	Reason        string   `json:"reason"`
+}
+
+func (h *Handler) handleListCompensation(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	employeeID := chi.URLParam(r, "employeeID")
+	if !h.selfEmployeeOnly(w, r, user, employeeID) {
+		return
+	}
+
+	records, err := h.Service.ListCompensation(r.Context(), user.TenantID, employeeID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "compensation_list_failed", "failed to list compensation history", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, records, middleware.GetRequestID(r.Context()))
+}
+
+// handleCreateCompensation records a salary, currency or pay group change from
+// a given date. Back-dated changes apply to payroll runs from that date; a
+// record for a date that already has one replaces it.
+func (h *Handler) handleCreateCompensation(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload compensationPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+
+	record := payroll.Compensation{
+		EmployeeID: chi.URLParam(r, "employeeID"),
+		Salary:     payload.Salary,
+		Currency:   strings.ToUpper(strings.TrimSpace(payload.Currency)),
+		PayGroupID: strings.TrimSpace(payload.PayGroupID),
+		Reason:     strings.TrimSpace(payload.Reason),
+		CreatedBy:  user.UserID,
+	}
+	if record.Currency == "" {
+		record.Currency = "USD"
+	}
+
+	validator := shared.NewValidator()
+	validator.Required("effectiveFrom", strings.TrimSpace(payload.EffectiveFrom), "is required")
+	if effectiveFrom, ok := validator.Date("effectiveFrom", strings.TrimSpace(payload.EffectiveFrom)); ok {
+		record.EffectiveFrom = effectiveFrom
+	}
+	if payload.Salary == nil {
+		validator.Add("salary", "is required")
+	} else if *payload.Salary < 0 {
+		validator.Add("salary", "must be zero or greater")
+	}
+	if len(record.Currency) != 3 {
+		validator.Add("currency", "must be a three letter currency code")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	id, err := h.Service.SaveCompensation(r.Context(), user.TenantID, record)
+	if err != nil {
+		if errors.Is(err, payroll.ErrEmployeeNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "employee not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "compensation_create_failed", "failed to record compensation change", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.compensation.create", "employee_compensation", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, map[string]any{
+		"employeeId":    record.EmployeeID,
+		"effectiveFrom": payload.EffectiveFrom,
+		"currency":      record.Currency,
+		"payGroupId":    record.PayGroupID,
+		"reason":        record.Reason,
+	}); err != nil {
+		slog.Warn("audit payroll.compensation.create failed", "err", err)
+	}
+	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
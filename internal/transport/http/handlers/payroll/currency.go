@@ -0,0 +1,206 @@
+package payrollhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/payroll"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type exchangeRatePayload struct {
+	FromCurrency  string   `json:"fromCurrency"`
+	ToCurrency    string   `json:"toCurrency"`
+	Rate          *float64 `json:"rate"`
+	EffectiveDate string   `json:"effectiveDate"`
+}
+
+func (h *Handler) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	settings, err := h.Service.Settings(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "payroll_settings_failed", "failed to load payroll settings", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, settings, middleware.GetRequestID(r.Context()))
+}
+
+// handleUpdateSettings changes the reporting currency that period summaries,
+// previews and journal exports are converted to, and the day the tax year
+// starts. The tax year start is kept when the payload omits it.
+func (h *Handler) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload payroll.Settings
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+	payload.ReportingCurrency = payroll.NormalizeCurrency(payload.ReportingCurrency)
+
+	validator := shared.NewValidator()
+	validator.Required("reportingCurrency", payload.ReportingCurrency, "is required")
+	if payload.ReportingCurrency != "" && !payroll.ValidCurrency(payload.ReportingCurrency) {
+		validator.Add("reportingCurrency", "must be a three-letter currency code")
+	}
+	if (payload.TaxYearStartMonth != 0 || payload.TaxYearStartDay != 0) && !payroll.ValidTaxYearStart(payload.TaxYearStartMonth, payload.TaxYearStartDay) {
+		validator.Add("taxYearStartDay", "taxYearStartMonth and taxYearStartDay must name a day every year has")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	before, err := h.Service.Settings(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "payroll_settings_failed", "failed to load payroll settings", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if payload.TaxYearStartMonth == 0 && payload.TaxYearStartDay == 0 {
+		payload.TaxYearStartMonth, payload.TaxYearStartDay = before.TaxYearStartMonth, before.TaxYearStartDay
+	}
+	if err := h.Service.UpsertSettings(r.Context(), user.TenantID, payload); err != nil {
+		api.Fail(w, http.StatusInternalServerError, "payroll_settings_failed", "failed to save payroll settings", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.settings.update", "payroll_settings", user.TenantID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), before, payload); err != nil {
+		slog.Warn("audit payroll.settings.update failed", "err", err)
+	}
+	api.Success(w, payload, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleListExchangeRates(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	rates, err := h.Service.ListExchangeRates(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "exchange_rate_list_failed", "failed to list exchange rates", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, rates, middleware.GetRequestID(r.Context()))
+}
+
+// handleUpsertExchangeRate records a dated rate for a currency pair. Posting
+// the same pair and effective date again replaces the earlier rate.
+func (h *Handler) handleUpsertExchangeRate(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload exchangeRatePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+
+	rate := payroll.ExchangeRate{
+		FromCurrency: payroll.NormalizeCurrency(payload.FromCurrency),
+		ToCurrency:   payroll.NormalizeCurrency(payload.ToCurrency),
+		CreatedBy:    user.UserID,
+	}
+	validator := shared.NewValidator()
+	validator.Required("fromCurrency", rate.FromCurrency, "is required")
+	if rate.FromCurrency != "" && !payroll.ValidCurrency(rate.FromCurrency) {
+		validator.Add("fromCurrency", "must be a three-letter currency code")
+	}
+	validator.Required("toCurrency", rate.ToCurrency, "is required")
+	if rate.ToCurrency != "" && !payroll.ValidCurrency(rate.ToCurrency) {
+		validator.Add("toCurrency", "must be a three-letter currency code")
+	}
+	if rate.FromCurrency != "" && rate.FromCurrency == rate.ToCurrency {
+		validator.Add("toCurrency", "must differ from fromCurrency")
+	}
+	if payload.Rate == nil {
+		validator.Add("rate", "is required")
+	} else if *payload.Rate <= 0 {
+		validator.Add("rate", "must be greater than zero")
+	} else {
+		rate.Rate = *payload.Rate
+	}
+	validator.Required("effectiveDate", strings.TrimSpace(payload.EffectiveDate), "is required")
+	if effectiveDate, ok := validator.Date("effectiveDate", strings.TrimSpace(payload.EffectiveDate)); ok {
+		rate.EffectiveDate = effectiveDate
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	id, err := h.Service.UpsertExchangeRate(r.Context(), user.TenantID, rate)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "exchange_rate_save_failed", "failed to save exchange rate", middleware.GetRequestID(r.Context()))
+		return
+	}
+	rate.ID = id
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.exchange_rate.upsert", "payroll_exchange_rate", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, rate); err != nil {
+		slog.Warn("audit payroll.exchange_rate.upsert failed", "err", err)
+	}
+	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleDeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	rateID := chi.URLParam(r, "rateID")
+	if err := h.Service.DeleteExchangeRate(r.Context(), user.TenantID, rateID); err != nil {
+		if errors.Is(err, payroll.ErrExchangeRateNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "exchange rate not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "exchange_rate_delete_failed", "failed to delete exchange rate", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.exchange_rate.delete", "payroll_exchange_rate", rateID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, nil); err != nil {
+		slog.Warn("audit payroll.exchange_rate.delete failed", "err", err)
+	}
+	api.Success(w, map[string]string{"id": rateID}, middleware.GetRequestID(r.Context()))
+}
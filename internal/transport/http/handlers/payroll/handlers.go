@@ -2,10 +2,10 @@ package payrollhandler
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -15,11 +15,11 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"hrm/internal/domain/audit"
 	"hrm/internal/domain/auth"
 	"hrm/internal/domain/core"
-	"hrm/internal/domain/leave"
 	"hrm/internal/domain/notifications"
 	"hrm/internal/domain/payroll"
 	cryptoutil "hrm/internal/platform/crypto"
@@ -30,13 +30,14 @@ import (
 )
 
 type Handler struct {
-	Service *payroll.Service
-	Perms   middleware.PermissionStore
-	Idem    *middleware.IdempotencyStore
-	Crypto  *cryptoutil.Service
-	Notify  *notifications.Service
-	Jobs    *jobs.Service
-	Audit   *audit.Service
+	Service     *payroll.Service
+	Perms       middleware.PermissionStore
+	Idem        *middleware.IdempotencyStore
+	Crypto      *cryptoutil.Service
+	Notify      *notifications.Service
+	Jobs        *jobs.Service
+	Audit       *audit.Service
+	Delegations *core.Service
 }
 
 func NewHandler(service *payroll.Service, perms middleware.PermissionStore, idem *middleware.IdempotencyStore, crypto *cryptoutil.Service, notify *notifications.Service, jobsSvc *jobs.Service, auditSvc *audit.Service) *Handler {
@@ -53,28 +54,63 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/payroll", func(r chi.Router) {
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/schedules", h.handleListSchedules)
 		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/schedules", h.handleCreateSchedule)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/schedules/{scheduleID}/calendar", h.handleProjectCalendar)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/calendar/generate", h.handleGenerateCalendars)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/groups", h.handleListGroups)
 		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/groups", h.handleCreateGroup)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/elements", h.handleListElements)
 		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/elements", h.handleCreateElement)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/journal-templates", h.handleListJournalTemplates)
 		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/journal-templates", h.handleCreateJournalTemplate)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/payslip-templates", h.handleListPayslipTemplates)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/payslip-templates", h.handleCreatePayslipTemplate)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Put("/payslip-templates/{templateID}", h.handleUpdatePayslipTemplate)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/tax-rules", h.handleListTaxRules)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/tax-rules", h.handleCreateTaxRule)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/payment-settings", h.handleGetPaymentSettings)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Put("/payment-settings", h.handleUpdatePaymentSettings)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/settings", h.handleGetSettings)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Put("/settings", h.handleUpdateSettings)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/exchange-rates", h.handleListExchangeRates)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/exchange-rates", h.handleUpsertExchangeRate)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Delete("/exchange-rates/{rateID}", h.handleDeleteExchangeRate)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods", h.handleListPeriods)
 		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/periods", h.handleCreatePeriod)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/periods/off-cycle", h.handleCreateOffCyclePeriod)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/retro", h.handleListRetroCandidates)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods/{periodID}/inputs", h.handleListInputs)
 		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/periods/{periodID}/inputs", h.handleCreateInput)
 		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/periods/{periodID}/inputs/import", h.handleImportInputs)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods/{periodID}/adjustments", h.handleListAdjustments)
 		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/periods/{periodID}/adjustments", h.handleCreateAdjustment)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods/{periodID}/summary", h.handlePeriodSummary)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods/{periodID}/retro", h.handleListRetroItems)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods/{periodID}/results/{employeeID}", h.handleEmployeeResult)
+		r.With(middleware.RequirePermission(auth.PermPayrollRun, h.Perms)).Get("/periods/{periodID}/preview", h.handlePreviewPayroll)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods/{periodID}/payslips/{employeeID}/preview", h.handlePreviewPayslip)
 		r.With(middleware.RequirePermission(auth.PermPayrollRun, h.Perms)).Post("/periods/{periodID}/run", h.handleRunPayroll)
+		r.With(middleware.RequirePermission(auth.PermPayrollRun, h.Perms)).Post("/periods/{periodID}/runs/{runID}/resume", h.handleResumePayrollRun)
+		r.With(middleware.RequirePermission(auth.PermPayrollRun, h.Perms)).Post("/periods/{periodID}/submit", h.handleSubmitPayroll)
+		r.Post("/periods/{periodID}/approve", h.handleApprovePayroll)
+		r.Post("/periods/{periodID}/reject", h.handleRejectPayroll)
 		r.With(middleware.RequirePermission(auth.PermPayrollFinalize, h.Perms)).Post("/periods/{periodID}/finalize", h.handleFinalizePayroll)
 		r.With(middleware.RequirePermission(auth.PermPayrollFinalize, h.Perms)).Post("/periods/{periodID}/reopen", h.handleReopenPeriod)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods/{periodID}/export/register", h.handleExportRegister)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods/{periodID}/export/journal", h.handleExportJournal)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/periods/{periodID}/journal-exports", h.handleListJournalExports)
+		r.With(middleware.RequirePermission(auth.PermPayrollFinalize, h.Perms)).Get("/periods/{periodID}/export/payments", h.handleExportPayments)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/payslips", h.handleListPayslips)
 		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/payslips/{payslipID}/download", h.handleDownloadPayslip)
 		r.With(middleware.RequirePermission(auth.PermPayrollFinalize, h.Perms)).Post("/payslips/{payslipID}/regenerate", h.handleRegeneratePayslip)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/employees/{employeeID}/accumulators", h.handleListAccumulators)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/employees/{employeeID}/compensation", h.handleListCompensation)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/employees/{employeeID}/compensation", h.handleCreateCompensation)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/employees/{employeeID}/recurring", h.handleListRecurringItems)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Post("/employees/{employeeID}/recurring", h.handleCreateRecurringItem)
+		r.With(middleware.RequirePermission(auth.PermPayrollWrite, h.Perms)).Put("/recurring/{itemID}", h.handleUpdateRecurringItem)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/annual-statements", h.handleListAnnualStatements)
+		r.With(middleware.RequirePermission(auth.PermPayrollFinalize, h.Perms)).Post("/annual-statements/{year}/generate", h.handleGenerateAnnualStatements)
+		r.With(middleware.RequirePermission(auth.PermPayrollRead, h.Perms)).Get("/annual-statements/{statementID}/download", h.handleDownloadAnnualStatement)
 	})
 }
 
@@ -111,7 +147,30 @@ func (h *Handler) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
 		api.Fail(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", middleware.GetRequestID(r.Context()))
 		return
 	}
-	id, err := h.Service.CreateSchedule(r.Context(), user.TenantID, payload.Name, payload.Frequency, payload.PayDay)
+	payload.Frequency = strings.ToLower(strings.TrimSpace(payload.Frequency))
+	payload.HolidayRegion = strings.TrimSpace(payload.HolidayRegion)
+	validator := shared.NewValidator()
+	validator.Required("frequency", payload.Frequency, "is required")
+	validator.Enum("frequency", payload.Frequency, []string{
+		payroll.ScheduleFrequencyWeekly,
+		payroll.ScheduleFrequencyBiweekly,
+		payroll.ScheduleFrequencySemiMonthly,
+		payroll.ScheduleFrequencyMonthly,
+	}, "must be weekly, biweekly, semimonthly or monthly")
+	switch {
+	case payload.PayDay < 0:
+		validator.Add("payDay", "must be zero or greater")
+	case payload.Frequency == payroll.ScheduleFrequencyMonthly && payload.PayDay > 31:
+		validator.Add("payDay", "must be a day of the month between 1 and 31")
+	case (payload.Frequency == payroll.ScheduleFrequencyWeekly || payload.Frequency == payroll.ScheduleFrequencyBiweekly) && payload.PayDay > 7:
+		validator.Add("payDay", "must be an ISO weekday between 1 and 7")
+	case payload.Frequency == payroll.ScheduleFrequencySemiMonthly && payload.PayDay > 15:
+		validator.Add("payDay", "must be between 0 and 15 days after the period end")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+	id, err := h.Service.CreateSchedule(r.Context(), user.TenantID, payload.Name, payload.Frequency, payload.PayDay, payload.HolidayRegion)
 	if err != nil {
 		api.Fail(w, http.StatusInternalServerError, "payroll_schedule_create_failed", "failed to create schedule", middleware.GetRequestID(r.Context()))
 		return
@@ -202,6 +261,68 @@ func (h *Handler) handleCreateElement(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	payload.Name = strings.TrimSpace(payload.Name)
+	payload.ElementType = strings.ToLower(strings.TrimSpace(payload.ElementType))
+	payload.CalcType = strings.ToLower(strings.TrimSpace(payload.CalcType))
+	payload.Expression = strings.TrimSpace(payload.Expression)
+	if payload.CalcType == "" {
+		payload.CalcType = payroll.CalcTypeFixed
+	}
+
+	validator := shared.NewValidator()
+	validator.Required("name", payload.Name, "is required")
+	validator.Enum("elementType", payload.ElementType, []string{payroll.ElementTypeEarning, payroll.ElementTypeDeduction}, "must be one of: earning, deduction")
+	validator.Enum("calcType", payload.CalcType, []string{payroll.CalcTypeFixed, payroll.CalcTypePctOfBase, payroll.CalcTypeUnitsXRate, payroll.CalcTypePctOfGross, payroll.CalcTypeTiered, payroll.CalcTypeFormula}, "must be one of: fixed, pct_of_base, units_x_rate, pct_of_gross, tiered, formula")
+	if payload.Amount < 0 {
+		validator.Add("amount", "must be greater than or equal to 0")
+	}
+	if payload.Rate < 0 {
+		validator.Add("rate", "must be greater than or equal to 0")
+	}
+	if (payload.CalcType == payroll.CalcTypePctOfBase || payload.CalcType == payroll.CalcTypePctOfGross) && payload.Rate > 1 {
+		validator.Add("rate", "must be between 0 and 1 for percentage elements")
+	}
+	if payload.CalcType == payroll.CalcTypeTiered {
+		if len(payload.Tiers) == 0 {
+			validator.Add("tiers", "is required for tiered elements")
+		}
+		validateBrackets(validator, "tiers", payload.Tiers, false)
+	} else if len(payload.Tiers) > 0 {
+		validator.Add("tiers", "only applies to tiered elements")
+	}
+	if payload.CalcType == payroll.CalcTypeFormula {
+		if payload.Expression == "" {
+			validator.Add("expression", "is required for formula elements")
+		} else if err := payroll.ValidateExpression(payload.Expression); err != nil {
+			validator.Add("expression", err.Error())
+		}
+	} else if payload.Expression != "" {
+		validator.Add("expression", "only applies to formula elements")
+	}
+	if len(payload.DependsOn) > 0 {
+		if payload.CalcType != payroll.CalcTypePctOfGross && payload.CalcType != payroll.CalcTypeFormula {
+			validator.Add("dependsOn", "only applies to pct_of_gross and formula elements")
+		} else {
+			elements, err := h.Service.ListElements(r.Context(), user.TenantID)
+			if err != nil {
+				api.Fail(w, http.StatusInternalServerError, "payroll_element_create_failed", "failed to load elements", middleware.GetRequestID(r.Context()))
+				return
+			}
+			earnings := map[string]bool{}
+			for _, element := range elements {
+				earnings[element.ID] = element.ElementType == payroll.ElementTypeEarning
+			}
+			for i, dep := range payload.DependsOn {
+				if !earnings[dep] {
+					validator.Add("dependsOn["+strconv.Itoa(i)+"]", "must reference an existing earning element")
+				}
+			}
+		}
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
 	id, err := h.Service.CreateElement(r.Context(), user.TenantID, payload)
 	if err != nil {
 		api.Fail(w, http.StatusInternalServerError, "payroll_element_create_failed", "failed to create element", middleware.GetRequestID(r.Context()))
@@ -255,6 +376,23 @@ func (h *Handler) handleCreateJournalTemplate(w http.ResponseWriter, r *http.Req
 	if payload.Config == nil {
 		payload.Config = map[string]any{}
 	}
+	cfg, err := payroll.ParseJournalConfig(payload.Config)
+	if err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "config", Reason: "accounts and departments must map to strings and objects"},
+		})
+		return
+	}
+	validator := shared.NewValidator()
+	validator.Enum("config.format", cfg.Format, []string{payroll.JournalFormatCSV, payroll.JournalFormatXero, payroll.JournalFormatJSON}, "must be one of: csv, xero, json")
+	for key, account := range cfg.Accounts {
+		if strings.TrimSpace(account) == "" {
+			validator.Add("config.accounts."+key, "must not be empty")
+		}
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
 	id, err := h.Service.CreateJournalTemplate(r.Context(), user.TenantID, payload.Name, payload.Config)
 	if err != nil {
 		api.Fail(w, http.StatusInternalServerError, "journal_template_create_failed", "failed to create journal template", middleware.GetRequestID(r.Context()))
@@ -263,6 +401,112 @@ func (h *Handler) handleCreateJournalTemplate(w http.ResponseWriter, r *http.Req
 	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
 }
 
+func (h *Handler) handleListTaxRules(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if denyEmployeePayrollOperations(w, r, user.RoleName) {
+		return
+	}
+
+	rules, err := h.Service.ListTaxRules(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "tax_rule_list_failed", "failed to list tax rules", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, rules, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleCreateTaxRule(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload struct {
+		Code          string               `json:"code"`
+		Name          string               `json:"name"`
+		Kind          string               `json:"kind"`
+		EffectiveFrom string               `json:"effectiveFrom"`
+		Brackets      []payroll.TaxBracket `json:"brackets"`
+		Allowance     float64              `json:"allowance"`
+		EarningsCap   float64              `json:"earningsCap"`
+		EmployeeRate  float64              `json:"employeeRate"`
+		EmployerRate  float64              `json:"employerRate"`
+		PreTax        bool                 `json:"preTax"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+
+	payload.Code = strings.ToLower(strings.TrimSpace(payload.Code))
+	payload.Name = strings.TrimSpace(payload.Name)
+	payload.Kind = strings.ToLower(strings.TrimSpace(payload.Kind))
+
+	validator := shared.NewValidator()
+	validator.Required("code", payload.Code, "is required")
+	validator.Required("name", payload.Name, "is required")
+	validator.Required("kind", payload.Kind, "is required")
+	validator.Enum("kind", payload.Kind, []string{payroll.TaxKindIncomeTax, payroll.TaxKindSocialContribution}, "must be one of: income_tax, social_contribution")
+	effectiveFrom, _ := validator.Date("effectiveFrom", payload.EffectiveFrom)
+	if payload.Allowance < 0 {
+		validator.Add("allowance", "must be greater than or equal to 0")
+	}
+	if payload.EarningsCap < 0 {
+		validator.Add("earningsCap", "must be greater than or equal to 0")
+	}
+	if payload.EmployeeRate < 0 || payload.EmployeeRate > 1 {
+		validator.Add("employeeRate", "must be between 0 and 1")
+	}
+	if payload.EmployerRate < 0 || payload.EmployerRate > 1 {
+		validator.Add("employerRate", "must be between 0 and 1")
+	}
+	if payload.PreTax && payload.Kind != payroll.TaxKindSocialContribution {
+		validator.Add("preTax", "only applies to social_contribution rules")
+	}
+	validateBrackets(validator, "brackets", payload.Brackets, true)
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	rule := payroll.TaxRule{
+		Code:          payload.Code,
+		Name:          payload.Name,
+		Kind:          payload.Kind,
+		EffectiveFrom: effectiveFrom,
+		Brackets:      payload.Brackets,
+		Allowance:     payload.Allowance,
+		EarningsCap:   payload.EarningsCap,
+		EmployeeRate:  payload.EmployeeRate,
+		EmployerRate:  payload.EmployerRate,
+		PreTax:        payload.PreTax,
+	}
+	id, err := h.Service.CreateTaxRule(r.Context(), user.TenantID, rule)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			api.Fail(w, http.StatusConflict, "tax_rule_exists", "a tax rule with this code is already effective from this date", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "tax_rule_create_failed", "failed to create tax rule", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.tax_rule.create", "payroll_tax_rule", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, payload); err != nil {
+		slog.Warn("audit payroll.tax_rule.create failed", "err", err)
+	}
+	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
+
 func (h *Handler) handleListPeriods(w http.ResponseWriter, r *http.Request) {
 	user, ok := middleware.GetUser(r.Context())
 	if !ok {
@@ -393,8 +637,13 @@ func (h *Handler) handleCreateInput(w http.ResponseWriter, r *http.Request) {
 	if payload.Rate < 0 {
 		validator.Add("rate", "must be greater than or equal to 0")
 	}
-	if payload.Amount <= 0 {
-		validator.Add("amount", "must be greater than 0")
+	calcTypes, err := h.elementCalcTypes(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "payroll_input_failed", "failed to load elements", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if payload.ElementID != "" {
+		validateInputForElement(validator, "", calcTypes, payload)
 	}
 	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
 		return
@@ -408,7 +657,43 @@ func (h *Handler) handleCreateInput(w http.ResponseWriter, r *http.Request) {
 	api.Created(w, map[string]string{"status": "input_added"}, middleware.GetRequestID(r.Context()))
 }
 
+func (h *Handler) handlePreviewPayroll(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	periodID := chi.URLParam(r, "periodID")
+	preview, err := h.Service.PreviewPeriod(r.Context(), user.TenantID, periodID)
+	if err != nil {
+		if isNoRowsError(err) {
+			api.Fail(w, http.StatusNotFound, "not_found", "payroll period not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		if errors.Is(err, payroll.ErrExchangeRateMissing) {
+			api.Fail(w, http.StatusUnprocessableEntity, "exchange_rate_missing", err.Error(), middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "payroll_preview_failed", "failed to preview payroll", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, preview, middleware.GetRequestID(r.Context()))
+}
+
 func (h *Handler) handleRunPayroll(w http.ResponseWriter, r *http.Request) {
+	h.startPayrollRun(w, r, "")
+}
+
+func (h *Handler) handleResumePayrollRun(w http.ResponseWriter, r *http.Request) {
+	h.startPayrollRun(w, r, chi.URLParam(r, "runID"))
+}
+
+func (h *Handler) startPayrollRun(w http.ResponseWriter, r *http.Request, resumeRunID string) {
 	user, ok := middleware.GetUser(r.Context())
 	if !ok {
 		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
@@ -430,154 +715,78 @@ func (h *Handler) handleRunPayroll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jobID := ""
-	if h.Jobs != nil {
-		if id, err := h.Service.CreateJobRun(r.Context(), user.TenantID, "payroll_run"); err != nil {
-			slog.Warn("payroll job run insert failed", "err", err)
-		} else {
-			jobID = id
+	var previous *payroll.RunProgress
+	if resumeRunID != "" {
+		previous, err = h.Service.ResumableRun(r.Context(), user.TenantID, periodID, resumeRunID)
+		if err != nil {
+			switch {
+			case isNoRowsError(err):
+				api.Fail(w, http.StatusNotFound, "not_found", "payroll run not found", middleware.GetRequestID(r.Context()))
+			case errors.Is(err, payroll.ErrRunNotResumable):
+				api.Fail(w, http.StatusConflict, "invalid_state", "only failed runs for this period can be resumed", middleware.GetRequestID(r.Context()))
+			default:
+				api.Fail(w, http.StatusInternalServerError, "payroll_run_failed", "failed to load payroll run", middleware.GetRequestID(r.Context()))
+			}
+			return
 		}
 	}
 
-	employees, err := h.Service.ListActiveEmployeesForRun(r.Context(), user.TenantID, core.EmployeeStatusActive)
-	if err != nil {
-		api.Fail(w, http.StatusInternalServerError, "payroll_run_failed", "failed to load employees", middleware.GetRequestID(r.Context()))
+	if activeRunID, err := h.Service.ActivePayrollRunID(r.Context(), user.TenantID, periodID); err == nil && activeRunID != "" {
+		api.FailWithDetails(w, http.StatusConflict, "payroll_run_in_progress", "payroll run already in progress", map[string]string{"runId": activeRunID}, middleware.GetRequestID(r.Context()))
+		return
+	} else if err != nil && !isNoRowsError(err) {
+		api.Fail(w, http.StatusInternalServerError, "payroll_run_failed", "failed to check payroll runs", middleware.GetRequestID(r.Context()))
 		return
 	}
-	processed := 0
-	for _, employee := range employees {
-		employeeID := employee.EmployeeID
-		if employee.GroupScheduleID != "" && employee.GroupScheduleID != periodDetails.ScheduleID {
-			continue
-		}
 
-		salary := 0.0
-		if employee.SalaryPlain != nil {
-			salary = *employee.SalaryPlain
-		}
-		if h.Crypto != nil && h.Crypto.Configured() && len(employee.SalaryEnc) > 0 {
-			if decrypted, err := h.Crypto.DecryptString(employee.SalaryEnc); err == nil {
-				if parsed, err := strconv.ParseFloat(decrypted, 64); err == nil {
-					salary = parsed
-				}
-			}
-		}
-		bankAccount := employee.BankPlain
-		if h.Crypto != nil && h.Crypto.Configured() && len(employee.BankEnc) > 0 {
-			if decrypted, err := h.Crypto.DecryptString(employee.BankEnc); err == nil {
-				bankAccount = decrypted
-			}
+	// The job first moves the period back to draft so it cannot be finalized
+	// against a half-recalculated set of results while it runs. Any earlier
+	// approval no longer applies to the new results. Doing this in the job
+	// leaves the period untouched when the run cannot be queued.
+	tenantID, userID := user.TenantID, user.UserID
+	run := func(ctx context.Context, progress func(any)) (any, error) {
+		if err := h.Service.MarkPeriodRun(ctx, tenantID, periodID, userID, previous != nil); err != nil {
+			return nil, err
 		}
+		return h.Service.RunPeriod(ctx, tenantID, periodID, previous, func(p payroll.RunProgress) {
+			progress(p)
+		})
+	}
+	initial := payroll.RunProgress{PeriodID: periodID, Employees: map[string]payroll.EmployeeRunStatus{}}
+	if previous != nil {
+		initial.ResumedFrom = previous.ResumedFrom
+	}
 
-		inputs, err := h.Service.ListInputLines(r.Context(), periodID, employeeID)
+	if h.Jobs == nil {
+		result, err := run(r.Context(), func(any) {})
 		if err != nil {
-			api.Fail(w, http.StatusInternalServerError, "payroll_run_failed", "failed to load inputs", middleware.GetRequestID(r.Context()))
+			api.FailWithDetails(w, http.StatusInternalServerError, "payroll_run_failed", "payroll run failed", result, middleware.GetRequestID(r.Context()))
 			return
 		}
+		api.Success(w, map[string]any{"status": payroll.PeriodStatusReviewed, "progress": result}, middleware.GetRequestID(r.Context()))
+		return
+	}
 
-		adjustments, err := h.Service.ListAdjustmentAmounts(r.Context(), user.TenantID, periodID, employeeID, periodDetails.StartDate, periodDetails.EndDate)
-		if err == nil {
-			for _, amount := range adjustments {
-				if amount >= 0 {
-					inputs = append(inputs, payroll.InputLine{Type: payroll.ElementTypeEarning, Amount: amount})
-				} else {
-					inputs = append(inputs, payroll.InputLine{Type: payroll.ElementTypeDeduction, Amount: -amount})
-				}
-			}
-		}
-
-		var unpaidDays float64
-		leaveWindows, err := h.Service.ListUnpaidLeaves(r.Context(), user.TenantID, employeeID, periodDetails.StartDate, periodDetails.EndDate, leave.StatusApproved)
-		if err == nil {
-			for _, leaveWindow := range leaveWindows {
-				overlapStart := leaveWindow.StartDate
-				if periodDetails.StartDate.After(overlapStart) {
-					overlapStart = periodDetails.StartDate
-				}
-				overlapEnd := leaveWindow.EndDate
-				if periodDetails.EndDate.Before(overlapEnd) {
-					overlapEnd = periodDetails.EndDate
-				}
-				days, err := leave.CalculateDays(overlapStart, overlapEnd)
-				if err != nil {
-					continue
-				}
-				if leaveWindow.StartHalf && overlapStart.Equal(leaveWindow.StartDate) {
-					days -= 0.5
-				}
-				if leaveWindow.EndHalf && overlapEnd.Equal(leaveWindow.EndDate) {
-					days -= 0.5
-				}
-				if days > 0 {
-					unpaidDays += days
-				}
-			}
-		}
-
-		if unpaidDays > 0 && salary > 0 {
-			periodDays, err := leave.CalculateDays(periodDetails.StartDate, periodDetails.EndDate)
-			if err == nil && periodDays > 0 {
-				deduction := (salary / periodDays) * unpaidDays
-				if deduction > 0 {
-					inputs = append(inputs, payroll.InputLine{Type: payroll.ElementTypeDeduction, Amount: deduction})
-				}
-			}
-		}
-
-		gross, deductions, net := payroll.ComputePayroll(salary, inputs)
-
-		var warnings []string
-		if bankAccount == "" {
-			warnings = append(warnings, payroll.WarningMissingBank)
-		}
-		if net < 0 {
-			warnings = append(warnings, payroll.WarningNegativeNet)
-		}
-		previousNet, err := h.Service.LatestNet(r.Context(), user.TenantID, employeeID)
-		if err != nil {
-			slog.Warn("previous net lookup failed", "err", err)
-		}
-		if previousNet > 0 {
-			diff := net - previousNet
-			if diff < 0 {
-				diff = -diff
-			}
-			if diff/previousNet > 0.5 {
-				warnings = append(warnings, payroll.WarningNetVariance)
-			}
-		}
-		warningsJSON, err := json.Marshal(warnings)
-		if err != nil {
-			slog.Warn("warnings marshal failed", "err", err)
-			warningsJSON = []byte("[]")
+	runID, err := h.Jobs.EnqueueTracked(r.Context(), payroll.JobTypePayrollRun, tenantID, initial, run)
+	if err != nil {
+		if errors.Is(err, jobs.ErrQueueFull) {
+			api.Fail(w, http.StatusServiceUnavailable, "job_queue_full", "job queue is full, try again shortly", middleware.GetRequestID(r.Context()))
+			return
 		}
-
-		if err := h.Service.UpsertPayrollResult(r.Context(), user.TenantID, periodID, employeeID, gross, deductions, net, employee.Currency, warningsJSON); err != nil {
-			api.Fail(w, http.StatusInternalServerError, "payroll_run_failed", "failed to persist payroll results", middleware.GetRequestID(r.Context()))
+		if errors.Is(err, jobs.ErrRunActive) {
+			activeRunID, _ := h.Service.ActivePayrollRunID(r.Context(), tenantID, periodID)
+			api.FailWithDetails(w, http.StatusConflict, "payroll_run_in_progress", "payroll run already in progress", map[string]string{"runId": activeRunID}, middleware.GetRequestID(r.Context()))
 			return
 		}
-		processed++
-	}
-
-	if err := h.Service.UpdatePeriodStatus(r.Context(), user.TenantID, periodID, payroll.PeriodStatusReviewed); err != nil {
-		api.Fail(w, http.StatusInternalServerError, "payroll_run_failed", "failed to update payroll period", middleware.GetRequestID(r.Context()))
+		api.Fail(w, http.StatusInternalServerError, "payroll_run_failed", "failed to queue payroll run", middleware.GetRequestID(r.Context()))
 		return
 	}
-	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.run", "payroll_period", periodID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, nil); err != nil {
-		slog.Warn("audit payroll.run failed", "err", err)
-	}
 
-	if jobID != "" {
-		details := map[string]any{
-			"processed": processed,
-			"periodId":  periodID,
-		}
-		if err := h.Service.UpdateJobRun(r.Context(), jobID, "completed", details); err != nil {
-			slog.Warn("payroll job run update failed", "err", err)
-		}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.run", "payroll_period", periodID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, map[string]string{"runId": runID, "resumedFrom": resumeRunID}); err != nil {
+		slog.Warn("audit payroll.run failed", "err", err)
 	}
 
-	api.Success(w, map[string]string{"status": payroll.PeriodStatusReviewed}, middleware.GetRequestID(r.Context()))
+	api.Accepted(w, map[string]string{"runId": runID, "status": "queued"}, middleware.GetRequestID(r.Context()))
 }
 
 func (h *Handler) handleFinalizePayroll(w http.ResponseWriter, r *http.Request) {
@@ -773,6 +982,12 @@ func (h *Handler) handleImportInputs(w http.ResponseWriter, r *http.Request) {
 		return ""
 	}
 
+	calcTypes, err := h.elementCalcTypes(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "payroll_input_failed", "failed to load elements", middleware.GetRequestID(r.Context()))
+		return
+	}
+
 	validator := shared.NewValidator()
 	insertRows := make([]payroll.Input, 0, 32)
 	rowNumber := 1
@@ -841,18 +1056,19 @@ func (h *Handler) handleImportInputs(w http.ResponseWriter, r *http.Request) {
 		if amount == 0 && units > 0 {
 			amount = units * rate
 		}
-		if amount <= 0 {
-			validator.Add("rows["+strconv.Itoa(rowNumber)+"].amount", "must be greater than 0")
-		}
 
-		insertRows = append(insertRows, payroll.Input{
+		input := payroll.Input{
 			EmployeeID: employeeID,
 			ElementID:  elementID,
 			Units:      units,
 			Rate:       rate,
 			Amount:     amount,
 			Source:     source,
-		})
+		}
+		if elementID != "" {
+			validateInputForElement(validator, "rows["+strconv.Itoa(rowNumber)+"].", calcTypes, input)
+		}
+		insertRows = append(insertRows, input)
 	}
 	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
 		return
@@ -985,12 +1201,53 @@ func (h *Handler) handlePeriodSummary(w http.ResponseWriter, r *http.Request) {
 
 	periodID := chi.URLParam(r, "periodID")
 	summary, err := h.Service.PeriodSummary(r.Context(), user.TenantID, periodID)
+	if errors.Is(err, payroll.ErrExchangeRateMissing) {
+		api.Fail(w, http.StatusUnprocessableEntity, "exchange_rate_missing", err.Error(), middleware.GetRequestID(r.Context()))
+		return
+	}
 	if err != nil {
 		slog.Warn("period summary totals query failed", "err", err)
 	}
 	api.Success(w, summary, middleware.GetRequestID(r.Context()))
 }
 
+func (h *Handler) handleEmployeeResult(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	periodID := chi.URLParam(r, "periodID")
+	employeeID := chi.URLParam(r, "employeeID")
+	if user.RoleName != auth.RoleHR {
+		selfEmployeeID, err := h.Service.FindEmployeeIDByUserID(r.Context(), user.TenantID, user.UserID)
+		if err != nil && !isNoRowsError(err) {
+			slog.Warn("payroll result self employee lookup failed", "err", err)
+		}
+		if selfEmployeeID == "" || selfEmployeeID != employeeID {
+			api.Fail(w, http.StatusForbidden, "forbidden", "employee can only access own payroll results", middleware.GetRequestID(r.Context()))
+			return
+		}
+		status, err := h.Service.PeriodStatus(r.Context(), user.TenantID, periodID)
+		if err != nil || status != payroll.PeriodStatusFinalized {
+			api.Fail(w, http.StatusNotFound, "not_found", "payroll result not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+	}
+
+	result, err := h.Service.EmployeeResult(r.Context(), user.TenantID, periodID, employeeID)
+	if err != nil {
+		if isNoRowsError(err) {
+			api.Fail(w, http.StatusNotFound, "not_found", "payroll result not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "payroll_result_failed", "failed to load payroll result", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, result, middleware.GetRequestID(r.Context()))
+}
+
 func (h *Handler) handleReopenPeriod(w http.ResponseWriter, r *http.Request) {
 	user, ok := middleware.GetUser(r.Context())
 	if !ok {
@@ -1014,18 +1271,19 @@ func (h *Handler) handleReopenPeriod(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currentStatus, err := h.Service.PeriodStatus(r.Context(), user.TenantID, periodID)
-	if err != nil {
-		api.Fail(w, http.StatusNotFound, "not_found", "payroll period not found", middleware.GetRequestID(r.Context()))
-		return
-	}
-	if currentStatus != payroll.PeriodStatusFinalized {
-		api.Fail(w, http.StatusBadRequest, "invalid_state", "only finalized periods can be reopened", middleware.GetRequestID(r.Context()))
-		return
-	}
-
-	if err := h.Service.UpdatePeriodStatus(r.Context(), user.TenantID, periodID, payroll.PeriodStatusDraft); err != nil {
-		api.Fail(w, http.StatusInternalServerError, "payroll_reopen_failed", "failed to reopen payroll", middleware.GetRequestID(r.Context()))
+	if err := h.Service.ReopenPeriod(r.Context(), user.TenantID, periodID); err != nil {
+		switch {
+		case errors.Is(err, payroll.ErrPeriodNotFound):
+			api.Fail(w, http.StatusNotFound, "not_found", "payroll period not found", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrReopenInvalidState):
+			api.Fail(w, http.StatusBadRequest, "invalid_state", err.Error(), middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrReopenHasOffCycle):
+			api.Fail(w, http.StatusConflict, "off_cycle_finalized", err.Error(), middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrReopenHasRetro):
+			api.Fail(w, http.StatusConflict, "retro_settled", err.Error(), middleware.GetRequestID(r.Context()))
+		default:
+			api.Fail(w, http.StatusInternalServerError, "payroll_reopen_failed", "failed to reopen payroll", middleware.GetRequestID(r.Context()))
+		}
 		return
 	}
 	if err := h.Service.DeleteResultsForPeriod(r.Context(), user.TenantID, periodID); err != nil {
@@ -1060,12 +1318,13 @@ func (h *Handler) handleExportRegister(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", "attachment; filename=payroll-register.csv")
+	headers, records := payroll.RegisterTable(rows)
 	writer := csv.NewWriter(w)
-	if err := writer.Write([]string{"employee_id", "first_name", "last_name", "gross", "deductions", "net", "currency"}); err != nil {
+	if err := writer.Write(headers); err != nil {
 		slog.Warn("export register header write failed", "err", err)
 	}
-	for _, row := range rows {
-		if err := writer.Write([]string{row.EmployeeID, row.FirstName, row.LastName, fmt.Sprintf("%.2f", row.Gross), fmt.Sprintf("%.2f", row.Deductions), fmt.Sprintf("%.2f", row.Net), row.Currency}); err != nil {
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
 			slog.Warn("export register row write failed", "err", err)
 		}
 	}
@@ -1086,55 +1345,74 @@ func (h *Handler) handleExportJournal(w http.ResponseWriter, r *http.Request) {
 	}
 
 	periodID := chi.URLParam(r, "periodID")
-	gross, deductions, net, err := h.Service.PeriodTotals(r.Context(), user.TenantID, periodID)
+	templateID := strings.TrimSpace(r.URL.Query().Get("templateId"))
+	journal, cfg, err := h.Service.PeriodJournal(r.Context(), user.TenantID, periodID, templateID)
 	if err != nil {
-		api.Fail(w, http.StatusInternalServerError, "export_failed", "failed to export journal", middleware.GetRequestID(r.Context()))
+		switch {
+		case errors.Is(err, payroll.ErrJournalTemplateNotFound):
+			api.Fail(w, http.StatusNotFound, "not_found", "journal template not found", middleware.GetRequestID(r.Context()))
+		case isNoRowsError(err):
+			api.Fail(w, http.StatusNotFound, "not_found", "payroll period not found", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrJournalUnbalanced):
+			api.Fail(w, http.StatusUnprocessableEntity, "journal_unbalanced", err.Error(), middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrExchangeRateMissing):
+			api.Fail(w, http.StatusUnprocessableEntity, "exchange_rate_missing", err.Error(), middleware.GetRequestID(r.Context()))
+		default:
+			api.Fail(w, http.StatusInternalServerError, "export_failed", "failed to export journal", middleware.GetRequestID(r.Context()))
+		}
 		return
 	}
 
-	expenseAccount := "Payroll Expense"
-	deductionAccount := "Payroll Deductions"
-	cashAccount := "Payroll Cash"
-	headers := []string{"account", "debit", "credit"}
-	if templateID := r.URL.Query().Get("templateId"); templateID != "" {
-		if cfg, err := h.Service.JournalTemplateConfig(r.Context(), user.TenantID, templateID); err == nil {
-			if val, ok := cfg["expenseAccount"].(string); ok && val != "" {
-				expenseAccount = val
-			}
-			if val, ok := cfg["deductionAccount"].(string); ok && val != "" {
-				deductionAccount = val
-			}
-			if val, ok := cfg["cashAccount"].(string); ok && val != "" {
-				cashAccount = val
-			}
-			if rawHeaders, ok := cfg["headers"].([]any); ok && len(rawHeaders) > 0 {
-				headers = []string{}
-				for _, header := range rawHeaders {
-					if hs, ok := header.(string); ok {
-						headers = append(headers, hs)
-					}
-				}
-				if len(headers) == 0 {
-					headers = []string{"account", "debit", "credit"}
-				}
-			}
+	format := cfg.Format
+	if raw := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))); raw != "" {
+		format = raw
+	}
+	validator := shared.NewValidator()
+	validator.Enum("format", format, []string{payroll.JournalFormatCSV, payroll.JournalFormatXero, payroll.JournalFormatJSON}, "must be one of: csv, xero, json")
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	export := payroll.JournalExport{
+		PeriodID:    periodID,
+		TemplateID:  templateID,
+		Format:      format,
+		Currency:    journal.Currency,
+		TotalDebit:  journal.TotalDebit,
+		TotalCredit: journal.TotalCredit,
+		LineCount:   len(journal.Lines),
+		ExportedBy:  user.UserID,
+	}
+	exportID, err := h.Service.CreateJournalExport(r.Context(), user.TenantID, export)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "export_failed", "failed to record journal export", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.journal.export", "journal_export", exportID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, export); err != nil {
+		slog.Warn("audit payroll.journal.export failed", "err", err)
+	}
+	w.Header().Set("X-Journal-Export-Id", exportID)
+
+	if format == payroll.JournalFormatJSON {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", "attachment; filename=payroll-journal.json")
+		if err := json.NewEncoder(w).Encode(journal); err != nil {
+			slog.Warn("export journal json write failed", "err", err)
 		}
+		return
 	}
 
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", "attachment; filename=payroll-journal.csv")
+	headers, records := payroll.JournalTable(journal, format, cfg.Headers)
 	writer := csv.NewWriter(w)
 	if err := writer.Write(headers); err != nil {
 		slog.Warn("export journal header write failed", "err", err)
 	}
-	if err := writer.Write([]string{expenseAccount, fmt.Sprintf("%.2f", gross), ""}); err != nil {
-		slog.Warn("export journal expense row write failed", "err", err)
-	}
-	if err := writer.Write([]string{deductionAccount, "", fmt.Sprintf("%.2f", deductions)}); err != nil {
-		slog.Warn("export journal deductions row write failed", "err", err)
-	}
-	if err := writer.Write([]string{cashAccount, "", fmt.Sprintf("%.2f", net)}); err != nil {
-		slog.Warn("export journal cash row write failed", "err", err)
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			slog.Warn("export journal row write failed", "err", err)
+		}
 	}
 	writer.Flush()
 	if err := writer.Error(); err != nil {
@@ -1142,6 +1420,24 @@ func (h *Handler) handleExportJournal(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *Handler) handleListJournalExports(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if denyEmployeePayrollOperations(w, r, user.RoleName) {
+		return
+	}
+
+	exports, err := h.Service.ListJournalExports(r.Context(), user.TenantID, chi.URLParam(r, "periodID"))
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "journal_export_list_failed", "failed to list journal exports", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, exports, middleware.GetRequestID(r.Context()))
+}
+
 func (h *Handler) handleDownloadPayslip(w http.ResponseWriter, r *http.Request) {
 	user, ok := middleware.GetUser(r.Context())
 	if !ok {
@@ -1243,6 +1539,65 @@ func (h *Handler) handleRegeneratePayslip(w http.ResponseWriter, r *http.Request
 	api.Success(w, map[string]string{"status": "regenerated"}, middleware.GetRequestID(r.Context()))
 }
 
+func (h *Handler) elementCalcTypes(ctx context.Context, tenantID string) (map[string]string, error) {
+	elements, err := h.Service.ListElements(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	calcTypes := make(map[string]string, len(elements))
+	for _, element := range elements {
+		calcTypes[element.ID] = element.CalcType
+	}
+	return calcTypes, nil
+}
+
+// validateInputForElement checks the figures an input must carry for its
+// element's formula: fixed elements need an amount, unit-based ones need units.
+func validateInputForElement(validator *shared.Validator, prefix string, calcTypes map[string]string, input payroll.Input) {
+	calcType, ok := calcTypes[input.ElementID]
+	if !ok {
+		validator.Add(prefix+"elementId", "must reference an existing element")
+		return
+	}
+	switch calcType {
+	case payroll.CalcTypeUnitsXRate, payroll.CalcTypeTiered:
+		if input.Units <= 0 {
+			validator.Add(prefix+"units", "must be greater than 0 for "+calcType+" elements")
+		}
+	case payroll.CalcTypePctOfBase, payroll.CalcTypePctOfGross:
+	default:
+		if input.Amount <= 0 {
+			validator.Add(prefix+"amount", "must be greater than 0")
+		}
+	}
+}
+
+// validateBrackets checks that brackets ascend and only the last is open-ended.
+// Tax rates are fractions; element tiers may pay more than 1 per unit.
+func validateBrackets(validator *shared.Validator, field string, brackets []payroll.TaxBracket, fractionalRates bool) {
+	previousUpTo := 0.0
+	for i, bracket := range brackets {
+		itemField := field + "[" + strconv.Itoa(i) + "]"
+		if bracket.Rate < 0 || (fractionalRates && bracket.Rate > 1) {
+			if fractionalRates {
+				validator.Add(itemField+".rate", "must be between 0 and 1")
+			} else {
+				validator.Add(itemField+".rate", "must be greater than or equal to 0")
+			}
+		}
+		if bracket.UpTo == nil {
+			if i != len(brackets)-1 {
+				validator.Add(itemField+".upTo", "only the last bracket may be open-ended")
+			}
+			continue
+		}
+		if *bracket.UpTo <= previousUpTo {
+			validator.Add(itemField+".upTo", "must be greater than the previous bracket")
+		}
+		previousUpTo = *bracket.UpTo
+	}
+}
+
 func nullIfEmpty(value string) any {
 	if strings.TrimSpace(value) == "" {
 		return nil
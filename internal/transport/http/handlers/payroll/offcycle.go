@@ -0,0 +1,110 @@
+package payrollhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/payroll"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type offCyclePeriodPayload struct {
+	RunType          string   `json:"runType"`
+	OriginalPeriodID string   `json:"originalPeriodId"`
+	Reason           string   `json:"reason"`
+	PayDate          string   `json:"payDate"`
+	EmployeeIDs      []string `json:"employeeIds"`
+}
+
+// handleCreateOffCyclePeriod opens a bonus, termination or correction run
+// against a finalized period. The new period is run, reviewed and finalized on
+// its own and never changes the original period's results or payslips.
+func (h *Handler) handleCreateOffCyclePeriod(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload offCyclePeriodPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+
+	request := payroll.OffCycleRequest{
+		RunType:          strings.ToLower(strings.TrimSpace(payload.RunType)),
+		OriginalPeriodID: strings.TrimSpace(payload.OriginalPeriodID),
+		Reason:           strings.TrimSpace(payload.Reason),
+	}
+	seen := map[string]bool{}
+	for _, employeeID := range payload.EmployeeIDs {
+		employeeID = strings.TrimSpace(employeeID)
+		if employeeID != "" && !seen[employeeID] {
+			seen[employeeID] = true
+			request.EmployeeIDs = append(request.EmployeeIDs, employeeID)
+		}
+	}
+
+	validator := shared.NewValidator()
+	validator.Required("runType", request.RunType, "is required")
+	validator.Enum("runType", request.RunType, []string{payroll.PeriodRunBonus, payroll.PeriodRunTermination, payroll.PeriodRunCorrection}, "must be one of: bonus, termination, correction")
+	validator.Required("originalPeriodId", request.OriginalPeriodID, "is required")
+	validator.Required("reason", request.Reason, "is required")
+	if _, err := uuid.Parse(request.OriginalPeriodID); request.OriginalPeriodID != "" && err != nil {
+		validator.Add("originalPeriodId", "must be a UUID")
+	}
+	if len(request.EmployeeIDs) == 0 {
+		validator.Add("employeeIds", "must list at least one employee")
+	}
+	for _, employeeID := range request.EmployeeIDs {
+		if _, err := uuid.Parse(employeeID); err != nil {
+			validator.Add("employeeIds", "must list employee UUIDs")
+			break
+		}
+	}
+	if raw := strings.TrimSpace(payload.PayDate); raw != "" {
+		if payDate, ok := validator.Date("payDate", raw); ok {
+			request.PayDate = &payDate
+		}
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	id, err := h.Service.CreateOffCyclePeriod(r.Context(), user.TenantID, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, payroll.ErrPeriodNotFound):
+			api.Fail(w, http.StatusNotFound, "not_found", "original payroll period not found", middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrOffCycleOriginal):
+			api.Fail(w, http.StatusBadRequest, "invalid_state", err.Error(), middleware.GetRequestID(r.Context()))
+		case errors.Is(err, payroll.ErrOffCycleEmployees):
+			shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+				{Field: "employeeIds", Reason: "must reference employees of this tenant"},
+			})
+		default:
+			api.Fail(w, http.StatusInternalServerError, "payroll_period_create_failed", "failed to create off-cycle period", middleware.GetRequestID(r.Context()))
+		}
+		return
+	}
+
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.period.create_off_cycle", "payroll_period", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, payload); err != nil {
+		slog.Warn("audit payroll.period.create_off_cycle failed", "err", err)
+	}
+	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
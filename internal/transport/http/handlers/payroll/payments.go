@@ -0,0 +1,208 @@
+package payrollhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/payroll"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+func (h *Handler) handleGetPaymentSettings(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	settings, err := h.Service.PaymentSettings(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "payment_settings_failed", "failed to load payment settings", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, settings, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleUpdatePaymentSettings(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload payroll.PaymentSettings
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+
+	payload.DebtorName = strings.TrimSpace(payload.DebtorName)
+	payload.DebtorIBAN = payroll.NormalizeIBAN(payload.DebtorIBAN)
+	payload.DebtorBIC = strings.ToUpper(strings.TrimSpace(payload.DebtorBIC))
+	payload.CompanyID = strings.TrimSpace(payload.CompanyID)
+	payload.RoutingNumber = strings.TrimSpace(payload.RoutingNumber)
+	payload.AccountNumber = strings.TrimSpace(payload.AccountNumber)
+	payload.DestinationName = strings.TrimSpace(payload.DestinationName)
+
+	validator := shared.NewValidator()
+	validator.Required("debtorName", payload.DebtorName, "is required")
+	if payload.DebtorIBAN != "" && !payroll.ValidIBAN(payload.DebtorIBAN) {
+		validator.Add("debtorIban", "must be a valid IBAN")
+	}
+	if payload.DebtorBIC != "" && !payroll.ValidBIC(payload.DebtorBIC) {
+		validator.Add("debtorBic", "must be a valid BIC")
+	}
+	if payload.RoutingNumber != "" && !payroll.ValidRoutingNumber(payload.RoutingNumber) {
+		validator.Add("routingNumber", "must be a valid 9-digit ABA routing number")
+	}
+	if payload.AccountNumber != "" && !payroll.ValidAccountNumber(payload.AccountNumber) {
+		validator.Add("accountNumber", "must be 1-17 letters or digits")
+	}
+	if len(payload.CompanyID) > 10 {
+		validator.Add("companyId", "must be at most 10 characters")
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	if err := h.Service.UpsertPaymentSettings(r.Context(), user.TenantID, payload); err != nil {
+		api.Fail(w, http.StatusInternalServerError, "payment_settings_failed", "failed to save payment settings", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.payment_settings.update", "payroll_payment_settings", user.TenantID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, payload.Masked()); err != nil {
+		slog.Warn("audit payroll.payment_settings.update failed", "err", err)
+	}
+	api.Success(w, payload.Masked(), middleware.GetRequestID(r.Context()))
+}
+
+// handleExportPayments builds a bank payment file for a finalized period.
+// Employees who cannot be paid are returned as exceptions; the file is only
+// produced without them when the caller passes allowPartial=true.
+func (h *Handler) handleExportPayments(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	periodID := chi.URLParam(r, "periodID")
+	query := r.URL.Query()
+	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
+	validator := shared.NewValidator()
+	validator.Required("format", format, "is required")
+	validator.Enum("format", format, []string{payroll.PaymentFormatSEPA, payroll.PaymentFormatNACHA}, "must be one of: sepa, nacha")
+	executionDate := time.Now().UTC().AddDate(0, 0, 1)
+	if raw := strings.TrimSpace(query.Get("executionDate")); raw != "" {
+		if parsed, ok := validator.Date("executionDate", raw); ok {
+			executionDate = parsed
+		}
+	}
+	allowPartial := false
+	if raw := query.Get("allowPartial"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			validator.Add("allowPartial", "must be true or false")
+		}
+		allowPartial = parsed
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	status, err := h.Service.PeriodStatus(r.Context(), user.TenantID, periodID)
+	if err != nil {
+		api.Fail(w, http.StatusNotFound, "not_found", "payroll period not found", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if status != payroll.PeriodStatusFinalized {
+		api.Fail(w, http.StatusBadRequest, "invalid_state", "payment files require a finalized period", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	settings, err := h.Service.PaymentFileSettings(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "export_failed", "failed to load payment settings", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if missing := settings.MissingFields(format); len(missing) > 0 {
+		api.FailWithDetails(w, http.StatusUnprocessableEntity, "payment_settings_incomplete", payroll.ErrPaymentSettings.Error(), map[string]any{"missing": missing}, middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	batch, err := h.Service.PaymentBatch(r.Context(), user.TenantID, periodID, format)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "export_failed", "failed to export payments", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if len(batch.Exceptions) > 0 && !allowPartial {
+		api.FailWithDetails(w, http.StatusUnprocessableEntity, "payment_exceptions", "some employees cannot be paid", map[string]any{"exceptions": batch.Exceptions}, middleware.GetRequestID(r.Context()))
+		return
+	}
+	if len(batch.Payments) == 0 {
+		api.FailWithDetails(w, http.StatusUnprocessableEntity, "no_payments", "no employees can be paid", map[string]any{"exceptions": batch.Exceptions}, middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	now := time.Now().UTC()
+	var content []byte
+	var contentType, filename string
+	switch format {
+	case payroll.PaymentFormatSEPA:
+		periodRef := strings.ReplaceAll(periodID, "-", "")
+		if len(periodRef) > 8 {
+			periodRef = periodRef[:8]
+		}
+		messageID := fmt.Sprintf("PAY-%s-%s", now.Format("20060102150405"), periodRef)
+		content, err = payroll.BuildSEPA(settings, batch.Payments, messageID, "Salary "+executionDate.Format("2006-01"), executionDate, now)
+		contentType, filename = "application/xml", "payroll-payments.xml"
+	case payroll.PaymentFormatNACHA:
+		content, err = payroll.BuildNACHA(settings, batch.Payments, executionDate, now)
+		contentType, filename = "text/plain", "payroll-payments.ach"
+	}
+	if errors.Is(err, payroll.ErrPaymentRoutingNumber) {
+		api.FailWithDetails(w, http.StatusUnprocessableEntity, "payment_settings_invalid", err.Error(), map[string]any{"invalid": []string{"routingNumber"}}, middleware.GetRequestID(r.Context()))
+		return
+	}
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "export_failed", "failed to export payments", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	details := map[string]any{"format": format, "payments": len(batch.Payments), "exceptions": batch.Exceptions}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.payments.export", "payroll_period", periodID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, details); err != nil {
+		slog.Warn("audit payroll.payments.export failed", "err", err)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+	w.Header().Set("X-Payment-Count", strconv.Itoa(len(batch.Payments)))
+	w.Header().Set("X-Payment-Excluded", strconv.Itoa(len(batch.Exceptions)))
+	if _, err := w.Write(content); err != nil {
+		slog.Warn("export payments write failed", "err", err)
+	}
+}
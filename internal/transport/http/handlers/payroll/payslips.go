@@ -0,0 +1,160 @@
+package payrollhandler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/payroll"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type payslipTemplatePayload struct {
+	Name              string `json:"name"`
+	CompanyName       string `json:"companyName"`
+	CompanyAddress    string `json:"companyAddress"`
+	Logo              string `json:"logo"`
+	RemoveLogo        bool   `json:"removeLogo"`
+	Footer            string `json:"footer"`
+	ShowLeaveBalances *bool  `json:"showLeaveBalances"`
+	ShowYTD           *bool  `json:"showYtd"`
+	IsDefault         bool   `json:"isDefault"`
+}
+
+func (h *Handler) handleListPayslipTemplates(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if denyEmployeePayrollOperations(w, r, user.RoleName) {
+		return
+	}
+
+	templates, err := h.Service.ListPayslipTemplates(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "payslip_template_list_failed", "failed to list payslip templates", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, templates, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleCreatePayslipTemplate(w http.ResponseWriter, r *http.Request) {
+	h.savePayslipTemplate(w, r, "")
+}
+
+func (h *Handler) handleUpdatePayslipTemplate(w http.ResponseWriter, r *http.Request) {
+	h.savePayslipTemplate(w, r, chi.URLParam(r, "templateID"))
+}
+
+func (h *Handler) savePayslipTemplate(w http.ResponseWriter, r *http.Request, templateID string) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload payslipTemplatePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+
+	template := payroll.PayslipTemplate{
+		ID:                templateID,
+		Name:              strings.TrimSpace(payload.Name),
+		CompanyName:       strings.TrimSpace(payload.CompanyName),
+		CompanyAddress:    strings.TrimSpace(payload.CompanyAddress),
+		Footer:            strings.TrimSpace(payload.Footer),
+		ShowLeaveBalances: payload.ShowLeaveBalances == nil || *payload.ShowLeaveBalances,
+		ShowYTD:           payload.ShowYTD == nil || *payload.ShowYTD,
+		IsDefault:         payload.IsDefault,
+	}
+	validator := shared.NewValidator()
+	validator.Required("name", template.Name, "is required")
+	if len(template.Footer) > 500 {
+		validator.Add("footer", "must be at most 500 characters")
+	}
+	replaceLogo := payload.RemoveLogo || templateID == ""
+	if strings.TrimSpace(payload.Logo) != "" {
+		logo, logoType, err := payroll.DecodeLogo(payload.Logo)
+		if err != nil {
+			validator.Add("logo", err.Error())
+		}
+		template.Logo, template.LogoType = logo, logoType
+		replaceLogo = true
+	}
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	id, err := h.Service.SavePayslipTemplate(r.Context(), user.TenantID, template, replaceLogo)
+	if err != nil {
+		if isNoRowsError(err) {
+			api.Fail(w, http.StatusNotFound, "not_found", "payslip template not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "payslip_template_save_failed", "failed to save payslip template", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	payload.Logo = ""
+	action := "payroll.payslip_template.update"
+	if templateID == "" {
+		action = "payroll.payslip_template.create"
+	}
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, action, "payslip_template", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, payload); err != nil {
+		slog.Warn("audit "+action+" failed", "err", err)
+	}
+	if templateID == "" {
+		api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
+
+// handlePreviewPayslip renders an employee's payslip for a calculated period
+// without storing it, so HR can check a template before finalizing.
+func (h *Handler) handlePreviewPayslip(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	periodID := chi.URLParam(r, "periodID")
+	employeeID := chi.URLParam(r, "employeeID")
+	templateID := strings.TrimSpace(r.URL.Query().Get("templateId"))
+	content, err := h.Service.RenderPayslipPDF(r.Context(), user.TenantID, periodID, employeeID, templateID)
+	if err != nil {
+		if isNoRowsError(err) {
+			api.Fail(w, http.StatusNotFound, "not_found", "payroll result or template not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		slog.Warn("payslip preview failed", "err", err)
+		api.Fail(w, http.StatusInternalServerError, "payslip_preview_failed", "failed to render payslip", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "inline; filename=payslip-preview.pdf")
+	if _, err := w.Write(content); err != nil {
+		slog.Warn("payslip preview write failed", "err", err)
+	}
+}
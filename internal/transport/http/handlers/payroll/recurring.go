@@ -0,0 +1,216 @@
+package payrollhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/payroll"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+	"hrm/internal/transport/http/shared"
+)
+
+type recurringItemPayload struct {
+	Kind         string   `json:"kind"`
+	Description  string   `json:"description"`
+	Instalment   *float64 `json:"instalment"`
+	TotalAmount  *float64 `json:"totalAmount"`
+	Taxable      bool     `json:"taxable"`
+	Priority     *int     `json:"priority"`
+	ProtectedNet float64  `json:"protectedNet"`
+	StartDate    string   `json:"startDate"`
+	EndDate      string   `json:"endDate"`
+	Reference    string   `json:"reference"`
+	Status       string   `json:"status"`
+}
+
+var recurringKinds = []string{
+	payroll.RecurringKindLoan,
+	payroll.RecurringKindAdvance,
+	payroll.RecurringKindGarnishment,
+	payroll.RecurringKindUnionDues,
+	payroll.RecurringKindDeduction,
+	payroll.RecurringKindEarning,
+}
+
+func (h *Handler) handleListRecurringItems(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	employeeID := chi.URLParam(r, "employeeID")
+	if !h.selfEmployeeOnly(w, r, user, employeeID) {
+		return
+	}
+
+	items, err := h.Service.ListRecurringItems(r.Context(), user.TenantID, employeeID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "recurring_list_failed", "failed to list recurring items", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, items, middleware.GetRequestID(r.Context()))
+}
+
+// handleCreateRecurringItem records a loan, advance, garnishment, union dues or
+// other deduction, or a standing earning, that regular payroll runs apply
+// automatically from its start date.
+func (h *Handler) handleCreateRecurringItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload recurringItemPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+
+	item := payroll.RecurringItem{
+		EmployeeID: chi.URLParam(r, "employeeID"),
+		Kind:       strings.ToLower(strings.TrimSpace(payload.Kind)),
+		CreatedBy:  user.UserID,
+	}
+	validator := shared.NewValidator()
+	validator.Required("kind", item.Kind, "is required")
+	validator.Enum("kind", item.Kind, recurringKinds, "must be loan, advance, garnishment, union_dues, deduction or earning")
+	validator.Required("startDate", strings.TrimSpace(payload.StartDate), "is required")
+	if startDate, ok := validator.Date("startDate", strings.TrimSpace(payload.StartDate)); ok {
+		item.StartDate = startDate
+	}
+	if payload.Priority == nil {
+		item.Priority = payroll.DefaultRecurringPriority(item.Kind)
+	}
+	applyRecurringPayload(validator, &item, payload)
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	id, err := h.Service.CreateRecurringItem(r.Context(), user.TenantID, item)
+	if err != nil {
+		if errors.Is(err, payroll.ErrEmployeeNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "employee not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "recurring_create_failed", "failed to create recurring item", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.recurring.create", "payroll_recurring_item", id, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, item); err != nil {
+		slog.Warn("audit payroll.recurring.create failed", "err", err)
+	}
+	api.Created(w, map[string]string{"id": id}, middleware.GetRequestID(r.Context()))
+}
+
+// handleUpdateRecurringItem changes the terms of a recurring item or cancels
+// it. The kind, employee and start date cannot change; amounts already paid
+// through finalized periods are kept.
+func (h *Handler) handleUpdateRecurringItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	var payload recurringItemPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		shared.FailValidation(w, middleware.GetRequestID(r.Context()), []shared.ValidationIssue{
+			{Field: "payload", Reason: "must be valid JSON"},
+		})
+		return
+	}
+
+	before, err := h.Service.GetRecurringItem(r.Context(), user.TenantID, chi.URLParam(r, "itemID"))
+	if err != nil {
+		if errors.Is(err, payroll.ErrRecurringNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "recurring item not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "recurring_update_failed", "failed to load recurring item", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	item := before
+	validator := shared.NewValidator()
+	applyRecurringPayload(validator, &item, payload)
+	item.Status = strings.ToLower(strings.TrimSpace(payload.Status))
+	if item.Status == "" {
+		item.Status = payroll.RecurringStatusActive
+	}
+	validator.Enum("status", item.Status, []string{payroll.RecurringStatusActive, payroll.RecurringStatusCancelled}, "must be active or cancelled")
+	if validator.Reject(w, middleware.GetRequestID(r.Context())) {
+		return
+	}
+
+	if err := h.Service.UpdateRecurringItem(r.Context(), user.TenantID, item); err != nil {
+		if errors.Is(err, payroll.ErrRecurringNotFound) {
+			api.Fail(w, http.StatusNotFound, "not_found", "recurring item not found", middleware.GetRequestID(r.Context()))
+			return
+		}
+		api.Fail(w, http.StatusInternalServerError, "recurring_update_failed", "failed to update recurring item", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "payroll.recurring.update", "payroll_recurring_item", item.ID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), before, item); err != nil {
+		slog.Warn("audit payroll.recurring.update failed", "err", err)
+	}
+	api.Success(w, map[string]string{"id": item.ID}, middleware.GetRequestID(r.Context()))
+}
+
+// applyRecurringPayload copies and validates the editable terms of a
+// recurring item.
+func applyRecurringPayload(validator *shared.Validator, item *payroll.RecurringItem, payload recurringItemPayload) {
+	item.Description = strings.TrimSpace(payload.Description)
+	item.Reference = strings.TrimSpace(payload.Reference)
+	item.Taxable = payload.Taxable && item.Kind == payroll.RecurringKindEarning
+	item.TotalAmount = payload.TotalAmount
+	item.ProtectedNet = payload.ProtectedNet
+	if payload.Priority != nil {
+		item.Priority = *payload.Priority
+	}
+
+	validator.Required("description", item.Description, "is required")
+	if payload.Instalment == nil {
+		validator.Add("instalment", "is required")
+	} else if *payload.Instalment <= 0 {
+		validator.Add("instalment", "must be greater than zero")
+	} else {
+		item.Instalment = *payload.Instalment
+	}
+	if payload.TotalAmount != nil && *payload.TotalAmount <= 0 {
+		validator.Add("totalAmount", "must be greater than zero")
+	}
+	if payload.ProtectedNet < 0 {
+		validator.Add("protectedNet", "must be zero or greater")
+	}
+	if item.Priority < 0 {
+		validator.Add("priority", "must be zero or greater")
+	}
+
+	item.EndDate = nil
+	if raw := strings.TrimSpace(payload.EndDate); raw != "" {
+		if endDate, ok := validator.Date("endDate", raw); ok {
+			item.EndDate = &endDate
+			validator.DateOrder("startDate", item.StartDate, "endDate", endDate)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package payrollhandler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"hrm/internal/domain/auth"
+	"hrm/internal/transport/http/api"
+	"hrm/internal/transport/http/middleware"
+)
+
+// handleListRetroCandidates lists finalized periods that back-dated changes
+// have touched and that the next regular run will recalculate.
+func (h *Handler) handleListRetroCandidates(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	candidates, err := h.Service.ListRetroCandidates(r.Context(), user.TenantID)
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "retro_list_failed", "failed to list retro pay candidates", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, candidates, middleware.GetRequestID(r.Context()))
+}
+
+func (h *Handler) handleListRetroItems(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUser(r.Context())
+	if !ok {
+		api.Fail(w, http.StatusUnauthorized, "unauthorized", "authentication required", middleware.GetRequestID(r.Context()))
+		return
+	}
+	if user.RoleName != auth.RoleHR {
+		api.Fail(w, http.StatusForbidden, "forbidden", "hr role required", middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	items, err := h.Service.ListRetroItems(r.Context(), user.TenantID, chi.URLParam(r, "periodID"))
+	if err != nil {
+		api.Fail(w, http.StatusInternalServerError, "retro_list_failed", "failed to list retro pay", middleware.GetRequestID(r.Context()))
+		return
+	}
+	api.Success(w, items, middleware.GetRequestID(r.Context()))
+}
@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"hrm/internal/app/server"
+	"hrm/internal/domain/auth"
+	"hrm/internal/domain/payroll"
+)
+
+func TestPayrollFinalizeRequiresSeparateApprover(t *testing.T) {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	cfg := testConfig(dbURL)
+	app, err := server.New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("failed to start app: %v", err)
+	}
+	defer app.Close()
+	startJobs(t, app)
+
+	ts := httptest.NewServer(app.Router)
+	defer ts.Close()
+	client := ts.Client()
+
+	adminToken := login(t, client, ts.URL, cfg.SeedAdminEmail, cfg.SeedAdminPassword)
+	systemAdminToken := login(t, client, ts.URL, cfg.SeedSystemAdminEmail, cfg.SeedSystemAdminPassword)
+	_ = createEmployee(t, client, ts.URL, adminToken, fmt.Sprintf("payroll-approval-%d@example.com", time.Now().UnixNano()))
+
+	scheduleID := createPayrollSchedule(t, client, ts.URL, adminToken)
+	periodID := createPayrollPeriodWithRange(t, client, ts.URL, adminToken, scheduleID, "2026-06-01", "2026-06-30")
+	if runPayroll(t, client, ts.URL, adminToken, periodID) != payroll.PeriodStatusReviewed {
+		t.Fatalf("expected reviewed payroll period before approval")
+	}
+	periodURL := ts.URL + "/api/v1/payroll/periods/" + periodID
+
+	unapprovedStatus, unapproved := postJSONAnyStatusWithHeaders(t, client, periodURL+"/finalize", adminToken, map[string]any{}, map[string]string{
+		"Idempotency-Key": "approval-unapproved-key",
+	})
+	if unapprovedStatus != http.StatusBadRequest {
+		t.Fatalf("expected 400 when finalizing without approval, got %d", unapprovedStatus)
+	}
+	if code := envelopeErrorCode(unapproved); code != "invalid_state" {
+		t.Fatalf("expected invalid_state when finalizing without approval, got %+v", unapproved.Error)
+	}
+
+	submitted := postJSON(t, client, periodURL+"/submit", adminToken, map[string]any{})
+	if status := envelopeDataStatus(t, submitted); status != payroll.PeriodStatusPendingApproval {
+		t.Fatalf("expected pending_approval after submit, got %s", status)
+	}
+
+	selfApproved := postJSONStatus(t, client, periodURL+"/approve", adminToken, map[string]any{}, http.StatusForbidden)
+	if code := envelopeErrorCode(selfApproved); code != "segregation_of_duties" {
+		t.Fatalf("expected segregation_of_duties when the maker approves, got %+v", selfApproved.Error)
+	}
+
+	approverToken := createPayrollApprover(t, client, ts.URL, systemAdminToken)
+	missingComment := postJSONStatus(t, client, periodURL+"/reject", approverToken, map[string]any{}, http.StatusBadRequest)
+	if code := envelopeErrorCode(missingComment); code != "validation_error" {
+		t.Fatalf("expected validation_error when rejecting without a comment, got %+v", missingComment.Error)
+	}
+
+	rejected := postJSON(t, client, periodURL+"/reject", approverToken, map[string]any{"comment": "bonus missing"})
+	if status := envelopeDataStatus(t, rejected); status != payroll.PeriodStatusDraft {
+		t.Fatalf("expected draft after rejection, got %s", status)
+	}
+
+	if runPayroll(t, client, ts.URL, adminToken, periodID) != payroll.PeriodStatusReviewed {
+		t.Fatalf("expected reviewed payroll period after rerun")
+	}
+	approvePayroll(t, client, ts.URL, adminToken, approverToken, periodID)
+
+	finalizeStatus, finalizeEnv := postJSONAnyStatusWithHeaders(t, client, periodURL+"/finalize", adminToken, map[string]any{}, map[string]string{
+		"Idempotency-Key": "approval-finalize-key",
+	})
+	if finalizeStatus != http.StatusOK {
+		t.Fatalf("expected 200 for finalize after approval, got %d", finalizeStatus)
+	}
+	if status := envelopeDataStatus(t, finalizeEnv); status != payroll.PeriodStatusFinalized {
+		t.Fatalf("expected finalized status after approval, got %s", status)
+	}
+}
+
+func TestPayrollDelegateWithoutFinalizeApprovesOnBehalfOfApprover(t *testing.T) {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	cfg := testConfig(dbURL)
+	app, err := server.New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("failed to start app: %v", err)
+	}
+	defer app.Close()
+	startJobs(t, app)
+
+	ts := httptest.NewServer(app.Router)
+	defer ts.Close()
+	client := ts.Client()
+
+	adminToken := login(t, client, ts.URL, cfg.SeedAdminEmail, cfg.SeedAdminPassword)
+	systemAdminToken := login(t, client, ts.URL, cfg.SeedSystemAdminEmail, cfg.SeedSystemAdminPassword)
+	_ = createEmployee(t, client, ts.URL, adminToken, fmt.Sprintf("payroll-delegation-%d@example.com", time.Now().UnixNano()))
+
+	scheduleID := createPayrollSchedule(t, client, ts.URL, adminToken)
+	periodID := createPayrollPeriodWithRange(t, client, ts.URL, adminToken, scheduleID, "2026-07-01", "2026-07-31")
+	if runPayroll(t, client, ts.URL, adminToken, periodID) != payroll.PeriodStatusReviewed {
+		t.Fatalf("expected reviewed payroll period before approval")
+	}
+	periodURL := ts.URL + "/api/v1/payroll/periods/" + periodID
+	postJSON(t, client, periodURL+"/submit", adminToken, map[string]any{})
+
+	approverEmail := fmt.Sprintf("payroll-delegator-%d@example.com", time.Now().UnixNano())
+	approver := createUserAccountWithEmployee(t, client, ts.URL, systemAdminToken, auth.RoleHR, approverEmail, "")
+	approverToken := login(t, client, ts.URL, approverEmail, approver.TempPassword)
+	delegateEmail := fmt.Sprintf("payroll-delegate-%d@example.com", time.Now().UnixNano())
+	delegate := createUserAccountWithEmployee(t, client, ts.URL, systemAdminToken, auth.RoleManager, delegateEmail, "")
+	delegateToken := login(t, client, ts.URL, delegateEmail, delegate.TempPassword)
+
+	undelegated := postJSONStatus(t, client, periodURL+"/approve", delegateToken, map[string]any{}, http.StatusForbidden)
+	if code := envelopeErrorCode(undelegated); code != "forbidden" {
+		t.Fatalf("expected forbidden before the delegation, got %+v", undelegated.Error)
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	postJSON(t, client, ts.URL+"/api/v1/delegations", approverToken, map[string]any{
+		"delegateId": delegate.UserID,
+		"scope":      "payroll",
+		"startDate":  today,
+		"endDate":    today,
+	})
+
+	approved := postJSON(t, client, periodURL+"/approve", delegateToken, map[string]any{"comment": "checked for the approver"})
+	if status := envelopeDataStatus(t, approved); status != payroll.PeriodStatusApproved {
+		t.Fatalf("expected approved after the delegate decides, got %s", status)
+	}
+
+	events := getJSON(t, client, ts.URL+"/api/v1/audit/events?action=payroll.approve&entityType=payroll_period&includeDetails=true", adminToken)
+	var list []struct {
+		EntityID string          `json:"entityId"`
+		ActorID  string          `json:"actorId"`
+		After    json.RawMessage `json:"after"`
+	}
+	if err := json.Unmarshal(events.Data, &list); err != nil {
+		t.Fatalf("failed to decode audit events: %v", err)
+	}
+	for _, event := range list {
+		if event.EntityID != periodID {
+			continue
+		}
+		var after struct {
+			OnBehalfOf string `json:"onBehalfOf"`
+		}
+		if err := json.Unmarshal(event.After, &after); err != nil {
+			t.Fatalf("failed to decode audit details: %v", err)
+		}
+		if event.ActorID != delegate.UserID || after.OnBehalfOf != approver.UserID {
+			t.Fatalf("expected approval by %s on behalf of %s, got actor %s on behalf of %q", delegate.UserID, approver.UserID, event.ActorID, after.OnBehalfOf)
+		}
+		return
+	}
+	t.Fatalf("payroll.approve audit event for period %s not found", periodID)
+}
@@ -29,6 +29,7 @@ func TestPayrollFinalizeRequiresIdempotencyAndReplays(t *testing.T) {
 		t.Fatalf("failed to start app: %v", err)
 	}
 	defer app.Close()
+	startJobs(t, app)
 
 	ts := httptest.NewServer(app.Router)
 	defer ts.Close()
@@ -44,6 +45,8 @@ func TestPayrollFinalizeRequiresIdempotencyAndReplays(t *testing.T) {
 	if runStatus != payroll.PeriodStatusReviewed {
 		t.Fatalf("expected reviewed payroll period before finalize, got %s", runStatus)
 	}
+	approverToken := createPayrollApprover(t, client, ts.URL, login(t, client, ts.URL, cfg.SeedSystemAdminEmail, cfg.SeedSystemAdminPassword))
+	approvePayroll(t, client, ts.URL, adminToken, approverToken, periodID)
 
 	missingKeyResp := postJSONStatus(t, client, ts.URL+"/api/v1/payroll/periods/"+periodID+"/finalize", adminToken, map[string]any{}, http.StatusBadRequest)
 	if code := envelopeErrorCode(missingKeyResp); code != "validation_error" {
@@ -99,6 +102,7 @@ func TestPayrollFinalizeIdempotencyConflictAcrossPeriods(t *testing.T) {
 		t.Fatalf("failed to start app: %v", err)
 	}
 	defer app.Close()
+	startJobs(t, app)
 
 	ts := httptest.NewServer(app.Router)
 	defer ts.Close()
@@ -113,6 +117,8 @@ func TestPayrollFinalizeIdempotencyConflictAcrossPeriods(t *testing.T) {
 	if runPayroll(t, client, ts.URL, adminToken, periodOne) != payroll.PeriodStatusReviewed {
 		t.Fatalf("expected period %s to be reviewed before finalize", periodOne)
 	}
+	approverToken := createPayrollApprover(t, client, ts.URL, login(t, client, ts.URL, cfg.SeedSystemAdminEmail, cfg.SeedSystemAdminPassword))
+	approvePayroll(t, client, ts.URL, adminToken, approverToken, periodOne)
 
 	firstFinalizeStatus, _ := postJSONAnyStatusWithHeaders(t, client, ts.URL+"/api/v1/payroll/periods/"+periodOne+"/finalize", adminToken, map[string]any{}, map[string]string{
 		"Idempotency-Key": "shared-key",
@@ -125,6 +131,7 @@ func TestPayrollFinalizeIdempotencyConflictAcrossPeriods(t *testing.T) {
 	if runPayroll(t, client, ts.URL, adminToken, periodTwo) != payroll.PeriodStatusReviewed {
 		t.Fatalf("expected period %s to be reviewed before finalize", periodTwo)
 	}
+	approvePayroll(t, client, ts.URL, adminToken, approverToken, periodTwo)
 
 	conflictStatus, conflictEnv := postJSONAnyStatusWithHeaders(t, client, ts.URL+"/api/v1/payroll/periods/"+periodTwo+"/finalize", adminToken, map[string]any{}, map[string]string{
 		"Idempotency-Key": "shared-key",
@@ -158,15 +165,15 @@ func TestPayrollFinalizeRollsBackWhenNoResultsExist(t *testing.T) {
 	scheduleID := createPayrollSchedule(t, client, ts.URL, adminToken)
 	periodID := createPayrollPeriodWithRange(t, client, ts.URL, adminToken, scheduleID, "2026-04-01", "2026-04-30")
 
-	if _, err := app.DB.Exec(context.Background(), "UPDATE payroll_periods SET status = $1 WHERE id = $2", payroll.PeriodStatusReviewed, periodID); err != nil {
-		t.Fatalf("failed to force period to reviewed state: %v", err)
+	if _, err := app.DB.Exec(context.Background(), "UPDATE payroll_periods SET status = $1 WHERE id = $2", payroll.PeriodStatusApproved, periodID); err != nil {
+		t.Fatalf("failed to force period to approved state: %v", err)
 	}
 
 	finalizeStatus, finalizeEnv := postJSONAnyStatusWithHeaders(t, client, ts.URL+"/api/v1/payroll/periods/"+periodID+"/finalize", adminToken, map[string]any{}, map[string]string{
 		"Idempotency-Key": "rollback-check-key",
 	})
 	if finalizeStatus != http.StatusBadRequest {
-		t.Fatalf("expected 400 when finalizing reviewed period with no results, got %d", finalizeStatus)
+		t.Fatalf("expected 400 when finalizing approved period with no results, got %d", finalizeStatus)
 	}
 	if code := envelopeErrorCode(finalizeEnv); code != "invalid_state" {
 		t.Fatalf("expected invalid_state for finalize with no results, got %+v", finalizeEnv.Error)
@@ -176,8 +183,8 @@ func TestPayrollFinalizeRollsBackWhenNoResultsExist(t *testing.T) {
 	if err := app.DB.QueryRow(context.Background(), "SELECT status FROM payroll_periods WHERE id = $1", periodID).Scan(&currentStatus); err != nil {
 		t.Fatalf("failed to read payroll period status: %v", err)
 	}
-	if currentStatus != payroll.PeriodStatusReviewed {
-		t.Fatalf("expected rollback to keep reviewed status, got %s", currentStatus)
+	if currentStatus != payroll.PeriodStatusApproved {
+		t.Fatalf("expected rollback to keep approved status, got %s", currentStatus)
 	}
 }
 
@@ -193,6 +200,7 @@ func TestPayrollFinalizeConcurrentRequestsResolveDeterministically(t *testing.T)
 		t.Fatalf("failed to start app: %v", err)
 	}
 	defer app.Close()
+	startJobs(t, app)
 
 	ts := httptest.NewServer(app.Router)
 	defer ts.Close()
@@ -207,6 +215,8 @@ func TestPayrollFinalizeConcurrentRequestsResolveDeterministically(t *testing.T)
 	if runPayroll(t, client, ts.URL, adminToken, periodID) != payroll.PeriodStatusReviewed {
 		t.Fatalf("expected reviewed payroll period before finalize")
 	}
+	approverToken := createPayrollApprover(t, client, ts.URL, login(t, client, ts.URL, cfg.SeedSystemAdminEmail, cfg.SeedSystemAdminPassword))
+	approvePayroll(t, client, ts.URL, adminToken, approverToken, periodID)
 
 	type result struct {
 		status int
@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"hrm/internal/app/server"
+)
+
+func TestPayrollPaymentSettingsResponsesAreMasked(t *testing.T) {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	cfg := testConfig(dbURL)
+	app, err := server.New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("failed to start app: %v", err)
+	}
+	defer app.Close()
+
+	ts := httptest.NewServer(app.Router)
+	defer ts.Close()
+	client := ts.Client()
+
+	adminToken := login(t, client, ts.URL, cfg.SeedAdminEmail, cfg.SeedAdminPassword)
+	settingsURL := ts.URL + "/api/v1/payroll/payment-settings"
+
+	type settingsView struct {
+		DebtorIBAN    string `json:"debtorIban"`
+		AccountNumber string `json:"accountNumber"`
+		RoutingNumber string `json:"routingNumber"`
+	}
+	assertMasked := func(label string, env envelope) {
+		t.Helper()
+		var view settingsView
+		if err := json.Unmarshal(env.Data, &view); err != nil {
+			t.Fatalf("failed to decode %s payment settings: %v", label, err)
+		}
+		if view.DebtorIBAN != "******************3000" || view.AccountNumber != "****7766" {
+			t.Fatalf("expected masked %s payment settings, got %+v", label, view)
+		}
+		if view.RoutingNumber != "021000021" {
+			t.Fatalf("expected the routing number in the %s response, got %q", label, view.RoutingNumber)
+		}
+	}
+
+	updated := putJSON(t, client, settingsURL, adminToken, map[string]any{
+		"debtorName":    "Test Tenant",
+		"debtorIban":    "DE89 3704 0044 0532 0130 00",
+		"routingNumber": "021000021",
+		"accountNumber": "99887766",
+	})
+	assertMasked("updated", updated)
+	assertMasked("loaded", getJSON(t, client, settingsURL, adminToken))
+}
+
+func putJSON(t *testing.T, client *http.Client, url, token string, body any) envelope {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(raw))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respRaw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		t.Fatalf("unexpected status %d: %s", resp.StatusCode, string(respRaw))
+	}
+	var env envelope
+	if err := json.Unmarshal(respRaw, &env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return env
+}
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"hrm/internal/domain/audit"
 	"hrm/internal/domain/auth"
+	"hrm/internal/domain/core"
 	"hrm/internal/domain/notifications"
 	"hrm/internal/domain/performance"
 	"hrm/internal/transport/http/api"
@@ -17,10 +19,11 @@ import (
 )
 
 type Handler struct {
-	Service *performance.Service
-	Perms   middleware.PermissionStore
-	Notify  *notifications.Service
-	Audit   *audit.Service
+	Service     *performance.Service
+	Perms       middleware.PermissionStore
+	Notify      *notifications.Service
+	Audit       *audit.Service
+	Delegations *core.Service
 }
 
 func NewHandler(service *performance.Service, perms middleware.PermissionStore, notify *notifications.Service, auditSvc *audit.Service) *Handler {
@@ -547,6 +550,16 @@ func (h *Handler) handleListReviewTasks(w http.ResponseWriter, r *http.Request)
 		api.Fail(w, http.StatusInternalServerError, "review_tasks_failed", "failed to list review tasks", middleware.GetRequestID(r.Context()))
 		return
 	}
+	if managerEmployeeID != "" {
+		for _, delegatedManagerID := range h.delegatedManagerIDs(r, user) {
+			delegated, err := h.Service.ListReviewTasks(r.Context(), user.TenantID, "", delegatedManagerID)
+			if err != nil {
+				slog.Warn("review tasks delegated lookup failed", "err", err)
+				continue
+			}
+			tasks = append(tasks, delegated...)
+		}
+	}
 	api.Success(w, tasks, middleware.GetRequestID(r.Context()))
 }
 
@@ -578,14 +591,18 @@ func (h *Handler) handleSubmitReviewResponse(w http.ResponseWriter, r *http.Requ
 			return
 		}
 	}
+	onBehalfOf := ""
 	if user.RoleName == auth.RoleManager {
 		managerEmployeeID, err := h.Service.EmployeeIDByUserID(r.Context(), user.TenantID, user.UserID)
 		if err != nil {
 			slog.Warn("review response manager lookup failed", "err", err)
 		}
 		if managerEmployeeID == "" || managerEmployeeID != ctxInfo.ManagerID {
-			api.Fail(w, http.StatusForbidden, "forbidden", "not allowed", middleware.GetRequestID(r.Context()))
-			return
+			onBehalfOf = h.delegatedManagerUserID(r, user, ctxInfo.ManagerID)
+			if onBehalfOf == "" {
+				api.Fail(w, http.StatusForbidden, "forbidden", "not allowed", middleware.GetRequestID(r.Context()))
+				return
+			}
 		}
 		if ctxInfo.Status != performance.ReviewTaskStatusManagerPending {
 			api.Fail(w, http.StatusBadRequest, "invalid_state", "manager review not available", middleware.GetRequestID(r.Context()))
@@ -665,7 +682,7 @@ func (h *Handler) handleSubmitReviewResponse(w http.ResponseWriter, r *http.Requ
 		slog.Warn("review task status update failed", "err", err)
 	}
 
-	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "performance.review.submit", "review_task", taskID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, map[string]any{"role": role}); err != nil {
+	if err := h.Audit.Record(r.Context(), user.TenantID, user.UserID, "performance.review.submit", "review_task", taskID, middleware.GetRequestID(r.Context()), shared.ClientIP(r), nil, reviewSubmitAudit(role, onBehalfOf)); err != nil {
 		slog.Warn("audit performance.review.submit failed", "err", err)
 	}
 	api.Created(w, map[string]string{"status": status}, middleware.GetRequestID(r.Context()))
@@ -1132,3 +1149,56 @@ func (h *Handler) managerEmployeeID(r *http.Request, user auth.UserContext, warn
 	}
 	return id, true
 }
+
+// delegatedManagerUserID returns the user ID of the task's manager when the
+// caller decides that manager's reviews through a delegation, or "".
+func (h *Handler) delegatedManagerUserID(r *http.Request, user auth.UserContext, managerEmployeeID string) string {
+	if h.Delegations == nil || managerEmployeeID == "" {
+		return ""
+	}
+	managerUserID, err := h.Service.EmployeeUserID(r.Context(), user.TenantID, managerEmployeeID)
+	if err != nil || managerUserID == "" {
+		return ""
+	}
+	delegators, err := h.Delegations.DelegatorsFor(r.Context(), user.TenantID, user.UserID, core.DelegationScopePerformance, time.Now().UTC())
+	if err != nil {
+		slog.Warn("review response delegation lookup failed", "err", err)
+		return ""
+	}
+	for _, delegatorID := range delegators {
+		if delegatorID == managerUserID {
+			return managerUserID
+		}
+	}
+	return ""
+}
+
+// delegatedManagerIDs returns the employee IDs of the managers whose reviews
+// the caller decides through a delegation.
+func (h *Handler) delegatedManagerIDs(r *http.Request, user auth.UserContext) []string {
+	if h.Delegations == nil {
+		return nil
+	}
+	delegators, err := h.Delegations.DelegatorsFor(r.Context(), user.TenantID, user.UserID, core.DelegationScopePerformance, time.Now().UTC())
+	if err != nil {
+		slog.Warn("review tasks delegation lookup failed", "err", err)
+		return nil
+	}
+	var managerIDs []string
+	for _, delegatorID := range delegators {
+		employeeID, err := h.Service.EmployeeIDByUserID(r.Context(), user.TenantID, delegatorID)
+		if err != nil || employeeID == "" {
+			continue
+		}
+		managerIDs = append(managerIDs, employeeID)
+	}
+	return managerIDs
+}
+
+func reviewSubmitAudit(role, onBehalfOf string) map[string]any {
+	out := map[string]any{"role": role}
+	if onBehalfOf != "" {
+		out["onBehalfOf"] = onBehalfOf
+	}
+	return out
+}
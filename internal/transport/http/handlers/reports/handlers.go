@@ -341,7 +341,7 @@ func (h *Handler) handleJobRuns(w http.ResponseWriter, r *http.Request) {
 
 	validator := shared.NewValidator()
 	if filter.Status != "" {
-		validator.Enum("status", filter.Status, []string{"running", "completed", "failed"}, "must be one of: running, completed, failed")
+		validator.Enum("status", filter.Status, []string{"queued", "running", "completed", "failed"}, "must be one of: queued, running, completed, failed")
 	}
 	if raw := strings.TrimSpace(r.URL.Query().Get("startedFrom")); raw != "" {
 		startedFrom, err := parseJobRunDate(raw, false)
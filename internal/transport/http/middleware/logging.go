@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
 	"hrm/internal/platform/metrics"
 )
 
@@ -18,6 +20,18 @@ func (s *statusRecorder) WriteHeader(code int) {
 	s.ResponseWriter.WriteHeader(code)
 }
 
+// routePath returns the route pattern a request matched, such as
+// /api/v1/leave/calendar-feeds/{token}/calendar.ics, so secrets carried in
+// the path are not logged. Unmatched requests fall back to the raw path.
+func routePath(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
+
 func Logger(collector *metrics.Collector) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -30,7 +44,7 @@ func Logger(collector *metrics.Collector) func(http.Handler) http.Handler {
 			}
 			slog.Info("http.request",
 				"method", r.Method,
-				"path", r.URL.Path,
+				"path", routePath(r),
 				"status", recorder.status,
 				"durationMs", duration.Milliseconds(),
 				"requestId", GetRequestID(r.Context()),
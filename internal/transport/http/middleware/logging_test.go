@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestLoggerRedactsPathParameters(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	router := chi.NewRouter()
+	router.Use(Logger(nil))
+	router.Route("/api/v1/leave", func(r chi.Router) {
+		r.Get("/calendar-feeds/{token}/calendar.ics", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/leave/calendar-feeds/s3cret-token/calendar.ics", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "s3cret-token") {
+		t.Fatalf("expected the feed token to be left out of the log, got %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "path=/api/v1/leave/calendar-feeds/{token}/calendar.ics") {
+		t.Fatalf("expected the route pattern to be logged, got %s", buf.String())
+	}
+}
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS payroll_tax_rules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  code TEXT NOT NULL,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  effective_from DATE NOT NULL,
+  brackets_json JSONB NOT NULL DEFAULT '[]',
+  allowance NUMERIC(12,2) NOT NULL DEFAULT 0,
+  earnings_cap NUMERIC(12,2),
+  employee_rate NUMERIC(8,6) NOT NULL DEFAULT 0,
+  employer_rate NUMERIC(8,6) NOT NULL DEFAULT 0,
+  pre_tax BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, code, effective_from)
+);
+
+CREATE TABLE IF NOT EXISTS payroll_result_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  period_id UUID NOT NULL REFERENCES payroll_periods(id) ON DELETE CASCADE,
+  employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  line_type TEXT NOT NULL,
+  source TEXT NOT NULL,
+  source_id UUID,
+  code TEXT NOT NULL,
+  description TEXT NOT NULL,
+  base NUMERIC(12,2) NOT NULL DEFAULT 0,
+  amount NUMERIC(12,2) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payroll_result_lines_period_employee
+  ON payroll_result_lines (period_id, employee_id);
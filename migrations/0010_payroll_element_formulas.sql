@@ -0,0 +1,5 @@
+ALTER TABLE pay_elements ADD COLUMN IF NOT EXISTS rate NUMERIC(12,6) NOT NULL DEFAULT 0;
+ALTER TABLE pay_elements ADD COLUMN IF NOT EXISTS tiers_json JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE pay_elements ADD COLUMN IF NOT EXISTS depends_on_json JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE payroll_inputs ALTER COLUMN rate TYPE NUMERIC(12,6);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS payroll_payment_settings (
+  tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+  debtor_name TEXT NOT NULL DEFAULT '',
+  debtor_iban TEXT NOT NULL DEFAULT '',
+  debtor_bic TEXT NOT NULL DEFAULT '',
+  company_id TEXT NOT NULL DEFAULT '',
+  routing_number TEXT NOT NULL DEFAULT '',
+  account_number TEXT NOT NULL DEFAULT '',
+  destination_name TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
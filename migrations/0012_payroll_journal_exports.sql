@@ -0,0 +1,8 @@
+ALTER TABLE journal_exports ADD COLUMN IF NOT EXISTS template_id UUID REFERENCES journal_templates(id) ON DELETE SET NULL;
+ALTER TABLE journal_exports ADD COLUMN IF NOT EXISTS format TEXT NOT NULL DEFAULT 'csv';
+ALTER TABLE journal_exports ADD COLUMN IF NOT EXISTS total_debit NUMERIC(14,2) NOT NULL DEFAULT 0;
+ALTER TABLE journal_exports ADD COLUMN IF NOT EXISTS total_credit NUMERIC(14,2) NOT NULL DEFAULT 0;
+ALTER TABLE journal_exports ADD COLUMN IF NOT EXISTS line_count INT NOT NULL DEFAULT 0;
+ALTER TABLE journal_exports ADD COLUMN IF NOT EXISTS exported_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_journal_exports_period ON journal_exports (tenant_id, period_id, exported_at DESC);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS payslip_templates (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  company_name TEXT NOT NULL DEFAULT '',
+  company_address TEXT NOT NULL DEFAULT '',
+  logo BYTEA,
+  logo_type TEXT NOT NULL DEFAULT '',
+  footer TEXT NOT NULL DEFAULT '',
+  show_leave_balances BOOLEAN NOT NULL DEFAULT true,
+  show_ytd BOOLEAN NOT NULL DEFAULT true,
+  is_default BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payslip_templates_default ON payslip_templates (tenant_id) WHERE is_default;
@@ -0,0 +1,25 @@
+ALTER TABLE payroll_results ADD COLUMN IF NOT EXISTS taxable_gross NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS payroll_accumulators (
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  tax_year INT NOT NULL,
+  line_type TEXT NOT NULL,
+  code TEXT NOT NULL,
+  currency TEXT NOT NULL DEFAULT '',
+  description TEXT NOT NULL DEFAULT '',
+  amount NUMERIC(14,2) NOT NULL DEFAULT 0,
+  periods INT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_id, employee_id, tax_year, currency, line_type, code)
+);
+
+CREATE TABLE IF NOT EXISTS payroll_annual_statements (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  tax_year INT NOT NULL,
+  file_url TEXT,
+  generated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, employee_id, tax_year)
+);
@@ -0,0 +1,13 @@
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS run_type TEXT NOT NULL DEFAULT 'regular';
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS original_period_id UUID REFERENCES payroll_periods(id);
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS reason TEXT;
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS pay_date DATE;
+
+CREATE INDEX IF NOT EXISTS payroll_periods_original_idx ON payroll_periods (tenant_id, original_period_id);
+
+CREATE TABLE IF NOT EXISTS payroll_period_employees (
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  period_id UUID NOT NULL REFERENCES payroll_periods(id) ON DELETE CASCADE,
+  employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  PRIMARY KEY (period_id, employee_id)
+);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS employee_compensation (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  effective_from DATE NOT NULL,
+  salary NUMERIC(12,2),
+  salary_enc BYTEA,
+  currency TEXT NOT NULL DEFAULT 'USD',
+  pay_group_id UUID REFERENCES pay_groups(id),
+  reason TEXT,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (employee_id, effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS employee_compensation_tenant_idx ON employee_compensation (tenant_id, effective_from);
+
+-- Seed the history with each employee's current compensation so existing
+-- payroll keeps working until the first effective-dated change is recorded.
+INSERT INTO employee_compensation (tenant_id, employee_id, effective_from, salary, salary_enc, currency, pay_group_id, reason)
+SELECT tenant_id, id, COALESCE(start_date, created_at::date), salary, salary_enc, COALESCE(currency, 'USD'), pay_group_id, 'initial'
+FROM employees
+ON CONFLICT (employee_id, effective_from) DO NOTHING;
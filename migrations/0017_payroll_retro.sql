@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS payroll_retro_items (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  period_id UUID NOT NULL REFERENCES payroll_periods(id) ON DELETE CASCADE,
+  employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  original_period_id UUID NOT NULL REFERENCES payroll_periods(id) ON DELETE CASCADE,
+  triggers TEXT[] NOT NULL DEFAULT '{}',
+  previous_pay NUMERIC(12,2) NOT NULL DEFAULT 0,
+  recalculated_pay NUMERIC(12,2) NOT NULL DEFAULT 0,
+  previous_taxable NUMERIC(12,2) NOT NULL DEFAULT 0,
+  recalculated_taxable NUMERIC(12,2) NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (period_id, employee_id, original_period_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payroll_retro_items_original
+  ON payroll_retro_items (tenant_id, original_period_id, employee_id);
@@ -0,0 +1,7 @@
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS run_by UUID REFERENCES users(id);
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS submitted_by UUID REFERENCES users(id);
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ;
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS decided_by UUID REFERENCES users(id);
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS decided_at TIMESTAMPTZ;
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS decision TEXT;
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS decision_comment TEXT;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS payroll_recurring_items (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL,
+  description TEXT NOT NULL,
+  instalment NUMERIC(12,2) NOT NULL,
+  total_amount NUMERIC(12,2),
+  paid_to_date NUMERIC(12,2) NOT NULL DEFAULT 0,
+  taxable BOOLEAN NOT NULL DEFAULT false,
+  priority INTEGER NOT NULL DEFAULT 100,
+  protected_net NUMERIC(12,2) NOT NULL DEFAULT 0,
+  start_date DATE NOT NULL,
+  end_date DATE,
+  status TEXT NOT NULL DEFAULT 'active',
+  reference TEXT,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payroll_recurring_items_employee
+  ON payroll_recurring_items (tenant_id, employee_id, status);
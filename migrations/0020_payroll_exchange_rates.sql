@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS payroll_exchange_rates (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  from_currency TEXT NOT NULL,
+  to_currency TEXT NOT NULL,
+  rate NUMERIC(18,8) NOT NULL,
+  effective_date DATE NOT NULL,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, from_currency, to_currency, effective_date)
+);
+
+CREATE TABLE IF NOT EXISTS payroll_settings (
+  tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+  reporting_currency TEXT NOT NULL DEFAULT 'USD',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE journal_exports ADD COLUMN IF NOT EXISTS currency TEXT;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS work_patterns (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  cycle_start DATE NOT NULL,
+  days_json JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, name)
+);
+
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS work_pattern_id UUID REFERENCES work_patterns(id) ON DELETE SET NULL;
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS holiday_region TEXT;
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS work_pattern_id UUID REFERENCES work_patterns(id) ON DELETE SET NULL;
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS holiday_region TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_holidays_tenant_date ON holidays (tenant_id, date);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS leave_approval_chains (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  leave_type_id UUID REFERENCES leave_types(id) ON DELETE CASCADE,
+  department_id UUID REFERENCES departments(id) ON DELETE CASCADE,
+  steps_json JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, name)
+);
+
+ALTER TABLE leave_approvals ALTER COLUMN approver_id DROP NOT NULL;
+ALTER TABLE leave_approvals ADD COLUMN IF NOT EXISTS approver_role TEXT;
+ALTER TABLE leave_approvals ADD COLUMN IF NOT EXISTS step_order INT;
+ALTER TABLE leave_approvals ADD COLUMN IF NOT EXISTS requested_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE leave_approvals ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_leave_approvals_request ON leave_approvals (leave_request_id, step_order);
+CREATE INDEX IF NOT EXISTS idx_leave_approvals_pending ON leave_approvals (tenant_id, status) WHERE step_order IS NOT NULL;
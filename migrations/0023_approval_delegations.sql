@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS approval_delegations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  delegator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  delegate_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  scope TEXT NOT NULL DEFAULT 'all',
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  reason TEXT,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  revoked_at TIMESTAMPTZ,
+  CHECK (delegator_id <> delegate_id),
+  CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_delegations_delegate ON approval_delegations (tenant_id, delegate_id, start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_approval_delegations_delegator ON approval_delegations (tenant_id, delegator_id, start_date, end_date);
@@ -0,0 +1,33 @@
+ALTER TABLE leave_policies ADD COLUMN IF NOT EXISTS carry_over_expiry_months INT NOT NULL DEFAULT 0;
+ALTER TABLE leave_policies ADD COLUMN IF NOT EXISTS year_start_month INT NOT NULL DEFAULT 1;
+ALTER TABLE leave_policies ADD COLUMN IF NOT EXISTS year_start_day INT NOT NULL DEFAULT 1;
+
+-- Carry-over is run per policy, so a leave type can only have one. Older
+-- duplicates were shadowed by the newest policy and are dropped.
+DELETE FROM leave_policies p
+USING leave_policies newer
+WHERE newer.tenant_id = p.tenant_id AND newer.leave_type_id = p.leave_type_id
+  AND (newer.created_at, newer.id) > (p.created_at, p.id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leave_policies_leave_type ON leave_policies (tenant_id, leave_type_id);
+
+ALTER TABLE leave_balances ADD COLUMN IF NOT EXISTS carried_over NUMERIC(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE leave_balances ADD COLUMN IF NOT EXISTS carry_over_expires_on DATE;
+ALTER TABLE leave_balances ADD COLUMN IF NOT EXISTS expiry_notified_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS leave_carry_over_runs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  policy_id UUID NOT NULL REFERENCES leave_policies(id) ON DELETE CASCADE,
+  year INT NOT NULL,
+  balances_processed INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, policy_id, year)
+);
+
+-- Years that ended before carry-over processing existed were settled by hand.
+INSERT INTO leave_carry_over_runs (tenant_id, policy_id, year)
+SELECT tenant_id, id, EXTRACT(YEAR FROM now())::int - 1
+FROM leave_policies
+ON CONFLICT (tenant_id, policy_id, year) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_leave_balances_carry_over_expiry ON leave_balances (tenant_id, carry_over_expires_on) WHERE carry_over_expires_on IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE leave_policies ADD COLUMN IF NOT EXISTS accrual_bands JSONB NOT NULL DEFAULT '[]'::jsonb;
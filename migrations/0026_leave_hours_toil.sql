@@ -0,0 +1,27 @@
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS unit TEXT NOT NULL DEFAULT 'days';
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS toil BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS toil_expiry_days INT NOT NULL DEFAULT 0;
+
+ALTER TABLE work_patterns ADD COLUMN IF NOT EXISTS hours_per_day NUMERIC(5,2) NOT NULL DEFAULT 8;
+
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS hours NUMERIC(10,2);
+
+CREATE TABLE IF NOT EXISTS toil_entries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  leave_type_id UUID NOT NULL REFERENCES leave_types(id) ON DELETE CASCADE,
+  work_date DATE NOT NULL,
+  hours NUMERIC(10,2) NOT NULL CHECK (hours > 0),
+  remaining NUMERIC(10,2) NOT NULL DEFAULT 0,
+  reason TEXT,
+  status TEXT NOT NULL DEFAULT 'pending',
+  expires_on DATE,
+  requested_by UUID REFERENCES users(id),
+  decided_by UUID REFERENCES users(id),
+  decided_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_toil_entries_employee ON toil_entries (tenant_id, employee_id, leave_type_id, status);
+CREATE INDEX IF NOT EXISTS idx_toil_entries_expiry ON toil_entries (tenant_id, expires_on) WHERE status = 'approved';
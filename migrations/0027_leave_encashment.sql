@@ -0,0 +1,24 @@
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS encashable BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS leave_encashments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  leave_type_id UUID NOT NULL REFERENCES leave_types(id) ON DELETE CASCADE,
+  amount NUMERIC(10,2) NOT NULL CHECK (amount > 0),
+  days NUMERIC(10,4),
+  source TEXT NOT NULL DEFAULT 'request',
+  status TEXT NOT NULL DEFAULT 'pending',
+  reason TEXT,
+  daily_rate NUMERIC(12,2),
+  value NUMERIC(12,2),
+  currency TEXT,
+  payroll_period_id UUID REFERENCES payroll_periods(id) ON DELETE SET NULL,
+  payroll_adjustment_id UUID REFERENCES payroll_adjustments(id) ON DELETE SET NULL,
+  requested_by UUID REFERENCES users(id),
+  decided_by UUID REFERENCES users(id),
+  decided_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_encashments_employee ON leave_encashments (tenant_id, employee_id, status);
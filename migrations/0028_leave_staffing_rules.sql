@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS leave_staffing_rules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  department_id UUID REFERENCES departments(id) ON DELETE CASCADE,
+  manager_id UUID REFERENCES employees(id) ON DELETE CASCADE,
+  max_absent INT,
+  start_date DATE,
+  end_date DATE,
+  enforcement TEXT NOT NULL DEFAULT 'warn',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, name)
+);
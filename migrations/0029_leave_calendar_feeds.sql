@@ -0,0 +1,19 @@
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+UPDATE leave_requests SET updated_at = COALESCE(approved_at, created_at) WHERE updated_at IS NULL;
+ALTER TABLE leave_requests ALTER COLUMN updated_at SET DEFAULT now();
+ALTER TABLE leave_requests ALTER COLUMN updated_at SET NOT NULL;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS leave_calendar_feeds (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL,
+  region TEXT,
+  token_hash TEXT NOT NULL UNIQUE,
+  last_used_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_calendar_feeds_user ON leave_calendar_feeds(tenant_id, user_id);
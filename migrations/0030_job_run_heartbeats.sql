@@ -0,0 +1,19 @@
+ALTER TABLE job_runs ADD COLUMN IF NOT EXISTS instance_id TEXT;
+ALTER TABLE job_runs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
+
+UPDATE job_runs j
+SET status = 'failed', completed_at = now()
+WHERE j.job_type = 'payroll_run'
+  AND j.status IN ('queued', 'running')
+  AND EXISTS (
+    SELECT 1 FROM job_runs newer
+    WHERE newer.tenant_id = j.tenant_id
+      AND newer.job_type = j.job_type
+      AND newer.status IN ('queued', 'running')
+      AND newer.details_json->>'periodId' = j.details_json->>'periodId'
+      AND (newer.started_at, newer.id) > (j.started_at, j.id)
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_job_runs_active_payroll_period
+  ON job_runs(tenant_id, (details_json->>'periodId'))
+  WHERE job_type = 'payroll_run' AND status IN ('queued', 'running');
@@ -0,0 +1,2 @@
+ALTER TABLE payroll_payment_settings ADD COLUMN IF NOT EXISTS debtor_iban_enc BYTEA;
+ALTER TABLE payroll_payment_settings ADD COLUMN IF NOT EXISTS account_number_enc BYTEA;
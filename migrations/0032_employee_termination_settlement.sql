@@ -0,0 +1,6 @@
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS termination_settled_on DATE;
+
+-- Leave of employees who had already left was settled when their end date
+-- was recorded.
+UPDATE employees SET termination_settled_on = end_date
+WHERE end_date IS NOT NULL AND end_date <= CURRENT_DATE AND termination_settled_on IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE pay_schedules ADD COLUMN IF NOT EXISTS holiday_region TEXT;
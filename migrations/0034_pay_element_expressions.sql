@@ -0,0 +1 @@
+ALTER TABLE pay_elements ADD COLUMN IF NOT EXISTS expression TEXT;
@@ -0,0 +1,9 @@
+ALTER TABLE payroll_settings ADD COLUMN IF NOT EXISTS tax_year_start_month INT NOT NULL DEFAULT 1;
+ALTER TABLE payroll_settings ADD COLUMN IF NOT EXISTS tax_year_start_day INT NOT NULL DEFAULT 1;
+
+-- Finalized periods keep the tax year they were accumulated under, so
+-- reopening one reverses the same accumulators after the start moves.
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS tax_year INT;
+
+UPDATE payroll_periods SET tax_year = EXTRACT(YEAR FROM end_date)::int
+WHERE status = 'finalized' AND tax_year IS NULL;
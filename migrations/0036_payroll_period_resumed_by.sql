@@ -0,0 +1,3 @@
+-- Resuming a failed run keeps run_by, the user who started the run, and
+-- records the user who resumed it here.
+ALTER TABLE payroll_periods ADD COLUMN IF NOT EXISTS resumed_by UUID REFERENCES users(id);